@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -521,6 +522,7 @@ func createApp(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 //	400: Invalid new pool
 //	401: Unauthorized
 //	404: Not found
+//	409: App addresses are swapped
 func updateApp(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	ctx := r.Context()
 	var ia inputApp
@@ -656,6 +658,9 @@ func updateApp(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 	if pkgErrors.Cause(err) == appTypes.ErrPlanNotFound {
 		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
 	}
+	if pkgErrors.Cause(err) == app.ErrAppAddressesSwapped {
+		return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
 	if _, ok := err.(*router.ErrRouterNotFound); ok {
 		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
 	}
@@ -1400,6 +1405,10 @@ func appLog(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 		InvertSource: invert,
 		Units:        units,
 	}
+	err = parseLogQuery(urlValues, &listArgs)
+	if err != nil {
+		return err
+	}
 	logs, err := a.LastLogs(ctx, logService, listArgs)
 	if err != nil {
 		return err
@@ -1750,25 +1759,6 @@ func addLog(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	return nil
 }
 
-// title: app swap
-// path: /swap
-// method: POST
-// consume: application/x-www-form-urlencoded
-// responses:
-//
-//	200: Ok
-//	400: Invalid data
-//	401: Unauthorized
-//	404: App not found
-//	409: App locked
-//	412: Number of units or platform don't match
-func swap(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
-	return &errors.HTTP{
-		Code:    http.StatusPreconditionFailed,
-		Message: "swapping is deprecated",
-	}
-}
-
 // title: app start
 // path: /apps/{app}/start
 // method: POST
@@ -1927,6 +1917,9 @@ func appRebuildRoutes(w http.ResponseWriter, r *http.Request, t auth.Token) (err
 //	400: Invalid data
 //	401: Unauthorized
 //	404: App not found
+//
+// When the managed form field is true, no certificate or key is expected and
+// tsuru obtains and renews the certificate for the cname through ACME.
 func setCertificate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	ctx := r.Context()
 	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
@@ -1945,6 +1938,10 @@ func setCertificate(w http.ResponseWriter, r *http.Request, t auth.Token) (err e
 	if cname == "" {
 		return &errors.HTTP{Code: http.StatusBadRequest, Message: "You must provide a cname."}
 	}
+	managed, _ := strconv.ParseBool(InputValue(r, "managed"))
+	if managed && (certificate != "" || key != "") {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "Managed certificates can't be uploaded."}
+	}
 	evt, err := event.New(ctx, &event.Opts{
 		Target:     appTarget(a.Name),
 		Kind:       permission.PermAppUpdateCertificateSet,
@@ -1957,7 +1954,11 @@ func setCertificate(w http.ResponseWriter, r *http.Request, t auth.Token) (err e
 		return err
 	}
 	defer func() { evt.Done(ctx, err) }()
-	err = a.SetCertificate(ctx, cname, certificate, key)
+	if managed {
+		err = a.SetManagedCertificate(ctx, cname)
+	} else {
+		err = a.SetCertificate(ctx, cname, certificate, key)
+	}
 	if err != nil {
 		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
 	}
@@ -2038,6 +2039,70 @@ func listCertificates(w http.ResponseWriter, r *http.Request, t auth.Token) erro
 	return json.NewEncoder(w).Encode(&result)
 }
 
+// title: list app managed certificates
+// path: /apps/{app}/certificate/managed
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: Ok
+//	204: No content
+//	401: Unauthorized
+//	404: App not found
+func listManagedCertificates(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermAppReadCertificate,
+		contextsForApp(&a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	managed, err := a.ManagedCertificates(ctx)
+	if err != nil {
+		return err
+	}
+	if len(managed) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	result := make([]appTypes.ManagedCertificate, 0, len(managed))
+	for _, cert := range managed {
+		result = append(result, cert)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CName < result[j].CName
+	})
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(result)
+}
+
+// title: answer acme challenge
+// path: /.well-known/acme-challenge/{token}
+// method: GET
+// produce: text/plain
+// responses:
+//
+//	200: Ok
+//	404: Challenge not found
+func acmeChallenge(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get(":token")
+	keyAuth, err := app.ACMEChallengeResponse(r.Context(), token)
+	if err == app.ErrManagedCertificateNotFound {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte(keyAuth))
+}
+
 func contextsForApp(a *app.App) []permTypes.PermissionContext {
 	return append(permission.Contexts(permTypes.CtxTeam, a.Teams),
 		permission.Context(permTypes.CtxApp, a.Name),
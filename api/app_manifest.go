@@ -0,0 +1,256 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/image"
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/service"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	volumeTypes "github.com/tsuru/tsuru/types/volume"
+)
+
+// title: app manifest
+// path: /apps/{app}/manifest
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: App not found
+func appManifest(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(ctx, t, permission.PermAppReadManifest, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	manifest, err := app.ExportManifest(ctx, &a)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(manifest)
+}
+
+// title: app manifest apply
+// path: /apps/{app}/manifest/apply
+// method: POST
+// consume: application/json
+// produce: application/x-json-stream
+// responses:
+//
+//	200: OK
+//	400: Invalid manifest
+//	401: Unauthorized
+//	404: App not found
+func appManifestApply(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	var manifest appTypes.Manifest
+	err = ParseJSON(r, &manifest)
+	if err != nil {
+		return err
+	}
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry-run"))
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(ctx, t, permission.PermAppUpdateManifest, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	plan, err := a.ApplyManifest(ctx, app.ApplyManifestArgs{Manifest: manifest, DryRun: true})
+	if err == service.ErrServiceInstanceNotFound || err == volumeTypes.ErrVolumeNotFound {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	err = checkManifestPermissions(ctx, t, &a, &manifest, plan)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		w.Header().Set("Content-Type", "application/json")
+		return json.NewEncoder(w).Encode(plan)
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateManifest,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: manifest,
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	plan, err = a.ApplyManifest(ctx, app.ApplyManifestArgs{
+		Manifest:  manifest,
+		Writer:    evt,
+		Event:     evt,
+		RequestID: requestIDHeader(r),
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(evt, "\nManifest applied to app %q with %d changes.\n", appName, len(plan.Changes))
+	return nil
+}
+
+// manifestPermissions holds the permissions required by each kind of change
+// by action, the same ones required when changing the app through the
+// specific handlers.
+var manifestPermissions = map[string]map[appTypes.ManifestAction]*permission.PermissionScheme{
+	appTypes.ManifestKindEnv: {
+		appTypes.ManifestActionAdd:    permission.PermAppUpdateEnvSet,
+		appTypes.ManifestActionUpdate: permission.PermAppUpdateEnvSet,
+		appTypes.ManifestActionRemove: permission.PermAppUpdateEnvUnset,
+	},
+	appTypes.ManifestKindCName: {
+		appTypes.ManifestActionAdd:    permission.PermAppUpdateCnameAdd,
+		appTypes.ManifestActionRemove: permission.PermAppUpdateCnameRemove,
+	},
+	appTypes.ManifestKindRouter: {
+		appTypes.ManifestActionAdd:    permission.PermAppUpdateRouterAdd,
+		appTypes.ManifestActionUpdate: permission.PermAppUpdateRouterUpdate,
+		appTypes.ManifestActionRemove: permission.PermAppUpdateRouterRemove,
+	},
+	appTypes.ManifestKindUnits: {
+		appTypes.ManifestActionAdd:    permission.PermAppUpdateUnitAdd,
+		appTypes.ManifestActionRemove: permission.PermAppUpdateUnitRemove,
+	},
+	appTypes.ManifestKindAutoscale: {
+		appTypes.ManifestActionAdd:    permission.PermAppUpdateUnitAutoscaleAdd,
+		appTypes.ManifestActionUpdate: permission.PermAppUpdateUnitAutoscaleAdd,
+		appTypes.ManifestActionRemove: permission.PermAppUpdateUnitAutoscaleRemove,
+	},
+}
+
+// manifestFieldPermissions holds the permissions required to change each
+// field of the app, as checked by updateApp.
+var manifestFieldPermissions = map[string][]*permission.PermissionScheme{
+	"description": {permission.PermAppUpdateDescription},
+	"platform":    {permission.PermAppUpdatePlatform, permission.PermAppUpdateImageReset},
+	"pool":        {permission.PermAppUpdatePool},
+	"teamOwner":   {permission.PermAppUpdateTeamowner},
+	"plan":        {permission.PermAppUpdatePlan},
+	"tags":        {permission.PermAppUpdateTags},
+	"metadata":    {permission.PermAppUpdateMetadata},
+	"processes":   {permission.PermAppUpdateProcesses},
+}
+
+// checkManifestPermissions ensures the token is allowed to make each change
+// in the manifest plan, app.update.manifest alone only allows applying
+// manifests that are already in effect.
+func checkManifestPermissions(ctx context.Context, t auth.Token, a *app.App, manifest *appTypes.Manifest, plan *appTypes.ManifestPlan) error {
+	for _, change := range plan.Changes {
+		var wantedPerms []*permission.PermissionScheme
+		if change.Kind == appTypes.ManifestKindApp {
+			for _, field := range change.Fields {
+				wantedPerms = append(wantedPerms, manifestFieldPermissions[field]...)
+				if field == "platform" {
+					err := checkManifestPlatform(ctx, t, manifest.Platform)
+					if err != nil {
+						return err
+					}
+				}
+			}
+		} else if perm := manifestPermissions[change.Kind][change.Action]; perm != nil {
+			wantedPerms = append(wantedPerms, perm)
+		}
+		for _, perm := range wantedPerms {
+			if !permission.Check(ctx, t, perm, contextsForApp(a)...) {
+				return permission.ErrUnauthorized
+			}
+		}
+	}
+	return checkManifestBindPermissions(ctx, t, plan)
+}
+
+// checkManifestPlatform ensures only platform admins set disabled platforms.
+func checkManifestPlatform(ctx context.Context, t auth.Token, platformName string) error {
+	repo, _ := image.SplitImageName(platformName)
+	platform, err := servicemanager.Platform.FindByName(ctx, repo)
+	if err != nil {
+		return err
+	}
+	if platform.Disabled {
+		canUsePlat := permission.Check(ctx, t, permission.PermPlatformUpdate) ||
+			permission.Check(ctx, t, permission.PermPlatformCreate)
+		if !canUsePlat {
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: appTypes.ErrInvalidPlatform.Error()}
+		}
+	}
+	return nil
+}
+
+// checkManifestBindPermissions ensures the token is allowed to change the
+// service instances and volumes bound by the manifest plan, as those are
+// checked against the bound resources instead of the app.
+func checkManifestBindPermissions(ctx context.Context, t auth.Token, plan *appTypes.ManifestPlan) error {
+	for _, change := range plan.Changes {
+		switch change.Kind {
+		case appTypes.ManifestKindServiceBind:
+			serviceName, instanceName, _ := strings.Cut(change.Name, "/")
+			instance, err := service.GetServiceInstance(ctx, serviceName, instanceName)
+			if err != nil {
+				return err
+			}
+			scheme := permission.PermServiceInstanceUpdateBind
+			if change.Action == appTypes.ManifestActionRemove {
+				scheme = permission.PermServiceInstanceUpdateUnbind
+			}
+			allowed := permission.Check(ctx, t, scheme,
+				append(permission.Contexts(permTypes.CtxTeam, instance.Teams),
+					permission.Context(permTypes.CtxTeam, instance.TeamOwner),
+					permission.Context(permTypes.CtxServiceInstance, instance.Name),
+				)...,
+			)
+			if !allowed {
+				return permission.ErrUnauthorized
+			}
+		case appTypes.ManifestKindVolumeBind:
+			volumeName, _, _ := strings.Cut(change.Name, ":")
+			v, err := servicemanager.Volume.Get(ctx, volumeName)
+			if err != nil {
+				return err
+			}
+			scheme := permission.PermVolumeUpdateBind
+			if change.Action == appTypes.ManifestActionRemove {
+				scheme = permission.PermVolumeUpdateUnbind
+			}
+			if !permission.Check(ctx, t, scheme, contextsForVolume(v)...) {
+				return permission.ErrUnauthorized
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppManifest(c *check.C) {
+	a := app.App{
+		Name:      "leper",
+		Platform:  "zend",
+		TeamOwner: s.team.Name,
+		Tags:      []string{"tag1"},
+		Env: map[string]bindTypes.EnvVar{
+			"PUBLIC":  {Name: "PUBLIC", Value: "1", Public: true},
+			"PRIVATE": {Name: "PRIVATE", Value: "secret"},
+		},
+	}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", fmt.Sprintf("/apps/%s/manifest", a.Name), nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var manifest appTypes.Manifest
+	err = json.Unmarshal(recorder.Body.Bytes(), &manifest)
+	c.Assert(err, check.IsNil)
+	c.Assert(manifest.Name, check.Equals, "leper")
+	c.Assert(manifest.Platform, check.Equals, "zend")
+	c.Assert(manifest.TeamOwner, check.Equals, s.team.Name)
+	c.Assert(manifest.Tags, check.DeepEquals, []string{"tag1"})
+	c.Assert(manifest.Env, check.DeepEquals, map[string]string{"PUBLIC": "1"})
+}
+
+func (s *S) TestAppManifestWithoutPermission(c *check.C) {
+	a := app.App{Name: "leper", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "reader", permission.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permTypes.CtxApp, a.Name),
+	})
+	request, err := http.NewRequest("GET", fmt.Sprintf("/apps/%s/manifest", a.Name), nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) exportManifest(c *check.C, appName string) appTypes.Manifest {
+	a, err := app.GetByName(context.TODO(), appName)
+	c.Assert(err, check.IsNil)
+	manifest, err := app.ExportManifest(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	return *manifest
+}
+
+func (s *S) TestAppManifestApplyDryRun(c *check.C) {
+	a := app.App{Name: "leper", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	manifest := s.exportManifest(c, a.Name)
+	manifest.Description = "my app"
+	manifest.Env = map[string]string{"FOO": "bar"}
+	data, err := json.Marshal(manifest)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", fmt.Sprintf("/apps/%s/manifest/apply?dry-run=true", a.Name), bytes.NewReader(data))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var plan appTypes.ManifestPlan
+	err = json.Unmarshal(recorder.Body.Bytes(), &plan)
+	c.Assert(err, check.IsNil)
+	c.Assert(plan, check.DeepEquals, appTypes.ManifestPlan{
+		App:    "leper",
+		DryRun: true,
+		Changes: []appTypes.ManifestChange{
+			{Kind: appTypes.ManifestKindApp, Action: appTypes.ManifestActionUpdate, Name: "leper", Detail: "description", Fields: []string{"description"}},
+			{Kind: appTypes.ManifestKindEnv, Action: appTypes.ManifestActionAdd, Name: "FOO"},
+		},
+	})
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Description, check.Equals, "")
+	c.Assert(dbApp.Env, check.HasLen, 0)
+}
+
+func (s *S) TestAppManifestApply(c *check.C) {
+	a := app.App{
+		Name:      "leper",
+		Platform:  "zend",
+		TeamOwner: s.team.Name,
+		Env: map[string]bindTypes.EnvVar{
+			"OLD": {Name: "OLD", Value: "1", Public: true},
+		},
+	}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	manifest := s.exportManifest(c, a.Name)
+	manifest.Description = "my app"
+	manifest.Env = map[string]string{"FOO": "bar"}
+	manifest.CNames = []string{"leper.example.com"}
+	data, err := json.Marshal(manifest)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", fmt.Sprintf("/apps/%s/manifest/apply", a.Name), bytes.NewReader(data))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/x-json-stream")
+	c.Assert(recorder.Body.String(), check.Matches, `(?s).*Manifest applied to app \\"leper\\" with 4 changes.*`)
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Description, check.Equals, "my app")
+	c.Assert(dbApp.CName, check.DeepEquals, []string{"leper.example.com"})
+	c.Assert(dbApp.Env, check.DeepEquals, map[string]bindTypes.EnvVar{
+		"FOO": {Name: "FOO", Value: "bar", Public: true},
+	})
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.manifest",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestAppManifestApplyNameMismatch(c *check.C) {
+	a := app.App{Name: "leper", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	data, err := json.Marshal(appTypes.Manifest{Name: "other"})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", fmt.Sprintf("/apps/%s/manifest/apply", a.Name), bytes.NewReader(data))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "manifest name \"other\" doesn't match app \"leper\"\n")
+}
+
+func (s *S) TestAppManifestApplyWithoutPermission(c *check.C) {
+	a := app.App{Name: "leper", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "envsetter", permission.Permission{
+		Scheme:  permission.PermAppUpdateEnvSet,
+		Context: permission.Context(permTypes.CtxApp, a.Name),
+	})
+	data, err := json.Marshal(appTypes.Manifest{Env: map[string]string{"FOO": "bar"}})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", fmt.Sprintf("/apps/%s/manifest/apply", a.Name), bytes.NewReader(data))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "b "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestAppManifestApplyRequiresChangePermissions(c *check.C) {
+	a := app.App{
+		Name:      "leper",
+		Platform:  "zend",
+		TeamOwner: s.team.Name,
+		CName:     []string{"leper.example.com"},
+		Env: map[string]bindTypes.EnvVar{
+			"OLD": {Name: "OLD", Value: "1", Public: true},
+		},
+	}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.provisioner.AddUnits(context.TODO(), &a, 2, "web", nil, nil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "manifester", permission.Permission{
+		Scheme:  permission.PermAppUpdateManifest,
+		Context: permission.Context(permTypes.CtxApp, a.Name),
+	})
+	tests := []struct {
+		name   string
+		change func(m *appTypes.Manifest)
+	}{
+		{"env set", func(m *appTypes.Manifest) { m.Env["FOO"] = "bar" }},
+		{"env unset", func(m *appTypes.Manifest) { m.Env = nil }},
+		{"cname add", func(m *appTypes.Manifest) { m.CNames = append(m.CNames, "other.example.com") }},
+		{"cname remove", func(m *appTypes.Manifest) { m.CNames = nil }},
+		{"router add", func(m *appTypes.Manifest) { m.Routers = append(m.Routers, appTypes.ManifestRouter{Name: "fake-tls"}) }},
+		{"router update", func(m *appTypes.Manifest) { m.Routers[0].Opts = map[string]string{"a": "b"} }},
+		{"router remove", func(m *appTypes.Manifest) { m.Routers = nil }},
+		{"unit add", func(m *appTypes.Manifest) { m.Units = map[string]uint{"web": 3} }},
+		{"unit remove", func(m *appTypes.Manifest) { m.Units = map[string]uint{"web": 1} }},
+		{"autoscale", func(m *appTypes.Manifest) {
+			m.Autoscale = append(m.Autoscale, provTypes.AutoScaleSpec{Process: "worker", AverageCPU: "300m", MinUnits: 1, MaxUnits: 3})
+		}},
+		{"description", func(m *appTypes.Manifest) { m.Description = "my app" }},
+		{"pool", func(m *appTypes.Manifest) { m.Pool = "other-pool" }},
+		{"team owner", func(m *appTypes.Manifest) { m.TeamOwner = "other-team" }},
+		{"plan", func(m *appTypes.Manifest) { m.Plan = "other-plan" }},
+		{"platform", func(m *appTypes.Manifest) { m.Platform = "python" }},
+	}
+	for _, tt := range tests {
+		manifest := s.exportManifest(c, a.Name)
+		c.Assert(manifest.Routers, check.Not(check.HasLen), 0)
+		tt.change(&manifest)
+		data, err := json.Marshal(manifest)
+		c.Assert(err, check.IsNil)
+		request, err := http.NewRequest("POST", fmt.Sprintf("/apps/%s/manifest/apply", a.Name), bytes.NewReader(data))
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "b "+token.GetValue())
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusForbidden, check.Commentf("%s: %s", tt.name, recorder.Body.String()))
+	}
+	manifest := s.exportManifest(c, a.Name)
+	data, err := json.Marshal(manifest)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", fmt.Sprintf("/apps/%s/manifest/apply?dry-run=true", a.Name), bytes.NewReader(data))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "b "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+}
+
+func (s *S) TestAppManifestApplyDisabledPlatform(c *check.C) {
+	a := app.App{Name: "leper", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.mockService.Platform.OnFindByName = func(name string) (*appTypes.Platform, error) {
+		return &appTypes.Platform{Name: name, Disabled: name == "python"}, nil
+	}
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "manifester", permission.Permission{
+		Scheme:  permission.PermAppUpdate,
+		Context: permission.Context(permTypes.CtxApp, a.Name),
+	})
+	manifest := s.exportManifest(c, a.Name)
+	manifest.Platform = "python"
+	data, err := json.Marshal(manifest)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", fmt.Sprintf("/apps/%s/manifest/apply", a.Name), bytes.NewReader(data))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "b "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, appTypes.ErrInvalidPlatform.Error()+"\n")
+}
@@ -5078,6 +5078,72 @@ func (s *S) TestAppLogShouldReturnLogByApp(c *check.C) {
 	c.Assert(logged, check.Equals, true)
 }
 
+func (s *S) TestAppLogFilterByMessageAndFields(c *check.C) {
+	a := app.App{Name: "lost", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	servicemanager.LogService.Add(a.Name, "request timeout", "web", "")
+	servicemanager.LogService.Add(a.Name, "request ok", "web", "")
+	servicemanager.LogService.Add(a.Name, `{"level": "error", "msg": "db timeout"}`, "web", "")
+	servicemanager.LogService.Add(a.Name, `{"level": "info", "msg": "timeout retried"}`, "web", "")
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppReadLog,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{query: "message=timeout", expected: []string{"request timeout", `{"level": "error", "msg": "db timeout"}`, `{"level": "info", "msg": "timeout retried"}`}},
+		{query: "message-regex=%5Erequest", expected: []string{"request timeout", "request ok"}},
+		{query: "message=timeout&field=level%3Derror", expected: []string{`{"level": "error", "msg": "db timeout"}`}},
+		{query: "since=2000-01-01T00:00:00Z&until=2001-01-01T00:00:00Z", expected: nil},
+	}
+	for _, tt := range tests {
+		url := fmt.Sprintf("/apps/%s/log/?:app=%s&lines=10&%s", a.Name, a.Name, tt.query)
+		request, err := http.NewRequest("GET", url, nil)
+		c.Assert(err, check.IsNil)
+		recorder := httptest.NewRecorder()
+		err = appLog(recorder, request, token)
+		c.Assert(err, check.IsNil)
+		logs := []appTypes.Applog{}
+		err = json.Unmarshal(recorder.Body.Bytes(), &logs)
+		c.Assert(err, check.IsNil)
+		var messages []string
+		for _, l := range logs {
+			messages = append(messages, l.Message)
+		}
+		c.Check(messages, check.DeepEquals, tt.expected, check.Commentf("query %q", tt.query))
+	}
+}
+
+func (s *S) TestAppLogInvalidFilters(c *check.C) {
+	a := app.App{Name: "lost", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	tests := []struct {
+		query   string
+		message string
+	}{
+		{query: "since=yesterday", message: `Parameter "since" must be a RFC3339 date.`},
+		{query: "since=2001-01-01T00:00:00Z&until=2000-01-01T00:00:00Z", message: `Parameter "until" must be after "since".`},
+		{query: "field=level", message: `Invalid field filter "level", expected <name>=<value>.`},
+		{query: "message-regex=%28a", message: "invalid message regex: .*"},
+	}
+	for _, tt := range tests {
+		url := fmt.Sprintf("/apps/%s/log/?:app=%s&lines=10&%s", a.Name, a.Name, tt.query)
+		request, err := http.NewRequest("GET", url, nil)
+		c.Assert(err, check.IsNil)
+		recorder := httptest.NewRecorder()
+		err = appLog(recorder, request, s.token)
+		c.Assert(err, check.NotNil)
+		e, ok := err.(*errors.HTTP)
+		c.Assert(ok, check.Equals, true)
+		c.Check(e.Code, check.Equals, http.StatusBadRequest)
+		c.Check(e.Message, check.Matches, tt.message)
+	}
+}
+
 func (s *S) TestBindHandlerEndpointIsDown(c *check.C) {
 	srvc := service.Service{Name: "mysql", Endpoint: map[string]string{"production": "http://localhost:1234"}, Password: "abcde", OwnerTeams: []string{s.team.Name}}
 	err := service.Create(context.TODO(), srvc)
@@ -6234,24 +6300,6 @@ func (s *S) TestGetApp(c *check.C) {
 	c.Assert(app, check.DeepEquals, *expected)
 }
 
-func (s *S) TestSwapDeprecated(c *check.C) {
-	app1 := app.App{Name: "app1", Platform: "x", TeamOwner: s.team.Name}
-	err := app.CreateApp(context.TODO(), &app1, s.user)
-	c.Assert(err, check.IsNil)
-	app2 := app.App{Name: "app2", Platform: "y", TeamOwner: s.team.Name}
-	err = app.CreateApp(context.TODO(), &app2, s.user)
-	c.Assert(err, check.IsNil)
-	b := strings.NewReader("app1=app1&app2=app2&force=true&cnameOnly=false")
-	request, err := http.NewRequest("POST", "/swap", b)
-	c.Assert(err, check.IsNil)
-	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	request.Header.Set("Authorization", "b "+s.token.GetValue())
-	recorder := httptest.NewRecorder()
-	s.testServer.ServeHTTP(recorder, request)
-	c.Assert(recorder.Code, check.Equals, http.StatusPreconditionFailed)
-	c.Assert(recorder.Body.String(), check.Equals, "swapping is deprecated\n")
-}
-
 func (s *S) TestStartHandler(c *check.C) {
 	config.Set("docker:router", "fake")
 	defer config.Unset("docker:router")
@@ -6537,6 +6585,92 @@ func (s *S) TestListCertificates(c *check.C) {
 	})
 }
 
+func (s *S) TestSetCertificateManaged(c *check.C) {
+	config.Set("acme:directory-url", "http://localhost/directory")
+	defer config.Unset("acme")
+	a := app.App{Name: "myapp", TeamOwner: s.team.Name, CName: []string{"app.io"}, Router: "fake-tls"}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	v := url.Values{}
+	v.Set("cname", "app.io")
+	v.Set("managed", "true")
+	request, err := http.NewRequest("PUT", "/apps/myapp/certificate", strings.NewReader(v.Encode()))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	certs, err := a.ManagedCertificates(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(certs["app.io"].Status, check.Equals, appTypes.ManagedCertificatePending)
+	request, err = http.NewRequest("GET", "/1.24/apps/myapp/certificate/managed", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var managed []appTypes.ManagedCertificate
+	err = json.Unmarshal(recorder.Body.Bytes(), &managed)
+	c.Assert(err, check.IsNil)
+	c.Assert(managed, check.HasLen, 1)
+	c.Assert(managed[0].CName, check.Equals, "app.io")
+	c.Assert(managed[0].Status, check.Equals, appTypes.ManagedCertificatePending)
+}
+
+func (s *S) TestListManagedCertificatesEmpty(c *check.C) {
+	a := app.App{Name: "myapp", TeamOwner: s.team.Name, CName: []string{"app.io"}, Router: "fake-tls"}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.24/apps/myapp/certificate/managed", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestSetCertificateManagedWithCertificate(c *check.C) {
+	a := app.App{Name: "myapp", TeamOwner: s.team.Name, CName: []string{"app.io"}, Router: "fake-tls"}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	v := url.Values{}
+	v.Set("cname", "app.io")
+	v.Set("managed", "true")
+	v.Set("certificate", testCert)
+	request, err := http.NewRequest("PUT", "/apps/myapp/certificate", strings.NewReader(v.Encode()))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "Managed certificates can't be uploaded.\n")
+}
+
+func (s *S) TestACMEChallenge(c *check.C) {
+	collection, err := storagev2.ManagedCertificatesCollection()
+	c.Assert(err, check.IsNil)
+	_, err = collection.InsertOne(context.TODO(), appTypes.ManagedCertificate{
+		App:       "myapp",
+		CName:     "app.io",
+		Status:    appTypes.ManagedCertificatePending,
+		Challenge: &appTypes.ACMEChallenge{Token: "tok3n", KeyAuthorization: "tok3n.thumb"},
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/.well-known/acme-challenge/tok3n", nil)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Body.String(), check.Equals, "tok3n.thumb")
+	request, err = http.NewRequest("GET", "/.well-known/acme-challenge/other", nil)
+	c.Assert(err, check.IsNil)
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
 type fakeEncoder struct {
 	done chan struct{}
 	msg  interface{}
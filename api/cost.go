@@ -0,0 +1,166 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/cost"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/pool"
+	costTypes "github.com/tsuru/tsuru/types/cost"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+// title: pool price
+// path: /pools/{name}/price
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: Price not found
+func poolPriceGet(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	poolName := r.URL.Query().Get(":name")
+	if !permission.Check(ctx, t, permission.PermPoolReadPrice, permission.Context(permTypes.CtxPool, poolName)) {
+		return permission.ErrUnauthorized
+	}
+	price, err := cost.GetPrice(ctx, poolName)
+	if err == costTypes.ErrPriceNotFound {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(price)
+}
+
+// title: set pool price
+// path: /pools/{name}/price
+// method: PUT
+// consume: application/json
+// responses:
+//
+//	200: Price updated
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Pool not found
+func poolPriceSet(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	poolName := r.URL.Query().Get(":name")
+	if !permission.Check(ctx, t, permission.PermPoolUpdatePrice, permission.Context(permTypes.CtxPool, poolName)) {
+		return permission.ErrUnauthorized
+	}
+	var price costTypes.Price
+	err = ParseJSON(r, &price)
+	if err != nil {
+		return err
+	}
+	price.Pool = poolName
+	_, err = pool.GetPoolByName(ctx, poolName)
+	if err == pool.ErrPoolNotFound {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypePool, Value: poolName},
+		Kind:       permission.PermPoolUpdatePrice,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: price,
+		Allowed:    event.Allowed(permission.PermPoolReadEvents, permission.Context(permTypes.CtxPool, poolName)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	return cost.SetPrice(ctx, price)
+}
+
+// title: cost report
+// path: /reports/cost
+// method: GET
+// produce: application/json, text/csv
+// responses:
+//
+//	200: OK
+//	400: Invalid data
+//	401: Unauthorized
+func costReport(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	query := r.URL.Query()
+	opts := costTypes.ReportOpts{GroupBy: query["groupBy"]}
+	isGlobal := false
+	for _, c := range permission.ContextsForPermission(ctx, t, permission.PermTeamReadCost) {
+		if c.CtxType == permTypes.CtxGlobal {
+			isGlobal = true
+			break
+		}
+		if c.CtxType == permTypes.CtxTeam {
+			opts.Teams = append(opts.Teams, c.Value)
+		}
+	}
+	if !isGlobal && len(opts.Teams) == 0 {
+		return permission.ErrUnauthorized
+	}
+	if isGlobal {
+		opts.Teams = nil
+	}
+	if team := query.Get("team"); team != "" {
+		if !permission.Check(ctx, t, permission.PermTeamReadCost, permission.Context(permTypes.CtxTeam, team)) {
+			return permission.ErrUnauthorized
+		}
+		opts.Teams = []string{team}
+	}
+	var err error
+	opts.Start, err = parseReportTime(query.Get("start"))
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid start: %v", err)}
+	}
+	opts.End, err = parseReportTime(query.Get("end"))
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid end: %v", err)}
+	}
+	if opts.End.IsZero() {
+		opts.End = time.Now().UTC()
+	}
+	if opts.Start.IsZero() {
+		opts.Start = opts.End.AddDate(0, -1, 0)
+	}
+	entries, err := cost.Report(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if query.Get("format") == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		return cost.WriteCSV(w, opts.GroupBy, entries)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(entries)
+}
+
+// parseReportTime accepts either a date, interpreted as midnight UTC, or a
+// RFC3339 timestamp. An empty value results in the zero time.
+func parseReportTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,179 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/tsuru/tsuru/cost"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/pool"
+	costTypes "github.com/tsuru/tsuru/types/cost"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestPoolPriceSet(c *check.C) {
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader(`{"cpuCoreHour": 0.04, "memoryGiBHour": 0.005, "storageGiBHour": {"nfs": 0.0001}}`)
+	req, err := http.NewRequest(http.MethodPut, "/pools/pool1/price", body)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	price, err := cost.GetPrice(context.TODO(), "pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(*price, check.DeepEquals, costTypes.Price{
+		Pool:           "pool1",
+		CPUCoreHour:    0.04,
+		MemoryGiBHour:  0.005,
+		StorageGiBHour: map[string]float64{"nfs": 0.0001},
+	})
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypePool, Value: "pool1"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "pool.update.price",
+	}, eventtest.HasEvent)
+	req, err = http.NewRequest(http.MethodGet, "/pools/pool1/price", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec = httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	var result costTypes.Price
+	err = json.Unmarshal(rec.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, *price)
+}
+
+func (s *S) TestPoolPriceSetPoolNotFound(c *check.C) {
+	body := strings.NewReader(`{"cpuCoreHour": 0.04}`)
+	req, err := http.NewRequest(http.MethodPut, "/pools/pool1/price", body)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestPoolPriceSetInvalid(c *check.C) {
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader(`{"cpuCoreHour": -1}`)
+	req, err := http.NewRequest(http.MethodPut, "/pools/pool1/price", body)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(rec.Body.String(), check.Equals, "prices cannot be negative\n")
+}
+
+func (s *S) TestPoolPriceGetNotFound(c *check.C) {
+	req, err := http.NewRequest(http.MethodGet, "/pools/pool1/price", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) insertCostSamples(c *check.C, samples ...costTypes.Sample) {
+	collection, err := storagev2.CostSamplesCollection()
+	c.Assert(err, check.IsNil)
+	for _, sample := range samples {
+		_, err = collection.InsertOne(context.TODO(), sample)
+		c.Assert(err, check.IsNil)
+	}
+}
+
+func (s *S) TestCostReport(c *check.C) {
+	s.insertCostSamples(c,
+		costTypes.Sample{Time: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC), Team: "team1", Pool: "pool1", App: "app1", Usage: costTypes.Usage{UnitHours: 1, Cost: 2}},
+		costTypes.Sample{Time: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC), Team: "team1", Pool: "pool1", App: "app2", Usage: costTypes.Usage{UnitHours: 1, Cost: 3}},
+		costTypes.Sample{Time: time.Date(2026, 3, 5, 10, 0, 0, 0, time.UTC), Team: "team1", Pool: "pool1", App: "app1", Usage: costTypes.Usage{UnitHours: 1, Cost: 7}},
+	)
+	req, err := http.NewRequest(http.MethodGet, "/reports/cost?start=2026-03-01&end=2026-03-03&groupBy=team&groupBy=app", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	c.Assert(rec.Header().Get("Content-Type"), check.Equals, "application/json")
+	var entries []costTypes.ReportEntry
+	err = json.Unmarshal(rec.Body.Bytes(), &entries)
+	c.Assert(err, check.IsNil)
+	c.Assert(entries, check.DeepEquals, []costTypes.ReportEntry{
+		{Team: "team1", App: "app1", Usage: costTypes.Usage{UnitHours: 1, Cost: 2}},
+		{Team: "team1", App: "app2", Usage: costTypes.Usage{UnitHours: 1, Cost: 3}},
+	})
+}
+
+func (s *S) TestCostReportCSV(c *check.C) {
+	s.insertCostSamples(c,
+		costTypes.Sample{Time: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC), Team: "team1", Pool: "pool1", App: "app1", Usage: costTypes.Usage{UnitHours: 1, Cost: 2}},
+	)
+	req, err := http.NewRequest(http.MethodGet, "/reports/cost?start=2026-03-01T00:00:00Z&end=2026-03-02T00:00:00Z&groupBy=pool&format=csv", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	c.Assert(rec.Header().Get("Content-Type"), check.Equals, "text/csv")
+	c.Assert(rec.Body.String(), check.Equals, "pool,unit_hours,cpu_core_hours,memory_gib_hours,storage_gib_hours,cost\n"+
+		"pool1,1,0,0,0,2\n")
+}
+
+func (s *S) TestCostReportOnlyAllowedTeams(c *check.C) {
+	s.insertCostSamples(c,
+		costTypes.Sample{Time: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC), Team: "team1", Pool: "pool1", App: "app1", Usage: costTypes.Usage{UnitHours: 1, Cost: 2}},
+		costTypes.Sample{Time: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC), Team: "team2", Pool: "pool1", App: "app2", Usage: costTypes.Usage{UnitHours: 1, Cost: 3}},
+	)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermTeamReadCost,
+		Context: permission.Context(permTypes.CtxTeam, "team2"),
+	})
+	req, err := http.NewRequest(http.MethodGet, "/reports/cost?start=2026-03-01&end=2026-03-02", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	var entries []costTypes.ReportEntry
+	err = json.Unmarshal(rec.Body.Bytes(), &entries)
+	c.Assert(err, check.IsNil)
+	c.Assert(entries, check.DeepEquals, []costTypes.ReportEntry{
+		{Team: "team2", Usage: costTypes.Usage{UnitHours: 1, Cost: 3}},
+	})
+	req, err = http.NewRequest(http.MethodGet, "/reports/cost?start=2026-03-01&end=2026-03-02&team=team1", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	rec = httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestCostReportInvalidDate(c *check.C) {
+	req, err := http.NewRequest(http.MethodGet, "/reports/cost?start=yesterday", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(rec.Body.String(), check.Matches, "invalid start: .*\n")
+}
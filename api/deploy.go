@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -489,6 +490,82 @@ func deployRebuild(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	return nil
 }
 
+// title: canary deploy
+// path: /apps/{app}/deploy/canary
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/x-json-stream
+// responses:
+//
+//	200: OK
+//	400: Invalid data
+//	403: Forbidden
+//	404: Not found
+func deployCanary(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	appName := r.URL.Query().Get(":app")
+	instance, err := app.GetByName(ctx, appName)
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: fmt.Sprintf("App %s not found.", appName)}
+	}
+	canDeploy := permission.Check(ctx, t, permission.PermAppDeployCanary, contextsForApp(instance)...)
+	if !canDeploy {
+		return &tsuruErrors.HTTP{Code: http.StatusForbidden, Message: permission.ErrUnauthorized.Error()}
+	}
+	opts := app.CanaryOptions{
+		Version: InputValue(r, "version"),
+	}
+	if opts.Version == "" {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "you must specify the version"}
+	}
+	if steps := InputValue(r, "steps"); steps != "" {
+		for _, step := range strings.Split(steps, ",") {
+			var weight int
+			weight, err = strconv.Atoi(strings.TrimSpace(step))
+			if err != nil {
+				return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid canary step %q", step)}
+			}
+			opts.Steps = append(opts.Steps, weight)
+		}
+	}
+	if interval := InputValue(r, "step-interval"); interval != "" {
+		opts.StepInterval, err = time.ParseDuration(interval)
+		if err != nil {
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid step interval: %v", err)}
+		}
+	}
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	opts.OutputStream = &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:        appTarget(appName),
+		Kind:          permission.PermAppDeployCanary,
+		Owner:         t,
+		RemoteAddr:    r.RemoteAddr,
+		CustomData:    event.FormToCustomData(InputFields(r)),
+		Allowed:       event.Allowed(permission.PermAppReadEvents, contextsForApp(instance)...),
+		AllowedCancel: event.Allowed(permission.PermAppUpdateEvents, contextsForApp(instance)...),
+		Cancelable:    true,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	w.Header().Set(eventIDHeader, evt.UniqueID.Hex())
+	ctx, cancel := evt.CancelableContext(ctx)
+	defer cancel()
+	opts.Event = evt
+	err = app.CanaryDeploy(ctx, instance, opts)
+	if err != nil {
+		if err == app.ErrCanaryInvalidSteps {
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+		}
+		return err
+	}
+	return nil
+}
+
 // title: rollback update
 // path: /apps/{app}/deploy/rollback/update
 // method: PUT
@@ -560,3 +637,73 @@ func deployRollbackUpdate(w http.ResponseWriter, r *http.Request, t auth.Token)
 	}
 	return err
 }
+
+// title: deploy approve
+// path: /deploys/{deploy}/approve
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//
+//	200: Deploy approved
+//	400: Deploy is not pending approval
+//	403: Forbidden
+//	404: Not found
+func deployApprove(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	return deployDecideApproval(r, t, true)
+}
+
+// title: deploy reject
+// path: /deploys/{deploy}/reject
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//
+//	200: Deploy rejected
+//	400: Deploy is not pending approval
+//	403: Forbidden
+//	404: Not found
+func deployReject(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	return deployDecideApproval(r, t, false)
+}
+
+func deployDecideApproval(r *http.Request, t auth.Token, approve bool) (err error) {
+	ctx := r.Context()
+	depID := r.URL.Query().Get(":deploy")
+	deployEvt, err := event.GetByHexID(ctx, depID)
+	if err != nil || deployEvt.Kind.Name != permission.PermAppDeploy.FullName() {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: "Deploy not found."}
+	}
+	instance, err := app.GetByName(ctx, deployEvt.Target.Value)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(ctx, t, permission.PermAppDeployApprove, contextsForApp(instance)...) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:      appTarget(instance.Name),
+		Kind:        permission.PermAppDeployApprove,
+		Owner:       t,
+		RemoteAddr:  r.RemoteAddr,
+		CustomData:  event.FormToCustomData(InputFields(r)),
+		Allowed:     event.Allowed(permission.PermAppReadEvents, contextsForApp(instance)...),
+		DisableLock: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	reason := InputValue(r, "reason")
+	if approve {
+		err = deployEvt.Approve(ctx, event.OwnerFromToken(t), reason)
+	} else {
+		err = deployEvt.Reject(ctx, event.OwnerFromToken(t), reason)
+	}
+	switch err {
+	case event.ErrApprovalByOwner:
+		return &tsuruErrors.HTTP{Code: http.StatusForbidden, Message: err.Error()}
+	case event.ErrNotPendingApproval:
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return err
+}
@@ -41,6 +41,7 @@ import (
 	permTypes "github.com/tsuru/tsuru/types/permission"
 	provTypes "github.com/tsuru/tsuru/types/provision"
 	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"golang.org/x/crypto/bcrypt"
 	check "gopkg.in/check.v1"
 )
@@ -1792,3 +1793,175 @@ func (s *DeploySuite) TestJobDeployFailed(c *check.C) {
 		ErrorMatches: "Some fake error during Build",
 	}, eventtest.HasEvent)
 }
+
+func (s *DeploySuite) TestDeployCanaryInvalidSteps(c *check.C) {
+	a := app.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	v := url.Values{}
+	v.Set("version", "1")
+	v.Set("steps", "50,10")
+	u := fmt.Sprintf("/apps/%s/deploy/canary", a.Name)
+	request, err := http.NewRequest("POST", u, strings.NewReader(v.Encode()))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, app.ErrCanaryInvalidSteps.Error()+"\n")
+}
+
+func (s *DeploySuite) TestDeployCanaryWithoutVersion(c *check.C) {
+	a := app.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	u := fmt.Sprintf("/apps/%s/deploy/canary", a.Name)
+	request, err := http.NewRequest("POST", u, strings.NewReader(""))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "you must specify the version\n")
+}
+
+func (s *DeploySuite) TestDeployCanaryAppNotFound(c *check.C) {
+	request, err := http.NewRequest("POST", "/apps/unknown/deploy/canary", strings.NewReader("version=1"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *DeploySuite) createPendingDeploy(c *check.C, a *app.App) *event.Event {
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:  eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: event.Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.RequestApproval(context.TODO(), time.Hour)
+	c.Assert(err, check.IsNil)
+	return evt
+}
+
+func (s *DeploySuite) TestDeployApprove(c *check.C) {
+	a := app.App{Name: "g1", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	evt := s.createPendingDeploy(c, &a)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "approver", permission.Permission{
+		Scheme:  permission.PermAppDeployApprove,
+		Context: permission.Context(permTypes.CtxApp, a.Name),
+	})
+	body := strings.NewReader("reason=lgtm")
+	request, err := http.NewRequest("POST", fmt.Sprintf("/deploys/%s/approve", evt.UniqueID.Hex()), body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	err = evt.WaitApproval(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.Approval.Owner, check.Equals, token.GetUserName())
+	c.Assert(evt.Approval.Reason, check.Equals, "lgtm")
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a.Name),
+		Owner:  token.GetUserName(),
+		Kind:   "app.deploy.approve",
+		StartCustomData: []map[string]interface{}{
+			{"name": "reason", "value": "lgtm"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *DeploySuite) TestDeployReject(c *check.C) {
+	a := app.App{Name: "g1", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	evt := s.createPendingDeploy(c, &a)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "approver", permission.Permission{
+		Scheme:  permission.PermAppDeployApprove,
+		Context: permission.Context(permTypes.CtxApp, a.Name),
+	})
+	body := strings.NewReader("reason=not+now")
+	request, err := http.NewRequest("POST", fmt.Sprintf("/deploys/%s/reject", evt.UniqueID.Hex()), body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	err = evt.WaitApproval(context.TODO())
+	c.Assert(err, check.ErrorMatches, "rejected by "+token.GetUserName()+": not now")
+}
+
+func (s *DeploySuite) TestDeployApproveByRequester(c *check.C) {
+	a := app.App{Name: "g1", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	evt := s.createPendingDeploy(c, &a)
+	request, err := http.NewRequest("POST", fmt.Sprintf("/deploys/%s/approve", evt.UniqueID.Hex()), nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	c.Assert(recorder.Body.String(), check.Equals, event.ErrApprovalByOwner.Error()+"\n")
+}
+
+func (s *DeploySuite) TestDeployApproveNotPending(c *check.C) {
+	a := app.App{Name: "g1", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:  eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: event.Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "approver", permission.Permission{
+		Scheme:  permission.PermAppDeployApprove,
+		Context: permission.Context(permTypes.CtxApp, a.Name),
+	})
+	request, err := http.NewRequest("POST", fmt.Sprintf("/deploys/%s/approve", evt.UniqueID.Hex()), nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, event.ErrNotPendingApproval.Error()+"\n")
+}
+
+func (s *DeploySuite) TestDeployApproveWithoutPermission(c *check.C) {
+	a := app.App{Name: "g1", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	evt := s.createPendingDeploy(c, &a)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "reader", permission.Permission{
+		Scheme:  permission.PermAppReadDeploy,
+		Context: permission.Context(permTypes.CtxApp, a.Name),
+	})
+	request, err := http.NewRequest("POST", fmt.Sprintf("/deploys/%s/approve", evt.UniqueID.Hex()), nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *DeploySuite) TestDeployApproveNotFound(c *check.C) {
+	request, err := http.NewRequest("POST", fmt.Sprintf("/deploys/%s/approve", primitive.NewObjectID().Hex()), nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
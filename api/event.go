@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/app"
@@ -201,6 +202,41 @@ func eventBlockList(w http.ResponseWriter, r *http.Request, t auth.Token) error
 	return json.NewEncoder(w).Encode(blocks)
 }
 
+// title: list upcoming event block windows
+// path: /events/blocks/upcoming
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	204: No content
+//	400: Invalid within duration
+//	401: Unauthorized
+func eventBlockUpcoming(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	if !permission.Check(ctx, t, permission.PermEventBlockRead) {
+		return permission.ErrUnauthorized
+	}
+	within := 7 * 24 * time.Hour
+	if withinStr := InputValue(r, "within"); withinStr != "" {
+		var err error
+		within, err = time.ParseDuration(withinStr)
+		if err != nil || within <= 0 {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid within duration: %q", withinStr)}
+		}
+	}
+	windows, err := event.UpcomingWindows(ctx, time.Now().Add(within))
+	if err != nil {
+		return err
+	}
+	if len(windows) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Add("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(windows)
+}
+
 // title: add event block
 // path: /events/blocks
 // method: POST
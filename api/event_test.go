@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"time"
 
 	"github.com/cezarsa/form"
 	"github.com/tsuru/config"
@@ -648,6 +649,59 @@ func (s *EventSuite) TestEventBlockListEmpty(c *check.C) {
 	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
 }
 
+func (s *EventSuite) TestEventBlockUpcoming(c *check.C) {
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permission.Permission{
+		Scheme:  permission.PermEventBlockRead,
+		Context: permTypes.PermissionContext{CtxType: permTypes.CtxGlobal},
+	})
+	start := time.Now().Add(time.Hour)
+	err := event.AddBlock(context.TODO(), &event.Block{KindName: "app.deploy", Reason: "maintenance", StartTime: start, EndTime: start.Add(time.Hour)})
+	c.Assert(err, check.IsNil)
+	err = event.AddBlock(context.TODO(), &event.Block{KindName: "app.deploy", Reason: "later", StartTime: start.Add(48 * time.Hour)})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/events/blocks/upcoming?within=24h", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var windows []event.BlockWindow
+	err = json.NewDecoder(recorder.Body).Decode(&windows)
+	c.Assert(err, check.IsNil)
+	c.Assert(windows, check.HasLen, 1)
+	c.Assert(windows[0].Block.Reason, check.Equals, "maintenance")
+}
+
+func (s *EventSuite) TestEventBlockUpcomingEmpty(c *check.C) {
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permission.Permission{
+		Scheme:  permission.PermEventBlockRead,
+		Context: permTypes.PermissionContext{CtxType: permTypes.CtxGlobal},
+	})
+	request, err := http.NewRequest("GET", "/events/blocks/upcoming", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *EventSuite) TestEventBlockUpcomingInvalidWithin(c *check.C) {
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permission.Permission{
+		Scheme:  permission.PermEventBlockRead,
+		Context: permTypes.PermissionContext{CtxType: permTypes.CtxGlobal},
+	})
+	request, err := http.NewRequest("GET", "/events/blocks/upcoming?within=tomorrow", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "invalid within duration: \"tomorrow\"\n")
+}
+
 func (s *EventSuite) TestEventBlockAdd(c *check.C) {
 	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myuser", permission.Permission{
 		Scheme:  permission.PermEventBlockAdd,
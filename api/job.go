@@ -48,12 +48,14 @@ type inputJob struct {
 
 	DeployOptions *jobTypes.DeployOptions `json:"deployOptions"`
 
-	Container             jobTypes.ContainerInfo `json:"container"`
-	Schedule              string                 `json:"schedule"`
-	Manual                bool                   `json:"manual"`  // creates a cronjob with the suspended attr + label tsuru.io/job-manual = true + "invalid" schedule
-	Trigger               bool                   `json:"trigger"` // Trigger means the client wants to forcefully run a job
-	ActiveDeadlineSeconds *int64                 `json:"activeDeadlineSeconds,omitempty"`
-	ConcurrencyPolicy     *string                `json:"concurrencyPolicy,omitempty"`
+	Container             jobTypes.ContainerInfo  `json:"container"`
+	Schedule              string                  `json:"schedule"`
+	Manual                bool                    `json:"manual"`  // creates a cronjob with the suspended attr + label tsuru.io/job-manual = true + "invalid" schedule
+	Trigger               bool                    `json:"trigger"` // Trigger means the client wants to forcefully run a job
+	ActiveDeadlineSeconds *int64                  `json:"activeDeadlineSeconds,omitempty"`
+	ConcurrencyPolicy     *string                 `json:"concurrencyPolicy,omitempty"`
+	OnSuccess             []jobTypes.JobDependent `json:"onSuccess,omitempty"`
+	OnFailure             []jobTypes.JobDependent `json:"onFailure,omitempty"`
 }
 
 func getJob(ctx stdContext.Context, name string) (*jobTypes.Job, error) {
@@ -139,14 +141,27 @@ func jobList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 // title: job trigger
 // path: /job/trigger/{name}
 // method: PUT
+// consume: application/json
 // produce: application/json
 // responses:
 //
 //	200: OK
+//	400: Invalid overrides
 //	401: Unauthorized
 //	404: Not found
 func jobTrigger(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	ctx := r.Context()
+	var opts jobTypes.TriggerOpts
+	err = ParseInput(r, &opts)
+	if err != nil {
+		return err
+	}
+	opts.Workflow = ""
+	for _, env := range opts.Envs {
+		if err = isEnvVarUnixLike(env.Name); err != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+		}
+	}
 	name := r.URL.Query().Get(":name")
 	j, err := getJob(ctx, name)
 	if err != nil {
@@ -158,23 +173,38 @@ func jobTrigger(w http.ResponseWriter, r *http.Request, t auth.Token) (err error
 	if !canRun {
 		return permission.ErrUnauthorized
 	}
+	kind := permission.PermJobTrigger
+	var customData interface{} = event.FormToCustomData(InputFields(r))
+	if opts.HasOverrides() {
+		if !permission.Check(ctx, t, permission.PermJobTriggerAdhoc, contextsForJob(j)...) {
+			return permission.ErrUnauthorized
+		}
+		kind = permission.PermJobTriggerAdhoc
+		recorded := opts
+		recorded.Envs = nil
+		for _, env := range opts.Envs {
+			recorded.Envs = append(recorded.Envs, bindTypes.EnvVar{Name: env.Name})
+		}
+		customData = recorded
+	}
 	evt, err := event.New(ctx, &event.Opts{
 		Target:     jobTarget(j.Name),
-		Kind:       permission.PermJobTrigger,
+		Kind:       kind,
 		Owner:      t,
-		CustomData: event.FormToCustomData(InputFields(r)),
+		CustomData: customData,
 		Allowed:    event.Allowed(permission.PermJobReadEvents, contextsForJob(j)...),
 	})
 	if err != nil {
 		return err
 	}
 	defer func() { evt.Done(ctx, err) }()
-	err = servicemanager.Job.Trigger(ctx, j)
+	runID, err := servicemanager.Job.Trigger(ctx, j, opts)
 	if err != nil {
 		return err
 	}
 	msg := map[string]interface{}{
 		"status": "success",
+		"runID":  runID,
 	}
 	jsonMsg, err := json.Marshal(msg)
 	if err != nil {
@@ -381,8 +411,14 @@ func updateJob(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 			Container:             ij.Container,
 			Manual:                ij.Manual,
 			ActiveDeadlineSeconds: ij.ActiveDeadlineSeconds,
+			OnSuccess:             ij.OnSuccess,
+			OnFailure:             ij.OnFailure,
 		},
 	}
+	err = checkJobDependentsPermission(ctx, t, &newJob.Spec)
+	if err != nil {
+		return err
+	}
 
 	if newJob.Pool != "" && oldJob.Pool != newJob.Pool {
 		return &errors.HTTP{
@@ -459,6 +495,8 @@ func createJob(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 			Manual:            ij.Manual,
 			Schedule:          ij.Schedule,
 			Container:         ij.Container,
+			OnSuccess:         ij.OnSuccess,
+			OnFailure:         ij.OnFailure,
 		},
 	}
 	if ij.ActiveDeadlineSeconds != nil && *ij.ActiveDeadlineSeconds >= 0 {
@@ -476,6 +514,10 @@ func createJob(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 	if !canCreate {
 		return permission.ErrUnauthorized
 	}
+	err = checkJobDependentsPermission(ctx, t, &j.Spec)
+	if err != nil {
+		return err
+	}
 	u, err := t.User(ctx)
 	if err != nil {
 		return err
@@ -982,6 +1024,10 @@ func jobLog(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 		Type:  log.LogTypeJob,
 		Limit: lines,
 	}
+	err = parseLogQuery(urlValues, &listArgs)
+	if err != nil {
+		return err
+	}
 	logService := servicemanager.LogService
 	logs, err := logService.List(ctx, listArgs)
 	if err != nil {
@@ -1002,6 +1048,24 @@ func jobLog(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	return followLogs(tsuruNet.CancelableParentContext(r.Context()), j.Name, watcher, encoder)
 }
 
+// checkJobDependentsPermission ensures the token is allowed to run the jobs
+// triggered by the job spec, as they are run on its behalf.
+func checkJobDependentsPermission(ctx stdContext.Context, t auth.Token, spec *jobTypes.JobSpec) error {
+	for _, d := range append(append([]jobTypes.JobDependent{}, spec.OnSuccess...), spec.OnFailure...) {
+		dep, err := servicemanager.Job.GetByName(ctx, d.Job)
+		if err == jobTypes.ErrJobNotFound {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("dependent job %q not found", d.Job)}
+		}
+		if err != nil {
+			return err
+		}
+		if !permission.Check(ctx, t, permission.PermJobRun, contextsForJob(dep)...) {
+			return permission.ErrUnauthorized
+		}
+	}
+	return nil
+}
+
 func jobTarget(jobName string) eventTypes.Target {
 	return eventTypes.Target{Type: eventTypes.TargetTypeJob, Value: jobName}
 }
@@ -0,0 +1,105 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+)
+
+// title: job runs
+// path: /jobs/{name}/runs
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	204: No content
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Job not found
+func jobRuns(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	query := r.URL.Query()
+	j, err := getJob(ctx, query.Get(":name"))
+	if err != nil {
+		return err
+	}
+	if !permission.Check(ctx, t, permission.PermJobRead, contextsForJob(j)...) {
+		return permission.ErrUnauthorized
+	}
+	filter := jobTypes.RunFilter{Status: jobTypes.RunStatus(query.Get("status"))}
+	if l := query.Get("limit"); l != "" {
+		filter.Limit, err = strconv.Atoi(l)
+		if err != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: `Parameter "limit" must be an integer.`}
+		}
+	}
+	runs, err := servicemanager.JobRun.ListRuns(ctx, j.Name, filter)
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	for i := range runs {
+		runs[i].LogsURL = jobRunLogsURL(&runs[i])
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(runs)
+}
+
+// title: job run
+// path: /jobs/{name}/runs/{id}
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	401: Unauthorized
+//	404: Job or run not found
+func jobRun(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	query := r.URL.Query()
+	j, err := getJob(ctx, query.Get(":name"))
+	if err != nil {
+		return err
+	}
+	if !permission.Check(ctx, t, permission.PermJobRead, contextsForJob(j)...) {
+		return permission.ErrUnauthorized
+	}
+	run, err := servicemanager.JobRun.GetRun(ctx, j.Name, query.Get(":id"))
+	if err == jobTypes.ErrJobRunNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	run.LogsURL = jobRunLogsURL(run)
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(run)
+}
+
+// jobRunLogsURL returns the path of the job logs restricted to the time the
+// run was running.
+func jobRunLogsURL(run *jobTypes.Run) string {
+	values := url.Values{}
+	values.Set("since", run.StartTime.Format(time.RFC3339Nano))
+	if run.EndTime != nil {
+		values.Set("until", run.EndTime.Format(time.RFC3339Nano))
+	}
+	return fmt.Sprintf("/jobs/%s/log?%s", url.PathEscape(run.Job), values.Encode())
+}
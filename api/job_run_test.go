@@ -0,0 +1,123 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) insertJobWithRuns(c *check.C) []jobTypes.Run {
+	jobsCollection, err := storagev2.JobsCollection()
+	c.Assert(err, check.IsNil)
+	_, err = jobsCollection.InsertOne(context.TODO(), jobTypes.Job{Name: "myjob", Pool: "pool1", TeamOwner: s.team.Name, Teams: []string{s.team.Name}})
+	c.Assert(err, check.IsNil)
+	start := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(90 * time.Second)
+	exitCode := int32(1)
+	runs := []jobTypes.Run{
+		{ID: "run1", Job: "myjob", Unit: "myjob-1", Pool: "pool1", Trigger: jobTypes.RunTriggerCron, Status: jobTypes.RunStatusFailed, StartTime: start, EndTime: &end, ExitCode: &exitCode, Reason: "BackoffLimitExceeded"},
+		{ID: "run2", Job: "myjob", Unit: "myjob-2", Pool: "pool1", Trigger: jobTypes.RunTriggerAPI, Status: jobTypes.RunStatusRunning, StartTime: start.Add(time.Hour)},
+		{ID: "run3", Job: "otherjob", Unit: "otherjob-1", Pool: "pool1", Trigger: jobTypes.RunTriggerCron, Status: jobTypes.RunStatusRunning, StartTime: start},
+	}
+	for i := range runs {
+		err = servicemanager.JobRun.SaveRun(context.TODO(), &runs[i])
+		c.Assert(err, check.IsNil)
+	}
+	return runs
+}
+
+func (s *S) TestJobRuns(c *check.C) {
+	s.insertJobWithRuns(c)
+	request, err := http.NewRequest(http.MethodGet, "/jobs/myjob/runs", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var runs []jobTypes.Run
+	err = json.Unmarshal(recorder.Body.Bytes(), &runs)
+	c.Assert(err, check.IsNil)
+	c.Assert(runs, check.HasLen, 2)
+	c.Assert(runs[0].ID, check.Equals, "run2")
+	c.Assert(runs[0].LogsURL, check.Equals, "/jobs/myjob/log?since=2026-05-01T11%3A00%3A00Z")
+	c.Assert(runs[1].ID, check.Equals, "run1")
+	c.Assert(runs[1].Duration, check.Equals, 90.0)
+	c.Assert(*runs[1].ExitCode, check.Equals, int32(1))
+	c.Assert(runs[1].LogsURL, check.Equals, "/jobs/myjob/log?since=2026-05-01T10%3A00%3A00Z&until=2026-05-01T10%3A01%3A30Z")
+}
+
+func (s *S) TestJobRunsFilterByStatus(c *check.C) {
+	s.insertJobWithRuns(c)
+	request, err := http.NewRequest(http.MethodGet, "/jobs/myjob/runs?status=failed&limit=10", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var runs []jobTypes.Run
+	err = json.Unmarshal(recorder.Body.Bytes(), &runs)
+	c.Assert(err, check.IsNil)
+	c.Assert(runs, check.HasLen, 1)
+	c.Assert(runs[0].ID, check.Equals, "run1")
+	request, err = http.NewRequest(http.MethodGet, "/jobs/myjob/runs?status=succeeded", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestJobRunsForbidden(c *check.C) {
+	s.insertJobWithRuns(c)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermJobRead,
+		Context: permission.Context(permTypes.CtxTeam, "no-access"),
+	})
+	request, err := http.NewRequest(http.MethodGet, "/jobs/myjob/runs", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestJobRun(c *check.C) {
+	s.insertJobWithRuns(c)
+	request, err := http.NewRequest(http.MethodGet, "/jobs/myjob/runs/run1", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var run jobTypes.Run
+	err = json.Unmarshal(recorder.Body.Bytes(), &run)
+	c.Assert(err, check.IsNil)
+	c.Assert(run.Unit, check.Equals, "myjob-1")
+	c.Assert(run.Status, check.Equals, jobTypes.RunStatusFailed)
+	c.Assert(run.Reason, check.Equals, "BackoffLimitExceeded")
+}
+
+func (s *S) TestJobRunNotFound(c *check.C) {
+	s.insertJobWithRuns(c)
+	for _, id := range []string{"unknown", "run3"} {
+		request, err := http.NewRequest(http.MethodGet, "/jobs/myjob/runs/"+id, nil)
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Authorization", "b "+s.token.GetValue())
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	}
+}
@@ -20,8 +20,10 @@ import (
 
 	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/event/eventtest"
 	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/provision/provisiontest"
@@ -828,6 +830,141 @@ func (s *S) TestTriggerCronjob(c *check.C) {
 	c.Assert(recorder.Code, check.Equals, http.StatusOK)
 }
 
+func (s *S) TestTriggerCronjobWithOverrides(c *check.C) {
+	oldProvisioner := provision.DefaultProvisioner
+	defer func() { provision.DefaultProvisioner = oldProvisioner }()
+	provision.DefaultProvisioner = "jobProv"
+	provision.Register("jobProv", func() (provision.Provisioner, error) {
+		return &provisiontest.JobProvisioner{FakeProvisioner: provisiontest.ProvisionerInstance}, nil
+	})
+	defer provision.Unregister("jobProv")
+	j1 := jobTypes.Job{
+		TeamOwner: s.team.Name,
+		Pool:      "test1",
+		Name:      "manual-job",
+		Spec: jobTypes.JobSpec{
+			Schedule: "* */15 * * *",
+			Container: jobTypes.ContainerInfo{
+				OriginalImageSrc: "ubuntu:latest",
+				Command:          []string{"echo", "hello world"},
+			},
+		},
+	}
+	user, _ := auth.ConvertOldUser(s.user, nil)
+	err := servicemanager.Job.CreateJob(context.TODO(), &j1, user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader(`{"command":["echo","bye"],"image":"ubuntu:24.04","envs":[{"name":"DRY_RUN","value":"true"}],"activeDeadlineSeconds":30}`)
+	request, err := http.NewRequest("POST", fmt.Sprintf("/jobs/%s/trigger", j1.Name), body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var result map[string]string
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, map[string]string{"status": "success", "runID": "manual-job-run-1"})
+	deadline := int64(30)
+	expectedOpts := jobTypes.TriggerOpts{
+		Command:               []string{"echo", "bye"},
+		Image:                 "ubuntu:24.04",
+		Envs:                  []bindTypes.EnvVar{{Name: "DRY_RUN", Value: "true"}},
+		ActiveDeadlineSeconds: &deadline,
+	}
+	c.Assert(s.provisioner.JobTriggers(j1.Name), check.DeepEquals, []jobTypes.TriggerOpts{expectedOpts})
+	c.Assert(eventtest.EventDesc{
+		Target: jobTarget(j1.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "job.trigger.adhoc",
+	}, eventtest.HasEvent)
+	evts, err := event.List(context.TODO(), &event.Filter{
+		Target:    jobTarget(j1.Name),
+		KindNames: []string{"job.trigger.adhoc"},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	var recorded jobTypes.TriggerOpts
+	err = evts[0].StartData(&recorded)
+	c.Assert(err, check.IsNil)
+	expectedOpts.Envs = []bindTypes.EnvVar{{Name: "DRY_RUN"}}
+	c.Assert(recorded, check.DeepEquals, expectedOpts)
+}
+
+func (s *S) TestTriggerCronjobWithInvalidOverrides(c *check.C) {
+	oldProvisioner := provision.DefaultProvisioner
+	defer func() { provision.DefaultProvisioner = oldProvisioner }()
+	provision.DefaultProvisioner = "jobProv"
+	provision.Register("jobProv", func() (provision.Provisioner, error) {
+		return &provisiontest.JobProvisioner{FakeProvisioner: provisiontest.ProvisionerInstance}, nil
+	})
+	defer provision.Unregister("jobProv")
+	j1 := jobTypes.Job{
+		TeamOwner: s.team.Name,
+		Pool:      "test1",
+		Name:      "manual-job",
+		Spec: jobTypes.JobSpec{
+			Schedule: "* */15 * * *",
+			Container: jobTypes.ContainerInfo{
+				OriginalImageSrc: "ubuntu:latest",
+			},
+		},
+	}
+	user, _ := auth.ConvertOldUser(s.user, nil)
+	err := servicemanager.Job.CreateJob(context.TODO(), &j1, user)
+	c.Assert(err, check.IsNil)
+	for _, body := range []string{
+		`{"image":"alpine:latest"}`,
+		`{"envs":[{"name":"1INVALID","value":"x"}]}`,
+	} {
+		request, err := http.NewRequest("POST", fmt.Sprintf("/jobs/%s/trigger", j1.Name), strings.NewReader(body))
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Authorization", "b "+s.token.GetValue())
+		request.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	}
+	c.Assert(s.provisioner.JobExecutions(j1.Name), check.Equals, 0)
+}
+
+func (s *S) TestTriggerCronjobWithOverridesWithoutPermission(c *check.C) {
+	oldProvisioner := provision.DefaultProvisioner
+	defer func() { provision.DefaultProvisioner = oldProvisioner }()
+	provision.DefaultProvisioner = "jobProv"
+	provision.Register("jobProv", func() (provision.Provisioner, error) {
+		return &provisiontest.JobProvisioner{FakeProvisioner: provisiontest.ProvisionerInstance}, nil
+	})
+	defer provision.Unregister("jobProv")
+	j1 := jobTypes.Job{
+		TeamOwner: s.team.Name,
+		Pool:      "test1",
+		Name:      "manual-job",
+		Spec: jobTypes.JobSpec{
+			Schedule: "* */15 * * *",
+			Container: jobTypes.ContainerInfo{
+				OriginalImageSrc: "ubuntu:latest",
+			},
+		},
+	}
+	user, _ := auth.ConvertOldUser(s.user, nil)
+	err := servicemanager.Job.CreateJob(context.TODO(), &j1, user)
+	c.Assert(err, check.IsNil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "runner", permission.Permission{
+		Scheme:  permission.PermJobRun,
+		Context: permission.Context(permTypes.CtxJob, j1.Name),
+	})
+	body := strings.NewReader(`{"command":["echo","bye"]}`)
+	request, err := http.NewRequest("POST", fmt.Sprintf("/jobs/%s/trigger", j1.Name), body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	c.Assert(s.provisioner.JobExecutions(j1.Name), check.Equals, 0)
+}
+
 func (s *S) TestJobList(c *check.C) {
 	oldProvisioner := provision.DefaultProvisioner
 	defer func() { provision.DefaultProvisioner = oldProvisioner }()
@@ -6,10 +6,16 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/tsuru/tsuru/api/context"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/servicemanager"
 	appTypes "github.com/tsuru/tsuru/types/app"
@@ -64,3 +70,41 @@ func scanLogs(stream io.Reader) error {
 type errMsg struct {
 	Error string `json:"error"`
 }
+
+// parseLogQuery fills the time range, message and field filters of args
+// from the since, until, message, message-regex and field query params.
+func parseLogQuery(values url.Values, args *appTypes.ListLogArgs) error {
+	var err error
+	for param, dst := range map[string]*time.Time{"since": &args.Since, "until": &args.Until} {
+		v := values.Get(param)
+		if v == "" {
+			continue
+		}
+		*dst, err = time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			msg := fmt.Sprintf("Parameter %q must be a RFC3339 date.", param)
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: msg}
+		}
+	}
+	if !args.Since.IsZero() && !args.Until.IsZero() && args.Until.Before(args.Since) {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: `Parameter "until" must be after "since".`}
+	}
+	args.Message = values.Get("message")
+	args.MessageRegex = values.Get("message-regex")
+	for _, field := range values["field"] {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			msg := fmt.Sprintf("Invalid field filter %q, expected <name>=<value>.", field)
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: msg}
+		}
+		if args.Fields == nil {
+			args.Fields = map[string]string{}
+		}
+		args.Fields[parts[0]] = parts[1]
+	}
+	_, err = appTypes.NewLogMatcher(*args)
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+// title: log drain list
+// path: /apps/{app}/log-drains
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: List log drains
+//	204: No content
+//	401: Unauthorized
+//	404: App not found
+func logDrainList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(ctx, t, permission.PermAppReadLogDrain, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	drains, err := servicemanager.LogDrain.List(ctx, a.Name)
+	if err != nil {
+		return err
+	}
+	if len(drains) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	for i := range drains {
+		drains[i] = redactLogDrain(drains[i])
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(drains)
+}
+
+const redactedLogDrainValue = "xxxxx"
+
+// redactLogDrain hides the credentials a drain may carry in its headers and
+// in the userinfo of its url, which are only needed to deliver the logs.
+func redactLogDrain(drain appTypes.LogDrain) appTypes.LogDrain {
+	if len(drain.Headers) > 0 {
+		headers := make(http.Header, len(drain.Headers))
+		for name := range drain.Headers {
+			headers.Set(name, redactedLogDrainValue)
+		}
+		drain.Headers = headers
+	}
+	if u, err := url.Parse(drain.URL); err == nil && u.User != nil {
+		u.User = url.User(redactedLogDrainValue)
+		drain.URL = u.String()
+	}
+	return drain
+}
+
+// logDrainInputFields returns the request fields recorded in events, without
+// the headers and with the url of the drain redacted, as both may carry
+// credentials.
+func logDrainInputFields(r *http.Request, drain *appTypes.LogDrain) url.Values {
+	fields := InputFields(r, "url")
+	if fields == nil {
+		fields = url.Values{}
+	}
+	for key := range fields {
+		if strings.HasPrefix(key, "headers") {
+			delete(fields, key)
+		}
+	}
+	if drain != nil && drain.URL != "" {
+		fields.Set("url", redactLogDrain(*drain).URL)
+	}
+	return fields
+}
+
+// title: log drain create
+// path: /apps/{app}/log-drains
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//
+//	201: Log drain created
+//	400: Invalid log drain
+//	401: Unauthorized
+//	404: App not found
+//	409: Log drain already exists
+func logDrainCreate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	var drain appTypes.LogDrain
+	err = ParseInput(r, &drain)
+	if err != nil {
+		return err
+	}
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(ctx, t, permission.PermAppUpdateLogDrainAdd, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	drain.App = a.Name
+	drain.Pool = ""
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateLogDrainAdd,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(logDrainInputFields(r, &drain)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = servicemanager.LogDrain.Create(ctx, drain)
+	if err == appTypes.ErrLogDrainAlreadyExists {
+		return &tsuruErrors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusCreated)
+	return nil
+}
+
+// title: log drain delete
+// path: /apps/{app}/log-drains/{name}
+// method: DELETE
+// responses:
+//
+//	200: Log drain deleted
+//	401: Unauthorized
+//	404: App or log drain not found
+func logDrainDelete(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	appName := r.URL.Query().Get(":app")
+	drainName := r.URL.Query().Get(":name")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(ctx, t, permission.PermAppUpdateLogDrainRemove, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateLogDrainRemove,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(logDrainInputFields(r, nil)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = servicemanager.LogDrain.Delete(ctx, a.Name, drainName)
+	if err == appTypes.ErrLogDrainNotFound {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/tsuru/tsuru/servicemanager"
 	"github.com/tsuru/tsuru/set"
 	appTypes "github.com/tsuru/tsuru/types/app"
+	"github.com/tsuru/tsuru/types/quota"
 )
 
 const (
@@ -181,6 +182,12 @@ func errorHandlingMiddleware(w http.ResponseWriter, r *http.Request, next http.H
 		switch t := errors.Cause(err).(type) {
 		case *tsuruErrors.ValidationError:
 			code = http.StatusBadRequest
+		case *quota.QuotaExceededError:
+			// unit and app quotas keep being reported as they were by the
+			// handlers, only resource quotas default to forbidden.
+			if len(t.Resources) > 0 {
+				code = http.StatusForbidden
+			}
 		case *tsuruErrors.HTTP:
 			code = t.Code
 		}
@@ -0,0 +1,31 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+//go:generate go run ./openapi/generator -o openapi.json
+
+// openAPISpec is generated from the route table and the handlers in this
+// package. Please run 'go generate' after changing them.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// title: openapi specification
+// path: /openapi.json
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+func openAPI(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	_, err := w.Write(openAPISpec)
+	return err
+}
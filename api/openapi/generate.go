@@ -0,0 +1,239 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openapi
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	documentTitle       = "tsuru"
+	documentDescription = "Open source, extensible and Docker-based Platform as a Service (PaaS)"
+)
+
+var pathVariableRegexp = regexp.MustCompile(`\{([^}:]+):[^}]*\}`)
+
+// Generate builds the document of the API registered by the package in dir,
+// usually the tsuru api package. Each route becomes an operation under its
+// versioned path, e.g. /1.24/swaps, and the document version is the most
+// recent version in the route table. Routes whose handler isn't a function,
+// like the metrics endpoint, are left out.
+func Generate(dir string) (*Document, error) {
+	pkg, err := loadPackage(dir)
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{
+		pkg:          pkg,
+		schemas:      newSchemaBuilder(),
+		decls:        map[*types.Func]*ast.FuncDecl{},
+		operationIDs: map[string]bool{},
+	}
+	for _, f := range pkg.files {
+		for _, d := range f.Decls {
+			decl, ok := d.(*ast.FuncDecl)
+			if !ok || decl.Recv != nil || decl.Body == nil {
+				continue
+			}
+			if fn, ok := pkg.info.Defs[decl.Name].(*types.Func); ok {
+				g.decls[fn] = decl
+			}
+		}
+	}
+	doc := &Document{
+		OpenAPI: openAPIVersion,
+		Info:    Info{Title: documentTitle, Description: documentDescription},
+		Paths:   map[string]*PathItem{},
+	}
+	for _, r := range findRoutes(pkg) {
+		if r.handler == nil {
+			continue
+		}
+		if compareVersions(r.version, doc.Info.Version) > 0 {
+			doc.Info.Version = r.version
+		}
+		path := "/" + r.version + pathVariableRegexp.ReplaceAllString(r.path, "{$1}")
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		for _, method := range r.methods {
+			method = strings.ToLower(method)
+			if _, ok := (*item)[method]; ok {
+				continue
+			}
+			(*item)[method] = g.operation(r, method, path)
+		}
+	}
+	doc.Components = Components{
+		Schemas: g.schemas.components,
+		SecuritySchemes: map[string]*SecurityScheme{
+			securitySchemeName: {Type: "http", Scheme: "bearer"},
+		},
+	}
+	return doc, nil
+}
+
+type generator struct {
+	pkg          *sourcePackage
+	schemas      *schemaBuilder
+	decls        map[*types.Func]*ast.FuncDecl
+	operationIDs map[string]bool
+}
+
+func (g *generator) operation(r route, method, path string) *Operation {
+	op := &Operation{
+		OperationID: g.operationID(r.handler.Name(), method),
+		Tags:        pathTags(r.path),
+		Responses:   map[string]*Response{},
+	}
+	if r.authenticated {
+		op.Security = []map[string][]string{{securitySchemeName: {}}}
+	}
+	for _, match := range pathParamRegexp.FindAllStringSubmatch(path, -1) {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+	var doc handlerDoc
+	var usage handlerIO
+	if decl, ok := g.decls[r.handler]; ok {
+		if decl.Doc != nil {
+			doc, _ = parseHandlerDoc(decl.Doc.Text())
+		}
+		usage = inspectHandler(g.pkg, decl)
+	}
+	op.Summary = doc.title
+	query := usage.query
+	hasBody := method != "get" && method != "delete" && method != "head"
+	if !hasBody {
+		query = append(usage.inputs, query...)
+	}
+	for _, in := range query {
+		op.Parameters = append(op.Parameters, Parameter{Name: in.name, In: "query", Schema: inputSchema(in)})
+	}
+	if hasBody {
+		op.RequestBody = g.requestBody(doc, usage)
+	}
+	g.addResponses(op, doc, usage)
+	return op
+}
+
+func (g *generator) requestBody(doc handlerDoc, usage handlerIO) *RequestBody {
+	var schema *Schema
+	defaultMediaType := "application/json"
+	switch {
+	case usage.request != nil:
+		schema = g.schemas.schema(usage.request)
+	case len(usage.inputs) > 0:
+		schema = &Schema{Type: "object", Properties: map[string]*Schema{}}
+		for _, in := range usage.inputs {
+			schema.Properties[in.name] = inputSchema(in)
+		}
+		defaultMediaType = "application/x-www-form-urlencoded"
+	default:
+		return nil
+	}
+	consume := doc.consume
+	if len(consume) == 0 {
+		consume = []string{defaultMediaType}
+	}
+	body := &RequestBody{Content: map[string]MediaType{}}
+	for _, mediaType := range consume {
+		body.Content[mediaType] = MediaType{Schema: schema}
+	}
+	return body
+}
+
+// addResponses adds the documented responses of the handler, describing
+// the content of the first success response with a body.
+func (g *generator) addResponses(op *Operation, doc handlerDoc, usage handlerIO) {
+	withContent := false
+	for _, resp := range doc.responses {
+		response := &Response{Description: resp.description}
+		op.Responses[resp.code] = response
+		if withContent || !strings.HasPrefix(resp.code, "2") || resp.code == "204" {
+			continue
+		}
+		withContent = true
+		produce := doc.produce
+		if len(produce) == 0 && usage.response != nil {
+			produce = []string{"application/json"}
+		}
+		if len(produce) == 0 {
+			continue
+		}
+		response.Content = map[string]MediaType{}
+		for _, mediaType := range produce {
+			var media MediaType
+			if usage.response != nil && strings.Contains(mediaType, "json") {
+				media.Schema = g.schemas.schemaOrAny(usage.response)
+			}
+			response.Content[mediaType] = media
+		}
+	}
+	if len(op.Responses) == 0 {
+		op.Responses["default"] = &Response{Description: "Undocumented response"}
+	}
+}
+
+// operationID returns the handler name, suffixed by the method or a counter
+// when the handler is registered in more than one route.
+func (g *generator) operationID(name, method string) string {
+	id := name
+	if g.operationIDs[id] {
+		id = name + strings.ToUpper(method[:1]) + method[1:]
+	}
+	for i := 2; g.operationIDs[id]; i++ {
+		id = fmt.Sprintf("%s%d", name, i)
+	}
+	g.operationIDs[id] = true
+	return id
+}
+
+func inputSchema(in input) *Schema {
+	if in.multi {
+		return &Schema{Type: "array", Items: &Schema{Type: "string"}}
+	}
+	return &Schema{Type: "string"}
+}
+
+// pathTags groups operations by the first segment of their path, e.g. apps.
+func pathTags(path string) []string {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if segment == "" || strings.HasPrefix(segment, "{") {
+		return nil
+	}
+	return []string{segment}
+}
+
+func compareVersions(v1, v2 string) int {
+	parts1, parts2 := strings.Split(v1, "."), strings.Split(v2, ".")
+	for i := 0; i < len(parts1) || i < len(parts2); i++ {
+		var n1, n2 int
+		if i < len(parts1) {
+			n1, _ = strconv.Atoi(parts1[i])
+		}
+		if i < len(parts2) {
+			n2, _ = strconv.Atoi(parts2[i])
+		}
+		if n1 != n2 {
+			if n1 < n2 {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,37 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/tsuru/tsuru/api/openapi"
+)
+
+func main() {
+	dir := flag.String("d", ".", "directory of the api package")
+	out := flag.String("o", "", "output file")
+	flag.Parse()
+	doc, err := openapi.Generate(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = openapi.Validate(doc)
+	if err != nil {
+		log.Fatalf("invalid document: %v", err)
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	data = append(data, '\n')
+	err = os.WriteFile(*out, data, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
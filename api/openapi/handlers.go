@@ -0,0 +1,219 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openapi
+
+import (
+	"go/ast"
+	"go/types"
+	"regexp"
+	"strings"
+)
+
+var responseLineRegexp = regexp.MustCompile(`^([1-5][0-9][0-9]):\s*(.*)$`)
+
+// handlerDoc is the structured comment preceding each handler:
+//
+//	title: app swap
+//	path: /swap
+//	method: POST
+//	consume: application/x-www-form-urlencoded
+//	produce: application/json
+//	responses:
+//	  200: Ok
+//	  404: App not found
+//
+// The path and method are taken from the route table instead, as handlers
+// may be registered in more than one route.
+type handlerDoc struct {
+	title     string
+	consume   []string
+	produce   []string
+	responses []handlerResponse
+}
+
+type handlerResponse struct {
+	code        string
+	description string
+}
+
+func parseHandlerDoc(text string) (handlerDoc, bool) {
+	var doc handlerDoc
+	var found, inResponses bool
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if inResponses {
+			if match := responseLineRegexp.FindStringSubmatch(line); match != nil {
+				doc.responses = append(doc.responses, handlerResponse{code: match[1], description: match[2]})
+				continue
+			}
+			inResponses = false
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "title":
+			doc.title = value
+			found = true
+		case "consume":
+			doc.consume = mediaTypes(value)
+		case "produce":
+			doc.produce = mediaTypes(value)
+		case "responses":
+			inResponses = true
+		}
+	}
+	return doc, found
+}
+
+// mediaTypes splits a comma separated list of media types, ignoring free
+// text values like "Websocket connection upgrade".
+func mediaTypes(value string) []string {
+	var result []string
+	for _, v := range strings.Split(value, ",") {
+		v = strings.TrimSpace(v)
+		if strings.Contains(v, "/") && !strings.Contains(v, " ") {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// handlerIO describes what a handler reads from the request and writes to
+// the response, as found in its body.
+type handlerIO struct {
+	// request is the type decoded from the request body by ParseInput,
+	// ParseJSON or a json.Decoder reading the body.
+	request types.Type
+	// response is the first type encoded by a json.Encoder or, lacking
+	// one, by json.Marshal.
+	response types.Type
+	// inputs are the fields read with InputValue, InputValues or
+	// FormValue, which come from the body or the query string.
+	inputs []input
+	// query are the fields read from r.URL.Query().
+	query []input
+}
+
+type input struct {
+	name  string
+	multi bool
+}
+
+func inspectHandler(pkg *sourcePackage, decl *ast.FuncDecl) handlerIO {
+	var result handlerIO
+	var marshaled types.Type
+	seen := map[string]bool{}
+	addInput := func(list *[]input, name string, multi bool) {
+		// values prefixed by a colon are the path variables of the route.
+		if strings.HasPrefix(name, ":") || seen[name] {
+			return
+		}
+		seen[name] = true
+		*list = append(*list, input{name: name, multi: multi})
+	}
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		fn := calledFunc(pkg, call)
+		if fn == nil {
+			return true
+		}
+		if fn.Pkg() == pkg.types {
+			switch fn.Name() {
+			case "InputValue", "InputValues":
+				if name, ok := constantString(pkg, call.Args[1]); ok {
+					addInput(&result.inputs, name, fn.Name() == "InputValues")
+				}
+			case "ParseInput", "ParseJSON":
+				if result.request == nil {
+					result.request = pointedType(pkg, call.Args[1])
+				}
+			}
+			return true
+		}
+		switch fn.FullName() {
+		case "(*net/http.Request).FormValue", "(*net/http.Request).PostFormValue":
+			if name, ok := constantString(pkg, call.Args[0]); ok {
+				addInput(&result.inputs, name, false)
+			}
+		case "(net/url.Values).Get":
+			if isQueryCall(pkg, call.Fun.(*ast.SelectorExpr).X) {
+				if name, ok := constantString(pkg, call.Args[0]); ok {
+					addInput(&result.query, name, false)
+				}
+			}
+		case "(*encoding/json.Decoder).Decode":
+			if result.request == nil && isBodyDecoder(pkg, call.Fun.(*ast.SelectorExpr).X) {
+				result.request = pointedType(pkg, call.Args[0])
+			}
+		case "(*encoding/json.Encoder).Encode":
+			if result.response == nil {
+				result.response = pkg.info.TypeOf(call.Args[0])
+			}
+		case "encoding/json.Marshal":
+			if marshaled == nil {
+				marshaled = pkg.info.TypeOf(call.Args[0])
+			}
+		}
+		return true
+	})
+	if result.response == nil {
+		result.response = marshaled
+	}
+	return result
+}
+
+func calledFunc(pkg *sourcePackage, call *ast.CallExpr) *types.Func {
+	ident := calleeIdent(call.Fun)
+	if ident == nil {
+		return nil
+	}
+	fn, _ := pkg.info.Uses[ident].(*types.Func)
+	return fn
+}
+
+// pointedType returns the type of the value decoded into, given the
+// pointer passed to a decoding function.
+func pointedType(pkg *sourcePackage, expr ast.Expr) types.Type {
+	t := pkg.info.TypeOf(expr)
+	if ptr, ok := t.(*types.Pointer); ok {
+		return ptr.Elem()
+	}
+	return t
+}
+
+// isQueryCall reports whether expr is a call to the Query method of a
+// *url.URL, as in r.URL.Query().
+func isQueryCall(pkg *sourcePackage, expr ast.Expr) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	fn := calledFunc(pkg, call)
+	return fn != nil && fn.FullName() == "(*net/url.URL).Query"
+}
+
+// isBodyDecoder reports whether expr is a json.Decoder reading the body of
+// the request, as in json.NewDecoder(r.Body).
+func isBodyDecoder(pkg *sourcePackage, expr ast.Expr) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return false
+	}
+	fn := calledFunc(pkg, call)
+	if fn == nil || fn.FullName() != "encoding/json.NewDecoder" {
+		return false
+	}
+	sel, ok := call.Args[0].(*ast.SelectorExpr)
+	return ok && sel.Sel.Name == "Body"
+}
@@ -0,0 +1,99 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+type listedPackage struct {
+	ImportPath string
+	Dir        string
+	Export     string
+	GoFiles    []string
+	Error      *struct {
+		Err string
+	}
+}
+
+// sourcePackage is a package parsed and type checked from source, keeping
+// the comments needed to read the handler documentation.
+type sourcePackage struct {
+	fset  *token.FileSet
+	files []*ast.File
+	types *types.Package
+	info  *types.Info
+}
+
+// loadPackage type checks the package in dir. Its dependencies are imported
+// from the export data built by the go command, so only the package itself
+// is parsed.
+func loadPackage(dir string) (*sourcePackage, error) {
+	cmd := exec.Command("go", "list", "-export", "-deps", "-json=ImportPath,Dir,Export,GoFiles,Error", ".")
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to list packages: %s", stderr.String())
+	}
+	exports := map[string]string{}
+	var target listedPackage
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	for decoder.More() {
+		var pkg listedPackage
+		err = decoder.Decode(&pkg)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to decode package list")
+		}
+		if pkg.Error != nil {
+			return nil, errors.Errorf("unable to load package %s: %s", pkg.ImportPath, pkg.Error.Err)
+		}
+		exports[pkg.ImportPath] = pkg.Export
+		// go list prints dependencies first, the package itself comes last.
+		target = pkg
+	}
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range target.GoFiles {
+		f, err := parser.ParseFile(fset, filepath.Join(target.Dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "gc", func(path string) (io.ReadCloser, error) {
+			export, ok := exports[path]
+			if !ok || export == "" {
+				return nil, errors.Errorf("no export data for package %s", path)
+			}
+			return os.Open(export)
+		}),
+	}
+	info := &types.Info{
+		Types:      map[ast.Expr]types.TypeAndValue{},
+		Defs:       map[*ast.Ident]types.Object{},
+		Uses:       map[*ast.Ident]types.Object{},
+		Selections: map[*ast.SelectorExpr]*types.Selection{},
+	}
+	pkg, err := conf.Check(target.ImportPath, fset, files, info)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to type check package %s", target.ImportPath)
+	}
+	return &sourcePackage{fset: fset, files: files, types: pkg, info: info}, nil
+}
@@ -0,0 +1,205 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package openapi generates the OpenAPI 3 document of the tsuru API from the
+// route table registered in api/server.go, the structured comments of each
+// handler and the types the handlers decode and encode.
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+)
+
+const (
+	openAPIVersion = "3.0.3"
+
+	schemaRefPrefix    = "#/components/schemas/"
+	securitySchemeName = "Bearer"
+)
+
+var (
+	pathParamRegexp    = regexp.MustCompile(`\{([^}]+)\}`)
+	responseCodeRegexp = regexp.MustCompile(`^[1-5][0-9][0-9]$`)
+)
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem holds the operations of a path by lower case HTTP method.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Content map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme,omitempty"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Validate checks the structural rules of the OpenAPI 3 specification the
+// generated documents rely on: path parameters must be declared by every
+// operation, operation ids must be unique and every schema reference must
+// point to an existing component.
+func Validate(doc *Document) error {
+	multi := tsuruErrors.NewMultiError()
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		multi.Add(fmt.Errorf("unsupported openapi version %q", doc.OpenAPI))
+	}
+	if doc.Info.Title == "" || doc.Info.Version == "" {
+		multi.Add(fmt.Errorf("info title and version are required"))
+	}
+	for _, name := range sortedKeys(doc.Components.Schemas) {
+		validateSchema(doc, "component "+name, doc.Components.Schemas[name], multi)
+	}
+	operationIDs := map[string]string{}
+	for _, path := range sortedKeys(doc.Paths) {
+		if !strings.HasPrefix(path, "/") {
+			multi.Add(fmt.Errorf("path %q must start with /", path))
+		}
+		var templateParams []string
+		for _, match := range pathParamRegexp.FindAllStringSubmatch(path, -1) {
+			templateParams = append(templateParams, match[1])
+		}
+		item := doc.Paths[path]
+		for _, method := range sortedKeys(*item) {
+			op := (*item)[method]
+			where := fmt.Sprintf("%s %s", strings.ToUpper(method), path)
+			validateOperation(doc, where, op, templateParams, multi)
+			if op.OperationID == "" {
+				continue
+			}
+			if other, ok := operationIDs[op.OperationID]; ok {
+				multi.Add(fmt.Errorf("%s: operation id %q is already used by %s", where, op.OperationID, other))
+				continue
+			}
+			operationIDs[op.OperationID] = where
+		}
+	}
+	return multi.ToError()
+}
+
+func validateOperation(doc *Document, where string, op *Operation, templateParams []string, multi *tsuruErrors.MultiError) {
+	if op.OperationID == "" {
+		multi.Add(fmt.Errorf("%s: operation id is required", where))
+	}
+	if len(op.Responses) == 0 {
+		multi.Add(fmt.Errorf("%s: at least one response is required", where))
+	}
+	for _, code := range sortedKeys(op.Responses) {
+		resp := op.Responses[code]
+		if code != "default" && !responseCodeRegexp.MatchString(code) {
+			multi.Add(fmt.Errorf("%s: invalid response code %q", where, code))
+		}
+		for _, mediaType := range sortedKeys(resp.Content) {
+			validateSchema(doc, fmt.Sprintf("%s response %s %s", where, code, mediaType), resp.Content[mediaType].Schema, multi)
+		}
+	}
+	if op.RequestBody != nil {
+		for _, mediaType := range sortedKeys(op.RequestBody.Content) {
+			validateSchema(doc, fmt.Sprintf("%s request %s", where, mediaType), op.RequestBody.Content[mediaType].Schema, multi)
+		}
+	}
+	declared := map[string]bool{}
+	for _, param := range op.Parameters {
+		if param.In != "path" {
+			continue
+		}
+		declared[param.Name] = true
+		if !param.Required {
+			multi.Add(fmt.Errorf("%s: path parameter %q must be required", where, param.Name))
+		}
+	}
+	for _, name := range templateParams {
+		if !declared[name] {
+			multi.Add(fmt.Errorf("%s: path parameter %q is not declared", where, name))
+		}
+		delete(declared, name)
+	}
+	for _, name := range sortedKeys(declared) {
+		multi.Add(fmt.Errorf("%s: path parameter %q is not in the path", where, name))
+	}
+}
+
+func validateSchema(doc *Document, where string, schema *Schema, multi *tsuruErrors.MultiError) {
+	if schema == nil {
+		return
+	}
+	if schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, schemaRefPrefix)
+		if _, ok := doc.Components.Schemas[name]; !ok || name == schema.Ref {
+			multi.Add(fmt.Errorf("%s: unresolved schema reference %q", where, schema.Ref))
+		}
+		return
+	}
+	validateSchema(doc, where, schema.Items, multi)
+	validateSchema(doc, where, schema.AdditionalProperties, multi)
+	for _, name := range sortedKeys(schema.Properties) {
+		validateSchema(doc, where+"."+name, schema.Properties[name], multi)
+	}
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
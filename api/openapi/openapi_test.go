@@ -0,0 +1,174 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openapi
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+var bearerSecurity = []map[string][]string{{"Bearer": {}}}
+
+func (s *S) TestGenerate(c *check.C) {
+	doc, err := Generate("testdata/sample")
+	c.Assert(err, check.IsNil)
+	c.Assert(Validate(doc), check.IsNil)
+	c.Assert(doc.Info.Version, check.Equals, "1.2")
+	c.Assert(sortedKeys(doc.Paths), check.DeepEquals, []string{"/1.0/items", "/1.1/files/{path}", "/1.2/items", "/1.2/items/{name}/rename"})
+	itemRef := &Schema{Ref: "#/components/schemas/api.openapi.testdata.sample.Item"}
+	c.Assert((*doc.Paths["/1.0/items"])["get"], check.DeepEquals, &Operation{
+		OperationID: "listItems",
+		Summary:     "item list",
+		Tags:        []string{"items"},
+		Parameters: []Parameter{
+			{Name: "label", In: "query", Schema: &Schema{Type: "string"}},
+			{Name: "name", In: "query", Schema: &Schema{Type: "string"}},
+		},
+		Responses: map[string]*Response{
+			"200": {Description: "OK", Content: map[string]MediaType{
+				"application/json": {Schema: &Schema{Type: "array", Items: itemRef}},
+			}},
+			"204": {Description: "No content"},
+		},
+		Security: bearerSecurity,
+	})
+	c.Assert((*doc.Paths["/1.2/items"])["post"], check.DeepEquals, &Operation{
+		OperationID: "createItem",
+		Summary:     "item create",
+		Tags:        []string{"items"},
+		RequestBody: &RequestBody{Content: map[string]MediaType{
+			"application/json": {Schema: itemRef},
+		}},
+		Responses: map[string]*Response{
+			"201": {Description: "Created", Content: map[string]MediaType{
+				"application/json": {Schema: itemRef},
+			}},
+			"400": {Description: "Invalid data"},
+		},
+		Security: bearerSecurity,
+	})
+	c.Assert((*doc.Paths["/1.2/items/{name}/rename"])["post"], check.DeepEquals, &Operation{
+		OperationID: "renameItem",
+		Summary:     "item rename",
+		Tags:        []string{"items"},
+		Parameters: []Parameter{
+			{Name: "name", In: "path", Required: true, Schema: &Schema{Type: "string"}},
+		},
+		RequestBody: &RequestBody{Content: map[string]MediaType{
+			"application/x-www-form-urlencoded": {Schema: &Schema{Type: "object", Properties: map[string]*Schema{
+				"newName": {Type: "string"},
+			}}},
+		}},
+		Responses: map[string]*Response{
+			"200": {Description: "OK"},
+			"404": {Description: "Not found"},
+		},
+		Security: bearerSecurity,
+	})
+	c.Assert((*doc.Paths["/1.1/files/{path}"])["get"], check.DeepEquals, &Operation{
+		OperationID: "getFile",
+		Tags:        []string{"files"},
+		Parameters: []Parameter{
+			{Name: "path", In: "path", Required: true, Schema: &Schema{Type: "string"}},
+		},
+		Responses: map[string]*Response{
+			"default": {Description: "Undocumented response"},
+		},
+	})
+	c.Assert(doc.Components.Schemas, check.DeepEquals, map[string]*Schema{
+		"api.openapi.testdata.sample.Item": {Type: "object", Properties: map[string]*Schema{
+			"id":        {Type: "string"},
+			"name":      {Type: "string"},
+			"size":      {Type: "string"},
+			"weight":    {Type: "number", Format: "double"},
+			"createdAt": {Type: "string", Format: "date-time"},
+			"children":  {Type: "array", Items: itemRef},
+			"labels":    {Type: "object", AdditionalProperties: &Schema{Type: "string"}},
+			"Data":      {Type: "string", Format: "byte"},
+		}},
+	})
+}
+
+func (s *S) TestGeneratedDocumentIsUpToDate(c *check.C) {
+	doc, err := Generate("..")
+	c.Assert(err, check.IsNil)
+	c.Assert(Validate(doc), check.IsNil)
+	data, err := json.MarshalIndent(doc, "", "  ")
+	c.Assert(err, check.IsNil)
+	current, err := os.ReadFile("../openapi.json")
+	c.Assert(err, check.IsNil)
+	c.Assert(string(current) == string(data)+"\n", check.Equals, true, check.Commentf("api/openapi.json is outdated, please run 'go generate' in the api package"))
+}
+
+func (s *S) TestValidate(c *check.C) {
+	doc := &Document{
+		OpenAPI: "2.0",
+		Info:    Info{Title: "tsuru"},
+		Paths: map[string]*PathItem{
+			"/1.0/apps/{app}": {
+				"get": {
+					OperationID: "appInfo",
+					Responses: map[string]*Response{
+						"2xx": {Description: "OK", Content: map[string]MediaType{
+							"application/json": {Schema: &Schema{Ref: "#/components/schemas/App"}},
+						}},
+					},
+				},
+				"delete": {
+					OperationID: "appInfo",
+					Parameters:  []Parameter{{Name: "name", In: "path", Schema: &Schema{Type: "string"}}},
+				},
+			},
+		},
+	}
+	err := Validate(doc)
+	c.Assert(err, check.NotNil)
+	c.Assert(err.Error(), check.Equals, `multiple errors reported (10):
+error #0: unsupported openapi version "2.0"
+error #1: info title and version are required
+error #2: DELETE /1.0/apps/{app}: at least one response is required
+error #3: DELETE /1.0/apps/{app}: path parameter "name" must be required
+error #4: DELETE /1.0/apps/{app}: path parameter "app" is not declared
+error #5: DELETE /1.0/apps/{app}: path parameter "name" is not in the path
+error #6: GET /1.0/apps/{app}: invalid response code "2xx"
+error #7: GET /1.0/apps/{app} response 2xx application/json: unresolved schema reference "#/components/schemas/App"
+error #8: GET /1.0/apps/{app}: path parameter "app" is not declared
+error #9: GET /1.0/apps/{app}: operation id "appInfo" is already used by DELETE /1.0/apps/{app}
+`)
+}
+
+func (s *S) TestParseHandlerDoc(c *check.C) {
+	doc, ok := parseHandlerDoc(`title: app log
+path: /apps/{app}/log
+method: GET
+produce: application/x-json-stream, Websocket connection upgrade
+responses:
+
+	200: Ok
+	401: Unauthorized
+
+Deprecated: use something else.
+`)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(doc, check.DeepEquals, handlerDoc{
+		title:   "app log",
+		produce: []string{"application/x-json-stream"},
+		responses: []handlerResponse{
+			{code: "200", description: "Ok"},
+			{code: "401", description: "Unauthorized"},
+		},
+	})
+	_, ok = parseHandlerDoc("appLog returns the logs of an app.")
+	c.Assert(ok, check.Equals, false)
+}
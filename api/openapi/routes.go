@@ -0,0 +1,140 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openapi
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"net/http"
+)
+
+const routerPkgPath = modulePath + "api/router"
+
+const authorizationWrapper = "AuthorizationRequiredHandler"
+
+// route is a route registered in the api router with constant arguments.
+type route struct {
+	version string
+	methods []string
+	path    string
+	// handler is the function ultimately handling the route, nil when the
+	// handler isn't a plain function, e.g. promhttp.Handler().
+	handler *types.Func
+	// authenticated is set when the handler is wrapped in
+	// AuthorizationRequiredHandler.
+	authenticated bool
+}
+
+// findRoutes returns the routes registered through Add, AddNamed and AddAll
+// calls on the api router, in source order. Routes registered with values
+// only known at runtime, like the ones in tsuruHandlerList, are ignored.
+func findRoutes(pkg *sourcePackage) []route {
+	var routes []route
+	for _, f := range pkg.files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			method := routerMethod(pkg, call)
+			if method == "" {
+				return true
+			}
+			args := call.Args
+			if method == "AddNamed" {
+				args = args[1:]
+			}
+			var values []string
+			for _, arg := range args[:len(args)-1] {
+				value, ok := constantString(pkg, arg)
+				if !ok {
+					return true
+				}
+				values = append(values, value)
+			}
+			r := route{version: values[0], path: values[len(values)-1]}
+			if method == "AddAll" {
+				r.methods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+			} else {
+				r.methods = []string{values[1]}
+			}
+			r.handler, r.authenticated = resolveHandler(pkg, args[len(args)-1])
+			routes = append(routes, r)
+			return true
+		})
+	}
+	return routes
+}
+
+func routerMethod(pkg *sourcePackage, call *ast.CallExpr) string {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	selection, ok := pkg.info.Selections[sel]
+	if !ok || selection.Kind() != types.MethodVal {
+		return ""
+	}
+	recv := selection.Recv()
+	if ptr, ok := recv.(*types.Pointer); ok {
+		recv = ptr.Elem()
+	}
+	named, ok := recv.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || named.Obj().Pkg().Path() != routerPkgPath || named.Obj().Name() != "DelayedRouter" {
+		return ""
+	}
+	switch name := sel.Sel.Name; name {
+	case "Add", "AddNamed", "AddAll":
+		return name
+	}
+	return ""
+}
+
+func constantString(pkg *sourcePackage, expr ast.Expr) (string, bool) {
+	tv, ok := pkg.info.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
+
+// resolveHandler unwraps conversions and single argument wrappers, like
+// AuthorizationRequiredHandler(appInfo), down to the handler function.
+func resolveHandler(pkg *sourcePackage, expr ast.Expr) (*types.Func, bool) {
+	var authenticated bool
+	for {
+		switch e := expr.(type) {
+		case *ast.ParenExpr:
+			expr = e.X
+			continue
+		case *ast.CallExpr:
+			if len(e.Args) != 1 {
+				return nil, authenticated
+			}
+			if ident := calleeIdent(e.Fun); ident != nil && ident.Name == authorizationWrapper {
+				authenticated = true
+			}
+			expr = e.Args[0]
+			continue
+		}
+		ident := calleeIdent(expr)
+		if ident == nil {
+			return nil, authenticated
+		}
+		fn, _ := pkg.info.Uses[ident].(*types.Func)
+		return fn, authenticated
+	}
+}
+
+func calleeIdent(expr ast.Expr) *ast.Ident {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e
+	case *ast.SelectorExpr:
+		return e.Sel
+	}
+	return nil
+}
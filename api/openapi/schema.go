@@ -0,0 +1,181 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openapi
+
+import (
+	"go/types"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+const modulePath = "github.com/tsuru/tsuru/"
+
+var invalidComponentChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// schemaBuilder converts Go types into schemas following the rules of
+// encoding/json. Named struct types become components, referenced wherever
+// they're used.
+type schemaBuilder struct {
+	components map[string]*Schema
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{components: map[string]*Schema{}}
+}
+
+func (b *schemaBuilder) schema(t types.Type) *Schema {
+	t = types.Unalias(t)
+	if named, ok := t.(*types.Named); ok {
+		return b.namedSchema(named)
+	}
+	switch u := t.(type) {
+	case *types.Basic:
+		return basicSchema(u)
+	case *types.Pointer:
+		return b.schema(u.Elem())
+	case *types.Slice:
+		if basic, ok := types.Unalias(u.Elem()).(*types.Basic); ok && basic.Kind() == types.Byte {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.schemaOrAny(u.Elem())}
+	case *types.Array:
+		return &Schema{Type: "array", Items: b.schemaOrAny(u.Elem())}
+	case *types.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schemaOrAny(u.Elem())}
+	case *types.Interface:
+		return &Schema{}
+	case *types.Struct:
+		return b.structSchema(u)
+	}
+	// channels and functions can't be encoded as JSON.
+	return nil
+}
+
+func (b *schemaBuilder) schemaOrAny(t types.Type) *Schema {
+	if s := b.schema(t); s != nil {
+		return s
+	}
+	return &Schema{}
+}
+
+func (b *schemaBuilder) namedSchema(named *types.Named) *Schema {
+	obj := named.Obj()
+	if obj.Pkg() != nil && obj.Pkg().Path() == "time" && obj.Name() == "Time" {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	if hasMethod(named, "MarshalJSON") {
+		return &Schema{}
+	}
+	if hasMethod(named, "MarshalText") {
+		return &Schema{Type: "string"}
+	}
+	st, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return b.schema(named.Underlying())
+	}
+	name := componentName(named)
+	if _, ok := b.components[name]; !ok {
+		// the component is registered before its fields are visited, so
+		// recursive types end up referencing themselves.
+		component := &Schema{}
+		b.components[name] = component
+		*component = *b.structSchema(st)
+	}
+	return &Schema{Ref: schemaRefPrefix + name}
+}
+
+func (b *schemaBuilder) structSchema(st *types.Struct) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	b.addFields(schema, st)
+	if len(schema.Properties) == 0 {
+		schema.Properties = nil
+	}
+	return schema
+}
+
+func (b *schemaBuilder) addFields(schema *Schema, st *types.Struct) {
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		name, opts, _ := strings.Cut(reflect.StructTag(st.Tag(i)).Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		if field.Embedded() && name == "" {
+			fieldType := types.Unalias(field.Type())
+			if ptr, ok := fieldType.(*types.Pointer); ok {
+				fieldType = types.Unalias(ptr.Elem())
+			}
+			if embedded, ok := fieldType.Underlying().(*types.Struct); ok {
+				b.addFields(schema, embedded)
+				continue
+			}
+		}
+		if !field.Exported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name()
+		}
+		if _, ok := schema.Properties[name]; ok {
+			// fields of the outer struct take precedence over embedded ones.
+			continue
+		}
+		var fieldSchema *Schema
+		if strings.Contains(","+opts+",", ",string,") {
+			fieldSchema = &Schema{Type: "string"}
+		} else {
+			fieldSchema = b.schema(field.Type())
+		}
+		if fieldSchema != nil {
+			schema.Properties[name] = fieldSchema
+		}
+	}
+}
+
+func basicSchema(t *types.Basic) *Schema {
+	info := t.Info()
+	switch {
+	case info&types.IsBoolean != 0:
+		return &Schema{Type: "boolean"}
+	case info&types.IsInteger != 0:
+		switch t.Kind() {
+		case types.Int64, types.Uint64, types.Int, types.Uint:
+			return &Schema{Type: "integer", Format: "int64"}
+		}
+		return &Schema{Type: "integer", Format: "int32"}
+	case info&types.IsFloat != 0:
+		if t.Kind() == types.Float32 {
+			return &Schema{Type: "number", Format: "float"}
+		}
+		return &Schema{Type: "number", Format: "double"}
+	case info&types.IsString != 0:
+		return &Schema{Type: "string"}
+	}
+	return &Schema{}
+}
+
+func hasMethod(t types.Type, name string) bool {
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(t), true, nil, name)
+	_, ok := obj.(*types.Func)
+	return ok
+}
+
+// componentName identifies a named type by its package path, relative to
+// the tsuru module when it's part of it, e.g. types.app.AppSwap.
+func componentName(named *types.Named) string {
+	obj := named.Obj()
+	name := obj.Name()
+	if obj.Pkg() != nil {
+		path := strings.TrimPrefix(obj.Pkg().Path(), modulePath)
+		name = strings.ReplaceAll(path, "/", ".") + "." + name
+	}
+	if args := named.TypeArgs(); args != nil {
+		for i := 0; i < args.Len(); i++ {
+			name += "_" + types.TypeString(args.At(i), func(p *types.Package) string { return p.Name() })
+		}
+	}
+	return invalidComponentChars.ReplaceAllString(name, "_")
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sample
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	apiRouter "github.com/tsuru/tsuru/api/router"
+)
+
+type Handler func(http.ResponseWriter, *http.Request) error
+
+func (fn Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fn(w, r)
+}
+
+type AuthorizationRequiredHandler func(http.ResponseWriter, *http.Request) error
+
+func (fn AuthorizationRequiredHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fn(w, r)
+}
+
+func InputValue(r *http.Request, field string) string {
+	return r.FormValue(field)
+}
+
+func ParseInput(r *http.Request, dst interface{}) error {
+	return json.NewDecoder(r.Body).Decode(dst)
+}
+
+type Base struct {
+	ID string `json:"id"`
+}
+
+type Item struct {
+	Base
+	Name      string            `json:"name"`
+	Size      int64             `json:"size,string"`
+	Weight    float64           `json:"weight,omitempty"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Children  []Item            `json:"children"`
+	Labels    map[string]string `json:"labels"`
+	Data      []byte
+	Secret    string `json:"-"`
+	internal  string
+}
+
+func Routes() http.Handler {
+	m := apiRouter.NewRouter()
+	m.Add("1.0", http.MethodGet, "/items", AuthorizationRequiredHandler(listItems))
+	m.Add("1.2", http.MethodPost, "/items", AuthorizationRequiredHandler(createItem))
+	m.Add("1.2", http.MethodPost, "/items/{name}/rename", AuthorizationRequiredHandler(renameItem))
+	m.AddNamed("files", "1.1", http.MethodGet, "/files/{path:.*}", Handler(getFile))
+	return m
+}
+
+// title: item list
+// path: /items
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	204: No content
+func listItems(w http.ResponseWriter, r *http.Request) error {
+	_ = r.URL.Query().Get("name")
+	_ = InputValue(r, "label")
+	return json.NewEncoder(w).Encode([]Item{})
+}
+
+// title: item create
+// path: /items
+// method: POST
+// consume: application/json
+// produce: application/json
+// responses:
+//
+//	201: Created
+//	400: Invalid data
+func createItem(w http.ResponseWriter, r *http.Request) error {
+	var item Item
+	err := ParseInput(r, &item)
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(item)
+}
+
+// title: item rename
+// path: /items/{name}/rename
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//
+//	200: OK
+//	404: Not found
+func renameItem(w http.ResponseWriter, r *http.Request) error {
+	_ = r.URL.Query().Get(":name")
+	_ = InputValue(r, "newName")
+	return nil
+}
+
+func getFile(w http.ResponseWriter, r *http.Request) error {
+	_, err := w.Write(nil)
+	return err
+}
@@ -0,0 +1,30 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestOpenAPI(c *check.C) {
+	request, err := http.NewRequest(http.MethodGet, "/1.24/openapi.json", nil)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var doc struct {
+		OpenAPI string                 `json:"openapi"`
+		Paths   map[string]interface{} `json:"paths"`
+	}
+	err = json.NewDecoder(recorder.Body).Decode(&doc)
+	c.Assert(err, check.IsNil)
+	c.Assert(doc.OpenAPI, check.Equals, "3.0.3")
+	c.Assert(doc.Paths["/1.24/swaps/{id}/revert"], check.NotNil)
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/servicemanager"
 	authTypes "github.com/tsuru/tsuru/types/auth"
 	eventTypes "github.com/tsuru/tsuru/types/event"
@@ -270,3 +271,128 @@ func changeTeamQuota(w http.ResponseWriter, r *http.Request, t auth.Token) (err
 	}
 	return err
 }
+
+// title: team resource quotas
+// path: /teams/{name}/resource-quotas
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	204: No content
+//	401: Unauthorized
+//	404: Team not found
+func listTeamResourceQuotas(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	teamName := r.URL.Query().Get(":name")
+	allowed := permission.Check(ctx, t, permission.PermTeamReadQuota, permission.Context(permTypes.CtxTeam, teamName))
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	_, err := servicemanager.Team.FindByName(ctx, teamName)
+	if err == authTypes.ErrTeamNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	quotas, err := servicemanager.ResourceQuota.List(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	if len(quotas) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(quotas)
+}
+
+// title: set team resource quota
+// path: /teams/{name}/resource-quotas
+// method: PUT
+// consume: application/json
+// responses:
+//
+//	200: Quota updated
+//	400: Invalid data
+//	401: Unauthorized
+//	404: Team or pool not found
+func setTeamResourceQuota(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	teamName := r.URL.Query().Get(":name")
+	allowed := permission.Check(ctx, t, permission.PermTeamUpdateQuota, permission.Context(permTypes.CtxTeam, teamName))
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	q := quota.ResourceQuota{Limits: quota.UnlimitedResources}
+	err = ParseJSON(r, &q)
+	if err != nil {
+		return err
+	}
+	q.Team = teamName
+	_, err = servicemanager.Team.FindByName(ctx, teamName)
+	if err == authTypes.ErrTeamNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	if q.Pool != "" {
+		_, err = pool.GetPoolByName(ctx, q.Pool)
+		if err == pool.ErrPoolNotFound {
+			return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypeTeam, Value: teamName},
+		Kind:       permission.PermTeamUpdateQuota,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: q,
+		Allowed:    event.Allowed(permission.PermTeamReadEvents, permission.Context(permTypes.CtxTeam, teamName)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	return servicemanager.ResourceQuota.Set(ctx, q)
+}
+
+// title: remove team resource quota
+// path: /teams/{name}/resource-quotas
+// method: DELETE
+// responses:
+//
+//	200: Quota removed
+//	401: Unauthorized
+//	404: Quota not found
+func removeTeamResourceQuota(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	teamName := r.URL.Query().Get(":name")
+	poolName := r.URL.Query().Get("pool")
+	allowed := permission.Check(ctx, t, permission.PermTeamUpdateQuota, permission.Context(permTypes.CtxTeam, teamName))
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypeTeam, Value: teamName},
+		Kind:       permission.PermTeamUpdateQuota,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermTeamReadEvents, permission.Context(permTypes.CtxTeam, teamName)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = servicemanager.ResourceQuota.Delete(ctx, teamName, poolName)
+	if err == quota.ErrResourceQuotaNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
@@ -672,3 +672,115 @@ func (s *QuotaSuite) TestChangeAppQuotaLimitLowerThanAllocated(c *check.C) {
 		ErrorMatches: `New limit is less than the current allocated value`,
 	}, eventtest.HasEvent)
 }
+
+func (s *QuotaSuite) TestListTeamResourceQuotas(c *check.C) {
+	team := &authTypes.Team{Name: "avengers"}
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		c.Assert(name, check.Equals, team.Name)
+		return team, nil
+	}
+	quotas := []quota.ResourceQuotaInfo{
+		{
+			ResourceQuota: quota.ResourceQuota{Team: team.Name, Limits: quota.ResourceList{MilliCPU: 4000, Memory: -1, Storage: -1}},
+			InUse:         quota.ResourceList{MilliCPU: 1000, Memory: 1024},
+		},
+	}
+	s.mockService.ResourceQuota.OnList = func(name string) ([]quota.ResourceQuotaInfo, error) {
+		c.Assert(name, check.Equals, team.Name)
+		return quotas, nil
+	}
+	request, _ := http.NewRequest("GET", "/teams/avengers/resource-quotas", nil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var result []quota.ResourceQuotaInfo
+	err := json.NewDecoder(recorder.Body).Decode(&result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, quotas)
+}
+
+func (s *QuotaSuite) TestListTeamResourceQuotasEmpty(c *check.C) {
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		return &authTypes.Team{Name: name}, nil
+	}
+	request, _ := http.NewRequest("GET", "/teams/avengers/resource-quotas", nil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *QuotaSuite) TestSetTeamResourceQuota(c *check.C) {
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		return &authTypes.Team{Name: name}, nil
+	}
+	var stored []quota.ResourceQuota
+	s.mockService.ResourceQuota.OnSet = func(q quota.ResourceQuota) error {
+		stored = append(stored, q)
+		return nil
+	}
+	body := bytes.NewBufferString(`{"limits": {"milliCPU": 2000}}`)
+	request, _ := http.NewRequest("PUT", "/teams/avengers/resource-quotas", body)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(stored, check.DeepEquals, []quota.ResourceQuota{
+		{Team: "avengers", Limits: quota.ResourceList{MilliCPU: 2000, Memory: -1, Storage: -1}},
+	})
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypeTeam, Value: "avengers"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "team.update.quota",
+	}, eventtest.HasEvent)
+}
+
+func (s *QuotaSuite) TestSetTeamResourceQuotaPoolNotFound(c *check.C) {
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		return &authTypes.Team{Name: name}, nil
+	}
+	body := bytes.NewBufferString(`{"pool": "unknown", "limits": {"milliCPU": 2000}}`)
+	request, _ := http.NewRequest("PUT", "/teams/avengers/resource-quotas", body)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *QuotaSuite) TestSetTeamResourceQuotaRequiresPermission(c *check.C) {
+	token := userWithPermission(c)
+	body := bytes.NewBufferString(`{"limits": {"milliCPU": 2000}}`)
+	request, _ := http.NewRequest("PUT", "/teams/avengers/resource-quotas", body)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *QuotaSuite) TestRemoveTeamResourceQuota(c *check.C) {
+	s.mockService.ResourceQuota.OnDelete = func(team, pool string) error {
+		c.Assert(team, check.Equals, "avengers")
+		c.Assert(pool, check.Equals, "pool1")
+		return nil
+	}
+	request, _ := http.NewRequest("DELETE", "/teams/avengers/resource-quotas?pool=pool1", nil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+}
+
+func (s *QuotaSuite) TestRemoveTeamResourceQuotaNotFound(c *check.C) {
+	s.mockService.ResourceQuota.OnDelete = func(team, pool string) error {
+		return quota.ErrResourceQuotaNotFound
+	}
+	request, _ := http.NewRequest("DELETE", "/teams/avengers/resource-quotas", nil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
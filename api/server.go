@@ -42,6 +42,7 @@ import (
 	_ "github.com/tsuru/tsuru/auth/native"
 	_ "github.com/tsuru/tsuru/auth/oauth"
 	_ "github.com/tsuru/tsuru/auth/oidc"
+	"github.com/tsuru/tsuru/cost"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/event/webhook"
 	"github.com/tsuru/tsuru/hc"
@@ -147,6 +148,10 @@ func setupServices() error {
 	if err != nil {
 		return errors.Wrapf(err, "could not initialize team quota service")
 	}
+	servicemanager.ResourceQuota, err = app.ResourceQuotaService()
+	if err != nil {
+		return errors.Wrapf(err, "could not initialize resource quota service")
+	}
 	servicemanager.Webhook, err = webhook.WebhookService()
 	if err != nil {
 		return errors.Wrapf(err, "could not initialize webhook service")
@@ -167,6 +172,10 @@ func setupServices() error {
 	if err != nil {
 		return errors.Wrapf(err, "could not initialize app log service")
 	}
+	servicemanager.LogDrain, err = applog.LogDrainService()
+	if err != nil {
+		return errors.Wrapf(err, "could not initialize log drain service")
+	}
 	servicemanager.InstanceTracker, err = tracker.InstanceService()
 	if err != nil {
 		return errors.Wrapf(err, "could not initialize app tracker service")
@@ -195,6 +204,10 @@ func setupServices() error {
 	if err != nil {
 		return errors.Wrapf(err, "could not initialize job service")
 	}
+	servicemanager.JobRun, err = job.JobRunService()
+	if err != nil {
+		return errors.Wrapf(err, "could not initialize job run service")
+	}
 	servicemanager.Tag, err = tag.TagService()
 	if err != nil {
 		return errors.Wrapf(err, "could not initialize tag service")
@@ -235,6 +248,7 @@ func RunServer(dry bool) http.Handler {
 		m.Add("1.0", http.MethodGet, "/", Handler(index))
 	}
 	m.Add("1.0", http.MethodGet, "/info", AuthorizationRequiredHandler(info))
+	m.Add("1.24", http.MethodGet, "/openapi.json", Handler(openAPI))
 
 	m.Add("1.0", http.MethodGet, "/services/instances", AuthorizationRequiredHandler(serviceInstances))
 	m.Add("1.0", http.MethodPost, "/services/{service}/instances", AuthorizationRequiredHandler(createServiceInstance))
@@ -246,6 +260,11 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", http.MethodDelete, "/services/{service}/instances/{instance}/{app}", AuthorizationRequiredHandler(unbindServiceInstance))
 	m.Add("1.13", http.MethodPut, "/services/{service}/instances/{instance}/apps/{app}", AuthorizationRequiredHandler(bindServiceInstance))
 	m.Add("1.13", http.MethodDelete, "/services/{service}/instances/{instance}/apps/{app}", AuthorizationRequiredHandler(unbindServiceInstance))
+	m.Add("1.24", http.MethodPost, "/services/{service}/instances/{instance}/apps/{app}/rotate", AuthorizationRequiredHandler(serviceInstanceRotateAppCredentials))
+	m.Add("1.24", http.MethodPost, "/services/{service}/instances/{instance}/rotate", AuthorizationRequiredHandler(serviceInstanceRotateCredentials))
+	m.Add("1.24", http.MethodGet, "/services/{service}/instances/{instance}/backups", AuthorizationRequiredHandler(serviceInstanceBackupList))
+	m.Add("1.24", http.MethodPost, "/services/{service}/instances/{instance}/backups", AuthorizationRequiredHandler(serviceInstanceBackupCreate))
+	m.Add("1.24", http.MethodPost, "/services/{service}/instances/{instance}/backups/{backup}/restore", AuthorizationRequiredHandler(serviceInstanceBackupRestore))
 	m.Add("1.13", http.MethodPut, "/services/{service}/instances/{instance}/jobs/{job}", AuthorizationRequiredHandler(bindJobServiceInstance))
 	m.Add("1.13", http.MethodDelete, "/services/{service}/instances/{instance}/jobs/{job}", AuthorizationRequiredHandler(unbindJobServiceInstance))
 
@@ -271,6 +290,8 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", http.MethodGet, "/apps", AuthorizationRequiredHandler(appList))
 	m.Add("1.0", http.MethodPost, "/apps", AuthorizationRequiredHandler(createApp))
 	m.Add("1.0", http.MethodGet, "/apps/{app}", AuthorizationRequiredHandler(appInfo))
+	m.Add("1.24", http.MethodGet, "/apps/{app}/manifest", AuthorizationRequiredHandler(appManifest))
+	m.Add("1.24", http.MethodPost, "/apps/{app}/manifest/apply", AuthorizationRequiredHandler(appManifestApply))
 	m.Add("1.0", http.MethodDelete, "/apps/{app}", AuthorizationRequiredHandler(appDelete))
 	m.Add("1.0", http.MethodPut, "/apps/{app}", AuthorizationRequiredHandler(updateApp))
 	m.Add("1.0", http.MethodPost, "/apps/{app}/cname", AuthorizationRequiredHandler(setCName))
@@ -300,10 +321,16 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", http.MethodPost, "/apps/{app}/deploy/rollback", AuthorizationRequiredHandler(deployRollback))
 	m.Add("1.4", http.MethodPut, "/apps/{app}/deploy/rollback/update", AuthorizationRequiredHandler(deployRollbackUpdate))
 	m.Add("1.3", http.MethodPost, "/apps/{app}/deploy/rebuild", AuthorizationRequiredHandler(deployRebuild))
+	m.Add("1.24", http.MethodPost, "/apps/{app}/deploy/canary", AuthorizationRequiredHandler(deployCanary))
+	m.Add("1.24", http.MethodGet, "/apps/{app}/log-drains", AuthorizationRequiredHandler(logDrainList))
+	m.Add("1.24", http.MethodPost, "/apps/{app}/log-drains", AuthorizationRequiredHandler(logDrainCreate))
+	m.Add("1.24", http.MethodDelete, "/apps/{app}/log-drains/{name}", AuthorizationRequiredHandler(logDrainDelete))
 	m.Add("1.0", http.MethodPost, "/apps/{app}/routes", AuthorizationRequiredHandler(appRebuildRoutes))
 	m.Add("1.2", http.MethodGet, "/apps/{app}/certificate", AuthorizationRequiredHandler(listCertificates))
 	m.Add("1.2", http.MethodPut, "/apps/{app}/certificate", AuthorizationRequiredHandler(setCertificate))
 	m.Add("1.2", http.MethodDelete, "/apps/{app}/certificate", AuthorizationRequiredHandler(unsetCertificate))
+	m.Add("1.24", http.MethodGet, "/apps/{app}/certificate/managed", AuthorizationRequiredHandler(listManagedCertificates))
+	m.Add("1.24", http.MethodGet, "/.well-known/acme-challenge/{token}", http.HandlerFunc(acmeChallenge))
 
 	m.Add("1.5", http.MethodPost, "/apps/{app}/routers", AuthorizationRequiredHandler(addAppRouter))
 	m.Add("1.5", http.MethodPut, "/apps/{app}/routers/{router}", AuthorizationRequiredHandler(updateAppRouter))
@@ -312,9 +339,12 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.8", http.MethodPost, "/apps/{app}/routable", AuthorizationRequiredHandler(appSetRoutable))
 	m.Add("1.0", http.MethodGet, "/deploys", AuthorizationRequiredHandler(deploysList))
 	m.Add("1.0", http.MethodGet, "/deploys/{deploy}", AuthorizationRequiredHandler(deployInfo))
+	m.Add("1.24", http.MethodPost, "/deploys/{deploy}/approve", AuthorizationRequiredHandler(deployApprove))
+	m.Add("1.24", http.MethodPost, "/deploys/{deploy}/reject", AuthorizationRequiredHandler(deployReject))
 
 	m.Add("1.1", http.MethodGet, "/events", AuthorizationRequiredHandler(eventList))
 	m.Add("1.3", http.MethodGet, "/events/blocks", AuthorizationRequiredHandler(eventBlockList))
+	m.Add("1.24", http.MethodGet, "/events/blocks/upcoming", AuthorizationRequiredHandler(eventBlockUpcoming))
 	m.Add("1.3", http.MethodPost, "/events/blocks", AuthorizationRequiredHandler(eventBlockAdd))
 	m.Add("1.3", http.MethodDelete, "/events/blocks/{uuid}", AuthorizationRequiredHandler(eventBlockRemove))
 	m.Add("1.1", http.MethodGet, "/events/kinds", AuthorizationRequiredHandler(kindList))
@@ -326,6 +356,8 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.6", http.MethodGet, "/events/webhooks/{name}", AuthorizationRequiredHandler(webhookInfo))
 	m.Add("1.6", http.MethodPut, "/events/webhooks/{name}", AuthorizationRequiredHandler(webhookUpdate))
 	m.Add("1.6", http.MethodDelete, "/events/webhooks/{name}", AuthorizationRequiredHandler(webhookDelete))
+	m.Add("1.24", http.MethodGet, "/events/webhooks/{name}/deliveries", AuthorizationRequiredHandler(webhookDeliveries))
+	m.Add("1.24", http.MethodPost, "/events/webhooks/{name}/deliveries/{id}/redeliver", AuthorizationRequiredHandler(webhookRedeliver))
 
 	m.Add("1.0", http.MethodGet, "/platforms", AuthorizationRequiredHandler(platformList))
 	m.Add("1.0", http.MethodPost, "/platforms", AuthorizationRequiredHandler(platformAdd))
@@ -372,10 +404,15 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.4", http.MethodGet, "/teams/{name}", AuthorizationRequiredHandler(teamInfo))
 	m.Add("1.12", http.MethodGet, "/teams/{name}/quota", AuthorizationRequiredHandler(getTeamQuota))
 	m.Add("1.12", http.MethodPut, "/teams/{name}/quota", AuthorizationRequiredHandler(changeTeamQuota))
+	m.Add("1.24", http.MethodGet, "/teams/{name}/resource-quotas", AuthorizationRequiredHandler(listTeamResourceQuotas))
+	m.Add("1.24", http.MethodPut, "/teams/{name}/resource-quotas", AuthorizationRequiredHandler(setTeamResourceQuota))
+	m.Add("1.24", http.MethodDelete, "/teams/{name}/resource-quotas", AuthorizationRequiredHandler(removeTeamResourceQuota))
 	m.Add("1.17", http.MethodGet, "/teams/{name}/users", AuthorizationRequiredHandler(teamUserList))
 	m.Add("1.17", http.MethodGet, "/teams/{name}/groups", AuthorizationRequiredHandler(teamGroupList))
 
 	m.Add("1.0", http.MethodPost, "/swap", AuthorizationRequiredHandler(swap))
+	m.Add("1.24", http.MethodGet, "/swaps", AuthorizationRequiredHandler(listSwaps))
+	m.Add("1.24", http.MethodPost, "/swaps/{id}/revert", AuthorizationRequiredHandler(revertSwap))
 
 	m.Add("1.0", http.MethodGet, "/healthcheck/", http.HandlerFunc(healthcheck))
 	m.Add("1.0", http.MethodGet, "/healthcheck", http.HandlerFunc(healthcheck))
@@ -391,6 +428,9 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", http.MethodPost, "/pools/{name}/team", AuthorizationRequiredHandler(addTeamToPoolHandler))
 	m.Add("1.0", http.MethodDelete, "/pools/{name}/team", AuthorizationRequiredHandler(removeTeamToPoolHandler))
 	m.Add("1.8", http.MethodGet, "/pools/{name}", AuthorizationRequiredHandler(getPoolHandler))
+	m.Add("1.24", http.MethodGet, "/pools/{name}/price", AuthorizationRequiredHandler(poolPriceGet))
+	m.Add("1.24", http.MethodPut, "/pools/{name}/price", AuthorizationRequiredHandler(poolPriceSet))
+	m.Add("1.24", http.MethodGet, "/reports/cost", AuthorizationRequiredHandler(costReport))
 
 	m.Add("1.3", http.MethodGet, "/constraints", AuthorizationRequiredHandler(poolConstraintList))
 	m.Add("1.3", http.MethodPut, "/constraints", AuthorizationRequiredHandler(poolConstraintSet))
@@ -474,6 +514,8 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.13", http.MethodPost, "/jobs/{name}/env", AuthorizationRequiredHandler(setJobEnv))
 	m.Add("1.13", http.MethodDelete, "/jobs/{name}/env", AuthorizationRequiredHandler(unsetJobEnv))
 	m.Add("1.13", http.MethodGet, "/jobs/{name}/log", AuthorizationRequiredHandler(jobLog))
+	m.Add("1.24", http.MethodGet, "/jobs/{name}/runs", AuthorizationRequiredHandler(jobRuns))
+	m.Add("1.24", http.MethodGet, "/jobs/{name}/runs/{id}", AuthorizationRequiredHandler(jobRun))
 	m.Add("1.13", http.MethodDelete, "/jobs/{name}/units/{unit}", AuthorizationRequiredHandler(killJob))
 	m.Add("1.23", http.MethodPost, "/jobs/{name}/deploy", AuthorizationRequiredHandler(jobDeploy))
 
@@ -592,6 +634,30 @@ func startServer(handler http.Handler) error {
 	if err != nil {
 		return errors.Wrap(err, "unable to initialize old image gc")
 	}
+	err = cost.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize cost sampler")
+	}
+	err = service.InitializeOperationPoller()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize broker operation poller")
+	}
+	err = job.InitializeWorkflowTimeouts()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize job workflow timeouts")
+	}
+	err = app.InitializeCertificateManager()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize certificate manager")
+	}
+	err = app.InitializeRouterReconciler()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize router reconciler")
+	}
+	err = applog.InitializeProvisionerDrains()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize log drains for provisioner logs")
+	}
 	fmt.Println("Checking components status:")
 	results := hc.Check(ctx, "all")
 	for _, result := range results {
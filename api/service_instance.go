@@ -470,6 +470,7 @@ type serviceInstanceInfo struct {
 	CustomInfo      map[string]string
 	Tags            []string
 	Parameters      map[string]interface{}
+	OperationStatus string `json:",omitempty"`
 }
 
 // title: service instance info
@@ -520,6 +521,7 @@ func serviceInstance(w http.ResponseWriter, r *http.Request, t auth.Token) error
 		CustomInfo:      info,
 		Tags:            serviceInstance.Tags,
 		Parameters:      serviceInstance.Parameters,
+		OperationStatus: serviceInstance.OperationStatus,
 	}
 	if sInfo.PlanName == "" {
 		sInfo.PlanName = serviceInstance.PlanName
@@ -860,6 +862,107 @@ func serviceInstanceRevokeTeam(w http.ResponseWriter, r *http.Request, t auth.To
 	return serviceInstance.Revoke(ctx, teamName)
 }
 
+// title: rotate app credentials of service instance
+// path: /services/{service}/instances/{instance}/apps/{app}/rotate
+// method: POST
+// produce: application/x-json-stream
+// responses:
+//
+//	200: Credentials rotated
+//	400: App not bound to the service instance
+//	401: Unauthorized
+//	404: Service instance or app not found
+func serviceInstanceRotateAppCredentials(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	return rotateServiceInstanceCredentials(w, r, t, []string{r.URL.Query().Get(":app")})
+}
+
+// title: rotate credentials of all apps bound to service instance
+// path: /services/{service}/instances/{instance}/rotate
+// method: POST
+// produce: application/x-json-stream
+// responses:
+//
+//	200: Credentials rotated
+//	400: No apps bound to the service instance
+//	401: Unauthorized
+//	404: Service instance not found
+func serviceInstanceRotateCredentials(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	return rotateServiceInstanceCredentials(w, r, t, nil)
+}
+
+// rotateServiceInstanceCredentials rotates the credentials of the given apps,
+// or of every app bound to the instance when appNames is nil, within a
+// single event.
+func rotateServiceInstanceCredentials(w http.ResponseWriter, r *http.Request, t auth.Token, appNames []string) (err error) {
+	ctx := r.Context()
+	instanceName := r.URL.Query().Get(":instance")
+	serviceName := r.URL.Query().Get(":service")
+	si, err := getServiceInstanceOrError(ctx, serviceName, instanceName)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermServiceInstanceUpdateCredentials,
+		contextsForServiceInstance(si, serviceName)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	if appNames == nil {
+		appNames = si.Apps
+	}
+	if len(appNames) == 0 {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "No apps bound to the service instance."}
+	}
+	force, _ := strconv.ParseBool(InputValue(r, "force"))
+	var extraTargets []eventTypes.ExtraTarget
+	for _, appName := range appNames {
+		a, err := getApp(ctx, appName)
+		if err != nil {
+			return err
+		}
+		if si.FindApp(appName) == -1 {
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: service.ErrAppNotBound.Error()}
+		}
+		if !permission.Check(ctx, t, permission.PermAppUpdateBind, contextsForApp(a)...) {
+			return permission.ErrUnauthorized
+		}
+		extraTargets = append(extraTargets, eventTypes.ExtraTarget{Target: appTarget(appName), Lock: true})
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:       serviceInstanceTarget(serviceName, instanceName),
+		ExtraTargets: extraTargets,
+		Kind:         permission.PermServiceInstanceUpdateCredentials,
+		Owner:        t,
+		RemoteAddr:   r.RemoteAddr,
+		CustomData:   event.FormToCustomData(InputFields(r)),
+		Allowed: event.Allowed(permission.PermServiceInstanceReadEvents,
+			contextsForServiceInstance(si, serviceName)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	for _, appName := range appNames {
+		// apps are fetched again as they may have changed while the event
+		// was waiting for the lock.
+		a, err := getApp(ctx, appName)
+		if err != nil {
+			return err
+		}
+		err = si.RotateAppCredentials(ctx, a, force, evt, requestIDHeader(r))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(writer, "\nCredentials of instance %q rotated in app %q.\n", instanceName, appName)
+	}
+	return nil
+}
+
 func contextsForServiceInstance(si *service.ServiceInstance, serviceName string) []permTypes.PermissionContext {
 	permissionValue := serviceIntancePermName(serviceName, si.Name)
 	return append(permission.Contexts(permTypes.CtxTeam, si.Teams),
@@ -0,0 +1,149 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/service"
+)
+
+// title: service instance backup list
+// path: /services/{service}/instances/{instance}/backups
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	204: No content
+//	400: Service does not support backups
+//	401: Unauthorized
+//	404: Service instance not found
+func serviceInstanceBackupList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	serviceName := r.URL.Query().Get(":service")
+	instanceName := r.URL.Query().Get(":instance")
+	si, err := getServiceInstanceOrError(ctx, serviceName, instanceName)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermServiceInstanceReadBackups,
+		contextsForServiceInstance(si, serviceName)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	backups, err := si.Backups(ctx, requestIDHeader(r))
+	if err != nil {
+		return backupError(err)
+	}
+	if len(backups) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(backups)
+}
+
+// title: service instance backup create
+// path: /services/{service}/instances/{instance}/backups
+// method: POST
+// produce: application/json
+// responses:
+//
+//	201: Backup created
+//	400: Service does not support backups
+//	401: Unauthorized
+//	404: Service instance not found
+func serviceInstanceBackupCreate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	serviceName := r.URL.Query().Get(":service")
+	instanceName := r.URL.Query().Get(":instance")
+	si, err := getServiceInstanceOrError(ctx, serviceName, instanceName)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermServiceInstanceUpdateBackup,
+		contextsForServiceInstance(si, serviceName)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     serviceInstanceTarget(serviceName, instanceName),
+		Kind:       permission.PermServiceInstanceUpdateBackup,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed: event.Allowed(permission.PermServiceInstanceReadEvents,
+			contextsForServiceInstance(si, serviceName)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	backup, err := si.CreateBackup(ctx, evt, requestIDHeader(r))
+	if err != nil {
+		return backupError(err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(backup)
+}
+
+// title: service instance backup restore
+// path: /services/{service}/instances/{instance}/backups/{backup}/restore
+// method: POST
+// responses:
+//
+//	200: Restore requested
+//	400: Service does not support backups
+//	401: Unauthorized
+//	404: Service instance or backup not found
+func serviceInstanceBackupRestore(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	serviceName := r.URL.Query().Get(":service")
+	instanceName := r.URL.Query().Get(":instance")
+	backupID := r.URL.Query().Get(":backup")
+	si, err := getServiceInstanceOrError(ctx, serviceName, instanceName)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermServiceInstanceUpdateRestore,
+		contextsForServiceInstance(si, serviceName)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     serviceInstanceTarget(serviceName, instanceName),
+		Kind:       permission.PermServiceInstanceUpdateRestore,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed: event.Allowed(permission.PermServiceInstanceReadEvents,
+			contextsForServiceInstance(si, serviceName)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	return backupError(si.RestoreBackup(ctx, backupID, evt, requestIDHeader(r)))
+}
+
+func backupError(err error) error {
+	switch err {
+	case service.ErrBackupsNotSupported:
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	case service.ErrBackupNotFound, service.ErrInstanceNotFoundInAPI:
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
@@ -0,0 +1,140 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	stdContext "context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	"github.com/tsuru/tsuru/service"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *ServiceInstanceSuite) createBackupInstance(c *check.C, capabilities string) (*service.ServiceInstance, *httptest.Server) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /resources/my-mysql/capabilities":
+			w.Write([]byte(capabilities))
+		case "GET /resources/my-mysql/backups":
+			w.Write([]byte(`[{"id": "b1", "status": "done"}]`))
+		case "POST /resources/my-mysql/backups":
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"id": "b2", "status": "pending"}`))
+		case "POST /resources/my-mysql/backups/b1/restore":
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	srvc := service.Service{Name: "mysql", Endpoint: map[string]string{"production": ts.URL}, Password: "abcde", OwnerTeams: []string{s.team.Name}}
+	err := service.Create(stdContext.TODO(), srvc)
+	c.Assert(err, check.IsNil)
+	si := service.ServiceInstance{Name: "my-mysql", ServiceName: "mysql", Teams: []string{s.team.Name}}
+	serviceInstancesCollection, err := storagev2.ServiceInstancesCollection()
+	c.Assert(err, check.IsNil)
+	_, err = serviceInstancesCollection.InsertOne(stdContext.TODO(), si)
+	c.Assert(err, check.IsNil)
+	return &si, ts
+}
+
+func (s *ServiceInstanceSuite) TestServiceInstanceBackupList(c *check.C) {
+	si, ts := s.createBackupInstance(c, `["backups"]`)
+	defer ts.Close()
+	url := fmt.Sprintf("/services/%s/instances/%s/backups?:service=%s&:instance=%s", si.ServiceName, si.Name, si.ServiceName, si.Name)
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	err = serviceInstanceBackupList(recorder, request, s.token)
+	c.Assert(err, check.IsNil)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var backups []service.Backup
+	err = json.NewDecoder(recorder.Body).Decode(&backups)
+	c.Assert(err, check.IsNil)
+	c.Assert(backups, check.DeepEquals, []service.Backup{{ID: "b1", Status: "done"}})
+}
+
+func (s *ServiceInstanceSuite) TestServiceInstanceBackupListNotSupported(c *check.C) {
+	si, ts := s.createBackupInstance(c, `[]`)
+	defer ts.Close()
+	url := fmt.Sprintf("/services/%s/instances/%s/backups?:service=%s&:instance=%s", si.ServiceName, si.Name, si.ServiceName, si.Name)
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	err = serviceInstanceBackupList(recorder, request, s.token)
+	c.Assert(err, check.DeepEquals, &errors.HTTP{Code: http.StatusBadRequest, Message: service.ErrBackupsNotSupported.Error()})
+}
+
+func (s *ServiceInstanceSuite) TestServiceInstanceBackupListUnauthorized(c *check.C) {
+	si, ts := s.createBackupInstance(c, `["backups"]`)
+	defer ts.Close()
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "reader", permission.Permission{
+		Scheme:  permission.PermServiceInstanceReadStatus,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	url := fmt.Sprintf("/services/%s/instances/%s/backups?:service=%s&:instance=%s", si.ServiceName, si.Name, si.ServiceName, si.Name)
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	err = serviceInstanceBackupList(recorder, request, token)
+	c.Assert(err, check.Equals, permission.ErrUnauthorized)
+}
+
+func (s *ServiceInstanceSuite) TestServiceInstanceBackupCreate(c *check.C) {
+	si, ts := s.createBackupInstance(c, `["backups"]`)
+	defer ts.Close()
+	url := fmt.Sprintf("/services/%s/instances/%s/backups?:service=%s&:instance=%s", si.ServiceName, si.Name, si.ServiceName, si.Name)
+	request, err := http.NewRequest(http.MethodPost, url, nil)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	err = serviceInstanceBackupCreate(recorder, request, s.token)
+	c.Assert(err, check.IsNil)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+	var backup service.Backup
+	err = json.NewDecoder(recorder.Body).Decode(&backup)
+	c.Assert(err, check.IsNil)
+	c.Assert(backup, check.DeepEquals, service.Backup{ID: "b2", Status: "pending"})
+	c.Assert(eventtest.EventDesc{
+		Target: serviceInstanceTarget("mysql", "my-mysql"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "service-instance.update.backup",
+	}, eventtest.HasEvent)
+}
+
+func (s *ServiceInstanceSuite) TestServiceInstanceBackupRestore(c *check.C) {
+	si, ts := s.createBackupInstance(c, `["backups"]`)
+	defer ts.Close()
+	url := fmt.Sprintf("/services/%s/instances/%s/backups/b1/restore?:service=%s&:instance=%s&:backup=b1", si.ServiceName, si.Name, si.ServiceName, si.Name)
+	request, err := http.NewRequest(http.MethodPost, url, nil)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	err = serviceInstanceBackupRestore(recorder, request, s.token)
+	c.Assert(err, check.IsNil)
+	c.Assert(eventtest.EventDesc{
+		Target: serviceInstanceTarget("mysql", "my-mysql"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "service-instance.update.restore",
+	}, eventtest.HasEvent)
+}
+
+func (s *ServiceInstanceSuite) TestServiceInstanceBackupRestoreNotFound(c *check.C) {
+	si, ts := s.createBackupInstance(c, `["backups"]`)
+	defer ts.Close()
+	url := fmt.Sprintf("/services/%s/instances/%s/backups/b9/restore?:service=%s&:instance=%s&:backup=b9", si.ServiceName, si.Name, si.ServiceName, si.Name)
+	request, err := http.NewRequest(http.MethodPost, url, nil)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	err = serviceInstanceBackupRestore(recorder, request, s.token)
+	c.Assert(err, check.DeepEquals, &errors.HTTP{Code: http.StatusNotFound, Message: service.ErrBackupNotFound.Error()})
+}
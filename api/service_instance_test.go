@@ -21,6 +21,7 @@ import (
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/api/context"
 	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/bind"
 	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/db/storagev2"
 	"github.com/tsuru/tsuru/errors"
@@ -39,6 +40,7 @@ import (
 	tsuruTest "github.com/tsuru/tsuru/test"
 	appTypes "github.com/tsuru/tsuru/types/app"
 	authTypes "github.com/tsuru/tsuru/types/auth"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
 	permTypes "github.com/tsuru/tsuru/types/permission"
 	provisionTypes "github.com/tsuru/tsuru/types/provision"
 	serviceTypes "github.com/tsuru/tsuru/types/service"
@@ -2912,3 +2914,105 @@ func (s *ServiceInstanceSuite) TestGrantRevokeServiceToTeamWithManyInstanceName(
 	c.Assert(err, check.IsNil)
 	c.Assert(sinst.Teams, check.DeepEquals, []string{s.team.Name})
 }
+
+func (s *ServiceInstanceSuite) createRotationInstance(c *check.C, apps []string) (*app.App, *service.ServiceInstance) {
+	a := app.App{
+		Name:      "painkiller",
+		Platform:  "zend",
+		TeamOwner: s.team.Name,
+	}
+	err := app.CreateApp(stdContext.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.AddInstance(stdContext.TODO(), bind.AddInstanceArgs{
+		Envs: []bindTypes.ServiceEnvVar{{
+			EnvVar:       bindTypes.EnvVar{Name: "DATABASE_PASSWORD", Value: "old"},
+			ServiceName:  "mysql",
+			InstanceName: "my-mysql",
+		}},
+	})
+	c.Assert(err, check.IsNil)
+	si := service.ServiceInstance{
+		Name:        "my-mysql",
+		ServiceName: "mysql",
+		Teams:       []string{s.team.Name},
+		Apps:        apps,
+	}
+	serviceInstancesCollection, err := storagev2.ServiceInstancesCollection()
+	c.Assert(err, check.IsNil)
+	_, err = serviceInstancesCollection.InsertOne(stdContext.TODO(), si)
+	c.Assert(err, check.IsNil)
+	return &a, &si
+}
+
+func (s *ServiceInstanceSuite) TestServiceInstanceRotateAppCredentials(c *check.C) {
+	var calls []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		if r.Method == http.MethodPost {
+			w.Write([]byte(`{"DATABASE_PASSWORD": "new"}`))
+		}
+	}))
+	defer ts.Close()
+	srvc := service.Service{Name: "mysql", Endpoint: map[string]string{"production": ts.URL}, Password: "abcde", OwnerTeams: []string{s.team.Name}}
+	err := service.Create(stdContext.TODO(), srvc)
+	c.Assert(err, check.IsNil)
+	a, si := s.createRotationInstance(c, []string{"painkiller"})
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "rotator", permission.Permission{
+		Scheme:  permission.PermServiceInstanceUpdateCredentials,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	}, permission.Permission{
+		Scheme:  permission.PermAppUpdateBind,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	url := fmt.Sprintf("/services/%s/instances/%s/apps/%s/rotate?:service=%s&:instance=%s&:app=%s",
+		si.ServiceName, si.Name, a.Name, si.ServiceName, si.Name, a.Name)
+	request, err := http.NewRequest(http.MethodPost, url, nil)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	err = serviceInstanceRotateAppCredentials(recorder, request, token)
+	c.Assert(err, check.IsNil)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/x-json-stream")
+	c.Assert(recorder.Body.String(), check.Matches, `(?s).*Credentials of instance \\"my-mysql\\" rotated in app \\"painkiller\\".*`)
+	c.Assert(calls, check.DeepEquals, []string{
+		"POST /resources/my-mysql/bind-app/rotate",
+		"DELETE /resources/my-mysql/bind-app/rotate",
+	})
+	dbApp, err := app.GetByName(stdContext.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.InstanceEnvs("mysql", "my-mysql"), check.DeepEquals, map[string]bindTypes.EnvVar{
+		"DATABASE_PASSWORD": {Name: "DATABASE_PASSWORD", Value: "new"},
+	})
+	c.Assert(eventtest.EventDesc{
+		Target: serviceInstanceTarget("mysql", "my-mysql"),
+		Owner:  token.GetUserName(),
+		Kind:   "service-instance.update.credentials",
+	}, eventtest.HasEvent)
+}
+
+func (s *ServiceInstanceSuite) TestServiceInstanceRotateCredentialsNoApps(c *check.C) {
+	srvc := service.Service{Name: "mysql", Endpoint: map[string]string{"production": s.ts.URL}, Password: "abcde", OwnerTeams: []string{s.team.Name}}
+	err := service.Create(stdContext.TODO(), srvc)
+	c.Assert(err, check.IsNil)
+	_, si := s.createRotationInstance(c, nil)
+	url := fmt.Sprintf("/services/%s/instances/%s/rotate?:service=%s&:instance=%s",
+		si.ServiceName, si.Name, si.ServiceName, si.Name)
+	request, err := http.NewRequest(http.MethodPost, url, nil)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	err = serviceInstanceRotateCredentials(recorder, request, s.token)
+	c.Assert(err, check.DeepEquals, &errors.HTTP{Code: http.StatusBadRequest, Message: "No apps bound to the service instance."})
+}
+
+func (s *ServiceInstanceSuite) TestServiceInstanceRotateAppCredentialsAppNotBound(c *check.C) {
+	srvc := service.Service{Name: "mysql", Endpoint: map[string]string{"production": s.ts.URL}, Password: "abcde", OwnerTeams: []string{s.team.Name}}
+	err := service.Create(stdContext.TODO(), srvc)
+	c.Assert(err, check.IsNil)
+	a, si := s.createRotationInstance(c, nil)
+	url := fmt.Sprintf("/services/%s/instances/%s/apps/%s/rotate?:service=%s&:instance=%s&:app=%s",
+		si.ServiceName, si.Name, a.Name, si.ServiceName, si.Name, a.Name)
+	request, err := http.NewRequest(http.MethodPost, url, nil)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	err = serviceInstanceRotateAppCredentials(recorder, request, s.token)
+	c.Assert(err, check.DeepEquals, &errors.HTTP{Code: http.StatusBadRequest, Message: service.ErrAppNotBound.Error()})
+}
@@ -133,12 +133,16 @@ func (s *S) SetUpTest(c *check.C) {
 	c.Assert(err, check.IsNil)
 	servicemanager.LogService, err = applog.AppLogService()
 	c.Assert(err, check.IsNil)
+	servicemanager.LogDrain, err = applog.LogDrainService()
+	c.Assert(err, check.IsNil)
 	servicemanager.AppVersion, err = version.AppVersionService()
 	c.Assert(err, check.IsNil)
 	servicemanager.AuthGroup, err = auth.GroupService()
 	c.Assert(err, check.IsNil)
 	servicemanager.Job, err = job.JobService()
 	c.Assert(err, check.IsNil)
+	servicemanager.JobRun, err = job.JobRunService()
+	c.Assert(err, check.IsNil)
 	servicemanager.Tag, err = tag.TagService()
 	c.Assert(err, check.IsNil)
 }
@@ -0,0 +1,196 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	stdContext "context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	pkgErrors "github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+)
+
+// title: app swap
+// path: /swap
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//
+//	200: Ok
+//	400: Invalid data
+//	401: Unauthorized
+//	404: App not found
+//	409: App locked or already swapped
+//	412: App backend not ready
+func swap(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	app1Name := InputValue(r, "app1")
+	app2Name := InputValue(r, "app2")
+	force, _ := strconv.ParseBool(InputValue(r, "force"))
+	cnameOnly, _ := strconv.ParseBool(InputValue(r, "cnameOnly"))
+	app1, app2, err := getSwapApps(ctx, t, app1Name, app2Name)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target: appTarget(app1Name),
+		ExtraTargets: []eventTypes.ExtraTarget{
+			{Target: appTarget(app2Name), Lock: true},
+		},
+		Kind:       permission.PermAppUpdateSwap,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, append(contextsForApp(app1), contextsForApp(app2)...)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	// apps are fetched again as they may have changed while waiting for the
+	// event lock.
+	app1, app2, err = getSwapApps(ctx, t, app1Name, app2Name)
+	if err != nil {
+		return err
+	}
+	result, err := app.Swap(ctx, app1, app2, app.SwapOptions{
+		CNameOnly: cnameOnly,
+		Force:     force,
+		Owner:     t.GetUserName(),
+		Writer:    evt,
+	})
+	if err != nil {
+		return swapHTTPError(err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(result)
+}
+
+// title: app swap list
+// path: /swaps
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: OK
+//	204: No content
+//	400: Invalid data
+//	401: Unauthorized
+//	404: App not found
+func listSwaps(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	appName := InputValue(r, "app")
+	if appName == "" {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "app is required"}
+	}
+	a, err := getApp(ctx, appName)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(ctx, t, permission.PermAppRead, contextsForApp(a)...)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	swaps, err := app.ListSwaps(ctx, a.Name)
+	if err != nil {
+		return err
+	}
+	if len(swaps) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(swaps)
+}
+
+// title: app swap revert
+// path: /swaps/{id}/revert
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//
+//	200: Ok
+//	401: Unauthorized
+//	404: Swap or app not found
+//	409: App locked, swap already reverted or newer swap active
+//	412: App backend not ready
+func revertSwap(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	force, _ := strconv.ParseBool(InputValue(r, "force"))
+	s, err := app.GetSwap(ctx, r.URL.Query().Get(":id"))
+	if err == app.ErrSwapNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	app1, app2, err := getSwapApps(ctx, t, s.App1, s.App2)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target: appTarget(s.App1),
+		ExtraTargets: []eventTypes.ExtraTarget{
+			{Target: appTarget(s.App2), Lock: true},
+		},
+		Kind:       permission.PermAppUpdateSwap,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: append(event.FormToCustomData(InputFields(r)), map[string]interface{}{"name": "id", "value": s.ID}),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, append(contextsForApp(app1), contextsForApp(app2)...)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	s, err = app.GetSwap(ctx, s.ID)
+	if err != nil {
+		return err
+	}
+	err = app.RevertSwap(ctx, s, force, evt)
+	if err != nil {
+		return swapHTTPError(err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(s)
+}
+
+func getSwapApps(ctx stdContext.Context, t auth.Token, app1Name, app2Name string) (*app.App, *app.App, error) {
+	var apps []*app.App
+	for _, name := range []string{app1Name, app2Name} {
+		a, err := getApp(ctx, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		allowed := permission.Check(ctx, t, permission.PermAppUpdateSwap, contextsForApp(a)...)
+		if !allowed {
+			return nil, nil, permission.ErrUnauthorized
+		}
+		apps = append(apps, a)
+	}
+	return apps[0], apps[1], nil
+}
+
+func swapHTTPError(err error) error {
+	if _, ok := pkgErrors.Cause(err).(*app.ErrSwapNotReady); ok {
+		return &errors.HTTP{Code: http.StatusPreconditionFailed, Message: err.Error()}
+	}
+	switch pkgErrors.Cause(err) {
+	case app.ErrSwapSameApp, app.ErrSwapDifferentRouters, app.ErrSwapNoCNames, app.ErrSwapDifferentPools:
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	case app.ErrAppAddressesSwapped, app.ErrSwapAlreadyReverted, app.ErrSwapNewerActive:
+		return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	return err
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	"github.com/tsuru/tsuru/router/routertest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) createSwapApps(c *check.C) {
+	app1 := app.App{Name: "app1", Platform: "x", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &app1, s.user)
+	c.Assert(err, check.IsNil)
+	err = app1.AddCName(context.TODO(), "cname.io")
+	c.Assert(err, check.IsNil)
+	app2 := app.App{Name: "app2", Platform: "y", TeamOwner: s.team.Name}
+	err = app.CreateApp(context.TODO(), &app2, s.user)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) doSwapRequest(c *check.C, method, url, body, token string) *httptest.ResponseRecorder {
+	request, err := http.NewRequest(method, url, strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+token)
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (s *S) TestSwap(c *check.C) {
+	s.createSwapApps(c)
+	recorder := s.doSwapRequest(c, http.MethodPost, "/swap", "app1=app1&app2=app2&cnameOnly=true", s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var swap appTypes.AppSwap
+	err := json.NewDecoder(recorder.Body).Decode(&swap)
+	c.Assert(err, check.IsNil)
+	c.Assert(swap.App1, check.Equals, "app1")
+	c.Assert(swap.App2, check.Equals, "app2")
+	c.Assert(swap.CNameOnly, check.Equals, true)
+	c.Assert(swap.Status, check.Equals, appTypes.AppSwapStatusSwapped)
+	c.Assert(swap.Owner, check.Equals, s.token.GetUserName())
+	c.Assert(routertest.FakeRouter.HasCNameFor("app2", "cname.io"), check.Equals, true)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("app1"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.swap",
+		StartCustomData: []map[string]interface{}{
+			{"name": "app1", "value": "app1"},
+			{"name": "app2", "value": "app2"},
+			{"name": "cnameOnly", "value": "true"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestSwapBackendNotReady(c *check.C) {
+	s.createSwapApps(c)
+	routertest.FakeRouter.FailuresByHost["app2"] = true
+	recorder := s.doSwapRequest(c, http.MethodPost, "/swap", "app1=app1&app2=app2", s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusPreconditionFailed)
+	c.Assert(recorder.Body.String(), check.Equals, "backend of app \"app2\" is not ready in router \"fake\": Forced failure\n")
+	c.Assert(routertest.FakeRouter.HasCNameFor("app1", "cname.io"), check.Equals, true)
+}
+
+func (s *S) TestSwapSameApp(c *check.C) {
+	s.createSwapApps(c)
+	recorder := s.doSwapRequest(c, http.MethodPost, "/swap", "app1=app1&app2=app1", s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, app.ErrSwapSameApp.Error()+"\n")
+}
+
+func (s *S) TestSwapAppNotFound(c *check.C) {
+	s.createSwapApps(c)
+	recorder := s.doSwapRequest(c, http.MethodPost, "/swap", "app1=app1&app2=unknown", s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestSwapUnauthorized(c *check.C) {
+	s.createSwapApps(c)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "swapper", permission.Permission{
+		Scheme:  permission.PermAppUpdateSwap,
+		Context: permission.Context(permTypes.CtxApp, "app1"),
+	})
+	recorder := s.doSwapRequest(c, http.MethodPost, "/swap", "app1=app1&app2=app2", token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	c.Assert(routertest.FakeRouter.HasCNameFor("app1", "cname.io"), check.Equals, true)
+}
+
+func (s *S) TestListAndRevertSwaps(c *check.C) {
+	s.createSwapApps(c)
+	recorder := s.doSwapRequest(c, http.MethodGet, "/swaps?app=app2", "", s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+	recorder = s.doSwapRequest(c, http.MethodPost, "/swap", "app1=app1&app2=app2", s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	recorder = s.doSwapRequest(c, http.MethodGet, "/swaps?app=app2", "", s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var swaps []appTypes.AppSwap
+	err := json.NewDecoder(recorder.Body).Decode(&swaps)
+	c.Assert(err, check.IsNil)
+	c.Assert(swaps, check.HasLen, 1)
+	c.Assert(swaps[0].CNameOnly, check.Equals, false)
+	recorder = s.doSwapRequest(c, http.MethodPost, "/swaps/"+swaps[0].ID+"/revert", "", s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var reverted appTypes.AppSwap
+	err = json.NewDecoder(recorder.Body).Decode(&reverted)
+	c.Assert(err, check.IsNil)
+	c.Assert(reverted.Status, check.Equals, appTypes.AppSwapStatusReverted)
+	c.Assert(routertest.FakeRouter.HasCNameFor("app1", "cname.io"), check.Equals, true)
+	recorder = s.doSwapRequest(c, http.MethodPost, "/swaps/"+swaps[0].ID+"/revert", "", s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+	c.Assert(recorder.Body.String(), check.Equals, app.ErrSwapAlreadyReverted.Error()+"\n")
+}
+
+func (s *S) TestRevertSwapNotFound(c *check.C) {
+	recorder := s.doSwapRequest(c, http.MethodPost, "/swaps/unknown/revert", "", s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	c.Assert(recorder.Body.String(), check.Equals, app.ErrSwapNotFound.Error()+"\n")
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
 	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/servicemanager"
@@ -280,6 +281,7 @@ func volumeDelete(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 // responses:
 //
 //	200: Volume binded
+//	400: Volume plan not allowed in job pool
 //	401: Unauthorized
 //	404: Volume not found
 //	409: Volume bind already exists
@@ -287,6 +289,7 @@ func volumeBind(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	ctx := r.Context()
 	var bindInfo struct {
 		App        string
+		Job        string
 		MountPoint string
 		ReadOnly   bool
 		NoRestart  bool
@@ -306,6 +309,14 @@ func volumeBind(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	if !canBindVolume {
 		return permission.ErrUnauthorized
 	}
+	if bindInfo.Job != "" {
+		return volumeBindJob(r, t, &volumeTypes.BindOpts{
+			Volume:     dbVolume,
+			JobName:    bindInfo.Job,
+			MountPoint: bindInfo.MountPoint,
+			ReadOnly:   bindInfo.ReadOnly,
+		})
+	}
 	a, err := getAppFromContext(bindInfo.App, r)
 	if err != nil {
 		return err
@@ -359,6 +370,7 @@ func volumeUnbind(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	ctx := r.Context()
 	var bindInfo struct {
 		App        string
+		Job        string
 		MountPoint string
 		NoRestart  bool
 	}
@@ -377,6 +389,13 @@ func volumeUnbind(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	if !canUnbind {
 		return permission.ErrUnauthorized
 	}
+	if bindInfo.Job != "" {
+		return volumeUnbindJob(r, t, &volumeTypes.BindOpts{
+			Volume:     dbVolume,
+			JobName:    bindInfo.Job,
+			MountPoint: bindInfo.MountPoint,
+		})
+	}
 	a, err := getAppFromContext(bindInfo.App, r)
 	if err != nil {
 		return err
@@ -415,3 +434,88 @@ func volumeUnbind(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	evt.SetLogWriter(writer)
 	return a.Restart(ctx, "", "", evt)
 }
+
+// volumeBindJob binds the volume to a job, updating the job in the
+// provisioner so its next runs mount the volume. The volume plan must be
+// allowed in the job pool.
+func volumeBindJob(r *http.Request, t auth.Token, opts *volumeTypes.BindOpts) (err error) {
+	ctx := r.Context()
+	j, err := getJob(ctx, opts.JobName)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(ctx, t, permission.PermJobUpdateBindVolume, contextsForJob(j)...) {
+		return permission.ErrUnauthorized
+	}
+	err = servicemanager.Volume.CheckPoolVolumeConstraints(ctx, volumeTypes.Volume{
+		Name: opts.Volume.Name,
+		Pool: j.Pool,
+		Plan: opts.Volume.Plan,
+	})
+	if err == volumeTypes.ErrVolumePlanNotFound || err == pool.ErrPoolHasNoVolumePlan {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypeVolume, Value: opts.Volume.Name},
+		Kind:       permission.PermVolumeUpdateBind,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermVolumeReadEvents, contextsForVolume(opts.Volume)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = servicemanager.Volume.BindJob(ctx, opts)
+	if err == volumeTypes.ErrVolumeAlreadyBound {
+		return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	err = servicemanager.Job.UpdateJobProv(ctx, j)
+	if err != nil {
+		// the job would only see the volume in its next update, which
+		// could fail for the same reason, so the bind is removed.
+		if unbindErr := servicemanager.Volume.UnbindJob(ctx, opts); unbindErr != nil {
+			log.Errorf("unable to remove bind of volume %q to job %q: %v", opts.Volume.Name, j.Name, unbindErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func volumeUnbindJob(r *http.Request, t auth.Token, opts *volumeTypes.BindOpts) (err error) {
+	ctx := r.Context()
+	j, err := getJob(ctx, opts.JobName)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(ctx, t, permission.PermJobUpdateUnbindVolume, contextsForJob(j)...) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypeVolume, Value: opts.Volume.Name},
+		Kind:       permission.PermVolumeUpdateUnbind,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermVolumeReadEvents, contextsForVolume(opts.Volume)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	err = servicemanager.Volume.UnbindJob(ctx, opts)
+	if err == volumeTypes.ErrVolumeBindNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	return servicemanager.Job.UpdateJobProv(ctx, j)
+}
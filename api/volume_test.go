@@ -7,6 +7,7 @@ package api
 import (
 	"context"
 	"encoding/json"
+	stdErrors "errors"
 	"net/http"
 	"net/http/httptest"
 	"sort"
@@ -14,12 +15,18 @@ import (
 
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/db/storagev2"
 	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event/eventtest"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/permission/permissiontest"
+	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/provision/provisiontest"
 	"github.com/tsuru/tsuru/servicemanager"
 	authTypes "github.com/tsuru/tsuru/types/auth"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	jobTypes "github.com/tsuru/tsuru/types/job"
 	permTypes "github.com/tsuru/tsuru/types/permission"
 	volumeTypes "github.com/tsuru/tsuru/types/volume"
 	check "gopkg.in/check.v1"
@@ -877,3 +884,148 @@ func (s *S) TestVolumeUnbindNoRestart(c *check.C) {
 	c.Assert(recorder.Code, check.Equals, http.StatusOK)
 	c.Assert(recorder.Body.String(), check.Equals, "")
 }
+
+func (s *S) setupVolumeJob(c *check.C) (*volumeTypes.Volume, func()) {
+	oldProvisioner := provision.DefaultProvisioner
+	provision.DefaultProvisioner = "jobProv"
+	provision.Register("jobProv", func() (provision.Provisioner, error) {
+		return &provisiontest.JobProvisioner{FakeProvisioner: provisiontest.ProvisionerInstance}, nil
+	})
+	cleanup := func() {
+		provision.DefaultProvisioner = oldProvisioner
+		provision.Unregister("jobProv")
+	}
+	jobsCollection, err := storagev2.JobsCollection()
+	c.Assert(err, check.IsNil)
+	_, err = jobsCollection.InsertOne(context.TODO(), jobTypes.Job{Name: "myjob", Pool: s.Pool, TeamOwner: s.team.Name, Teams: []string{s.team.Name}})
+	c.Assert(err, check.IsNil)
+	v1 := volumeTypes.Volume{Name: "v1", Pool: s.Pool, TeamOwner: s.team.Name, Plan: volumeTypes.VolumePlan{Name: "nfs"}}
+	s.mockService.VolumeService.OnGet = func(ctx context.Context, name string) (*volumeTypes.Volume, error) {
+		return &v1, nil
+	}
+	return &v1, cleanup
+}
+
+func (s *S) TestVolumeBindJob(c *check.C) {
+	v1, cleanup := s.setupVolumeJob(c)
+	defer cleanup()
+	var checkedPool string
+	s.mockService.VolumeService.OnCheckPoolVolumeConstraints = func(ctx context.Context, v volumeTypes.Volume) error {
+		checkedPool = v.Pool
+		c.Assert(v.Plan.Name, check.Equals, "nfs")
+		return nil
+	}
+	var bindOpts *volumeTypes.BindOpts
+	s.mockService.VolumeService.OnBindJob = func(ctx context.Context, opts *volumeTypes.BindOpts) error {
+		bindOpts = opts
+		return nil
+	}
+	body := strings.NewReader(`job=myjob&mountpoint=/mnt1&readonly=true`)
+	request, err := http.NewRequest("POST", "/1.24/volumes/v1/bind", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(checkedPool, check.Equals, s.Pool)
+	c.Assert(bindOpts, check.DeepEquals, &volumeTypes.BindOpts{
+		Volume:     v1,
+		JobName:    "myjob",
+		MountPoint: "/mnt1",
+		ReadOnly:   true,
+	})
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypeVolume, Value: "v1"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "volume.update.bind",
+	}, eventtest.HasEvent)
+}
+
+type failingUpdateJobService struct {
+	jobTypes.JobService
+}
+
+func (failingUpdateJobService) UpdateJobProv(ctx context.Context, job *jobTypes.Job) error {
+	return stdErrors.New("update failed")
+}
+
+func (s *S) TestVolumeBindJobRemovesBindOnUpdateFailure(c *check.C) {
+	_, cleanup := s.setupVolumeJob(c)
+	defer cleanup()
+	oldJobService := servicemanager.Job
+	servicemanager.Job = failingUpdateJobService{JobService: oldJobService}
+	defer func() { servicemanager.Job = oldJobService }()
+	var bindOpts, unbindOpts *volumeTypes.BindOpts
+	s.mockService.VolumeService.OnBindJob = func(ctx context.Context, opts *volumeTypes.BindOpts) error {
+		bindOpts = opts
+		return nil
+	}
+	s.mockService.VolumeService.OnUnbindJob = func(ctx context.Context, opts *volumeTypes.BindOpts) error {
+		unbindOpts = opts
+		return nil
+	}
+	body := strings.NewReader(`job=myjob&mountpoint=/mnt1`)
+	request, err := http.NewRequest("POST", "/1.24/volumes/v1/bind", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusInternalServerError)
+	c.Assert(bindOpts, check.NotNil)
+	c.Assert(unbindOpts, check.DeepEquals, bindOpts)
+}
+
+func (s *S) TestVolumeBindJobPlanNotAllowedInPool(c *check.C) {
+	_, cleanup := s.setupVolumeJob(c)
+	defer cleanup()
+	s.mockService.VolumeService.OnCheckPoolVolumeConstraints = func(ctx context.Context, v volumeTypes.Volume) error {
+		return volumeTypes.ErrVolumePlanNotFound
+	}
+	s.mockService.VolumeService.OnBindJob = func(ctx context.Context, opts *volumeTypes.BindOpts) error {
+		c.Fatal("volume must not be bound")
+		return nil
+	}
+	body := strings.NewReader(`job=myjob&mountpoint=/mnt1`)
+	request, err := http.NewRequest("POST", "/1.24/volumes/v1/bind", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, volumeTypes.ErrVolumePlanNotFound.Error()+"\n")
+}
+
+func (s *S) TestVolumeUnbindJob(c *check.C) {
+	_, cleanup := s.setupVolumeJob(c)
+	defer cleanup()
+	var unbindOpts *volumeTypes.BindOpts
+	s.mockService.VolumeService.OnUnbindJob = func(ctx context.Context, opts *volumeTypes.BindOpts) error {
+		unbindOpts = opts
+		return nil
+	}
+	request, err := http.NewRequest("DELETE", "/1.24/volumes/v1/bind?job=myjob&mountpoint=/mnt1", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(unbindOpts.JobName, check.Equals, "myjob")
+	c.Assert(unbindOpts.MountPoint, check.Equals, "/mnt1")
+}
+
+func (s *S) TestVolumeUnbindJobNotFound(c *check.C) {
+	_, cleanup := s.setupVolumeJob(c)
+	defer cleanup()
+	s.mockService.VolumeService.OnUnbindJob = func(ctx context.Context, opts *volumeTypes.BindOpts) error {
+		return volumeTypes.ErrVolumeBindNotFound
+	}
+	request, err := http.NewRequest("DELETE", "/1.24/volumes/v1/bind?job=myjob&mountpoint=/mnt1", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
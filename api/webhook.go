@@ -7,8 +7,10 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/servicemanager"
@@ -39,6 +41,9 @@ func webhookList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	if err != nil {
 		return err
 	}
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
 	if len(webhooks) == 0 {
 		w.WriteHeader(http.StatusNoContent)
 		return nil
@@ -70,6 +75,7 @@ func webhookInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	if !permission.Check(ctx, t, permission.PermWebhookRead, permissionCtx) {
 		return permission.ErrUnauthorized
 	}
+	webhook.Secret = ""
 	w.Header().Set("Content-Type", "application/json")
 	return json.NewEncoder(w).Encode(webhook)
 }
@@ -105,7 +111,7 @@ func webhookCreate(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 		Kind:       permission.PermWebhookCreate,
 		Owner:      t,
 		RemoteAddr: r.RemoteAddr,
-		CustomData: event.FormToCustomData(InputFields(r)),
+		CustomData: event.FormToCustomData(InputFields(r, "secret")),
 		Allowed:    event.Allowed(permission.PermWebhookReadEvents, permCtx),
 	})
 	if err != nil {
@@ -147,7 +153,7 @@ func webhookUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 		Kind:       permission.PermWebhookUpdate,
 		Owner:      t,
 		RemoteAddr: r.RemoteAddr,
-		CustomData: event.FormToCustomData(InputFields(r)),
+		CustomData: event.FormToCustomData(InputFields(r, "secret")),
 		Allowed:    event.Allowed(permission.PermWebhookReadEvents, permissionCtx),
 	})
 	if err != nil {
@@ -201,3 +207,100 @@ func webhookDelete(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	}()
 	return servicemanager.Webhook.Delete(ctx, webhookName)
 }
+
+// title: webhook deliveries
+// path: /events/webhooks/{name}/deliveries
+// method: GET
+// produce: application/json
+// responses:
+//
+//	200: List webhook deliveries
+//	204: No content
+//	400: Invalid limit
+//	401: Unauthorized
+//	404: Webhook not found
+func webhookDeliveries(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	webhookName := r.URL.Query().Get(":name")
+	webhook, err := servicemanager.Webhook.Find(ctx, webhookName)
+	if err != nil {
+		if err == eventTypes.ErrWebhookNotFound {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		return err
+	}
+	permissionCtx := permission.Context(permTypes.CtxTeam, webhook.TeamOwner)
+	if !permission.Check(ctx, t, permission.PermWebhookRead, permissionCtx) {
+		return permission.ErrUnauthorized
+	}
+	filter := eventTypes.WebhookDeliveryFilter{
+		Webhook: webhook.Name,
+		Status:  eventTypes.WebhookDeliveryStatus(r.URL.Query().Get("status")),
+		Limit:   100,
+	}
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit <= 0 {
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "invalid limit, must be a positive integer"}
+		}
+	}
+	deliveries, err := servicemanager.Webhook.Deliveries(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if len(deliveries) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(deliveries)
+}
+
+// title: webhook redeliver
+// path: /events/webhooks/{name}/deliveries/{id}/redeliver
+// method: POST
+// produce: application/json
+// responses:
+//
+//	200: Delivery attempted
+//	401: Unauthorized
+//	404: Webhook or delivery not found
+func webhookRedeliver(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	webhookName := r.URL.Query().Get(":name")
+	deliveryID := r.URL.Query().Get(":id")
+	webhook, err := servicemanager.Webhook.Find(ctx, webhookName)
+	if err != nil {
+		if err == eventTypes.ErrWebhookNotFound {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		return err
+	}
+	permissionCtx := permission.Context(permTypes.CtxTeam, webhook.TeamOwner)
+	if !permission.Check(ctx, t, permission.PermWebhookUpdateRedeliver, permissionCtx) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:     eventTypes.Target{Type: eventTypes.TargetTypeWebhook, Value: webhook.Name},
+		Kind:       permission.PermWebhookUpdateRedeliver,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermWebhookReadEvents, permissionCtx),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		evt.Done(ctx, err)
+	}()
+	delivery, err := servicemanager.Webhook.Redeliver(ctx, webhook.Name, deliveryID)
+	if err == eventTypes.ErrWebhookDeliveryNotFound {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(delivery)
+}
@@ -12,8 +12,11 @@ import (
 	"strings"
 
 	"github.com/cezarsa/form"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/servicemanager"
+	"github.com/tsuru/tsuru/storage"
 	eventTypes "github.com/tsuru/tsuru/types/event"
 	permTypes "github.com/tsuru/tsuru/types/permission"
 	check "gopkg.in/check.v1"
@@ -164,6 +167,39 @@ func (s *S) TestWebhookCreate(c *check.C) {
 	})
 }
 
+func (s *S) TestWebhookCreateDoesNotRecordSecret(c *check.C) {
+	webhook1 := eventTypes.Webhook{
+		TeamOwner: s.team.Name,
+		Name:      "wh1",
+		URL:       "http://me",
+		Secret:    "s3cr3t",
+	}
+	bodyData, err := json.Marshal(webhook1)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/1.6/events/webhooks", strings.NewReader(string(bodyData)))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	wh, err := servicemanager.Webhook.Find(context.TODO(), "wh1")
+	c.Assert(err, check.IsNil)
+	c.Assert(wh.Secret, check.Equals, "s3cr3t")
+	evts, err := event.List(context.TODO(), &event.Filter{
+		Target:    eventTypes.Target{Type: eventTypes.TargetTypeWebhook, Value: "wh1"},
+		KindNames: []string{permission.PermWebhookCreate.FullName()},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	var data []map[string]interface{}
+	err = evts[0].StartData(&data)
+	c.Assert(err, check.IsNil)
+	raw, err := json.Marshal(data)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(raw), check.Not(check.Matches), "(?s).*s3cr3t.*")
+}
+
 func (s *S) TestWebhookCreateAutoTeam(c *check.C) {
 	webhook1 := eventTypes.Webhook{
 		Name: "wh1",
@@ -395,3 +431,132 @@ func (s *S) TestWebhookInfoNotFound(c *check.C) {
 	s.testServer.ServeHTTP(recorder, request)
 	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
 }
+
+func (s *S) TestWebhookInfoHidesSecret(c *check.C) {
+	err := servicemanager.Webhook.Create(context.TODO(), eventTypes.Webhook{
+		TeamOwner: s.team.Name,
+		Name:      "wh1",
+		URL:       "http://me/xyz",
+		Secret:    "s3cr3t",
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.6/events/webhooks/wh1", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(strings.Contains(recorder.Body.String(), "s3cr3t"), check.Equals, false)
+}
+
+func (s *S) TestWebhookDeliveries(c *check.C) {
+	err := servicemanager.Webhook.Create(context.TODO(), eventTypes.Webhook{
+		TeamOwner: s.team.Name,
+		Name:      "wh1",
+		URL:       "http://me/xyz",
+	})
+	c.Assert(err, check.IsNil)
+	dbDriver, err := storage.GetDefaultDbDriver()
+	c.Assert(err, check.IsNil)
+	deliveryStorage := dbDriver.WebhookDeliveryStorage
+	for _, status := range []eventTypes.WebhookDeliveryStatus{eventTypes.WebhookDeliverySuccess, eventTypes.WebhookDeliveryDeadLetter} {
+		err = deliveryStorage.Insert(context.TODO(), &eventTypes.WebhookDelivery{Webhook: "wh1", EventID: string(status), Status: status})
+		c.Assert(err, check.IsNil)
+	}
+	request, err := http.NewRequest("GET", "/1.24/events/webhooks/wh1/deliveries?status=dead-letter", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var deliveries []eventTypes.WebhookDelivery
+	err = json.Unmarshal(recorder.Body.Bytes(), &deliveries)
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveries, check.HasLen, 1)
+	c.Assert(deliveries[0].EventID, check.Equals, "dead-letter")
+}
+
+func (s *S) TestWebhookDeliveriesInvalidLimit(c *check.C) {
+	err := servicemanager.Webhook.Create(context.TODO(), eventTypes.Webhook{
+		TeamOwner: s.team.Name,
+		Name:      "wh1",
+		URL:       "http://me/xyz",
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.24/events/webhooks/wh1/deliveries?limit=abc", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *S) TestWebhookDeliveriesNotFound(c *check.C) {
+	request, err := http.NewRequest("GET", "/1.24/events/webhooks/wh1/deliveries", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestWebhookRedeliver(c *check.C) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	err := servicemanager.Webhook.Create(context.TODO(), eventTypes.Webhook{
+		TeamOwner: s.team.Name,
+		Name:      "wh1",
+		URL:       srv.URL,
+	})
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:  eventTypes.Target{Type: eventTypes.TargetTypeWebhook, Value: "wh1"},
+		Kind:    permission.PermWebhookUpdate,
+		Owner:   s.token,
+		Allowed: event.Allowed(permission.PermWebhookReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	delivery := eventTypes.WebhookDelivery{Webhook: "wh1", EventID: evt.UniqueID.Hex(), Status: eventTypes.WebhookDeliveryDeadLetter}
+	dbDriver, err := storage.GetDefaultDbDriver()
+	c.Assert(err, check.IsNil)
+	err = dbDriver.WebhookDeliveryStorage.Insert(context.TODO(), &delivery)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/1.24/events/webhooks/wh1/deliveries/"+delivery.ID.Hex()+"/redeliver", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var result eventTypes.WebhookDelivery
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Status, check.Equals, eventTypes.WebhookDeliverySuccess)
+	c.Assert(result.Attempts, check.HasLen, 1)
+	c.Assert(result.Attempts[0].Manual, check.Equals, true)
+	c.Assert(eventtest.EventDesc{
+		Target: eventTypes.Target{Type: eventTypes.TargetTypeWebhook, Value: "wh1"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "webhook.update.redeliver",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":name", "value": "wh1"},
+			{"name": ":id", "value": delivery.ID.Hex()},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestWebhookRedeliverNotFound(c *check.C) {
+	err := servicemanager.Webhook.Create(context.TODO(), eventTypes.Webhook{
+		TeamOwner: s.team.Name,
+		Name:      "wh1",
+		URL:       "http://me/xyz",
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/1.24/events/webhooks/wh1/deliveries/5c5b1a8f2f1e4b0001000000/redeliver", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
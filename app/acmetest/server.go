@@ -0,0 +1,401 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package acmetest provides a minimal ACME certificate authority, similar to
+// Pebble, to be used in tests of code obtaining certificates through ACME.
+package acmetest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme"
+)
+
+// ValidateFunc fetches the response served for the HTTP-01 challenge token
+// of the domain, like a real certificate authority would do by requesting
+// http://<domain>/.well-known/acme-challenge/<token>.
+type ValidateFunc func(domain, token string) (string, error)
+
+// Server is an ACME server issuing certificates signed by an in-memory CA
+// after validating HTTP-01 challenges using its Validate function. Only
+// ECDSA P-256 account keys are supported.
+type Server struct {
+	*httptest.Server
+
+	Validate ValidateFunc
+	// CertificateTTL is the validity of the issued certificates, defaults to
+	// 90 days.
+	CertificateTTL time.Duration
+
+	mu         sync.Mutex
+	caKey      *ecdsa.PrivateKey
+	caCert     *x509.Certificate
+	thumbprint string
+	nextID     int
+	orders     map[string]*order
+	authzs     map[string]*authorization
+	certs      map[string][]byte
+}
+
+type order struct {
+	id      string
+	domain  string
+	authzID string
+	status  string
+	certID  string
+}
+
+type authorization struct {
+	id     string
+	domain string
+	token  string
+	status string
+}
+
+// NewServer starts a new ACME server.
+func NewServer(validate ValidateFunc) (*Server, error) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "acmetest root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(10 * 365 * 24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+	caCert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		Validate:       validate,
+		CertificateTTL: 90 * 24 * time.Hour,
+		caKey:          caKey,
+		caCert:         caCert,
+		orders:         map[string]*order{},
+		authzs:         map[string]*authorization{},
+		certs:          map[string][]byte{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s, nil
+}
+
+// DirectoryURL is the URL of the ACME directory of the server.
+func (s *Server) DirectoryURL() string {
+	return s.URL + "/directory"
+}
+
+// CACertificate returns the certificate signing the issued certificates.
+func (s *Server) CACertificate() *x509.Certificate {
+	return s.caCert
+}
+
+func (s *Server) newID() string {
+	s.nextID++
+	return fmt.Sprint(s.nextID)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Replay-Nonce", base64.RawURLEncoding.EncodeToString([]byte(time.Now().String())))
+	w.Header().Set("Cache-Control", "no-store")
+	if r.URL.Path == "/directory" {
+		s.writeJSON(w, http.StatusOK, map[string]string{
+			"newNonce":   s.URL + "/new-nonce",
+			"newAccount": s.URL + "/new-account",
+			"newOrder":   s.URL + "/new-order",
+			"revokeCert": s.URL + "/revoke-cert",
+			"keyChange":  s.URL + "/key-change",
+		})
+		return
+	}
+	if r.URL.Path == "/new-nonce" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	protected, payload, err := decodeJWS(r)
+	if err != nil {
+		s.writeProblem(w, http.StatusBadRequest, "malformed", err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	parts := strings.SplitN(strings.Trim(r.URL.Path, "/"), "/", 2)
+	id := ""
+	if len(parts) > 1 {
+		id = parts[1]
+	}
+	switch parts[0] {
+	case "new-account":
+		s.newAccount(w, protected)
+	case "new-order":
+		s.newOrder(w, payload)
+	case "order":
+		s.getOrder(w, id)
+	case "authz":
+		s.getAuthz(w, id)
+	case "challenge":
+		s.acceptChallenge(w, id)
+	case "finalize":
+		s.finalize(w, id, payload)
+	case "cert":
+		s.getCert(w, id)
+	default:
+		s.writeProblem(w, http.StatusNotFound, "malformed", "not found")
+	}
+}
+
+func (s *Server) newAccount(w http.ResponseWriter, protected map[string]json.RawMessage) {
+	var jwk struct {
+		Kty string `json:"kty"`
+		Crv string `json:"crv"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}
+	err := json.Unmarshal(protected["jwk"], &jwk)
+	if err != nil || jwk.Kty != "EC" || jwk.Crv != "P-256" {
+		s.writeProblem(w, http.StatusBadRequest, "badPublicKey", "only P-256 keys are supported")
+		return
+	}
+	x, errX := base64.RawURLEncoding.DecodeString(jwk.X)
+	y, errY := base64.RawURLEncoding.DecodeString(jwk.Y)
+	if errX != nil || errY != nil {
+		s.writeProblem(w, http.StatusBadRequest, "badPublicKey", "invalid key coordinates")
+		return
+	}
+	pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	s.thumbprint, err = acme.JWKThumbprint(pub)
+	if err != nil {
+		s.writeProblem(w, http.StatusBadRequest, "badPublicKey", err.Error())
+		return
+	}
+	w.Header().Set("Location", s.URL+"/account/1")
+	s.writeJSON(w, http.StatusCreated, map[string]interface{}{"status": "valid"})
+}
+
+func (s *Server) newOrder(w http.ResponseWriter, payload []byte) {
+	var req struct {
+		Identifiers []struct {
+			Type  string `json:"type"`
+			Value string `json:"value"`
+		} `json:"identifiers"`
+	}
+	err := json.Unmarshal(payload, &req)
+	if err != nil || len(req.Identifiers) != 1 || req.Identifiers[0].Type != "dns" {
+		s.writeProblem(w, http.StatusBadRequest, "rejectedIdentifier", "exactly one dns identifier is supported")
+		return
+	}
+	authz := &authorization{
+		id:     s.newID(),
+		domain: req.Identifiers[0].Value,
+		status: acme.StatusPending,
+	}
+	authz.token = base64.RawURLEncoding.EncodeToString([]byte("token-" + authz.id))
+	s.authzs[authz.id] = authz
+	o := &order{id: s.newID(), domain: authz.domain, authzID: authz.id, status: acme.StatusPending}
+	s.orders[o.id] = o
+	w.Header().Set("Location", s.URL+"/order/"+o.id)
+	s.writeJSON(w, http.StatusCreated, s.orderJSON(o))
+}
+
+func (s *Server) orderJSON(o *order) map[string]interface{} {
+	if o.status == acme.StatusPending && s.authzs[o.authzID].status == acme.StatusValid {
+		o.status = acme.StatusReady
+	}
+	if s.authzs[o.authzID].status == acme.StatusInvalid {
+		o.status = acme.StatusInvalid
+	}
+	data := map[string]interface{}{
+		"status":         o.status,
+		"identifiers":    []map[string]string{{"type": "dns", "value": o.domain}},
+		"authorizations": []string{s.URL + "/authz/" + o.authzID},
+		"finalize":       s.URL + "/finalize/" + o.id,
+	}
+	if o.certID != "" {
+		data["certificate"] = s.URL + "/cert/" + o.certID
+	}
+	return data
+}
+
+func (s *Server) getOrder(w http.ResponseWriter, id string) {
+	o, ok := s.orders[id]
+	if !ok {
+		s.writeProblem(w, http.StatusNotFound, "malformed", "order not found")
+		return
+	}
+	s.writeJSON(w, http.StatusOK, s.orderJSON(o))
+}
+
+func (s *Server) challengeJSON(authz *authorization) map[string]interface{} {
+	return map[string]interface{}{
+		"type":   "http-01",
+		"url":    s.URL + "/challenge/" + authz.id,
+		"token":  authz.token,
+		"status": authz.status,
+	}
+}
+
+func (s *Server) getAuthz(w http.ResponseWriter, id string) {
+	authz, ok := s.authzs[id]
+	if !ok {
+		s.writeProblem(w, http.StatusNotFound, "malformed", "authorization not found")
+		return
+	}
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":     authz.status,
+		"identifier": map[string]string{"type": "dns", "value": authz.domain},
+		"challenges": []interface{}{s.challengeJSON(authz)},
+	})
+}
+
+func (s *Server) acceptChallenge(w http.ResponseWriter, id string) {
+	authz, ok := s.authzs[id]
+	if !ok {
+		s.writeProblem(w, http.StatusNotFound, "malformed", "challenge not found")
+		return
+	}
+	if authz.status == acme.StatusPending {
+		authz.status = acme.StatusInvalid
+		response, err := s.Validate(authz.domain, authz.token)
+		if err == nil && response == authz.token+"."+s.thumbprint {
+			authz.status = acme.StatusValid
+		}
+	}
+	s.writeJSON(w, http.StatusOK, s.challengeJSON(authz))
+}
+
+func (s *Server) finalize(w http.ResponseWriter, id string, payload []byte) {
+	o, ok := s.orders[id]
+	if !ok {
+		s.writeProblem(w, http.StatusNotFound, "malformed", "order not found")
+		return
+	}
+	s.orderJSON(o)
+	if o.status != acme.StatusReady {
+		s.writeProblem(w, http.StatusForbidden, "orderNotReady", "order is not ready")
+		return
+	}
+	var req struct {
+		CSR string `json:"csr"`
+	}
+	err := json.Unmarshal(payload, &req)
+	if err != nil {
+		s.writeProblem(w, http.StatusBadRequest, "badCSR", err.Error())
+		return
+	}
+	csrDER, err := base64.RawURLEncoding.DecodeString(req.CSR)
+	if err != nil {
+		s.writeProblem(w, http.StatusBadRequest, "badCSR", err.Error())
+		return
+	}
+	csr, err := x509.ParseCertificateRequest(csrDER)
+	if err != nil {
+		s.writeProblem(w, http.StatusBadRequest, "badCSR", err.Error())
+		return
+	}
+	if len(csr.DNSNames) != 1 || csr.DNSNames[0] != o.domain {
+		s.writeProblem(w, http.StatusBadRequest, "badCSR", "csr names don't match the order")
+		return
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(int64(s.nextID + 100)),
+		Subject:      pkix.Name{CommonName: o.domain},
+		DNSNames:     csr.DNSNames,
+		NotBefore:    now.Add(-time.Minute),
+		NotAfter:     now.Add(s.CertificateTTL),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, s.caCert, csr.PublicKey, s.caKey)
+	if err != nil {
+		s.writeProblem(w, http.StatusInternalServerError, "serverInternal", err.Error())
+		return
+	}
+	chain := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	chain = append(chain, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.caCert.Raw})...)
+	o.certID = s.newID()
+	o.status = acme.StatusValid
+	s.certs[o.certID] = chain
+	w.Header().Set("Location", s.URL+"/order/"+o.id)
+	s.writeJSON(w, http.StatusOK, s.orderJSON(o))
+}
+
+func (s *Server) getCert(w http.ResponseWriter, id string) {
+	chain, ok := s.certs[id]
+	if !ok {
+		s.writeProblem(w, http.StatusNotFound, "malformed", "certificate not found")
+		return
+	}
+	w.Header().Set("Content-Type", "application/pem-certificate-chain")
+	w.Write(chain)
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func (s *Server) writeProblem(w http.ResponseWriter, status int, problemType, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"type":   "urn:ietf:params:acme:error:" + problemType,
+		"detail": detail,
+		"status": status,
+	})
+}
+
+// decodeJWS returns the protected header and payload of the JWS in the
+// request body. Signatures aren't verified.
+func decodeJWS(r *http.Request) (map[string]json.RawMessage, []byte, error) {
+	var jws struct {
+		Protected string `json:"protected"`
+		Payload   string `json:"payload"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&jws)
+	if err != nil {
+		return nil, nil, err
+	}
+	rawProtected, err := base64.RawURLEncoding.DecodeString(jws.Protected)
+	if err != nil {
+		return nil, nil, err
+	}
+	var protected map[string]json.RawMessage
+	err = json.Unmarshal(rawProtected, &protected)
+	if err != nil {
+		return nil, nil, err
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(jws.Payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	return protected, payload, nil
+}
@@ -671,3 +671,90 @@ var rebuildRoutes = action.Action{
 		return nil, nil
 	},
 }
+
+var swapAppsCNames = action.Action{
+	Name: "swap-apps-cnames",
+	Forward: func(ctx action.FWContext) (action.Result, error) {
+		app1 := ctx.Params[0].(*App)
+		app2 := ctx.Params[1].(*App)
+		swap := ctx.Params[2].(*appTypes.AppSwap)
+		w := ctx.Params[3].(io.Writer)
+		previous := [2][]string{append([]string{}, app1.CName...), append([]string{}, app2.CName...)}
+		cnames1, cnames2, err := swapCNames(ctx.Context, app1, app2, swap, w)
+		if err != nil {
+			return nil, err
+		}
+		err = setAppsCNames(ctx.Context, app1, app2, cnames1, cnames2)
+		if err != nil {
+			return nil, err
+		}
+		return previous, nil
+	},
+	Backward: func(ctx action.BWContext) {
+		app1 := ctx.Params[0].(*App)
+		app2 := ctx.Params[1].(*App)
+		w := ctx.Params[3].(io.Writer)
+		previous := ctx.FWResult.([2][]string)
+		err := setAppsCNames(ctx.Context, app1, app2, previous[0], previous[1])
+		if err != nil {
+			log.Errorf("BACKWARD swap apps cnames - unable to restore cnames: %s", err)
+			return
+		}
+		err = swapRoutes(ctx.Context, app1, app2, w)
+		if err != nil {
+			log.Errorf("BACKWARD swap apps cnames - unable to restore routes: %s", err)
+		}
+	},
+}
+
+var saveAppSwap = action.Action{
+	Name: "save-app-swap",
+	Forward: func(ctx action.FWContext) (action.Result, error) {
+		swap := ctx.Params[2].(*appTypes.AppSwap)
+		collection, err := storagev2.AppSwapsCollection()
+		if err != nil {
+			return nil, err
+		}
+		var previous appTypes.AppSwap
+		err = collection.FindOne(ctx.Context, mongoBSON.M{"_id": swap.ID}).Decode(&previous)
+		if err == mongo.ErrNoDocuments {
+			_, err = collection.InsertOne(ctx.Context, swap)
+			return nil, err
+		}
+		if err != nil {
+			return nil, err
+		}
+		_, err = collection.ReplaceOne(ctx.Context, mongoBSON.M{"_id": swap.ID}, swap)
+		if err != nil {
+			return nil, err
+		}
+		return &previous, nil
+	},
+	Backward: func(ctx action.BWContext) {
+		swap := ctx.Params[2].(*appTypes.AppSwap)
+		collection, err := storagev2.AppSwapsCollection()
+		if err != nil {
+			log.Errorf("BACKWARD save app swap - unable to connect: %s", err)
+			return
+		}
+		if previous, ok := ctx.FWResult.(*appTypes.AppSwap); ok {
+			_, err = collection.ReplaceOne(ctx.Context, mongoBSON.M{"_id": swap.ID}, previous)
+		} else {
+			_, err = collection.DeleteOne(ctx.Context, mongoBSON.M{"_id": swap.ID})
+		}
+		if err != nil {
+			log.Errorf("BACKWARD save app swap - unable to restore swap %s: %s", swap.ID, err)
+		}
+	},
+}
+
+var rebuildSwappedRoutes = action.Action{
+	Name: "rebuild-swapped-routes",
+	Forward: func(ctx action.FWContext) (action.Result, error) {
+		app1 := ctx.Params[0].(*App)
+		app2 := ctx.Params[1].(*App)
+		w := ctx.Params[3].(io.Writer)
+		fmt.Fprintf(w, "---- Swapping routes of apps %q and %q ----\n", app1.Name, app2.Name)
+		return nil, swapRoutes(ctx.Context, app1, app2, w)
+	},
+}
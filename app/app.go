@@ -74,10 +74,8 @@ var (
 	ErrNoVersionProvisioner = errors.New("The current app provisioner does not support multiple versions handling")
 	ErrKillUnitProvisioner  = errors.New("The current app provisioner does not support killing a unit")
 	ErrSwapMultipleVersions = errors.New("swapping apps with multiple versions is not allowed")
-	ErrSwapMultipleRouters  = errors.New("swapping apps with multiple routers is not supported")
 	ErrSwapDifferentRouters = errors.New("swapping apps with different routers is not supported")
 	ErrSwapNoCNames         = errors.New("no cnames to swap")
-	ErrSwapDeprecated       = errors.New("swapping without cnameOnly is deprecated")
 )
 
 var (
@@ -417,6 +415,7 @@ func (app *App) Update(ctx context.Context, args UpdateAppArgs) (err error) {
 	platform := args.UpdateData.Platform
 	tags := processTags(args.UpdateData.Tags)
 	oldApp := *app
+	oldApp.Processes = append([]appTypes.Process(nil), app.Processes...)
 
 	oldPlan, err := json.Marshal(oldApp.Plan)
 	if err != nil {
@@ -432,6 +431,15 @@ func (app *App) Update(ctx context.Context, args UpdateAppArgs) (err error) {
 		app.Description = description
 	}
 	if poolName != "" {
+		if poolName != oldApp.Pool {
+			swap, errSwap := activeAddressSwap(ctx, app.Name)
+			if errSwap != nil {
+				return errSwap
+			}
+			if swap != nil {
+				return errors.Wrapf(ErrAppAddressesSwapped, "app %q is swapped with %q in swap %s, revert it before changing the pool", app.Name, swap.Partner(app.Name), swap.ID)
+			}
+		}
 		app.Pool = poolName
 		_, err = app.getPoolForApp(ctx, app.Pool)
 		if err != nil {
@@ -515,6 +523,13 @@ func (app *App) Update(ctx context.Context, args UpdateAppArgs) (err error) {
 	if err != nil {
 		return err
 	}
+	planChanged := string(newPlan) != string(oldPlan) || processesHasChanged
+	if planChanged || app.Pool != oldApp.Pool || app.TeamOwner != oldApp.TeamOwner {
+		err = app.checkUpdateResourceQuota(ctx, &oldApp, planChanged)
+		if err != nil {
+			return err
+		}
+	}
 	actions := []*action.Action{
 		&saveApp,
 	}
@@ -725,6 +740,13 @@ func (app *App) unbindVolumes(ctx context.Context) error {
 
 // Delete deletes an app.
 func Delete(ctx context.Context, app *App, evt *event.Event, requestID string) error {
+	swap, err := activeAddressSwap(ctx, app.Name)
+	if err != nil {
+		return err
+	}
+	if swap != nil {
+		return errors.Wrapf(router.ErrBackendSwapped, "app is swapped with %q, revert swap %s first", swap.Partner(app.Name), swap.ID)
+	}
 	w := evt
 	appName := app.Name
 	fmt.Fprintf(w, "---- Removing application %q...\n", appName)
@@ -856,6 +878,15 @@ func (app *App) AddUnits(ctx context.Context, n uint, process, versionStr string
 		return err
 	}
 
+	plan, err := app.planForProcess(ctx, process)
+	if err != nil {
+		return err
+	}
+	err = app.checkResourceQuota(ctx, resourcesForUnits(plan, int(n)))
+	if err != nil {
+		return err
+	}
+
 	units, err := app.Units(ctx)
 	if err != nil {
 		return err
@@ -928,7 +959,6 @@ func (app *App) RemoveUnits(ctx context.Context, n uint, process, versionStr str
 	if err != nil {
 		return newErrorWithLog(ctx, err, app, "remove units")
 	}
-
 	err = rebuild.RebuildRoutesWithAppName(app.Name, w)
 	return err
 }
@@ -1722,7 +1752,17 @@ func (app *App) RemoveCName(ctx context.Context, cnames ...string) error {
 		&removeCNameFromDatabase,
 		&rebuildRoutes,
 	}
-	return action.NewPipeline(actions...).Execute(ctx, app, cnames)
+	err := action.NewPipeline(actions...).Execute(ctx, app, cnames)
+	if err != nil {
+		return err
+	}
+	for _, cname := range cnames {
+		err = removeManagedCertificate(ctx, app.Name, cname)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (app *App) AddInstance(ctx context.Context, addArgs bind.AddInstanceArgs) error {
@@ -2314,7 +2354,18 @@ func (app *App) Shell(ctx context.Context, opts provision.ExecOptions) error {
 	return execProv.ExecuteCommand(ctx, opts)
 }
 
+// SetCertificate adds the certificate for the name to the app routers with
+// TLS support. Uploading a certificate for a managed cname stops tsuru from
+// renewing it.
 func (app *App) SetCertificate(ctx context.Context, name, certificate, key string) error {
+	err := app.addCertificate(ctx, name, certificate, key)
+	if err != nil {
+		return err
+	}
+	return removeManagedCertificate(ctx, app.Name, name)
+}
+
+func (app *App) addCertificate(ctx context.Context, name, certificate, key string) error {
 	err := app.validateNameForCert(ctx, name)
 	if err != nil {
 		return err
@@ -2377,7 +2428,7 @@ func (app *App) RemoveCertificate(ctx context.Context, name string) error {
 	if !removedAny {
 		return errors.New("no router with tls support")
 	}
-	return nil
+	return removeManagedCertificate(ctx, app.Name, name)
 }
 
 func (app *App) validateNameForCert(ctx context.Context, name string) error {
@@ -2430,7 +2481,13 @@ func (app *App) GetCertificates(ctx context.Context) (map[string]map[string]stri
 	return allCertificates, nil
 }
 
+// RoutableAddresses returns the addresses that must be routed to the app, the
+// ones of the other app while their router addresses are swapped.
 func (app *App) RoutableAddresses(ctx context.Context) ([]appTypes.RoutableAddresses, error) {
+	app, err := app.routingApp(ctx)
+	if err != nil {
+		return nil, err
+	}
 	prov, err := app.getProvisioner(ctx)
 	if err != nil {
 		return nil, err
@@ -2495,6 +2552,10 @@ func RenameTeam(ctx context.Context, oldName, newName string) error {
 }
 
 func (app *App) GetHealthcheckData(ctx context.Context) (routerTypes.HealthcheckData, error) {
+	app, err := app.routingApp(ctx)
+	if err != nil {
+		return routerTypes.HealthcheckData{}, err
+	}
 	version, err := servicemanager.AppVersion.LatestSuccessfulVersion(ctx, app)
 	if err != nil {
 		if err == appTypes.ErrNoVersionsAvailable {
@@ -2520,6 +2581,36 @@ func (app *App) GetHealthcheckData(ctx context.Context) (routerTypes.Healthcheck
 	return yamlData.ToRouterHC(), nil
 }
 
+// GetRouterWeights returns the traffic weights of the app versions currently
+// taking part in a canary deploy, it returns nil when no weight is set.
+func (app *App) GetRouterWeights(ctx context.Context) ([]router.BackendWeight, error) {
+	app, err := app.routingApp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := servicemanager.AppVersion.AppVersions(ctx, app)
+	if err != nil {
+		if err == appTypes.ErrNoVersionsAvailable {
+			err = nil
+		}
+		return nil, err
+	}
+	var weights []router.BackendWeight
+	for _, vi := range versions.Versions {
+		if vi.TrafficWeight <= 0 || vi.MarkedToRemoval {
+			continue
+		}
+		weights = append(weights, router.BackendWeight{
+			Version: vi.Version,
+			Weight:  vi.TrafficWeight,
+		})
+	}
+	sort.Slice(weights, func(i, j int) bool {
+		return weights[i].Version < weights[j].Version
+	})
+	return weights, nil
+}
+
 func validateEnv(envName string) error {
 	if !envVarNameRegexp.MatchString(envName) {
 		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("Invalid environment variable name: '%s'", envName)}
@@ -2719,6 +2810,18 @@ func (app *App) AutoScale(ctx context.Context, spec provTypes.AutoScaleSpec) err
 	if !ok {
 		return errors.Errorf("provisioner %q does not support native autoscaling", prov.GetName())
 	}
+	plan, err := app.planForProcess(ctx, spec.Process)
+	if err != nil {
+		return err
+	}
+	counts, err := app.processUnitCounts(ctx)
+	if err != nil {
+		return err
+	}
+	err = app.checkResourceQuota(ctx, resourcesForUnits(plan, int(spec.MaxUnits)-counts[spec.Process]))
+	if err != nil {
+		return err
+	}
 	return autoscaleProv.SetAutoScale(ctx, app, spec)
 }
 
@@ -31,6 +31,9 @@ type App interface {
 
 	// RemoveInstance removes an instance from the application.
 	RemoveInstance(ctx context.Context, args RemoveInstanceArgs) error
+
+	// InstanceEnvs returns the env vars the instance set in the application.
+	InstanceEnvs(serviceName, instanceName string) map[string]bindTypes.EnvVar
 }
 
 type SetEnvArgs struct {
@@ -0,0 +1,253 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/router/rebuild"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+)
+
+var (
+	DefaultCanarySteps        = []int{5, 25, 100}
+	DefaultCanaryStepInterval = time.Minute
+
+	ErrCanaryInvalidSteps = errors.New("canary steps must be increasing percentages between 1 and 100")
+)
+
+type CanaryOptions struct {
+	// Version is the image or the version number that will receive the
+	// traffic, it must have been deployed using new-version.
+	Version      string
+	Steps        []int
+	StepInterval time.Duration
+	Event        *event.Event
+	OutputStream io.Writer
+}
+
+// CanaryStep describes the progress of a canary deploy, it's stored as custom
+// data in the event of the deploy.
+type CanaryStep struct {
+	Step          int       `json:"step"`
+	TotalSteps    int       `json:"totalSteps"`
+	Version       int       `json:"version"`
+	Weight        int       `json:"weight"`
+	StableVersion int       `json:"stableVersion"`
+	Status        string    `json:"status"`
+	Reason        string    `json:"reason,omitempty"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+const (
+	CanaryStatusProgressing = "progressing"
+	CanaryStatusPromoted    = "promoted"
+	CanaryStatusAborted     = "aborted"
+)
+
+func normalizeCanarySteps(steps []int) ([]int, error) {
+	if len(steps) == 0 {
+		steps = DefaultCanarySteps
+	}
+	result := make([]int, 0, len(steps)+1)
+	last := 0
+	for _, step := range steps {
+		if step <= last || step > 100 {
+			return nil, ErrCanaryInvalidSteps
+		}
+		result = append(result, step)
+		last = step
+	}
+	if last != 100 {
+		result = append(result, 100)
+	}
+	return result, nil
+}
+
+// CanaryDeploy progressively shifts the app traffic to an already deployed
+// version. On each step the version receives the configured share of the
+// traffic, the units and routers are verified after the step interval and
+// any failure aborts the deploy, restoring all traffic to the stable version.
+// Reaching 100% promotes the version as the only routable one.
+func CanaryDeploy(ctx context.Context, app *App, opts CanaryOptions) error {
+	if opts.Event == nil {
+		return errors.Errorf("missing event in canary deploy opts")
+	}
+	steps, err := normalizeCanarySteps(opts.Steps)
+	if err != nil {
+		return err
+	}
+	if opts.StepInterval <= 0 {
+		opts.StepInterval = DefaultCanaryStepInterval
+	}
+	if opts.OutputStream != nil {
+		opts.Event.SetLogWriter(&tsuruIo.NoErrorWriter{Writer: opts.OutputStream})
+	}
+	canary, err := servicemanager.AppVersion.VersionByImageOrVersion(ctx, app, opts.Version)
+	if err != nil {
+		return err
+	}
+	stable, err := canaryStableVersion(ctx, app, canary)
+	if err != nil {
+		return err
+	}
+	for i, weight := range steps {
+		progress := CanaryStep{
+			Step:          i + 1,
+			TotalSteps:    len(steps),
+			Version:       canary.Version(),
+			Weight:        weight,
+			StableVersion: stable.Version(),
+			Status:        CanaryStatusProgressing,
+		}
+		if weight == 100 {
+			err = promoteCanary(ctx, app, canary, stable, opts.Event)
+			if err != nil {
+				return abortCanary(ctx, app, canary, stable, progress, opts.Event, err)
+			}
+			progress.Status = CanaryStatusPromoted
+			setCanaryProgress(ctx, opts.Event, progress)
+			return nil
+		}
+		fmt.Fprintf(opts.Event, "\n---- Canary step %d/%d: routing %d%% of traffic to version %d ----\n", progress.Step, progress.TotalSteps, weight, canary.Version())
+		err = setCanaryWeights(ctx, app, canary, stable, weight, opts.Event)
+		if err != nil {
+			return abortCanary(ctx, app, canary, stable, progress, opts.Event, err)
+		}
+		setCanaryProgress(ctx, opts.Event, progress)
+		select {
+		case <-ctx.Done():
+			return abortCanary(ctx, app, canary, stable, progress, opts.Event, ctx.Err())
+		case <-time.After(opts.StepInterval):
+		}
+		err = checkCanaryHealth(ctx, app, canary)
+		if err != nil {
+			return abortCanary(ctx, app, canary, stable, progress, opts.Event, err)
+		}
+	}
+	return nil
+}
+
+func canaryStableVersion(ctx context.Context, app *App, canary appTypes.AppVersion) (appTypes.AppVersion, error) {
+	deployed, err := app.DeployedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var others []int
+	var found bool
+	for _, v := range deployed {
+		if v == canary.Version() {
+			found = true
+			continue
+		}
+		others = append(others, v)
+	}
+	if !found {
+		return nil, errors.Errorf("version %d is not deployed, deploy it using new-version before starting a canary", canary.Version())
+	}
+	if len(others) != 1 {
+		return nil, errors.Errorf("canary deploy requires exactly one stable version besides version %d, found %d", canary.Version(), len(others))
+	}
+	return servicemanager.AppVersion.VersionByImageOrVersion(ctx, app, fmt.Sprint(others[0]))
+}
+
+func setCanaryWeights(ctx context.Context, app *App, canary, stable appTypes.AppVersion, weight int, w io.Writer) error {
+	err := canary.SetTrafficWeight(weight)
+	if err != nil {
+		return err
+	}
+	stableWeight := 0
+	if weight > 0 {
+		stableWeight = 100 - weight
+	}
+	err = stable.SetTrafficWeight(stableWeight)
+	if err != nil {
+		return err
+	}
+	return rebuild.RebuildRoutes(ctx, rebuild.RebuildRoutesOpts{
+		App:    app,
+		Writer: w,
+	})
+}
+
+func promoteCanary(ctx context.Context, app *App, canary, stable appTypes.AppVersion, w io.Writer) error {
+	fmt.Fprintf(w, "\n---- Promoting version %d, routing all traffic to it ----\n", canary.Version())
+	err := app.SetRoutable(ctx, canary, true)
+	if err != nil {
+		return err
+	}
+	err = app.SetRoutable(ctx, stable, false)
+	if err != nil {
+		return err
+	}
+	return setCanaryWeights(ctx, app, canary, stable, 0, w)
+}
+
+func abortCanary(ctx context.Context, app *App, canary, stable appTypes.AppVersion, progress CanaryStep, evt *event.Event, cause error) error {
+	// the request context may be already canceled, rolling back traffic
+	// must happen anyway.
+	ctx = context.WithoutCancel(ctx)
+	fmt.Fprintf(evt, "\n---- Aborting canary of version %d: %v ----\n", canary.Version(), cause)
+	err := app.SetRoutable(ctx, stable, true)
+	if err == nil {
+		err = app.SetRoutable(ctx, canary, false)
+	}
+	if err == nil {
+		err = setCanaryWeights(ctx, app, canary, stable, 0, evt)
+	}
+	if err != nil {
+		fmt.Fprintf(evt, "unable to restore traffic to version %d: %v\n", stable.Version(), err)
+	}
+	progress.Status = CanaryStatusAborted
+	progress.Reason = cause.Error()
+	setCanaryProgress(ctx, evt, progress)
+	return errors.Wrapf(cause, "canary deploy of version %d aborted", canary.Version())
+}
+
+func setCanaryProgress(ctx context.Context, evt *event.Event, progress CanaryStep) {
+	progress.UpdatedAt = time.Now().UTC()
+	err := evt.SetOtherCustomData(ctx, progress)
+	if err != nil {
+		fmt.Fprintf(evt, "unable to store canary progress: %v\n", err)
+	}
+}
+
+func checkCanaryHealth(ctx context.Context, app *App, canary appTypes.AppVersion) error {
+	units, err := app.Units(ctx)
+	if err != nil {
+		return err
+	}
+	for _, u := range units {
+		if u.Version != canary.Version() {
+			continue
+		}
+		if u.Status == provTypes.UnitStatusError {
+			return errors.Errorf("unit %s of version %d is in error state: %s", u.ID, canary.Version(), u.StatusReason)
+		}
+	}
+	for _, appRouter := range app.GetRouters() {
+		r, err := router.Get(ctx, appRouter.Name)
+		if err != nil {
+			return err
+		}
+		status, err := r.GetBackendStatus(ctx, app)
+		if err != nil {
+			return errors.Wrapf(err, "unable to check router %q", appRouter.Name)
+		}
+		if status.Status != router.BackendStatusReady {
+			return errors.Errorf("router %q backend not ready: %s", appRouter.Name, status.Detail)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/router/routertest"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) setupCanaryApp(c *check.C) (*App, *provisiontest.VersionsProvisioner, appTypes.AppVersion, appTypes.AppVersion, *event.Event) {
+	versionsProv := &provisiontest.VersionsProvisioner{FakeProvisioner: s.provisioner}
+	provision.Register("versionsProv", func() (provision.Provisioner, error) {
+		return versionsProv, nil
+	})
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "canary-pool", Provisioner: "versionsProv"})
+	c.Assert(err, check.IsNil)
+	a := App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name, Pool: "canary-pool"}
+	err = CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: "app", Value: a.Name},
+		Kind:     permission.PermAppDeployCanary,
+		RawOwner: eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	stable := newSuccessfulAppVersion(c, &a)
+	_, err = versionsProv.Deploy(context.TODO(), provision.DeployArgs{App: &a, Version: stable, Event: evt})
+	c.Assert(err, check.IsNil)
+	canary := newSuccessfulAppVersion(c, &a)
+	_, err = versionsProv.Deploy(context.TODO(), provision.DeployArgs{App: &a, Version: canary, Event: evt, PreserveVersions: true})
+	c.Assert(err, check.IsNil)
+	return &a, versionsProv, stable, canary, evt
+}
+
+func (s *S) TestCanaryDeploy(c *check.C) {
+	defer provision.Unregister("versionsProv")
+	a, prov, stable, canary, evt := s.setupCanaryApp(c)
+	c.Assert(prov.IsRoutable(a, canary.Version()), check.Equals, false)
+	err := CanaryDeploy(context.TODO(), a, CanaryOptions{
+		Version:      strconv.Itoa(canary.Version()),
+		Steps:        []int{10, 50},
+		StepInterval: time.Millisecond,
+		Event:        evt,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(prov.IsRoutable(a, canary.Version()), check.Equals, true)
+	c.Assert(prov.IsRoutable(a, stable.Version()), check.Equals, false)
+	c.Assert(routertest.FakeRouter.GetWeights(a.Name), check.HasLen, 0)
+	c.Assert(evt.Log(), check.Matches, `(?s).*Canary step 1/3: routing 10% of traffic.*Canary step 2/3: routing 50% of traffic.*Promoting version.*`)
+}
+
+func (s *S) TestCanaryDeployAbortOnRouterFailure(c *check.C) {
+	defer provision.Unregister("versionsProv")
+	a, prov, stable, canary, evt := s.setupCanaryApp(c)
+	routertest.FakeRouter.FailuresByHost[a.Name] = true
+	err := CanaryDeploy(context.TODO(), a, CanaryOptions{
+		Version:      strconv.Itoa(canary.Version()),
+		StepInterval: time.Millisecond,
+		Event:        evt,
+	})
+	c.Assert(err, check.ErrorMatches, `canary deploy of version 2 aborted: .*Forced failure`)
+	c.Assert(prov.IsRoutable(a, canary.Version()), check.Equals, false)
+	c.Assert(prov.IsRoutable(a, stable.Version()), check.Equals, true)
+	versions, err := servicemanager.AppVersion.AppVersions(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	c.Assert(versions.Versions[canary.Version()].TrafficWeight, check.Equals, 0)
+	c.Assert(versions.Versions[stable.Version()].TrafficWeight, check.Equals, 0)
+}
+
+func (s *S) TestCanaryDeployVersionNotDeployed(c *check.C) {
+	defer provision.Unregister("versionsProv")
+	a, _, _, _, evt := s.setupCanaryApp(c)
+	notDeployed := newSuccessfulAppVersion(c, a)
+	err := CanaryDeploy(context.TODO(), a, CanaryOptions{
+		Version: strconv.Itoa(notDeployed.Version()),
+		Event:   evt,
+	})
+	c.Assert(err, check.ErrorMatches, `version 3 is not deployed, deploy it using new-version before starting a canary`)
+}
+
+func (s *S) TestGetRouterWeights(c *check.C) {
+	defer provision.Unregister("versionsProv")
+	a, _, stable, canary, _ := s.setupCanaryApp(c)
+	err := canary.SetTrafficWeight(20)
+	c.Assert(err, check.IsNil)
+	err = stable.SetTrafficWeight(80)
+	c.Assert(err, check.IsNil)
+	weights, err := a.GetRouterWeights(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(weights, check.DeepEquals, []router.BackendWeight{
+		{Version: stable.Version(), Weight: 80},
+		{Version: canary.Version(), Weight: 20},
+	})
+}
+
+func (s *S) TestNormalizeCanarySteps(c *check.C) {
+	tests := []struct {
+		steps    []int
+		expected []int
+		err      error
+	}{
+		{steps: nil, expected: []int{5, 25, 100}},
+		{steps: []int{10, 50}, expected: []int{10, 50, 100}},
+		{steps: []int{30, 100}, expected: []int{30, 100}},
+		{steps: []int{50, 10}, err: ErrCanaryInvalidSteps},
+		{steps: []int{0, 10}, err: ErrCanaryInvalidSteps},
+		{steps: []int{10, 120}, err: ErrCanaryInvalidSteps},
+	}
+	for _, tt := range tests {
+		steps, err := normalizeCanarySteps(tt.steps)
+		c.Check(err, check.Equals, tt.err)
+		c.Check(steps, check.DeepEquals, tt.expected)
+	}
+}
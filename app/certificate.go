@@ -0,0 +1,582 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/router"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/crypto/acme"
+)
+
+const (
+	defaultCertificateCheckInterval = time.Minute
+	defaultCertificateRenewBefore   = 30 * 24 * time.Hour
+	defaultCertificateAlertBefore   = 7 * 24 * time.Hour
+	certificateRetryInterval        = time.Hour
+
+	certificateRenewalInternalKind    = "certificate-renewal"
+	certificateExpirationInternalKind = "certificate-expiration"
+)
+
+var (
+	ErrACMENotConfigured            = errors.New("acme is not configured")
+	ErrNoACMERouter                 = errors.New("no router able to answer acme challenges")
+	ErrManagedCertificateNotFound   = errors.New("managed certificate not found")
+	errManagedCertificateNotClaimed = errors.New("managed certificate is being renewed by another instance")
+)
+
+func acmeDirectoryURL() string {
+	directoryURL, _ := config.GetString("acme:directory-url")
+	return directoryURL
+}
+
+// SetManagedCertificate flags the cname as managed, making tsuru obtain a
+// certificate for it through ACME and renew it before it expires. The
+// certificate is issued in background by the certificate manager.
+func (app *App) SetManagedCertificate(ctx context.Context, name string) error {
+	if acmeDirectoryURL() == "" {
+		return ErrACMENotConfigured
+	}
+	err := app.validateNameForCert(ctx, name)
+	if err != nil {
+		return err
+	}
+	routers, err := app.acmeRouters(ctx)
+	if err != nil {
+		return err
+	}
+	if len(routers) == 0 {
+		return ErrNoACMERouter
+	}
+	collection, err := storagev2.ManagedCertificatesCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"app": app.Name, "cname": name}, mongoBSON.M{
+		"$set": mongoBSON.M{
+			"status":      appTypes.ManagedCertificatePending,
+			"nextattempt": time.Now().UTC(),
+			"error":       "",
+		},
+	}, options.Update().SetUpsert(true))
+	return err
+}
+
+// ManagedCertificates returns the state of the managed certificates of the
+// app, indexed by cname.
+func (app *App) ManagedCertificates(ctx context.Context) (map[string]appTypes.ManagedCertificate, error) {
+	collection, err := storagev2.ManagedCertificatesCollection()
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := collection.Find(ctx, mongoBSON.M{"app": app.Name})
+	if err != nil {
+		return nil, err
+	}
+	var certs []appTypes.ManagedCertificate
+	err = cursor.All(ctx, &certs)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]appTypes.ManagedCertificate, len(certs))
+	for _, cert := range certs {
+		result[cert.CName] = cert
+	}
+	return result, nil
+}
+
+func removeManagedCertificate(ctx context.Context, appName, cname string) error {
+	collection, err := storagev2.ManagedCertificatesCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.DeleteOne(ctx, mongoBSON.M{"app": appName, "cname": cname})
+	return err
+}
+
+// ACMEChallengeResponse returns the key authorization answering the pending
+// HTTP-01 challenge identified by the token.
+func ACMEChallengeResponse(ctx context.Context, token string) (string, error) {
+	collection, err := storagev2.ManagedCertificatesCollection()
+	if err != nil {
+		return "", err
+	}
+	var cert appTypes.ManagedCertificate
+	err = collection.FindOne(ctx, mongoBSON.M{"challenge.token": token}).Decode(&cert)
+	if err == mongo.ErrNoDocuments {
+		return "", ErrManagedCertificateNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return cert.Challenge.KeyAuthorization, nil
+}
+
+func setACMEChallenge(ctx context.Context, appName, cname string, challenge *appTypes.ACMEChallenge) error {
+	collection, err := storagev2.ManagedCertificatesCollection()
+	if err != nil {
+		return err
+	}
+	update := mongoBSON.M{"$unset": mongoBSON.M{"challenge": ""}}
+	if challenge != nil {
+		update = mongoBSON.M{"$set": mongoBSON.M{"challenge": challenge}}
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"app": appName, "cname": cname}, update)
+	return err
+}
+
+func (app *App) acmeRouters(ctx context.Context) ([]router.ACMEChallengeRouter, error) {
+	var routers []router.ACMEChallengeRouter
+	for _, appRouter := range app.GetRouters() {
+		r, err := router.Get(ctx, appRouter.Name)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := r.(router.TLSRouter); !ok {
+			continue
+		}
+		if acmeRouter, ok := r.(router.ACMEChallengeRouter); ok {
+			routers = append(routers, acmeRouter)
+		}
+	}
+	return routers, nil
+}
+
+// InitializeCertificateManager starts the worker obtaining and renewing the
+// managed certificates of app cnames through the ACME directory set in the
+// acme:directory-url config. It does nothing when ACME isn't configured.
+func InitializeCertificateManager() error {
+	directoryURL := acmeDirectoryURL()
+	if directoryURL == "" {
+		return nil
+	}
+	email, _ := config.GetString("acme:email")
+	keyFile, _ := config.GetString("acme:account-key-file")
+	issuer, err := newACMEIssuer(directoryURL, email, keyFile)
+	if err != nil {
+		return err
+	}
+	m := newCertificateManager(issuer)
+	m.start()
+	shutdown.Register(m)
+	return nil
+}
+
+type certificateManager struct {
+	issuer      *acmeIssuer
+	interval    time.Duration
+	renewBefore time.Duration
+	alertBefore time.Duration
+	once        *sync.Once
+	stopCh      chan struct{}
+}
+
+func newCertificateManager(issuer *acmeIssuer) *certificateManager {
+	m := &certificateManager{issuer: issuer, once: &sync.Once{}}
+	m.interval, _ = config.GetDuration("acme:check-interval")
+	if m.interval <= 0 {
+		m.interval = defaultCertificateCheckInterval
+	}
+	m.renewBefore, _ = config.GetDuration("acme:renew-before")
+	if m.renewBefore <= 0 {
+		m.renewBefore = defaultCertificateRenewBefore
+	}
+	m.alertBefore, _ = config.GetDuration("acme:alert-before")
+	if m.alertBefore <= 0 {
+		m.alertBefore = defaultCertificateAlertBefore
+	}
+	return m
+}
+
+func (m *certificateManager) start() {
+	m.once.Do(func() {
+		m.stopCh = make(chan struct{})
+		go m.spin()
+	})
+}
+
+func (m *certificateManager) Shutdown(ctx context.Context) error {
+	if m.stopCh == nil {
+		return nil
+	}
+	m.stopCh <- struct{}{}
+	m.stopCh = nil
+	m.once = &sync.Once{}
+	return nil
+}
+
+func (m *certificateManager) spin() {
+	for {
+		err := m.checkCertificates(context.Background())
+		if err != nil {
+			log.Errorf("[certificate manager] %v", err)
+		}
+		select {
+		case <-m.stopCh:
+			return
+		case <-time.After(m.interval):
+		}
+	}
+}
+
+// checkCertificates renews the managed certificates due to be renewed and
+// alerts about the ones close to their expiration.
+func (m *certificateManager) checkCertificates(ctx context.Context) error {
+	collection, err := storagev2.ManagedCertificatesCollection()
+	if err != nil {
+		return err
+	}
+	cursor, err := collection.Find(ctx, mongoBSON.M{})
+	if err != nil {
+		return err
+	}
+	var certs []appTypes.ManagedCertificate
+	err = cursor.All(ctx, &certs)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for i := range certs {
+		cert := &certs[i]
+		if !cert.NextAttempt.After(now) {
+			err = m.renew(ctx, cert)
+			if err != nil && err != errManagedCertificateNotClaimed {
+				log.Errorf("[certificate manager] unable to renew certificate for %q in app %q: %v", cert.CName, cert.App, err)
+			}
+		}
+		if m.expiring(cert, now) {
+			err = m.alert(ctx, cert)
+			if err != nil {
+				log.Errorf("[certificate manager] unable to alert expiration of certificate for %q in app %q: %v", cert.CName, cert.App, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *certificateManager) expiring(cert *appTypes.ManagedCertificate, now time.Time) bool {
+	if cert.NotAfter.IsZero() || cert.AlertedNotAfter.Equal(cert.NotAfter) {
+		return false
+	}
+	return cert.NotAfter.Sub(now) < m.alertBefore
+}
+
+// claimManagedCertificate atomically moves the next attempt of the certificate, returning
+// false if another tsuru API instance already did it.
+func claimManagedCertificate(ctx context.Context, cert *appTypes.ManagedCertificate, next time.Time) (bool, error) {
+	collection, err := storagev2.ManagedCertificatesCollection()
+	if err != nil {
+		return false, err
+	}
+	result, err := collection.UpdateOne(ctx, mongoBSON.M{
+		"app":         cert.App,
+		"cname":       cert.CName,
+		"nextattempt": cert.NextAttempt,
+	}, mongoBSON.M{"$set": mongoBSON.M{"nextattempt": next}})
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+func (m *certificateManager) renew(ctx context.Context, cert *appTypes.ManagedCertificate) (err error) {
+	now := time.Now().UTC()
+	claimed, err := claimManagedCertificate(ctx, cert, now.Add(certificateRetryInterval))
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return errManagedCertificateNotClaimed
+	}
+	a, err := GetByName(ctx, cert.App)
+	if err == appTypes.ErrAppNotFound {
+		return removeManagedCertificate(ctx, cert.App, cert.CName)
+	}
+	if err != nil {
+		return err
+	}
+	evt, err := event.NewInternal(ctx, &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+		InternalKind: certificateRenewalInternalKind,
+		DisableLock:  true,
+		CustomData:   map[string]interface{}{"cname": cert.CName},
+		Allowed:      event.Allowed(permission.PermAppReadEvents, permission.Contexts(permTypes.CtxApp, []string{a.Name})...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	fmt.Fprintf(evt, "Requesting certificate for %q\n", cert.CName)
+	certificate, key, notAfter, err := m.issuer.issue(ctx, a, cert.CName)
+	if err == nil {
+		err = a.addCertificate(ctx, cert.CName, string(certificate), string(key))
+	}
+	update := mongoBSON.M{}
+	if err != nil {
+		fmt.Fprintf(evt, "Unable to obtain certificate, retrying at %s\n", now.Add(certificateRetryInterval).Format(time.RFC3339))
+		update["status"] = appTypes.ManagedCertificateFailed
+		update["error"] = err.Error()
+	} else {
+		fmt.Fprintf(evt, "Certificate installed, valid until %s\n", notAfter.Format(time.RFC3339))
+		update["status"] = appTypes.ManagedCertificateIssued
+		update["error"] = ""
+		update["notafter"] = notAfter
+		update["renewedat"] = now
+		update["nextattempt"] = notAfter.Add(-m.renewBefore)
+	}
+	collection, updateErr := storagev2.ManagedCertificatesCollection()
+	if updateErr == nil {
+		_, updateErr = collection.UpdateOne(ctx, mongoBSON.M{"app": cert.App, "cname": cert.CName}, mongoBSON.M{"$set": update})
+	}
+	if err == nil {
+		err = updateErr
+	}
+	return err
+}
+
+// alert records a failed event for the app warning that the certificate is
+// about to expire, which can be used to trigger webhooks.
+func (m *certificateManager) alert(ctx context.Context, cert *appTypes.ManagedCertificate) error {
+	a, err := GetByName(ctx, cert.App)
+	if err != nil {
+		return err
+	}
+	evt, err := event.NewInternal(ctx, &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+		InternalKind: certificateExpirationInternalKind,
+		DisableLock:  true,
+		CustomData: map[string]interface{}{
+			"cname":    cert.CName,
+			"notAfter": cert.NotAfter,
+		},
+		Allowed: event.Allowed(permission.PermAppReadEvents, permission.Contexts(permTypes.CtxApp, []string{a.Name})...),
+	})
+	if err != nil {
+		return err
+	}
+	if cert.Error != "" {
+		fmt.Fprintf(evt, "Last renewal attempt failed: %s\n", cert.Error)
+	}
+	err = evt.Done(ctx, errors.Errorf("certificate for %q expires at %s", cert.CName, cert.NotAfter.Format(time.RFC3339)))
+	if err != nil {
+		return err
+	}
+	collection, err := storagev2.ManagedCertificatesCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"app": cert.App, "cname": cert.CName}, mongoBSON.M{
+		"$set": mongoBSON.M{"alertednotafter": cert.NotAfter},
+	})
+	return err
+}
+
+// acmeIssuer obtains certificates from an ACME certificate authority using
+// HTTP-01 challenges routed by the app routers to the tsuru API.
+type acmeIssuer struct {
+	client     *acme.Client
+	email      string
+	mu         sync.Mutex
+	registered bool
+}
+
+func newACMEIssuer(directoryURL, email, keyFile string) (*acmeIssuer, error) {
+	key, err := acmeAccountKey(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return &acmeIssuer{
+		client: &acme.Client{Key: key, DirectoryURL: directoryURL},
+		email:  email,
+	}, nil
+}
+
+// acmeAccountKey loads the PEM encoded ACME account key from the file. The
+// file is required, as generating a key on each start would register a new
+// ACME account every time, one for each API instance.
+func acmeAccountKey(keyFile string) (crypto.Signer, error) {
+	if keyFile == "" {
+		return nil, errors.New("acme:account-key-file must be set to use managed certificates")
+	}
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read acme account key")
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid acme account key: no PEM data found")
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid acme account key")
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("invalid acme account key: unsupported key type")
+	}
+	return signer, nil
+}
+
+func (i *acmeIssuer) register(ctx context.Context) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.registered {
+		return nil
+	}
+	account := &acme.Account{}
+	if i.email != "" {
+		account.Contact = []string{"mailto:" + i.email}
+	}
+	_, err := i.client.Register(ctx, account, acme.AcceptTOS)
+	if err != nil && err != acme.ErrAccountAlreadyExists {
+		return errors.Wrap(err, "unable to register acme account")
+	}
+	i.registered = true
+	return nil
+}
+
+// issue obtains a new certificate for the cname, returning the PEM encoded
+// certificate chain and private key along with the certificate expiration.
+func (i *acmeIssuer) issue(ctx context.Context, a *App, cname string) ([]byte, []byte, time.Time, error) {
+	err := i.register(ctx)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	order, err := i.client.AuthorizeOrder(ctx, acme.DomainIDs(cname))
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	for _, authzURL := range order.AuthzURLs {
+		err = i.authorize(ctx, a, cname, authzURL)
+		if err != nil {
+			return nil, nil, time.Time{}, err
+		}
+	}
+	order, err = i.client.WaitOrder(ctx, order.URI)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: cname},
+		DNSNames: []string{cname},
+	}, key)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	chain, _, err := i.client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	leaf, err := x509.ParseCertificate(chain[0])
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	var certificate bytes.Buffer
+	for _, der := range chain {
+		pem.Encode(&certificate, &pem.Block{Type: "CERTIFICATE", Bytes: der})
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certificate.Bytes(), keyPEM, leaf.NotAfter, nil
+}
+
+// authorize answers the HTTP-01 challenge of the authorization, routing the
+// challenge path of the cname to the tsuru API while the certificate
+// authority validates it.
+func (i *acmeIssuer) authorize(ctx context.Context, a *App, cname, authzURL string) error {
+	authz, err := i.client.GetAuthorization(ctx, authzURL)
+	if err != nil {
+		return err
+	}
+	if authz.Status == acme.StatusValid {
+		return nil
+	}
+	var chal *acme.Challenge
+	for _, c := range authz.Challenges {
+		if c.Type == "http-01" {
+			chal = c
+			break
+		}
+	}
+	if chal == nil {
+		return errors.Errorf("no http-01 challenge offered for %q", cname)
+	}
+	keyAuth, err := i.client.HTTP01ChallengeResponse(chal.Token)
+	if err != nil {
+		return err
+	}
+	err = setACMEChallenge(ctx, a.Name, cname, &appTypes.ACMEChallenge{Token: chal.Token, KeyAuthorization: keyAuth})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cleanupErr := setACMEChallenge(ctx, a.Name, cname, nil); cleanupErr != nil {
+			log.Errorf("[certificate manager] unable to remove challenge for %q: %v", cname, cleanupErr)
+		}
+	}()
+	routers, err := a.acmeRouters(ctx)
+	if err != nil {
+		return err
+	}
+	if len(routers) == 0 {
+		return ErrNoACMERouter
+	}
+	for _, r := range routers {
+		err = r.AddACMEChallenge(ctx, a, cname, chal.Token)
+		if err != nil {
+			return err
+		}
+		defer func(r router.ACMEChallengeRouter) {
+			if cleanupErr := r.RemoveACMEChallenge(ctx, a, cname, chal.Token); cleanupErr != nil {
+				log.Errorf("[certificate manager] unable to remove challenge route for %q: %v", cname, cleanupErr)
+			}
+		}(r)
+	}
+	_, err = i.client.Accept(ctx, chal)
+	if err != nil {
+		return err
+	}
+	_, err = i.client.WaitAuthorization(ctx, authzURL)
+	return err
+}
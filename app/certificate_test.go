@@ -0,0 +1,228 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app/acmetest"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/router/routertest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) createManagedCertificateApp(c *check.C) *App {
+	a := App{Name: "my-test-app", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-tls"}}, CName: []string{"app.io"}}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	return &a
+}
+
+func writeACMEAccountKey(c *check.C) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, check.IsNil)
+	der, err := x509.MarshalECPrivateKey(key)
+	c.Assert(err, check.IsNil)
+	keyFile := filepath.Join(c.MkDir(), "account.key")
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600)
+	c.Assert(err, check.IsNil)
+	return keyFile
+}
+
+func (s *S) newTestACMEServer(c *check.C) *acmetest.Server {
+	srv, err := acmetest.NewServer(func(domain, token string) (string, error) {
+		if routertest.TLSRouter.ACMEChallenge(domain) != token {
+			return "", errors.Errorf("challenge for %q not routed", domain)
+		}
+		return ACMEChallengeResponse(context.TODO(), token)
+	})
+	c.Assert(err, check.IsNil)
+	config.Set("acme:directory-url", srv.DirectoryURL())
+	return srv
+}
+
+func (s *S) TestSetManagedCertificate(c *check.C) {
+	config.Set("acme:directory-url", "http://localhost/directory")
+	defer config.Unset("acme")
+	a := s.createManagedCertificateApp(c)
+	err := a.SetManagedCertificate(context.TODO(), "app.io")
+	c.Assert(err, check.IsNil)
+	certs, err := a.ManagedCertificates(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(certs, check.HasLen, 1)
+	c.Assert(certs["app.io"].Status, check.Equals, appTypes.ManagedCertificatePending)
+	c.Assert(certs["app.io"].NextAttempt.IsZero(), check.Equals, false)
+}
+
+func (s *S) TestSetManagedCertificateACMENotConfigured(c *check.C) {
+	a := s.createManagedCertificateApp(c)
+	err := a.SetManagedCertificate(context.TODO(), "app.io")
+	c.Assert(err, check.Equals, ErrACMENotConfigured)
+}
+
+func (s *S) TestSetManagedCertificateInvalidName(c *check.C) {
+	config.Set("acme:directory-url", "http://localhost/directory")
+	defer config.Unset("acme")
+	a := s.createManagedCertificateApp(c)
+	err := a.SetManagedCertificate(context.TODO(), "example.com")
+	c.Assert(err, check.ErrorMatches, "invalid name")
+}
+
+func (s *S) TestSetManagedCertificateNoACMERouter(c *check.C) {
+	config.Set("acme:directory-url", "http://localhost/directory")
+	defer config.Unset("acme")
+	a := App{Name: "my-test-app", TeamOwner: s.team.Name, CName: []string{"app.io"}}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetManagedCertificate(context.TODO(), "app.io")
+	c.Assert(err, check.Equals, ErrNoACMERouter)
+}
+
+func (s *S) TestRemoveCNameStopsManagingCertificate(c *check.C) {
+	config.Set("acme:directory-url", "http://localhost/directory")
+	defer config.Unset("acme")
+	a := s.createManagedCertificateApp(c)
+	err := a.SetManagedCertificate(context.TODO(), "app.io")
+	c.Assert(err, check.IsNil)
+	err = a.RemoveCName(context.TODO(), "app.io")
+	c.Assert(err, check.IsNil)
+	certs, err := a.ManagedCertificates(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(certs, check.HasLen, 0)
+}
+
+func (s *S) TestSetCertificateStopsManagingCName(c *check.C) {
+	config.Set("acme:directory-url", "http://localhost/directory")
+	defer config.Unset("acme")
+	cert, err := os.ReadFile("testdata/certificate.crt")
+	c.Assert(err, check.IsNil)
+	key, err := os.ReadFile("testdata/private.key")
+	c.Assert(err, check.IsNil)
+	a := s.createManagedCertificateApp(c)
+	err = a.SetManagedCertificate(context.TODO(), "app.io")
+	c.Assert(err, check.IsNil)
+	err = a.SetCertificate(context.TODO(), "app.io", string(cert), string(key))
+	c.Assert(err, check.IsNil)
+	certs, err := a.ManagedCertificates(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(certs, check.HasLen, 0)
+}
+
+func (s *S) TestCertificateManagerIssuesCertificate(c *check.C) {
+	srv := s.newTestACMEServer(c)
+	defer srv.Close()
+	defer config.Unset("acme")
+	a := s.createManagedCertificateApp(c)
+	err := a.SetManagedCertificate(context.TODO(), "app.io")
+	c.Assert(err, check.IsNil)
+	issuer, err := newACMEIssuer(srv.DirectoryURL(), "admin@example.com", writeACMEAccountKey(c))
+	c.Assert(err, check.IsNil)
+	m := newCertificateManager(issuer)
+	err = m.checkCertificates(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.TLSRouter.Certs["app.io"], check.Not(check.Equals), "")
+	c.Assert(routertest.TLSRouter.ACMEChallenge("app.io"), check.Equals, "")
+	certs, err := a.ManagedCertificates(context.TODO())
+	c.Assert(err, check.IsNil)
+	cert := certs["app.io"]
+	c.Assert(cert.Status, check.Equals, appTypes.ManagedCertificateIssued)
+	c.Assert(cert.Error, check.Equals, "")
+	c.Assert(cert.Challenge, check.IsNil)
+	c.Assert(cert.NotAfter.After(time.Now().Add(89*24*time.Hour)), check.Equals, true)
+	c.Assert(cert.NextAttempt.Equal(cert.NotAfter.Add(-defaultCertificateRenewBefore)), check.Equals, true)
+	evts, err := event.List(context.TODO(), &event.Filter{
+		Target:    eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+		KindNames: []string{certificateRenewalInternalKind},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].Error, check.Equals, "")
+}
+
+func (s *S) TestCertificateManagerIssueFailure(c *check.C) {
+	srv := s.newTestACMEServer(c)
+	defer srv.Close()
+	defer config.Unset("acme")
+	srv.Validate = func(domain, token string) (string, error) {
+		return "", errors.New("connection refused")
+	}
+	a := s.createManagedCertificateApp(c)
+	err := a.SetManagedCertificate(context.TODO(), "app.io")
+	c.Assert(err, check.IsNil)
+	issuer, err := newACMEIssuer(srv.DirectoryURL(), "", writeACMEAccountKey(c))
+	c.Assert(err, check.IsNil)
+	m := newCertificateManager(issuer)
+	err = m.checkCertificates(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.TLSRouter.Certs["app.io"], check.Equals, "")
+	certs, err := a.ManagedCertificates(context.TODO())
+	c.Assert(err, check.IsNil)
+	cert := certs["app.io"]
+	c.Assert(cert.Status, check.Equals, appTypes.ManagedCertificateFailed)
+	c.Assert(cert.Error, check.Not(check.Equals), "")
+	c.Assert(cert.NextAttempt.After(time.Now().Add(certificateRetryInterval-time.Minute)), check.Equals, true)
+}
+
+func (s *S) TestCertificateManagerAlertsExpiration(c *check.C) {
+	a := s.createManagedCertificateApp(c)
+	collection, err := storagev2.ManagedCertificatesCollection()
+	c.Assert(err, check.IsNil)
+	notAfter := time.Now().UTC().Add(24 * time.Hour).Truncate(time.Millisecond)
+	_, err = collection.InsertOne(context.TODO(), appTypes.ManagedCertificate{
+		App:         a.Name,
+		CName:       "app.io",
+		Status:      appTypes.ManagedCertificateFailed,
+		NotAfter:    notAfter,
+		NextAttempt: time.Now().UTC().Add(time.Hour),
+		Error:       "connection refused",
+	})
+	c.Assert(err, check.IsNil)
+	issuer, err := newACMEIssuer("http://localhost/directory", "", writeACMEAccountKey(c))
+	c.Assert(err, check.IsNil)
+	m := newCertificateManager(issuer)
+	err = m.checkCertificates(context.TODO())
+	c.Assert(err, check.IsNil)
+	err = m.checkCertificates(context.TODO())
+	c.Assert(err, check.IsNil)
+	evts, err := event.List(context.TODO(), &event.Filter{
+		Target:    eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+		KindNames: []string{certificateExpirationInternalKind},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].Error, check.Matches, `certificate for "app.io" expires at .*`)
+	certs, err := a.ManagedCertificates(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(certs["app.io"].AlertedNotAfter.Equal(notAfter), check.Equals, true)
+}
+
+func (s *S) TestACMEAccountKey(c *check.C) {
+	_, err := acmeAccountKey("")
+	c.Assert(err, check.ErrorMatches, "acme:account-key-file must be set to use managed certificates")
+	keyFile := writeACMEAccountKey(c)
+	key1, err := acmeAccountKey(keyFile)
+	c.Assert(err, check.IsNil)
+	key2, err := acmeAccountKey(keyFile)
+	c.Assert(err, check.IsNil)
+	c.Assert(key1.Public().(*ecdsa.PublicKey).Equal(key2.Public()), check.Equals, true)
+}
+
+func (s *S) TestACMEChallengeResponseNotFound(c *check.C) {
+	_, err := ACMEChallengeResponse(context.TODO(), "unknown")
+	c.Assert(err, check.Equals, ErrManagedCertificateNotFound)
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/router/rebuild"
 	"github.com/tsuru/tsuru/servicemanager"
 	"github.com/tsuru/tsuru/set"
@@ -49,6 +50,7 @@ type DeployData struct {
 	CanRollback bool
 	Diff        string
 	Message     string
+	Approval    *eventTypes.ApprovalInfo
 }
 
 func findValidImages(ctx context.Context, appNames []string) (set.Set, error) {
@@ -131,6 +133,7 @@ func eventToDeployData(evt *event.Event, validImages set.Set, full bool) *Deploy
 		Duration:  evt.EndTime.Sub(evt.StartTime),
 		Error:     evt.Error,
 		User:      evt.Owner.Name,
+		Approval:  evt.Approval,
 	}
 	var err error
 	var deployOptions DeployOptions
@@ -336,6 +339,14 @@ func Deploy(ctx context.Context, opts DeployOptions) (string, error) {
 	logWriter.Async()
 	defer logWriter.Close()
 	opts.Event.SetLogWriter(io.MultiWriter(&tsuruIo.NoErrorWriter{Writer: opts.OutputStream}, &logWriter))
+	err = waitDeployApproval(ctx, &opts)
+	if err != nil {
+		return "", err
+	}
+	var previousVersion appTypes.AppVersion
+	if !opts.NewVersion && !opts.Rollback && opts.Kind != provisionTypes.DeployRollback {
+		previousVersion, _ = servicemanager.AppVersion.LatestSuccessfulVersion(ctx, opts.App)
+	}
 	imageID, err := deployToProvisioner(ctx, &opts, opts.Event)
 	if err != nil {
 		return "", newErrorWithLog(ctx, err, opts.App, "deploy")
@@ -355,9 +366,41 @@ func Deploy(ctx context.Context, opts DeployOptions) (string, error) {
 	} else if opts.App.UpdatePlatform {
 		opts.App.SetUpdatePlatform(ctx, false)
 	}
+	startDeployVerification(ctx, opts.App, previousVersion)
 	return imageID, nil
 }
 
+// waitDeployApproval blocks the deploy until it's approved by a second user
+// when the app pool requires deploy approvals. Rollbacks started internally,
+// e.g. by a failed post deploy verification, don't wait for approvals.
+func waitDeployApproval(ctx context.Context, opts *DeployOptions) error {
+	if opts.App.Pool == "" {
+		return nil
+	}
+	if opts.Kind == provisionTypes.DeployRollback && opts.Event.Owner.Type == eventTypes.OwnerTypeInternal {
+		return nil
+	}
+	p, err := pool.GetPoolByName(ctx, opts.App.Pool)
+	if err != nil {
+		return err
+	}
+	required, timeout, err := p.DeployApproval()
+	if err != nil || !required {
+		return err
+	}
+	err = opts.Event.RequestApproval(ctx, timeout)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(opts.Event, "---- Pool %q requires deploy approval, waiting up to %s for approval ----\n", p.Name, timeout)
+	err = opts.Event.WaitApproval(ctx)
+	if err != nil {
+		return errors.Wrap(err, "deploy not approved")
+	}
+	fmt.Fprintf(opts.Event, "---- Deploy approved by %s ----\n", opts.Event.Approval.Owner)
+	return nil
+}
+
 func RollbackUpdate(ctx context.Context, app *App, imageID, reason string, disableRollback bool) error {
 	version, err := servicemanager.AppVersion.VersionByImageOrVersion(ctx, app, imageID)
 	if err != nil {
@@ -403,6 +446,11 @@ func deployToProvisioner(ctx context.Context, opts *DeployOptions, evt *event.Ev
 		}
 	}
 
+	err = opts.App.checkDeployResourceQuota(ctx, version, opts.NewVersion)
+	if err != nil {
+		return "", err
+	}
+
 	return deployer.Deploy(ctx, provision.DeployArgs{
 		App:              opts.App,
 		Version:          version,
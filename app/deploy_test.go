@@ -19,6 +19,7 @@ import (
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/servicemanager"
 	appTypes "github.com/tsuru/tsuru/types/app"
 	authTypes "github.com/tsuru/tsuru/types/auth"
@@ -593,6 +594,103 @@ func (s *S) TestDeployAppIncrementDeployNumber(c *check.C) {
 	c.Assert(updatedApp.Deploys, check.Equals, uint(1))
 }
 
+func (s *S) TestDeployAppWaitsApproval(c *check.C) {
+	err := pool.PoolUpdate(context.TODO(), s.Pool, pool.UpdatePoolOptions{
+		Labels: map[string]string{"deploy-approval": "true"},
+	})
+	c.Assert(err, check.IsNil)
+	a := App{
+		Name:      "otherapp",
+		Platform:  "zend",
+		Teams:     []string{s.team.Name},
+		TeamOwner: s.team.Name,
+		Router:    "fake",
+	}
+	err = CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	writer := &bytes.Buffer{}
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: "app", Value: a.Name},
+		Kind:     permission.PermAppDeploy,
+		RawOwner: eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	approver := eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: "approver@tsuru.io"}
+	go func() {
+		for {
+			pending, _ := event.GetByID(context.TODO(), evt.UniqueID)
+			if pending != nil && pending.Approval != nil {
+				pending.Approve(context.TODO(), approver, "")
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+	_, err = Deploy(context.TODO(), DeployOptions{
+		App:          &a,
+		Image:        "myimage",
+		OutputStream: writer,
+		Event:        evt,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.Approval.Status, check.Equals, eventTypes.ApprovalApproved)
+	c.Assert(writer.String(), check.Matches, `(?s).*Deploy approved by approver@tsuru.io.*`)
+}
+
+func (s *S) TestDeployAppApprovalExpired(c *check.C) {
+	err := pool.PoolUpdate(context.TODO(), s.Pool, pool.UpdatePoolOptions{
+		Labels: map[string]string{"deploy-approval": "true", "deploy-approval-timeout": "50ms"},
+	})
+	c.Assert(err, check.IsNil)
+	a := App{
+		Name:      "otherapp",
+		Platform:  "zend",
+		Teams:     []string{s.team.Name},
+		TeamOwner: s.team.Name,
+		Router:    "fake",
+	}
+	err = CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: "app", Value: a.Name},
+		Kind:     permission.PermAppDeploy,
+		RawOwner: eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	_, err = Deploy(context.TODO(), DeployOptions{
+		App:          &a,
+		Image:        "myimage",
+		OutputStream: &bytes.Buffer{},
+		Event:        evt,
+	})
+	c.Assert(err, check.ErrorMatches, "deploy not approved: approval request expired without a decision")
+	c.Assert(a.Deploys, check.Equals, uint(0))
+}
+
+func (s *S) TestWaitDeployApprovalSkipsInternalRollbacks(c *check.C) {
+	err := pool.PoolUpdate(context.TODO(), s.Pool, pool.UpdatePoolOptions{
+		Labels: map[string]string{"deploy-approval": "true"},
+	})
+	c.Assert(err, check.IsNil)
+	a := App{Name: "otherapp", Pool: s.Pool}
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: "app", Value: a.Name},
+		Kind:     permission.PermAppDeploy,
+		RawOwner: eventTypes.Owner{Type: eventTypes.OwnerTypeInternal, Name: verificationOwner},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	err = waitDeployApproval(context.TODO(), &DeployOptions{
+		App:   &a,
+		Kind:  provisionTypes.DeployRollback,
+		Event: evt,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.Approval, check.IsNil)
+}
+
 func (s *S) TestDeployAppSaveDeployData(c *check.C) {
 	appsCollection, err := storagev2.AppsCollection()
 	c.Assert(err, check.IsNil)
@@ -0,0 +1,633 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/tsuru/tsuru/app/bind"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/service"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	volumeTypes "github.com/tsuru/tsuru/types/volume"
+)
+
+type ApplyManifestArgs struct {
+	Manifest  appTypes.Manifest
+	DryRun    bool
+	Writer    io.Writer
+	Event     *event.Event
+	RequestID string
+}
+
+type manifestStep struct {
+	change  appTypes.ManifestChange
+	restart bool
+	run     func(ctx context.Context) error
+}
+
+// ExportManifest returns the current state of the app as a manifest.
+func ExportManifest(ctx context.Context, app *App) (*appTypes.Manifest, error) {
+	m := &appTypes.Manifest{
+		Name:        app.Name,
+		Description: app.Description,
+		Platform:    app.platformWithVersion(),
+		Pool:        app.Pool,
+		TeamOwner:   app.TeamOwner,
+		Plan:        app.Plan.Name,
+		Tags:        app.Tags,
+		Metadata:    app.Metadata,
+		Processes:   app.Processes,
+		Env:         app.manifestEnvs(),
+		CNames:      app.CName,
+	}
+	for _, r := range app.GetRouters() {
+		m.Routers = append(m.Routers, appTypes.ManifestRouter{Name: r.Name, Opts: r.Opts})
+	}
+	autoscale, err := app.AutoScaleInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m.Autoscale = autoscale
+	m.Units, err = app.manifestUnits(ctx, autoscale)
+	if err != nil {
+		return nil, err
+	}
+	instances, err := service.GetServiceInstancesBoundToApp(ctx, app.Name)
+	if err != nil {
+		return nil, err
+	}
+	for _, si := range instances {
+		m.ServiceBinds = append(m.ServiceBinds, appTypes.ManifestServiceBind{Service: si.ServiceName, Instance: si.Name})
+	}
+	volumeBinds, err := servicemanager.Volume.BindsForApp(ctx, nil, app.Name)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range volumeBinds {
+		m.VolumeBinds = append(m.VolumeBinds, appTypes.ManifestVolumeBind{Volume: b.ID.Volume, MountPoint: b.ID.MountPoint, ReadOnly: b.ReadOnly})
+	}
+	sort.Slice(m.ServiceBinds, func(i, j int) bool {
+		return serviceBindKey(m.ServiceBinds[i]) < serviceBindKey(m.ServiceBinds[j])
+	})
+	sort.Slice(m.VolumeBinds, func(i, j int) bool {
+		return volumeBindKey(m.VolumeBinds[i]) < volumeBindKey(m.VolumeBinds[j])
+	})
+	return m, nil
+}
+
+// ApplyManifest converges the app to the state described in the manifest,
+// returning the list of changes needed. When DryRun is set the changes are
+// only planned. Empty scalar fields in the manifest are left unchanged.
+func (app *App) ApplyManifest(ctx context.Context, args ApplyManifestArgs) (*appTypes.ManifestPlan, error) {
+	m := args.Manifest
+	if m.Name != "" && m.Name != app.Name {
+		return nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("manifest name %q doesn't match app %q", m.Name, app.Name)}
+	}
+	current, err := ExportManifest(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	var steps []manifestStep
+	for _, planner := range []func(context.Context, *appTypes.Manifest, *appTypes.Manifest, ApplyManifestArgs) ([]manifestStep, error){
+		app.planAppUpdate,
+		app.planEnvs,
+		app.planCNames,
+		app.planRouters,
+		app.planServiceBinds,
+		app.planVolumeBinds,
+		app.planAutoscale,
+		app.planUnits,
+	} {
+		planned, err := planner(ctx, current, &m, args)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, planned...)
+	}
+	plan := &appTypes.ManifestPlan{App: app.Name, DryRun: args.DryRun, Changes: []appTypes.ManifestChange{}}
+	for _, s := range steps {
+		plan.Changes = append(plan.Changes, s.change)
+	}
+	if args.DryRun {
+		return plan, nil
+	}
+	w := args.Writer
+	if w == nil {
+		w = io.Discard
+	}
+	var restart bool
+	for _, s := range steps {
+		fmt.Fprintf(w, "---- Applying %s %s %s ----\n", s.change.Kind, s.change.Action, s.change.Name)
+		err = s.run(ctx)
+		if err != nil {
+			return plan, err
+		}
+		restart = restart || s.restart
+	}
+	if restart {
+		err = app.restartIfUnits(ctx, w)
+		if err != nil {
+			return plan, err
+		}
+	}
+	return plan, nil
+}
+
+func (app *App) platformWithVersion() string {
+	if version := app.GetPlatformVersion(); app.Platform != "" && version != "latest" {
+		return fmt.Sprintf("%s:%s", app.Platform, version)
+	}
+	return app.Platform
+}
+
+func (app *App) manifestEnvs() map[string]string {
+	envs := map[string]string{}
+	for name, env := range app.Env {
+		if env.Public && env.ManagedBy == "" {
+			envs[name] = env.Value
+		}
+	}
+	return envs
+}
+
+func (app *App) manifestUnits(ctx context.Context, autoscale []provTypes.AutoScaleSpec) (map[string]uint, error) {
+	units, err := app.Units(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]uint{}
+	for _, u := range units {
+		if autoscaleFor(autoscale, u.ProcessName) == nil {
+			result[u.ProcessName]++
+		}
+	}
+	return result, nil
+}
+
+func autoscaleFor(specs []provTypes.AutoScaleSpec, process string) *provTypes.AutoScaleSpec {
+	for i := range specs {
+		if specs[i].Process == process {
+			return &specs[i]
+		}
+	}
+	return nil
+}
+
+func (app *App) planAppUpdate(ctx context.Context, current, desired *appTypes.Manifest, args ApplyManifestArgs) ([]manifestStep, error) {
+	var update App
+	// fields describe the change for humans, changed holds the manifest keys.
+	var fields, changed []string
+	if desired.Description != "" && desired.Description != current.Description {
+		update.Description = desired.Description
+		fields = append(fields, "description")
+		changed = append(changed, "description")
+	}
+	if desired.Platform != "" && desired.Platform != current.Platform {
+		update.Platform = desired.Platform
+		fields = append(fields, "platform")
+		changed = append(changed, "platform")
+	}
+	if desired.Pool != "" && desired.Pool != current.Pool {
+		update.Pool = desired.Pool
+		fields = append(fields, "pool")
+		changed = append(changed, "pool")
+	}
+	if desired.TeamOwner != "" && desired.TeamOwner != current.TeamOwner {
+		update.TeamOwner = desired.TeamOwner
+		fields = append(fields, "team owner")
+		changed = append(changed, "teamOwner")
+	}
+	if desired.Plan != "" && desired.Plan != current.Plan {
+		update.Plan = appTypes.Plan{Name: desired.Plan}
+		fields = append(fields, "plan")
+		changed = append(changed, "plan")
+	}
+	if !sameStringSet(desired.Tags, current.Tags) {
+		update.Tags = append([]string{}, desired.Tags...)
+		fields = append(fields, "tags")
+		changed = append(changed, "tags")
+	}
+	metadata, metadataChanged := metadataDiff(current.Metadata, desired.Metadata)
+	if metadataChanged {
+		update.Metadata = metadata
+		fields = append(fields, "metadata")
+		changed = append(changed, "metadata")
+	}
+	processes := processesDiff(current.Processes, desired.Processes)
+	if len(processes) > 0 {
+		update.Processes = processes
+		fields = append(fields, "processes")
+		changed = append(changed, "processes")
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return []manifestStep{{
+		change: appTypes.ManifestChange{Kind: appTypes.ManifestKindApp, Action: appTypes.ManifestActionUpdate, Name: app.Name, Detail: strings.Join(fields, ", "), Fields: changed},
+		run: func(ctx context.Context) error {
+			return app.Update(ctx, UpdateAppArgs{UpdateData: update, Writer: args.Writer, ShouldRestart: true})
+		},
+	}}, nil
+}
+
+func (app *App) planEnvs(ctx context.Context, current, desired *appTypes.Manifest, args ApplyManifestArgs) ([]manifestStep, error) {
+	var steps []manifestStep
+	for _, name := range sortedKeys(desired.Env) {
+		value := desired.Env[name]
+		currentValue, exists := current.Env[name]
+		if exists && currentValue == value {
+			continue
+		}
+		if env, ok := app.Env[name]; ok && (!env.Public || env.ManagedBy != "") {
+			return nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("environment variable %q is private or managed and can't be set by a manifest", name)}
+		}
+		action := appTypes.ManifestActionAdd
+		if exists {
+			action = appTypes.ManifestActionUpdate
+		}
+		env := bindTypes.EnvVar{Name: name, Value: value, Public: true}
+		steps = append(steps, manifestStep{
+			change:  appTypes.ManifestChange{Kind: appTypes.ManifestKindEnv, Action: action, Name: name},
+			restart: true,
+			run: func(ctx context.Context) error {
+				return app.SetEnvs(ctx, bind.SetEnvArgs{Envs: []bindTypes.EnvVar{env}, Writer: args.Writer})
+			},
+		})
+	}
+	for _, name := range sortedKeys(current.Env) {
+		if _, ok := desired.Env[name]; ok {
+			continue
+		}
+		steps = append(steps, manifestStep{
+			change:  appTypes.ManifestChange{Kind: appTypes.ManifestKindEnv, Action: appTypes.ManifestActionRemove, Name: name},
+			restart: true,
+			run: func(ctx context.Context) error {
+				return app.UnsetEnvs(ctx, bind.UnsetEnvArgs{VariableNames: []string{name}, Writer: args.Writer})
+			},
+		})
+	}
+	return steps, nil
+}
+
+func (app *App) planCNames(ctx context.Context, current, desired *appTypes.Manifest, args ApplyManifestArgs) ([]manifestStep, error) {
+	var steps []manifestStep
+	for _, cname := range desired.CNames {
+		if cnameInSet(cname, current.CNames) {
+			continue
+		}
+		steps = append(steps, manifestStep{
+			change: appTypes.ManifestChange{Kind: appTypes.ManifestKindCName, Action: appTypes.ManifestActionAdd, Name: cname},
+			run: func(ctx context.Context) error {
+				return app.AddCName(ctx, cname)
+			},
+		})
+	}
+	for _, cname := range current.CNames {
+		if cnameInSet(cname, desired.CNames) {
+			continue
+		}
+		steps = append(steps, manifestStep{
+			change: appTypes.ManifestChange{Kind: appTypes.ManifestKindCName, Action: appTypes.ManifestActionRemove, Name: cname},
+			run: func(ctx context.Context) error {
+				return app.RemoveCName(ctx, cname)
+			},
+		})
+	}
+	return steps, nil
+}
+
+func (app *App) planRouters(ctx context.Context, current, desired *appTypes.Manifest, args ApplyManifestArgs) ([]manifestStep, error) {
+	var steps []manifestStep
+	currentRouters := map[string]appTypes.ManifestRouter{}
+	for _, r := range current.Routers {
+		currentRouters[r.Name] = r
+	}
+	desiredRouters := map[string]bool{}
+	for _, r := range desired.Routers {
+		desiredRouters[r.Name] = true
+		appRouter := appTypes.AppRouter{Name: r.Name, Opts: r.Opts}
+		existing, ok := currentRouters[r.Name]
+		switch {
+		case !ok:
+			steps = append(steps, manifestStep{
+				change: appTypes.ManifestChange{Kind: appTypes.ManifestKindRouter, Action: appTypes.ManifestActionAdd, Name: r.Name},
+				run: func(ctx context.Context) error {
+					return app.AddRouter(ctx, appRouter)
+				},
+			})
+		case !sameStringMap(existing.Opts, r.Opts):
+			steps = append(steps, manifestStep{
+				change: appTypes.ManifestChange{Kind: appTypes.ManifestKindRouter, Action: appTypes.ManifestActionUpdate, Name: r.Name},
+				run: func(ctx context.Context) error {
+					return app.UpdateRouter(ctx, appRouter)
+				},
+			})
+		}
+	}
+	for _, r := range current.Routers {
+		if desiredRouters[r.Name] {
+			continue
+		}
+		name := r.Name
+		steps = append(steps, manifestStep{
+			change: appTypes.ManifestChange{Kind: appTypes.ManifestKindRouter, Action: appTypes.ManifestActionRemove, Name: name},
+			run: func(ctx context.Context) error {
+				return app.RemoveRouter(ctx, name)
+			},
+		})
+	}
+	return steps, nil
+}
+
+func serviceBindKey(b appTypes.ManifestServiceBind) string {
+	return b.Service + "/" + b.Instance
+}
+
+func (app *App) planServiceBinds(ctx context.Context, current, desired *appTypes.Manifest, args ApplyManifestArgs) ([]manifestStep, error) {
+	var steps []manifestStep
+	currentBinds := map[string]bool{}
+	for _, b := range current.ServiceBinds {
+		currentBinds[serviceBindKey(b)] = true
+	}
+	desiredBinds := map[string]bool{}
+	for _, b := range desired.ServiceBinds {
+		key := serviceBindKey(b)
+		desiredBinds[key] = true
+		if currentBinds[key] {
+			continue
+		}
+		instance, err := service.GetServiceInstance(ctx, b.Service, b.Instance)
+		if err != nil {
+			return nil, err
+		}
+		err = app.ValidateService(ctx, b.Service)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, manifestStep{
+			change:  appTypes.ManifestChange{Kind: appTypes.ManifestKindServiceBind, Action: appTypes.ManifestActionAdd, Name: key},
+			restart: true,
+			run: func(ctx context.Context) error {
+				return instance.BindApp(ctx, app, nil, false, args.Writer, args.Event, args.RequestID)
+			},
+		})
+	}
+	for _, b := range current.ServiceBinds {
+		key := serviceBindKey(b)
+		if desiredBinds[key] {
+			continue
+		}
+		instance, err := service.GetServiceInstance(ctx, b.Service, b.Instance)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, manifestStep{
+			change:  appTypes.ManifestChange{Kind: appTypes.ManifestKindServiceBind, Action: appTypes.ManifestActionRemove, Name: key},
+			restart: true,
+			run: func(ctx context.Context) error {
+				return instance.UnbindApp(ctx, service.UnbindAppArgs{
+					App:       app,
+					Event:     args.Event,
+					RequestID: args.RequestID,
+				})
+			},
+		})
+	}
+	return steps, nil
+}
+
+func volumeBindKey(b appTypes.ManifestVolumeBind) string {
+	return b.Volume + ":" + b.MountPoint
+}
+
+func (app *App) planVolumeBinds(ctx context.Context, current, desired *appTypes.Manifest, args ApplyManifestArgs) ([]manifestStep, error) {
+	var steps []manifestStep
+	currentBinds := map[string]appTypes.ManifestVolumeBind{}
+	for _, b := range current.VolumeBinds {
+		currentBinds[volumeBindKey(b)] = b
+	}
+	desiredBinds := map[string]bool{}
+	for _, b := range desired.VolumeBinds {
+		key := volumeBindKey(b)
+		desiredBinds[key] = true
+		existing, ok := currentBinds[key]
+		if ok && existing.ReadOnly == b.ReadOnly {
+			continue
+		}
+		v, err := servicemanager.Volume.Get(ctx, b.Volume)
+		if err != nil {
+			return nil, err
+		}
+		opts := &volumeTypes.BindOpts{Volume: v, AppName: app.Name, MountPoint: b.MountPoint, ReadOnly: b.ReadOnly}
+		action := appTypes.ManifestActionAdd
+		if ok {
+			action = appTypes.ManifestActionUpdate
+		}
+		steps = append(steps, manifestStep{
+			change:  appTypes.ManifestChange{Kind: appTypes.ManifestKindVolumeBind, Action: action, Name: key},
+			restart: true,
+			run: func(ctx context.Context) error {
+				if action == appTypes.ManifestActionUpdate {
+					if err := servicemanager.Volume.UnbindApp(ctx, opts); err != nil {
+						return err
+					}
+				}
+				return servicemanager.Volume.BindApp(ctx, opts)
+			},
+		})
+	}
+	for _, b := range current.VolumeBinds {
+		key := volumeBindKey(b)
+		if desiredBinds[key] {
+			continue
+		}
+		v, err := servicemanager.Volume.Get(ctx, b.Volume)
+		if err != nil {
+			return nil, err
+		}
+		opts := &volumeTypes.BindOpts{Volume: v, AppName: app.Name, MountPoint: b.MountPoint}
+		steps = append(steps, manifestStep{
+			change:  appTypes.ManifestChange{Kind: appTypes.ManifestKindVolumeBind, Action: appTypes.ManifestActionRemove, Name: key},
+			restart: true,
+			run: func(ctx context.Context) error {
+				return servicemanager.Volume.UnbindApp(ctx, opts)
+			},
+		})
+	}
+	return steps, nil
+}
+
+func (app *App) planAutoscale(ctx context.Context, current, desired *appTypes.Manifest, args ApplyManifestArgs) ([]manifestStep, error) {
+	var steps []manifestStep
+	for _, spec := range desired.Autoscale {
+		existing := autoscaleFor(current.Autoscale, spec.Process)
+		action := appTypes.ManifestActionAdd
+		if existing != nil {
+			if spec.Version == 0 {
+				spec.Version = existing.Version
+			}
+			if reflect.DeepEqual(*existing, spec) {
+				continue
+			}
+			action = appTypes.ManifestActionUpdate
+		}
+		steps = append(steps, manifestStep{
+			change: appTypes.ManifestChange{Kind: appTypes.ManifestKindAutoscale, Action: action, Name: spec.Process},
+			run: func(ctx context.Context) error {
+				return app.AutoScale(ctx, spec)
+			},
+		})
+	}
+	for _, spec := range current.Autoscale {
+		if autoscaleFor(desired.Autoscale, spec.Process) != nil {
+			continue
+		}
+		process := spec.Process
+		steps = append(steps, manifestStep{
+			change: appTypes.ManifestChange{Kind: appTypes.ManifestKindAutoscale, Action: appTypes.ManifestActionRemove, Name: process},
+			run: func(ctx context.Context) error {
+				return app.RemoveAutoScale(ctx, process)
+			},
+		})
+	}
+	return steps, nil
+}
+
+func (app *App) planUnits(ctx context.Context, current, desired *appTypes.Manifest, args ApplyManifestArgs) ([]manifestStep, error) {
+	var steps []manifestStep
+	for _, process := range sortedKeys(desired.Units) {
+		if autoscaleFor(desired.Autoscale, process) != nil {
+			return nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("units for process %q can't be set while it has autoscale", process)}
+		}
+		want, have := desired.Units[process], current.Units[process]
+		if want == have {
+			continue
+		}
+		action := appTypes.ManifestActionAdd
+		if want < have {
+			action = appTypes.ManifestActionRemove
+		}
+		change := appTypes.ManifestChange{Kind: appTypes.ManifestKindUnits, Action: action, Name: process, Detail: fmt.Sprintf("%d -> %d", have, want)}
+		steps = append(steps, manifestStep{
+			change: change,
+			run: func(ctx context.Context) error {
+				if want > have {
+					return app.AddUnits(ctx, want-have, process, "", args.Writer)
+				}
+				return app.RemoveUnits(ctx, have-want, process, "", args.Writer)
+			},
+		})
+	}
+	return steps, nil
+}
+
+// metadataDiff returns the metadata update needed to turn current into
+// desired, items missing from desired are marked for removal.
+func metadataDiff(current, desired appTypes.Metadata) (appTypes.Metadata, bool) {
+	labels, labelsChanged := metadataItemsDiff(current.Labels, desired.Labels)
+	annotations, annotationsChanged := metadataItemsDiff(current.Annotations, desired.Annotations)
+	return appTypes.Metadata{Labels: labels, Annotations: annotations}, labelsChanged || annotationsChanged
+}
+
+func metadataItemsDiff(current, desired []appTypes.MetadataItem) ([]appTypes.MetadataItem, bool) {
+	currentValues := map[string]string{}
+	for _, item := range current {
+		currentValues[item.Name] = item.Value
+	}
+	var result []appTypes.MetadataItem
+	changed := false
+	desiredNames := map[string]bool{}
+	for _, item := range desired {
+		desiredNames[item.Name] = true
+		result = append(result, appTypes.MetadataItem{Name: item.Name, Value: item.Value})
+		if value, ok := currentValues[item.Name]; !ok || value != item.Value {
+			changed = true
+		}
+	}
+	for _, item := range current {
+		if !desiredNames[item.Name] {
+			result = append(result, appTypes.MetadataItem{Name: item.Name, Delete: true})
+			changed = true
+		}
+	}
+	return result, changed
+}
+
+func processesDiff(current, desired []appTypes.Process) []appTypes.Process {
+	currentByName := map[string]appTypes.Process{}
+	for _, p := range current {
+		currentByName[p.Name] = p
+	}
+	var result []appTypes.Process
+	desiredNames := map[string]bool{}
+	for _, p := range desired {
+		desiredNames[p.Name] = true
+		existing := currentByName[p.Name]
+		metadata, changed := metadataDiff(existing.Metadata, p.Metadata)
+		plan := p.Plan
+		if plan == "" && existing.Plan != "" {
+			plan = "$default"
+		}
+		if !changed && p.Plan == existing.Plan {
+			continue
+		}
+		result = append(result, appTypes.Process{Name: p.Name, Plan: plan, Metadata: metadata})
+	}
+	for _, p := range current {
+		if desiredNames[p.Name] {
+			continue
+		}
+		metadata, _ := metadataDiff(p.Metadata, appTypes.Metadata{})
+		result = append(result, appTypes.Process{Name: p.Name, Plan: "$default", Metadata: metadata})
+	}
+	return result
+}
+
+func sameStringSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := map[string]bool{}
+	for _, v := range a {
+		set[v] = true
+	}
+	for _, v := range b {
+		if !set[v] {
+			return false
+		}
+	}
+	return true
+}
+
+func sameStringMap(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,178 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"bytes"
+	"context"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestExportManifest(c *check.C) {
+	app := &App{
+		Name:        "ktulu",
+		Platform:    "python",
+		TeamOwner:   s.team.Name,
+		Description: "my app",
+		Tags:        []string{"a", "b"},
+		Env: map[string]bindTypes.EnvVar{
+			"PUBLIC":  {Name: "PUBLIC", Value: "1", Public: true},
+			"PRIVATE": {Name: "PRIVATE", Value: "secret"},
+			"MANAGED": {Name: "MANAGED", Value: "2", Public: true, ManagedBy: "terraform"},
+		},
+	}
+	err := CreateApp(context.TODO(), app, s.user)
+	c.Assert(err, check.IsNil)
+	err = app.AddCName(context.TODO(), "ktulu.mycompany.com")
+	c.Assert(err, check.IsNil)
+	manifest, err := ExportManifest(context.TODO(), app)
+	c.Assert(err, check.IsNil)
+	c.Assert(manifest.Name, check.Equals, "ktulu")
+	c.Assert(manifest.Description, check.Equals, "my app")
+	c.Assert(manifest.Platform, check.Equals, "python")
+	c.Assert(manifest.TeamOwner, check.Equals, s.team.Name)
+	c.Assert(manifest.Tags, check.DeepEquals, []string{"a", "b"})
+	c.Assert(manifest.Env, check.DeepEquals, map[string]string{"PUBLIC": "1"})
+	c.Assert(manifest.CNames, check.DeepEquals, []string{"ktulu.mycompany.com"})
+	c.Assert(manifest.Routers, check.DeepEquals, []appTypes.ManifestRouter{{Name: "fake"}})
+	c.Assert(manifest.ServiceBinds, check.HasLen, 0)
+	c.Assert(manifest.VolumeBinds, check.HasLen, 0)
+}
+
+func (s *S) TestApplyManifestDryRun(c *check.C) {
+	app := &App{Name: "ktulu", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), app, s.user)
+	c.Assert(err, check.IsNil)
+	manifest, err := ExportManifest(context.TODO(), app)
+	c.Assert(err, check.IsNil)
+	manifest.Tags = []string{"tag1"}
+	manifest.Env = map[string]string{"FOO": "bar"}
+	manifest.CNames = []string{"ktulu.mycompany.com"}
+	plan, err := app.ApplyManifest(context.TODO(), ApplyManifestArgs{Manifest: *manifest, DryRun: true})
+	c.Assert(err, check.IsNil)
+	c.Assert(plan, check.DeepEquals, &appTypes.ManifestPlan{
+		App:    "ktulu",
+		DryRun: true,
+		Changes: []appTypes.ManifestChange{
+			{Kind: appTypes.ManifestKindApp, Action: appTypes.ManifestActionUpdate, Name: "ktulu", Detail: "tags", Fields: []string{"tags"}},
+			{Kind: appTypes.ManifestKindEnv, Action: appTypes.ManifestActionAdd, Name: "FOO"},
+			{Kind: appTypes.ManifestKindCName, Action: appTypes.ManifestActionAdd, Name: "ktulu.mycompany.com"},
+		},
+	})
+	dbApp, err := GetByName(context.TODO(), app.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Tags, check.HasLen, 0)
+	c.Assert(dbApp.Env, check.HasLen, 0)
+	c.Assert(dbApp.CName, check.HasLen, 0)
+}
+
+func (s *S) TestApplyManifestNoChanges(c *check.C) {
+	app := &App{Name: "ktulu", Platform: "python", TeamOwner: s.team.Name, Tags: []string{"a"}}
+	err := CreateApp(context.TODO(), app, s.user)
+	c.Assert(err, check.IsNil)
+	manifest, err := ExportManifest(context.TODO(), app)
+	c.Assert(err, check.IsNil)
+	plan, err := app.ApplyManifest(context.TODO(), ApplyManifestArgs{Manifest: *manifest})
+	c.Assert(err, check.IsNil)
+	c.Assert(plan.Changes, check.HasLen, 0)
+}
+
+func (s *S) TestApplyManifest(c *check.C) {
+	app := &App{
+		Name:      "ktulu",
+		Platform:  "python",
+		TeamOwner: s.team.Name,
+		CName:     []string{"old.mycompany.com"},
+		Env: map[string]bindTypes.EnvVar{
+			"OLD":     {Name: "OLD", Value: "1", Public: true},
+			"CHANGED": {Name: "CHANGED", Value: "1", Public: true},
+			"PRIVATE": {Name: "PRIVATE", Value: "secret"},
+		},
+	}
+	err := CreateApp(context.TODO(), app, s.user)
+	c.Assert(err, check.IsNil)
+	manifest, err := ExportManifest(context.TODO(), app)
+	c.Assert(err, check.IsNil)
+	manifest.Description = "new description"
+	manifest.Env = map[string]string{"CHANGED": "2", "NEW": "3"}
+	manifest.CNames = []string{"new.mycompany.com"}
+	var buf bytes.Buffer
+	plan, err := app.ApplyManifest(context.TODO(), ApplyManifestArgs{Manifest: *manifest, Writer: &buf})
+	c.Assert(err, check.IsNil)
+	c.Assert(plan.DryRun, check.Equals, false)
+	c.Assert(plan.Changes, check.HasLen, 6)
+	c.Assert(buf.String(), check.Matches, `(?s).*---- Applying env remove OLD ----.*`)
+	dbApp, err := GetByName(context.TODO(), app.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Description, check.Equals, "new description")
+	c.Assert(dbApp.CName, check.DeepEquals, []string{"new.mycompany.com"})
+	c.Assert(dbApp.Env, check.DeepEquals, map[string]bindTypes.EnvVar{
+		"CHANGED": {Name: "CHANGED", Value: "2", Public: true},
+		"NEW":     {Name: "NEW", Value: "3", Public: true},
+		"PRIVATE": {Name: "PRIVATE", Value: "secret"},
+	})
+}
+
+func (s *S) TestApplyManifestNameMismatch(c *check.C) {
+	app := &App{Name: "ktulu", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), app, s.user)
+	c.Assert(err, check.IsNil)
+	_, err = app.ApplyManifest(context.TODO(), ApplyManifestArgs{Manifest: appTypes.Manifest{Name: "other"}})
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	c.Assert(err, check.ErrorMatches, `manifest name "other" doesn't match app "ktulu"`)
+}
+
+func (s *S) TestApplyManifestPrivateEnv(c *check.C) {
+	app := &App{
+		Name:      "ktulu",
+		Platform:  "python",
+		TeamOwner: s.team.Name,
+		Env: map[string]bindTypes.EnvVar{
+			"PRIVATE": {Name: "PRIVATE", Value: "secret"},
+		},
+	}
+	err := CreateApp(context.TODO(), app, s.user)
+	c.Assert(err, check.IsNil)
+	manifest := appTypes.Manifest{Env: map[string]string{"PRIVATE": "other"}}
+	_, err = app.ApplyManifest(context.TODO(), ApplyManifestArgs{Manifest: manifest, DryRun: true})
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+}
+
+func (s *S) TestMetadataDiff(c *check.C) {
+	current := appTypes.Metadata{
+		Labels: []appTypes.MetadataItem{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}},
+	}
+	_, changed := metadataDiff(current, current)
+	c.Assert(changed, check.Equals, false)
+	desired := appTypes.Metadata{
+		Labels:      []appTypes.MetadataItem{{Name: "a", Value: "10"}},
+		Annotations: []appTypes.MetadataItem{{Name: "c", Value: "3"}},
+	}
+	diff, changed := metadataDiff(current, desired)
+	c.Assert(changed, check.Equals, true)
+	c.Assert(diff, check.DeepEquals, appTypes.Metadata{
+		Labels:      []appTypes.MetadataItem{{Name: "a", Value: "10"}, {Name: "b", Delete: true}},
+		Annotations: []appTypes.MetadataItem{{Name: "c", Value: "3"}},
+	})
+}
+
+func (s *S) TestProcessesDiff(c *check.C) {
+	current := []appTypes.Process{
+		{Name: "web", Plan: "small"},
+		{Name: "worker", Plan: "large"},
+	}
+	c.Assert(processesDiff(current, current), check.HasLen, 0)
+	desired := []appTypes.Process{
+		{Name: "web", Plan: "medium"},
+	}
+	c.Assert(processesDiff(current, desired), check.DeepEquals, []appTypes.Process{
+		{Name: "web", Plan: "medium"},
+		{Name: "worker", Plan: "$default"},
+	})
+}
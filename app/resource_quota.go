@@ -0,0 +1,203 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/quota"
+	"github.com/tsuru/tsuru/servicemanager"
+	"github.com/tsuru/tsuru/storage"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	quotaTypes "github.com/tsuru/tsuru/types/quota"
+	volumeTypes "github.com/tsuru/tsuru/types/volume"
+)
+
+func ResourceQuotaService() (quotaTypes.ResourceQuotaService, error) {
+	dbDriver, err := storage.GetCurrentDbDriver()
+	if err != nil {
+		dbDriver, err = storage.GetDefaultDbDriver()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &quota.ResourceQuotaService{
+		Storage: dbDriver.ResourceQuotaStorage,
+		Usage:   TeamResourceUsage,
+	}, nil
+}
+
+// TeamResourceUsage returns the capacity reserved by apps and volumes owned by
+// team, restricted to pool when it's not empty. Units of processes with
+// autoscale are counted by their maximum number of units.
+func TeamResourceUsage(ctx context.Context, team, pool string) (quotaTypes.ResourceList, error) {
+	var usage quotaTypes.ResourceList
+	apps, err := List(ctx, &Filter{TeamOwner: team, Pool: pool})
+	if err != nil {
+		return usage, err
+	}
+	for i := range apps {
+		counts, err := apps[i].processUnitCounts(ctx)
+		if err != nil {
+			return usage, err
+		}
+		appUsage, err := apps[i].resourceUsage(ctx, counts)
+		if err != nil {
+			return usage, err
+		}
+		usage = usage.Add(appUsage)
+	}
+	volumes, err := servicemanager.Volume.ListByFilter(ctx, &volumeTypes.Filter{Teams: []string{team}})
+	if err != nil {
+		return usage, err
+	}
+	for _, v := range volumes {
+		if v.TeamOwner != team || (pool != "" && v.Pool != pool) {
+			continue
+		}
+		capacity, err := v.Capacity()
+		if err != nil {
+			return usage, err
+		}
+		usage.Storage += capacity
+	}
+	return usage, nil
+}
+
+// processUnitCounts returns the number of units reserved by each process of
+// the app, which are the units running in the provisioner or, for processes
+// with autoscale, the maximum number of units when it's greater.
+func (app *App) processUnitCounts(ctx context.Context) (map[string]int, error) {
+	units, err := app.Units(ctx)
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int{}
+	for _, u := range units {
+		counts[u.ProcessName]++
+	}
+	prov, err := app.getProvisioner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	autoscaleProv, ok := prov.(provision.AutoScaleProvisioner)
+	if !ok {
+		return counts, nil
+	}
+	autoscales, err := autoscaleProv.GetAutoScale(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	for _, as := range autoscales {
+		if int(as.MaxUnits) > counts[as.Process] {
+			counts[as.Process] = int(as.MaxUnits)
+		}
+	}
+	return counts, nil
+}
+
+func (app *App) resourceUsage(ctx context.Context, counts map[string]int) (quotaTypes.ResourceList, error) {
+	var usage quotaTypes.ResourceList
+	for process, count := range counts {
+		plan, err := app.planForProcess(ctx, process)
+		if err != nil {
+			return usage, err
+		}
+		usage = usage.Add(resourcesForUnits(plan, count))
+	}
+	return usage, nil
+}
+
+func (app *App) planForProcess(ctx context.Context, process string) (appTypes.Plan, error) {
+	p := app.GetProcess(process)
+	if p == nil || p.Plan == "" {
+		return app.Plan, nil
+	}
+	plan, err := servicemanager.Plan.FindByName(ctx, p.Plan)
+	if err != nil {
+		return appTypes.Plan{}, errors.WithMessagef(err, "could not find plan %q", p.Plan)
+	}
+	return *plan, nil
+}
+
+func resourcesForUnits(plan appTypes.Plan, units int) quotaTypes.ResourceList {
+	return quotaTypes.ResourceList{
+		MilliCPU: int64(plan.GetMilliCPU()) * int64(units),
+		Memory:   plan.GetMemory() * int64(units),
+	}
+}
+
+func (app *App) checkResourceQuota(ctx context.Context, requested quotaTypes.ResourceList) error {
+	if servicemanager.ResourceQuota == nil {
+		return nil
+	}
+	return servicemanager.ResourceQuota.Check(ctx, app.TeamOwner, app.Pool, requested)
+}
+
+// checkDeployResourceQuota ensures the units started by deploying version fit
+// the team quotas. Processes without units are started with one unit, as are
+// all the processes of versions deployed alongside the current ones.
+func (app *App) checkDeployResourceQuota(ctx context.Context, version appTypes.AppVersion, newVersion bool) error {
+	if servicemanager.ResourceQuota == nil {
+		return nil
+	}
+	processes, err := version.Processes()
+	if err != nil {
+		return err
+	}
+	counts, err := app.processUnitCounts(ctx)
+	if err != nil {
+		return err
+	}
+	var requested quotaTypes.ResourceList
+	for process := range processes {
+		if !newVersion && counts[process] > 0 {
+			continue
+		}
+		plan, err := app.planForProcess(ctx, process)
+		if err != nil {
+			return err
+		}
+		requested = requested.Add(resourcesForUnits(plan, 1))
+	}
+	if requested == (quotaTypes.ResourceList{}) {
+		return nil
+	}
+	return app.checkResourceQuota(ctx, requested)
+}
+
+// checkUpdateResourceQuota ensures the capacity reserved by the app after an
+// update fits the team quotas. Changing the plan, either in the app or in its
+// processes, requests the difference to the old plan, while moving the app to
+// another team or pool requests its whole capacity in the new one.
+func (app *App) checkUpdateResourceQuota(ctx context.Context, oldApp *App, planChanged bool) error {
+	counts, err := app.processUnitCounts(ctx)
+	if err != nil {
+		return err
+	}
+	newUsage, err := app.resourceUsage(ctx, counts)
+	if err != nil {
+		return err
+	}
+	if app.TeamOwner != oldApp.TeamOwner {
+		return app.checkResourceQuota(ctx, newUsage)
+	}
+	if planChanged {
+		oldUsage, err := oldApp.resourceUsage(ctx, counts)
+		if err != nil {
+			return err
+		}
+		err = app.checkResourceQuota(ctx, newUsage.Sub(oldUsage))
+		if err != nil {
+			return err
+		}
+	}
+	if app.Pool != oldApp.Pool && servicemanager.ResourceQuota != nil {
+		return servicemanager.ResourceQuota.CheckPool(ctx, app.TeamOwner, app.Pool, newUsage)
+	}
+	return nil
+}
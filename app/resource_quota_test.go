@@ -0,0 +1,228 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/builder"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	"github.com/tsuru/tsuru/types/quota"
+	volumeTypes "github.com/tsuru/tsuru/types/volume"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestTeamResourceUsage(c *check.C) {
+	s.plan = appTypes.Plan{Name: "big", Memory: 4096, CPUMilli: 1000}
+	a1 := App{Name: "app1", TeamOwner: s.team.Name, Plan: appTypes.Plan{Name: "default-plan"}}
+	err := CreateApp(context.TODO(), &a1, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a1)
+	err = a1.AddUnits(context.TODO(), 2, "web", "", nil)
+	c.Assert(err, check.IsNil)
+	err = a1.AddUnits(context.TODO(), 1, "worker", "", nil)
+	c.Assert(err, check.IsNil)
+	updateData := App{Processes: []appTypes.Process{{Name: "worker", Plan: "big"}}}
+	err = a1.Update(context.TODO(), UpdateAppArgs{UpdateData: updateData, Writer: new(bytes.Buffer)})
+	c.Assert(err, check.IsNil)
+	config.Set("volume-plans:nfs:fake:plugin", "nfs")
+	defer config.Unset("volume-plans")
+	v1 := volumeTypes.Volume{Name: "v1", Pool: s.Pool, TeamOwner: s.team.Name, Plan: volumeTypes.VolumePlan{Name: "nfs"}, Opts: map[string]string{"capacity": "1Gi"}}
+	err = servicemanager.Volume.Create(context.TODO(), &v1)
+	c.Assert(err, check.IsNil)
+	usage, err := TeamResourceUsage(context.TODO(), s.team.Name, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(usage, check.DeepEquals, quota.ResourceList{
+		MilliCPU: 1000,
+		Memory:   2*1024 + 4096,
+		Storage:  1024 * 1024 * 1024,
+	})
+	usage, err = TeamResourceUsage(context.TODO(), s.team.Name, "otherpool")
+	c.Assert(err, check.IsNil)
+	c.Assert(usage, check.DeepEquals, quota.ResourceList{})
+}
+
+func (s *S) TestAddUnitsResourceQuotaExceeded(c *check.C) {
+	a := App{Name: "warpaint", Platform: "python", Quota: quota.UnlimitedQuota, TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a)
+	s.mockService.ResourceQuota.OnCheck = func(team, pool string, requested quota.ResourceList) error {
+		c.Assert(team, check.Equals, s.team.Name)
+		c.Assert(pool, check.Equals, s.Pool)
+		c.Assert(requested, check.DeepEquals, quota.ResourceList{Memory: 5 * 1024})
+		return &quota.QuotaExceededError{Team: team, Resources: []quota.ResourceExceeded{
+			{Resource: quota.ResourceMemory, Limit: 4096, InUse: 0, Requested: requested.Memory},
+		}}
+	}
+	err = a.AddUnits(context.TODO(), 5, "web", "", nil)
+	c.Assert(err, check.FitsTypeOf, &quota.QuotaExceededError{})
+	units, err := a.Units(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(units, check.HasLen, 0)
+}
+
+func (s *S) TestAutoScaleResourceQuota(c *check.C) {
+	oldProvisioner := provision.DefaultProvisioner
+	defer func() { provision.DefaultProvisioner = oldProvisioner }()
+	provision.DefaultProvisioner = "autoscaleProv"
+	autoScaleProv := &provisiontest.AutoScaleProvisioner{FakeProvisioner: provisiontest.ProvisionerInstance}
+	provision.Register("autoscaleProv", func() (provision.Provisioner, error) {
+		return autoScaleProv, nil
+	})
+	defer provision.Unregister("autoscaleProv")
+	a := App{Name: "my-test-app", TeamOwner: s.team.Name, Plan: appTypes.Plan{Memory: 1024, CPUMilli: 100}}
+	err := s.provisioner.Provision(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	err = s.provisioner.AddUnits(context.TODO(), &a, 2, "web", nil, nil)
+	c.Assert(err, check.IsNil)
+	var requests []quota.ResourceList
+	s.mockService.ResourceQuota.OnCheck = func(team, pool string, requested quota.ResourceList) error {
+		requests = append(requests, requested)
+		if requested.MilliCPU > 500 {
+			return &quota.QuotaExceededError{Team: team}
+		}
+		return nil
+	}
+	err = a.AutoScale(context.TODO(), provTypes.AutoScaleSpec{Process: "web", MaxUnits: 10})
+	c.Assert(err, check.FitsTypeOf, &quota.QuotaExceededError{})
+	err = a.AutoScale(context.TODO(), provTypes.AutoScaleSpec{Process: "web", MaxUnits: 5})
+	c.Assert(err, check.IsNil)
+	c.Assert(requests, check.DeepEquals, []quota.ResourceList{
+		{MilliCPU: 800, Memory: 8 * 1024},
+		{MilliCPU: 300, Memory: 3 * 1024},
+	})
+	scales, err := a.AutoScaleInfo(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(scales, check.DeepEquals, []provTypes.AutoScaleSpec{{Process: "web", MaxUnits: 5}})
+	counts, err := a.processUnitCounts(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(counts, check.DeepEquals, map[string]int{"web": 5})
+	err = a.RemoveAutoScale(context.TODO(), "web")
+	c.Assert(err, check.IsNil)
+	counts, err = a.processUnitCounts(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(counts, check.DeepEquals, map[string]int{"web": 2})
+}
+
+func (s *S) TestUpdatePlanResourceQuotaExceeded(c *check.C) {
+	s.plan = appTypes.Plan{Name: "something", Memory: 4096}
+	a := App{Name: "my-test-app", Routers: []appTypes.AppRouter{{Name: "fake"}}, TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a)
+	err = a.AddUnits(context.TODO(), 3, "web", "", nil)
+	c.Assert(err, check.IsNil)
+	s.mockService.ResourceQuota.OnCheck = func(team, pool string, requested quota.ResourceList) error {
+		c.Assert(requested, check.DeepEquals, quota.ResourceList{Memory: 3 * (4096 - 1024)})
+		return &quota.QuotaExceededError{Team: team}
+	}
+	updateData := App{Name: "my-test-app", Plan: appTypes.Plan{Name: "something"}}
+	err = a.Update(context.TODO(), UpdateAppArgs{UpdateData: updateData, Writer: new(bytes.Buffer)})
+	c.Assert(err, check.FitsTypeOf, &quota.QuotaExceededError{})
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Plan, check.DeepEquals, s.defaultPlan)
+}
+
+func (s *S) TestTeamResourceUsageCountsProvisionerUnits(c *check.C) {
+	a := App{Name: "warpaint", Platform: "python", Quota: quota.UnlimitedQuota, TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newSuccessfulAppVersion(c, &a)
+	err = a.AddUnits(context.TODO(), 3, "web", "", nil)
+	c.Assert(err, check.IsNil)
+	err = a.RemoveUnits(context.TODO(), 1, "web", "", nil)
+	c.Assert(err, check.IsNil)
+	err = s.provisioner.AddUnits(context.TODO(), &a, 1, "", version, nil)
+	c.Assert(err, check.IsNil)
+	usage, err := TeamResourceUsage(context.TODO(), s.team.Name, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(usage.Memory, check.Equals, 3*s.defaultPlan.GetMemory())
+}
+
+func (s *S) TestDeployResourceQuotaExceeded(c *check.C) {
+	a := App{Name: "warpaint", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.builder.OnBuild = func(app provision.App, evt *event.Event, opts builder.BuildOpts) (appTypes.AppVersion, error) {
+		version, err := servicemanager.AppVersion.NewAppVersion(context.TODO(), appTypes.NewVersionArgs{
+			App: app,
+		})
+		if err != nil {
+			return nil, err
+		}
+		err = version.AddData(appTypes.AddVersionDataArgs{
+			Processes: map[string][]string{"web": {"run web"}, "worker": {"run worker"}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return version, version.CommitBuildImage()
+	}
+	var requests []quota.ResourceList
+	s.mockService.ResourceQuota.OnCheck = func(team, pool string, requested quota.ResourceList) error {
+		requests = append(requests, requested)
+		return &quota.QuotaExceededError{Team: team}
+	}
+	newDeployEvent := func() *event.Event {
+		evt, err := event.New(context.TODO(), &event.Opts{
+			Target:   eventTypes.Target{Type: "app", Value: a.Name},
+			Kind:     permission.PermAppDeploy,
+			RawOwner: eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: s.user.Email},
+			Allowed:  event.Allowed(permission.PermApp),
+		})
+		c.Assert(err, check.IsNil)
+		return evt
+	}
+	_, err = Deploy(context.TODO(), DeployOptions{App: &a, Image: "myimage", Event: newDeployEvent()})
+	c.Assert(errors.Cause(err), check.FitsTypeOf, &quota.QuotaExceededError{})
+	err = s.provisioner.AddUnits(context.TODO(), &a, 1, "web", nil, nil)
+	c.Assert(err, check.IsNil)
+	_, err = Deploy(context.TODO(), DeployOptions{App: &a, Image: "myimage", Event: newDeployEvent()})
+	c.Assert(errors.Cause(err), check.FitsTypeOf, &quota.QuotaExceededError{})
+	plan := s.defaultPlan
+	c.Assert(requests, check.DeepEquals, []quota.ResourceList{
+		resourcesForUnits(plan, 2),
+		resourcesForUnits(plan, 1),
+	})
+}
+
+func (s *S) TestUpdatePoolResourceQuotaExceeded(c *check.C) {
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "test2", Public: true})
+	c.Assert(err, check.IsNil)
+	a := App{Name: "my-test-app", TeamOwner: s.team.Name}
+	err = CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a)
+	err = a.AddUnits(context.TODO(), 3, "web", "", nil)
+	c.Assert(err, check.IsNil)
+	s.mockService.ResourceQuota.OnCheck = func(team, pool string, requested quota.ResourceList) error {
+		c.Fatalf("unexpected team wide check for %v", requested)
+		return nil
+	}
+	s.mockService.ResourceQuota.OnCheckPool = func(team, pool string, requested quota.ResourceList) error {
+		c.Assert(team, check.Equals, s.team.Name)
+		c.Assert(pool, check.Equals, "test2")
+		c.Assert(requested, check.DeepEquals, quota.ResourceList{Memory: 3 * s.defaultPlan.GetMemory()})
+		return &quota.QuotaExceededError{Team: team, Pool: pool}
+	}
+	err = a.Update(context.TODO(), UpdateAppArgs{UpdateData: App{Pool: "test2"}, Writer: new(bytes.Buffer)})
+	c.Assert(err, check.FitsTypeOf, &quota.QuotaExceededError{})
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Pool, check.Equals, s.Pool)
+}
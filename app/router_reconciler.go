@@ -0,0 +1,291 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/router/rebuild"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+const (
+	defaultRouterReconcileInterval = 10 * time.Minute
+	routerReconcileInternalKind    = "router-reconcile"
+	routerDriftInternalKind        = "router-drift"
+)
+
+var (
+	routerDriftsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tsuru_router_drifts_total",
+		Help: "The number of app backends found diverging from the expected state by router.",
+	}, []string{"router"})
+
+	routerDriftRepairsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tsuru_router_drift_repairs_total",
+		Help: "The number of attempts to repair drifted app backends by router and result.",
+	}, []string{"router", "result"})
+
+	routerDriftedApps = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "tsuru_router_drifted_apps",
+		Help: "The number of apps with drifted backends found in the last reconciliation by router.",
+	}, []string{"router"})
+)
+
+func init() {
+	prometheus.MustRegister(routerDriftsTotal, routerDriftRepairsTotal, routerDriftedApps)
+}
+
+// InitializeRouterReconciler starts the periodic comparison of the backends
+// held by routers with the state expected for each app, when enabled by the
+// router-reconciler:enabled config. Only one API instance reconciles routes
+// in each interval, as the run is guarded by a throttled global event.
+func InitializeRouterReconciler() error {
+	enabled, _ := config.GetBool("router-reconciler:enabled")
+	if !enabled {
+		return nil
+	}
+	r := newRouterReconciler()
+	event.SetThrottling(event.ThrottlingSpec{
+		TargetType: eventTypes.TargetTypeGlobal,
+		KindName:   routerReconcileInternalKind,
+		Time:       r.interval,
+		Max:        1,
+		AllTargets: true,
+		WaitFinish: true,
+	})
+	r.start()
+	shutdown.Register(r)
+	return nil
+}
+
+type routerReconciler struct {
+	interval   time.Duration
+	autoRepair bool
+	once       *sync.Once
+	stopCh     chan struct{}
+}
+
+// routerReconcileResult is stored as the custom data of each reconciliation
+// event.
+type routerReconcileResult struct {
+	Apps     int `json:"apps"`
+	Drifted  int `json:"drifted"`
+	Repaired int `json:"repaired"`
+}
+
+func newRouterReconciler() *routerReconciler {
+	r := &routerReconciler{once: &sync.Once{}}
+	r.interval, _ = config.GetDuration("router-reconciler:interval")
+	if r.interval <= 0 {
+		r.interval = defaultRouterReconcileInterval
+	}
+	r.autoRepair, _ = config.GetBool("router-reconciler:auto-repair")
+	return r
+}
+
+func (r *routerReconciler) start() {
+	r.once.Do(func() {
+		r.stopCh = make(chan struct{})
+		go r.spin()
+	})
+}
+
+func (r *routerReconciler) Shutdown(ctx context.Context) error {
+	if r.stopCh == nil {
+		return nil
+	}
+	r.stopCh <- struct{}{}
+	r.stopCh = nil
+	r.once = &sync.Once{}
+	return nil
+}
+
+func (r *routerReconciler) spin() {
+	for {
+		select {
+		case <-r.stopCh:
+			return
+		case <-time.After(r.interval):
+		}
+		err := r.run(context.Background())
+		if err != nil {
+			log.Errorf("[router reconciler] %v", err)
+		}
+	}
+}
+
+func (r *routerReconciler) run(ctx context.Context) (err error) {
+	expireAt := time.Now().Add(24 * time.Hour)
+	evt, err := event.NewInternal(ctx, &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeGlobal},
+		InternalKind: routerReconcileInternalKind,
+		Allowed:      event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxGlobal, "")),
+		ExpireAt:     &expireAt,
+	})
+	if err != nil {
+		_, isThrottled := err.(event.ErrThrottled)
+		_, isLocked := err.(event.ErrEventLocked)
+		if isThrottled || isLocked {
+			return nil
+		}
+		return errors.Wrap(err, "could not create event")
+	}
+	result, err := r.reconcile(ctx)
+	evt.DoneCustomData(ctx, err, result)
+	return err
+}
+
+// reconcile checks the routes of every app, recording an event for each app
+// with drifted backends. Failures checking an app don't prevent the
+// remaining apps from being checked.
+func (r *routerReconciler) reconcile(ctx context.Context) (routerReconcileResult, error) {
+	var result routerReconcileResult
+	apps, err := List(ctx, nil)
+	if err != nil {
+		return result, err
+	}
+	multi := tsuruErrors.NewMultiError()
+	driftedApps := map[string]int{}
+	for i := range apps {
+		a := &apps[i]
+		result.Apps++
+		drifts, err := rebuild.DiffRoutes(ctx, a)
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to check routes of app %q", a.Name))
+			continue
+		}
+		if len(drifts) == 0 {
+			continue
+		}
+		drifts, repaired, err := r.handleDrift(ctx, a)
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to handle routes drift of app %q", a.Name))
+		}
+		if len(drifts) == 0 {
+			continue
+		}
+		result.Drifted++
+		for _, drift := range drifts {
+			routerDriftsTotal.WithLabelValues(drift.Router).Inc()
+			driftedApps[drift.Router]++
+		}
+		if repaired {
+			result.Repaired++
+		}
+	}
+	routerDriftedApps.Reset()
+	for routerName, count := range driftedApps {
+		routerDriftedApps.WithLabelValues(routerName).Set(float64(count))
+	}
+	return result, multi.ToError()
+}
+
+// handleDrift checks the routes of the app again while holding its lock,
+// recording the drifts found in an event and rebuilding its routes in the
+// drifted routers when auto-repair is enabled. Without auto-repair, drifts
+// equal to the ones recorded by the last event of the app aren't recorded
+// again. Apps locked by another operation are left for the next run.
+func (r *routerReconciler) handleDrift(ctx context.Context, a *App) ([]rebuild.RoutesDrift, bool, error) {
+	evt, err := event.NewInternal(ctx, &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+		InternalKind: routerDriftInternalKind,
+		Allowed:      event.Allowed(permission.PermAppReadEvents, permission.Contexts(permTypes.CtxApp, []string{a.Name})...),
+	})
+	if err != nil {
+		if _, isLocked := err.(event.ErrEventLocked); isLocked {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	a, err = GetByName(ctx, a.Name)
+	if err != nil {
+		evt.Abort(ctx)
+		if err == appTypes.ErrAppNotFound {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	drifts, err := rebuild.DiffRoutes(ctx, a)
+	if err != nil || len(drifts) == 0 {
+		evt.Abort(ctx)
+		return nil, false, err
+	}
+	if !r.autoRepair {
+		recorded, err := lastRecordedDrifts(ctx, a.Name)
+		if err != nil {
+			evt.Abort(ctx)
+			return drifts, false, err
+		}
+		if reflect.DeepEqual(recorded, drifts) {
+			evt.Abort(ctx)
+			return drifts, false, nil
+		}
+	}
+	for _, drift := range drifts {
+		fmt.Fprintf(evt, "Drift detected in %s\n", drift.String())
+	}
+	if !r.autoRepair {
+		return drifts, false, evt.DoneCustomData(ctx, errors.Errorf("routes of app %q drifted in %d router(s)", a.Name, len(drifts)), drifts)
+	}
+	appRouters := map[string]appTypes.AppRouter{}
+	for _, appRouter := range a.GetRouters() {
+		appRouters[appRouter.Name] = appRouter
+	}
+	multi := tsuruErrors.NewMultiError()
+	for _, drift := range drifts {
+		appRouter, ok := appRouters[drift.Router]
+		if !ok {
+			continue
+		}
+		err = rebuild.RebuildRoutesInRouter(ctx, appRouter, rebuild.RebuildRoutesOpts{
+			App:    a,
+			Writer: evt,
+		})
+		if err != nil {
+			routerDriftRepairsTotal.WithLabelValues(drift.Router, "failure").Inc()
+			multi.Add(errors.Wrapf(err, "unable to repair routes in router %q", drift.Router))
+			continue
+		}
+		routerDriftRepairsTotal.WithLabelValues(drift.Router, "success").Inc()
+	}
+	repairErr := multi.ToError()
+	return drifts, repairErr == nil, evt.DoneCustomData(ctx, repairErr, drifts)
+}
+
+// lastRecordedDrifts returns the drifts recorded by the last finished drift
+// event of the app.
+func lastRecordedDrifts(ctx context.Context, appName string) ([]rebuild.RoutesDrift, error) {
+	running := false
+	evts, err := event.List(ctx, &event.Filter{
+		Target:    eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: appName},
+		KindNames: []string{routerDriftInternalKind},
+		Running:   &running,
+		Limit:     1,
+	})
+	if err != nil || len(evts) == 0 {
+		return nil, err
+	}
+	var drifts []rebuild.RoutesDrift
+	err = evts[0].EndData(&drifts)
+	if err != nil {
+		return nil, err
+	}
+	return drifts, nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/router/rebuild"
+	"github.com/tsuru/tsuru/router/routertest"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) createDriftedApp(c *check.C) *App {
+	a := App{Name: "my-test-app", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = rebuild.RebuildRoutes(context.TODO(), rebuild.RebuildRoutesOpts{App: &a})
+	c.Assert(err, check.IsNil)
+	opts := routertest.FakeRouter.BackendOpts[a.Name]
+	opts.CNames = []string{"other.io"}
+	routertest.FakeRouter.BackendOpts[a.Name] = opts
+	return &a
+}
+
+func (s *S) TestRouterReconcilerReportsDrift(c *check.C) {
+	a := s.createDriftedApp(c)
+	r := &routerReconciler{}
+	result, err := r.reconcile(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, routerReconcileResult{Apps: 1, Drifted: 1})
+	c.Assert(routertest.FakeRouter.BackendOpts[a.Name].CNames, check.DeepEquals, []string{"other.io"})
+	evts, err := event.List(context.TODO(), &event.Filter{
+		Target:    eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+		KindNames: []string{routerDriftInternalKind},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].Error, check.Equals, `routes of app "my-test-app" drifted in 1 router(s)`)
+	c.Assert(evts[0].Log(), check.Matches, `(?s).*Drift detected in router "fake": cname "other.io" is not expected.*`)
+}
+
+func (s *S) TestRouterReconcilerAutoRepair(c *check.C) {
+	a := s.createDriftedApp(c)
+	r := &routerReconciler{autoRepair: true}
+	result, err := r.reconcile(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, routerReconcileResult{Apps: 1, Drifted: 1, Repaired: 1})
+	c.Assert(routertest.FakeRouter.BackendOpts[a.Name].CNames, check.HasLen, 0)
+	evts, err := event.List(context.TODO(), &event.Filter{
+		Target:    eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+		KindNames: []string{routerDriftInternalKind},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].Error, check.Equals, "")
+	result, err = r.reconcile(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, routerReconcileResult{Apps: 1})
+}
+
+func (s *S) TestRouterReconcilerDoesNotRepeatDrift(c *check.C) {
+	a := s.createDriftedApp(c)
+	r := &routerReconciler{}
+	for i := 0; i < 2; i++ {
+		result, err := r.reconcile(context.TODO())
+		c.Assert(err, check.IsNil)
+		c.Assert(result, check.DeepEquals, routerReconcileResult{Apps: 1, Drifted: 1})
+	}
+	filter := &event.Filter{
+		Target:    eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+		KindNames: []string{routerDriftInternalKind},
+	}
+	evts, err := event.List(context.TODO(), filter)
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	opts := routertest.FakeRouter.BackendOpts[a.Name]
+	opts.CNames = []string{"another.io"}
+	routertest.FakeRouter.BackendOpts[a.Name] = opts
+	_, err = r.reconcile(context.TODO())
+	c.Assert(err, check.IsNil)
+	evts, err = event.List(context.TODO(), filter)
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 2)
+	c.Assert(evts[0].Log(), check.Matches, `(?s).*cname "another.io" is not expected.*`)
+}
+
+func (s *S) TestRouterReconcilerHandleDriftReloadsApp(c *check.C) {
+	a := s.createDriftedApp(c)
+	stale := *a
+	stale.CName = []string{"other.io"}
+	r := &routerReconciler{autoRepair: true}
+	drifts, repaired, err := r.handleDrift(context.TODO(), &stale)
+	c.Assert(err, check.IsNil)
+	c.Assert(repaired, check.Equals, true)
+	c.Assert(drifts, check.HasLen, 1)
+	c.Assert(routertest.FakeRouter.BackendOpts[a.Name].CNames, check.HasLen, 0)
+	drifts, repaired, err = r.handleDrift(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	c.Assert(repaired, check.Equals, false)
+	c.Assert(drifts, check.HasLen, 0)
+	evts, err := event.List(context.TODO(), &event.Filter{
+		Target:    eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+		KindNames: []string{routerDriftInternalKind},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+}
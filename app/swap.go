@@ -0,0 +1,463 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/action"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/router/rebuild"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrSwapSameApp         = errors.New("swapping an app with itself is not allowed")
+	ErrSwapNotFound        = errors.New("swap not found")
+	ErrSwapAlreadyReverted = errors.New("swap is already reverted")
+	ErrAppAddressesSwapped = errors.New("app addresses are already swapped")
+	ErrSwapDifferentPools  = errors.New("swapping the addresses of apps in different pools is not supported")
+	ErrSwapNewerActive     = errors.New("a newer swap of the apps must be reverted first")
+)
+
+// ErrSwapNotReady is returned when the backend of an app isn't ready in one
+// of its routers, as traffic can't be moved to it safely.
+type ErrSwapNotReady struct {
+	App    string
+	Router string
+	Detail string
+}
+
+func (e *ErrSwapNotReady) Error() string {
+	msg := fmt.Sprintf("backend of app %q is not ready in router %q", e.App, e.Router)
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	return msg
+}
+
+type SwapOptions struct {
+	// CNameOnly exchanges only the cnames of the apps. Otherwise the router
+	// addresses are exchanged instead, so each app address and cnames route
+	// traffic to the units of the other app until the swap is reverted.
+	CNameOnly bool
+	// Force skips the readiness check of the app backends.
+	Force  bool
+	Owner  string
+	Writer io.Writer
+}
+
+// Swap exchanges either the cnames or the router addresses of two apps
+// sharing the same routers, recording the swap so it can be reverted
+// later. Addresses are only swapped between apps in the same pool, as the
+// routing resources of an app live in the cluster of its pool. Unless
+// forced, both apps must have ready backends in every router. Managed
+// certificates follow the swapped cnames, uploaded ones don't.
+func Swap(ctx context.Context, app1, app2 *App, opts SwapOptions) (*appTypes.AppSwap, error) {
+	if app1.Name == app2.Name {
+		return nil, ErrSwapSameApp
+	}
+	if !sameRouters(app1, app2) {
+		return nil, ErrSwapDifferentRouters
+	}
+	if opts.CNameOnly && len(app1.CName) == 0 && len(app2.CName) == 0 {
+		return nil, ErrSwapNoCNames
+	}
+	if !opts.CNameOnly {
+		if app1.Pool != app2.Pool {
+			return nil, ErrSwapDifferentPools
+		}
+		for _, a := range []*App{app1, app2} {
+			swap, err := activeAddressSwap(ctx, a.Name)
+			if err != nil {
+				return nil, err
+			}
+			if swap != nil {
+				return nil, errors.Wrapf(ErrAppAddressesSwapped, "app %q is swapped with %q in swap %s", a.Name, swap.Partner(a.Name), swap.ID)
+			}
+		}
+	}
+	if !opts.Force {
+		err := checkSwapReadiness(ctx, app1, app2)
+		if err != nil {
+			return nil, err
+		}
+	}
+	swap := &appTypes.AppSwap{
+		ID:         primitive.NewObjectID().Hex(),
+		App1:       app1.Name,
+		App2:       app2.Name,
+		CNameOnly:  opts.CNameOnly,
+		App1CNames: append([]string{}, app1.CName...),
+		App2CNames: append([]string{}, app2.CName...),
+		Status:     appTypes.AppSwapStatusSwapped,
+		Owner:      opts.Owner,
+		SwappedAt:  time.Now().UTC(),
+	}
+	err := runSwapPipeline(ctx, app1, app2, swap, opts.Writer)
+	if err != nil {
+		return nil, err
+	}
+	return swap, nil
+}
+
+// RevertSwap gives back to each app the cnames it had before the swap, or
+// exchanges back the router addresses when they were swapped, marking the
+// swap as reverted. Cnames added to the apps after the swap are kept and
+// cnames taken by other apps in the meantime are not restored. Swaps must be
+// reverted in the reverse order they were made.
+func RevertSwap(ctx context.Context, swap *appTypes.AppSwap, force bool, w io.Writer) error {
+	if swap.Status != appTypes.AppSwapStatusSwapped {
+		return ErrSwapAlreadyReverted
+	}
+	newer, err := newerActiveSwap(ctx, swap)
+	if err != nil {
+		return err
+	}
+	if newer != nil {
+		return errors.Wrapf(ErrSwapNewerActive, "swap %s of apps %q and %q", newer.ID, newer.App1, newer.App2)
+	}
+	app1, err := GetByName(ctx, swap.App1)
+	if err != nil {
+		return err
+	}
+	app2, err := GetByName(ctx, swap.App2)
+	if err != nil {
+		return err
+	}
+	if !force {
+		err = checkSwapReadiness(ctx, app1, app2)
+		if err != nil {
+			return err
+		}
+	}
+	reverted := *swap
+	reverted.Status = appTypes.AppSwapStatusReverted
+	reverted.RevertedAt = time.Now().UTC()
+	err = runSwapPipeline(ctx, app1, app2, &reverted, w)
+	if err != nil {
+		return err
+	}
+	*swap = reverted
+	return nil
+}
+
+func runSwapPipeline(ctx context.Context, app1, app2 *App, swap *appTypes.AppSwap, w io.Writer) error {
+	if w == nil {
+		w = io.Discard
+	}
+	actions := []*action.Action{
+		&swapAppsCNames,
+		&saveAppSwap,
+		&rebuildSwappedRoutes,
+	}
+	return action.NewPipeline(actions...).Execute(ctx, app1, app2, swap, w)
+}
+
+// GetSwap returns the swap record with the given id.
+func GetSwap(ctx context.Context, id string) (*appTypes.AppSwap, error) {
+	collection, err := storagev2.AppSwapsCollection()
+	if err != nil {
+		return nil, err
+	}
+	var swap appTypes.AppSwap
+	err = collection.FindOne(ctx, mongoBSON.M{"_id": id}).Decode(&swap)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrSwapNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &swap, nil
+}
+
+// ListSwaps returns the swaps involving the app, most recent first.
+func ListSwaps(ctx context.Context, appName string) ([]appTypes.AppSwap, error) {
+	collection, err := storagev2.AppSwapsCollection()
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := collection.Find(ctx, mongoBSON.M{
+		"$or": []mongoBSON.M{{"app1": appName}, {"app2": appName}},
+	}, options.Find().SetSort(mongoBSON.M{"swappedat": -1}))
+	if err != nil {
+		return nil, err
+	}
+	swaps := []appTypes.AppSwap{}
+	err = cursor.All(ctx, &swaps)
+	if err != nil {
+		return nil, err
+	}
+	return swaps, nil
+}
+
+// newerActiveSwap returns a swap in effect involving any of the apps of swap
+// made after it, if any.
+func newerActiveSwap(ctx context.Context, swap *appTypes.AppSwap) (*appTypes.AppSwap, error) {
+	collection, err := storagev2.AppSwapsCollection()
+	if err != nil {
+		return nil, err
+	}
+	apps := []string{swap.App1, swap.App2}
+	var newer appTypes.AppSwap
+	err = collection.FindOne(ctx, mongoBSON.M{
+		"_id":       mongoBSON.M{"$ne": swap.ID},
+		"$or":       []mongoBSON.M{{"app1": mongoBSON.M{"$in": apps}}, {"app2": mongoBSON.M{"$in": apps}}},
+		"status":    appTypes.AppSwapStatusSwapped,
+		"swappedat": mongoBSON.M{"$gt": swap.SwappedAt},
+	}).Decode(&newer)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &newer, nil
+}
+
+// activeAddressSwap returns the swap in effect exchanging the router
+// addresses of the app with another app, if any.
+func activeAddressSwap(ctx context.Context, appName string) (*appTypes.AppSwap, error) {
+	collection, err := storagev2.AppSwapsCollection()
+	if err != nil {
+		return nil, err
+	}
+	var swap appTypes.AppSwap
+	err = collection.FindOne(ctx, mongoBSON.M{
+		"$or":       []mongoBSON.M{{"app1": appName}, {"app2": appName}},
+		"status":    appTypes.AppSwapStatusSwapped,
+		"cnameonly": false,
+	}).Decode(&swap)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &swap, nil
+}
+
+// routingApp returns the app whose units receive the traffic routed to app,
+// which is the other app while their router addresses are swapped.
+func (app *App) routingApp(ctx context.Context) (*App, error) {
+	swap, err := activeAddressSwap(ctx, app.Name)
+	if err != nil || swap == nil {
+		return app, err
+	}
+	return GetByName(ctx, swap.Partner(app.Name))
+}
+
+func sameRouters(app1, app2 *App) bool {
+	names := func(a *App) []string {
+		var result []string
+		for _, r := range a.GetRouters() {
+			result = append(result, r.Name)
+		}
+		sort.Strings(result)
+		return result
+	}
+	routers1, routers2 := names(app1), names(app2)
+	if len(routers1) != len(routers2) {
+		return false
+	}
+	for i := range routers1 {
+		if routers1[i] != routers2[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func checkSwapReadiness(ctx context.Context, apps ...*App) error {
+	for _, a := range apps {
+		for _, appRouter := range a.GetRouters() {
+			r, err := router.Get(ctx, appRouter.Name)
+			if err != nil {
+				return err
+			}
+			status, err := r.GetBackendStatus(ctx, a)
+			if err != nil {
+				return &ErrSwapNotReady{App: a.Name, Router: appRouter.Name, Detail: err.Error()}
+			}
+			if status.Status != router.BackendStatusReady {
+				return &ErrSwapNotReady{App: a.Name, Router: appRouter.Name, Detail: status.Detail}
+			}
+		}
+	}
+	return nil
+}
+
+// swapRoutes ensures the backends of both apps with their current cnames.
+// The cnames of app1 are removed from the routers first, so that routers
+// refusing cnames used by another backend accept the exchange.
+func swapRoutes(ctx context.Context, app1, app2 *App, w io.Writer) error {
+	err := rebuild.RebuildRoutes(ctx, rebuild.RebuildRoutesOpts{
+		App:    &appWithoutCNames{App: app1},
+		Writer: w,
+	})
+	if err != nil {
+		return err
+	}
+	err = rebuild.RebuildRoutes(ctx, rebuild.RebuildRoutesOpts{App: app2, Writer: w})
+	if err != nil {
+		return err
+	}
+	return rebuild.RebuildRoutes(ctx, rebuild.RebuildRoutesOpts{App: app1, Writer: w})
+}
+
+type appWithoutCNames struct {
+	*App
+}
+
+func (a *appWithoutCNames) GetCname() []string {
+	return nil
+}
+
+// swapCNames returns the cnames each app must have after the swap is
+// applied: the exchanged cnames when swapping, the recorded cnames of each
+// app when reverting. When reverting, cnames added to an app after the swap
+// stay with it and recorded cnames now used by other apps are not restored.
+// Address swaps keep the cnames with the apps, as their routers already send
+// the traffic of both the address and the cnames to the other app.
+func swapCNames(ctx context.Context, app1, app2 *App, swap *appTypes.AppSwap, w io.Writer) ([]string, []string, error) {
+	if !swap.CNameOnly {
+		return append([]string{}, app1.CName...), append([]string{}, app2.CName...), nil
+	}
+	if swap.Status != appTypes.AppSwapStatusReverted {
+		return append([]string{}, app2.CName...), append([]string{}, app1.CName...), nil
+	}
+	recorded := map[string]bool{}
+	for _, cname := range append(append([]string{}, swap.App1CNames...), swap.App2CNames...) {
+		recorded[cname] = true
+	}
+	owners, err := cnameOwners(ctx, recorded, app1.Name, app2.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+	restore := func(appName string, cnames, current []string) []string {
+		result := []string{}
+		for _, cname := range cnames {
+			if owner, ok := owners[cname]; ok {
+				fmt.Fprintf(w, " ---> Not restoring cname %q to app %q, it is used by app %q\n", cname, appName, owner)
+				continue
+			}
+			result = append(result, cname)
+		}
+		for _, cname := range current {
+			if !recorded[cname] {
+				result = append(result, cname)
+			}
+		}
+		return result
+	}
+	return restore(app1.Name, swap.App1CNames, app1.CName), restore(app2.Name, swap.App2CNames, app2.CName), nil
+}
+
+// cnameOwners returns the apps, other than the given ones, using any of the
+// cnames.
+func cnameOwners(ctx context.Context, cnames map[string]bool, apps ...string) (map[string]string, error) {
+	owners := map[string]string{}
+	if len(cnames) == 0 {
+		return owners, nil
+	}
+	var names []string
+	for cname := range cnames {
+		names = append(names, cname)
+	}
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := collection.Find(ctx, mongoBSON.M{
+		"cname": mongoBSON.M{"$in": names},
+		"name":  mongoBSON.M{"$nin": apps},
+	}, options.Find().SetProjection(mongoBSON.M{"name": 1, "cname": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var others []App
+	err = cursor.All(ctx, &others)
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range others {
+		for _, cname := range other.CName {
+			if cnames[cname] {
+				owners[cname] = other.Name
+			}
+		}
+	}
+	return owners, nil
+}
+
+// setAppsCNames sets the cnames of both apps in memory and in the database,
+// moving the managed certificates of the cnames changing apps along with
+// them.
+func setAppsCNames(ctx context.Context, app1, app2 *App, cnames1, cnames2 []string) error {
+	collection, err := storagev2.AppsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": app1.Name}, mongoBSON.M{"$set": mongoBSON.M{"cname": cnames1}})
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"name": app2.Name}, mongoBSON.M{"$set": mongoBSON.M{"cname": cnames2}})
+	if err != nil {
+		return err
+	}
+	moved1, moved2 := intersectCNames(app1.CName, cnames2), intersectCNames(app2.CName, cnames1)
+	app1.CName, app2.CName = cnames1, cnames2
+	return moveManagedCertificates(ctx, app1.Name, app2.Name, moved1, moved2)
+}
+
+func intersectCNames(cnames, others []string) []string {
+	set := map[string]bool{}
+	for _, cname := range others {
+		set[cname] = true
+	}
+	var result []string
+	for _, cname := range cnames {
+		if set[cname] {
+			result = append(result, cname)
+		}
+	}
+	return result
+}
+
+// moveManagedCertificates transfers the managed certificates of cnames1
+// from app1 to app2 and of cnames2 from app2 to app1. Moved certificates
+// are issued again, installing them in the backend of their new app.
+func moveManagedCertificates(ctx context.Context, app1, app2 string, cnames1, cnames2 []string) error {
+	collection, err := storagev2.ManagedCertificatesCollection()
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	if len(cnames1) > 0 {
+		_, err = collection.UpdateMany(ctx, mongoBSON.M{"app": app1, "cname": mongoBSON.M{"$in": cnames1}}, mongoBSON.M{
+			"$set": mongoBSON.M{"app": app2, "nextattempt": now},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if len(cnames2) > 0 {
+		_, err = collection.UpdateMany(ctx, mongoBSON.M{"app": app2, "cname": mongoBSON.M{"$in": cnames2}}, mongoBSON.M{
+			"$set": mongoBSON.M{"app": app1, "nextattempt": now},
+		})
+	}
+	return err
+}
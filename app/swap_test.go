@@ -0,0 +1,239 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/router/routertest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) createSwapApps(c *check.C) (*App, *App) {
+	app1 := &App{Name: "app1", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), app1, s.user)
+	c.Assert(err, check.IsNil)
+	err = app1.AddCName(context.TODO(), "cname.io")
+	c.Assert(err, check.IsNil)
+	app2 := &App{Name: "app2", TeamOwner: s.team.Name}
+	err = CreateApp(context.TODO(), app2, s.user)
+	c.Assert(err, check.IsNil)
+	return app1, app2
+}
+
+func (s *S) TestSwapCNameOnly(c *check.C) {
+	app1, app2 := s.createSwapApps(c)
+	swap, err := Swap(context.TODO(), app1, app2, SwapOptions{CNameOnly: true, Owner: s.user.Email})
+	c.Assert(err, check.IsNil)
+	c.Assert(swap.Status, check.Equals, appTypes.AppSwapStatusSwapped)
+	c.Assert(swap.App1CNames, check.DeepEquals, []string{"cname.io"})
+	c.Assert(swap.App2CNames, check.DeepEquals, []string{})
+	c.Assert(routertest.FakeRouter.HasCNameFor(app2.Name, "cname.io"), check.Equals, true)
+	dbApp1, err := GetByName(context.TODO(), app1.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp1.CName, check.HasLen, 0)
+	dbApp2, err := GetByName(context.TODO(), app2.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp2.CName, check.DeepEquals, []string{"cname.io"})
+	routing, err := dbApp1.routingApp(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(routing.Name, check.Equals, app1.Name)
+	err = RevertSwap(context.TODO(), swap, false, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(swap.Status, check.Equals, appTypes.AppSwapStatusReverted)
+	c.Assert(routertest.FakeRouter.HasCNameFor(app1.Name, "cname.io"), check.Equals, true)
+	swaps, err := ListSwaps(context.TODO(), app2.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(swaps, check.HasLen, 1)
+	c.Assert(swaps[0].Status, check.Equals, appTypes.AppSwapStatusReverted)
+	err = RevertSwap(context.TODO(), &swaps[0], false, nil)
+	c.Assert(err, check.Equals, ErrSwapAlreadyReverted)
+}
+
+func (s *S) TestSwapAddresses(c *check.C) {
+	app1, app2 := s.createSwapApps(c)
+	s.provisioner.MockRoutableAddresses(app1, []appTypes.RoutableAddresses{{ExtraData: map[string]string{"units": app1.Name}}})
+	s.provisioner.MockRoutableAddresses(app2, []appTypes.RoutableAddresses{{ExtraData: map[string]string{"units": app2.Name}}})
+	swap, err := Swap(context.TODO(), app1, app2, SwapOptions{})
+	c.Assert(err, check.IsNil)
+	routing, err := app1.routingApp(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(routing.Name, check.Equals, app2.Name)
+	routing, err = app2.routingApp(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(routing.Name, check.Equals, app1.Name)
+	dbApp1, err := GetByName(context.TODO(), app1.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp1.CName, check.DeepEquals, []string{"cname.io"})
+	c.Assert(routertest.FakeRouter.HasCNameFor(app1.Name, "cname.io"), check.Equals, true)
+	c.Assert(routertest.FakeRouter.BackendOpts[app1.Name].Prefixes, check.DeepEquals, []router.BackendPrefix{
+		{Target: map[string]string{"units": app2.Name}},
+	})
+	c.Assert(routertest.FakeRouter.BackendOpts[app2.Name].Prefixes, check.DeepEquals, []router.BackendPrefix{
+		{Target: map[string]string{"units": app1.Name}},
+	})
+	_, err = Swap(context.TODO(), app2, app1, SwapOptions{})
+	c.Assert(err, check.ErrorMatches, `app "app2" is swapped with "app1" in swap .*: app addresses are already swapped`)
+	err = RevertSwap(context.TODO(), swap, false, nil)
+	c.Assert(err, check.IsNil)
+	routing, err = app1.routingApp(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(routing.Name, check.Equals, app1.Name)
+	dbApp1, err = GetByName(context.TODO(), app1.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp1.CName, check.DeepEquals, []string{"cname.io"})
+	c.Assert(routertest.FakeRouter.HasCNameFor(app1.Name, "cname.io"), check.Equals, true)
+	c.Assert(routertest.FakeRouter.BackendOpts[app1.Name].Prefixes, check.DeepEquals, []router.BackendPrefix{
+		{Target: map[string]string{"units": app1.Name}},
+	})
+}
+
+func (s *S) TestUpdatePoolOfAddressSwappedApp(c *check.C) {
+	app1, app2 := s.createSwapApps(c)
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "other-pool", Public: true})
+	c.Assert(err, check.IsNil)
+	swap, err := Swap(context.TODO(), app1, app2, SwapOptions{})
+	c.Assert(err, check.IsNil)
+	err = app1.Update(context.TODO(), UpdateAppArgs{UpdateData: App{Pool: "other-pool"}, Writer: new(bytes.Buffer)})
+	c.Assert(errors.Cause(err), check.Equals, ErrAppAddressesSwapped)
+	dbApp1, err := GetByName(context.TODO(), app1.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp1.Pool, check.Equals, app2.Pool)
+	err = RevertSwap(context.TODO(), swap, false, nil)
+	c.Assert(err, check.IsNil)
+	err = dbApp1.Update(context.TODO(), UpdateAppArgs{UpdateData: App{Pool: "other-pool"}, Writer: new(bytes.Buffer)})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestRevertSwapSkipsCNamesUsedByOtherApps(c *check.C) {
+	app1, app2 := s.createSwapApps(c)
+	swap, err := Swap(context.TODO(), app1, app2, SwapOptions{CNameOnly: true})
+	c.Assert(err, check.IsNil)
+	err = app2.RemoveCName(context.TODO(), "cname.io")
+	c.Assert(err, check.IsNil)
+	app3 := &App{Name: "app3", TeamOwner: s.team.Name}
+	err = CreateApp(context.TODO(), app3, s.user)
+	c.Assert(err, check.IsNil)
+	err = app3.AddCName(context.TODO(), "cname.io")
+	c.Assert(err, check.IsNil)
+	var buf bytes.Buffer
+	err = RevertSwap(context.TODO(), swap, false, &buf)
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Matches, `(?s).*Not restoring cname "cname.io" to app "app1", it is used by app "app3".*`)
+	dbApp1, err := GetByName(context.TODO(), app1.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp1.CName, check.DeepEquals, []string{})
+	dbApp3, err := GetByName(context.TODO(), app3.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp3.CName, check.DeepEquals, []string{"cname.io"})
+	c.Assert(routertest.FakeRouter.HasCNameFor(app3.Name, "cname.io"), check.Equals, true)
+}
+
+func (s *S) TestRevertSwapRestoresRecordedCNames(c *check.C) {
+	app1, app2 := s.createSwapApps(c)
+	swap, err := Swap(context.TODO(), app1, app2, SwapOptions{CNameOnly: true})
+	c.Assert(err, check.IsNil)
+	err = app1.AddCName(context.TODO(), "app1-new.io")
+	c.Assert(err, check.IsNil)
+	err = app2.AddCName(context.TODO(), "app2-new.io")
+	c.Assert(err, check.IsNil)
+	err = RevertSwap(context.TODO(), swap, false, nil)
+	c.Assert(err, check.IsNil)
+	dbApp1, err := GetByName(context.TODO(), app1.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp1.CName, check.DeepEquals, []string{"cname.io", "app1-new.io"})
+	dbApp2, err := GetByName(context.TODO(), app2.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp2.CName, check.DeepEquals, []string{"app2-new.io"})
+	c.Assert(routertest.FakeRouter.HasCNameFor(app1.Name, "cname.io"), check.Equals, true)
+	c.Assert(routertest.FakeRouter.HasCNameFor(app2.Name, "app2-new.io"), check.Equals, true)
+}
+
+func (s *S) TestRevertSwapWithNewerActiveSwap(c *check.C) {
+	app1, app2 := s.createSwapApps(c)
+	app3 := &App{Name: "app3", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), app3, s.user)
+	c.Assert(err, check.IsNil)
+	swap1, err := Swap(context.TODO(), app1, app2, SwapOptions{CNameOnly: true})
+	c.Assert(err, check.IsNil)
+	swap1.SwappedAt = swap1.SwappedAt.Add(-time.Hour)
+	collection, err := storagev2.AppSwapsCollection()
+	c.Assert(err, check.IsNil)
+	_, err = collection.UpdateOne(context.TODO(), mongoBSON.M{"_id": swap1.ID}, mongoBSON.M{"$set": mongoBSON.M{"swappedat": swap1.SwappedAt}})
+	c.Assert(err, check.IsNil)
+	swap2, err := Swap(context.TODO(), app2, app3, SwapOptions{CNameOnly: true})
+	c.Assert(err, check.IsNil)
+	err = RevertSwap(context.TODO(), swap1, false, nil)
+	c.Assert(errors.Cause(err), check.Equals, ErrSwapNewerActive)
+	err = RevertSwap(context.TODO(), swap2, false, nil)
+	c.Assert(err, check.IsNil)
+	err = RevertSwap(context.TODO(), swap1, false, nil)
+	c.Assert(err, check.IsNil)
+	dbApp1, err := GetByName(context.TODO(), app1.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp1.CName, check.DeepEquals, []string{"cname.io"})
+}
+
+func (s *S) TestSwapAddressesDifferentPools(c *check.C) {
+	app1, app2 := s.createSwapApps(c)
+	app2.Pool = "other-pool"
+	_, err := Swap(context.TODO(), app1, app2, SwapOptions{})
+	c.Assert(err, check.Equals, ErrSwapDifferentPools)
+	_, err = Swap(context.TODO(), app1, app2, SwapOptions{CNameOnly: true})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestSwapInvalid(c *check.C) {
+	app1, app2 := s.createSwapApps(c)
+	_, err := Swap(context.TODO(), app1, app1, SwapOptions{})
+	c.Assert(err, check.Equals, ErrSwapSameApp)
+	app3 := &App{Name: "app3", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-tls"}}}
+	err = CreateApp(context.TODO(), app3, s.user)
+	c.Assert(err, check.IsNil)
+	_, err = Swap(context.TODO(), app1, app3, SwapOptions{})
+	c.Assert(err, check.Equals, ErrSwapDifferentRouters)
+	app1.CName = nil
+	_, err = Swap(context.TODO(), app1, app2, SwapOptions{CNameOnly: true})
+	c.Assert(err, check.Equals, ErrSwapNoCNames)
+}
+
+func (s *S) TestSwapBackendNotReady(c *check.C) {
+	app1, app2 := s.createSwapApps(c)
+	routertest.FakeRouter.FailuresByHost[app2.Name] = true
+	_, err := Swap(context.TODO(), app1, app2, SwapOptions{})
+	c.Assert(err, check.DeepEquals, &ErrSwapNotReady{App: app2.Name, Router: "fake", Detail: routertest.ErrForcedFailure.Error()})
+	swaps, err := ListSwaps(context.TODO(), app1.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(swaps, check.HasLen, 0)
+	_, err = Swap(context.TODO(), app1, app2, SwapOptions{Force: true})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestDeleteSwappedApp(c *check.C) {
+	app1, app2 := s.createSwapApps(c)
+	_, err := Swap(context.TODO(), app1, app2, SwapOptions{})
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: "app", Value: app1.Name},
+		Kind:     permission.PermAppDelete,
+		RawOwner: eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	err = Delete(context.TODO(), app1, evt, "")
+	c.Assert(err, check.ErrorMatches, `app is swapped with "app2", revert swap .* first: .*`)
+	c.Assert(errors.Cause(err), check.Equals, router.ErrBackendSwapped)
+}
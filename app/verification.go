@@ -0,0 +1,289 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	tsuruNet "github.com/tsuru/tsuru/net"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+)
+
+const (
+	defaultVerificationWindow   = 5 * time.Minute
+	defaultVerificationInterval = 30 * time.Second
+
+	verificationInternalKind = "deploy-verification"
+	verificationOwner        = "deploy-verification"
+)
+
+type verificationSample struct {
+	restarts   int
+	units      int
+	readyUnits int
+	metric     *float64
+}
+
+// deployVerifier watches a freshly deployed version during the verification
+// window configured in its tsuru.yaml and rolls the app back to the previous
+// version when the configured thresholds are breached.
+type deployVerifier struct {
+	app      *App
+	version  appTypes.AppVersion
+	previous appTypes.AppVersion
+	spec     provTypes.TsuruYamlVerification
+	query    func(ctx context.Context, query string) (float64, error)
+}
+
+func newDeployVerifier(app *App, version, previous appTypes.AppVersion, spec provTypes.TsuruYamlVerification) *deployVerifier {
+	if spec.WindowSeconds <= 0 {
+		spec.WindowSeconds = int(defaultVerificationWindow / time.Second)
+	}
+	if spec.IntervalSeconds <= 0 {
+		spec.IntervalSeconds = int(defaultVerificationInterval / time.Second)
+	}
+	return &deployVerifier{
+		app:      app,
+		version:  version,
+		previous: previous,
+		spec:     spec,
+		query:    prometheusQuery,
+	}
+}
+
+// startDeployVerification starts the verification of the version in
+// background, it does nothing when the version has no verification settings
+// or when there's no previous version to rollback to.
+func startDeployVerification(ctx context.Context, app *App, previous appTypes.AppVersion) {
+	if previous == nil {
+		return
+	}
+	version, err := servicemanager.AppVersion.LatestSuccessfulVersion(ctx, app)
+	if err != nil || version.Version() == previous.Version() {
+		return
+	}
+	yamlData, err := version.TsuruYamlData()
+	if err != nil || yamlData.Verification == nil {
+		return
+	}
+	verifier := newDeployVerifier(app, version, previous, *yamlData.Verification)
+	go func() {
+		verifyErr := verifier.run(context.Background())
+		if verifyErr != nil {
+			log.Errorf("[deploy-verification] error verifying app %q version %d: %v", app.Name, version.Version(), verifyErr)
+		}
+	}()
+}
+
+func (v *deployVerifier) run(ctx context.Context) (err error) {
+	evt, err := event.NewInternal(ctx, &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: v.app.Name},
+		InternalKind: verificationInternalKind,
+		DisableLock:  true,
+		CustomData: map[string]interface{}{
+			"version":  v.version.Version(),
+			"previous": v.previous.Version(),
+			"spec":     v.spec,
+		},
+		Allowed: event.Allowed(permission.PermAppReadEvents, permission.Contexts(permTypes.CtxApp, []string{v.app.Name})...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(ctx, err) }()
+	baseline, err := v.sample(ctx)
+	if err != nil {
+		return err
+	}
+	interval := time.Duration(v.spec.IntervalSeconds) * time.Second
+	deadline := time.Now().Add(time.Duration(v.spec.WindowSeconds) * time.Second)
+	failures := 0
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+		current, err := servicemanager.AppVersion.LatestSuccessfulVersion(ctx, v.app)
+		if err != nil {
+			return err
+		}
+		if current.Version() != v.version.Version() {
+			fmt.Fprintf(evt, "version %d was replaced by version %d, stopping verification\n", v.version.Version(), current.Version())
+			return nil
+		}
+		s, err := v.sample(ctx)
+		if err != nil {
+			return err
+		}
+		reason := v.breach(baseline, s)
+		if reason == "" {
+			fmt.Fprintf(evt, "sample ok: %d/%d units ready, %d restarts\n", s.readyUnits, s.units, s.restarts-baseline.restarts)
+			continue
+		}
+		failures++
+		fmt.Fprintf(evt, "sample failed (%d/%d allowed): %s\n", failures, v.spec.AllowedFailures, reason)
+		if failures > v.spec.AllowedFailures {
+			return v.rollback(ctx, evt, reason)
+		}
+	}
+	fmt.Fprintf(evt, "version %d verified successfully\n", v.version.Version())
+	return nil
+}
+
+func (v *deployVerifier) sample(ctx context.Context) (verificationSample, error) {
+	var s verificationSample
+	units, err := v.app.Units(ctx)
+	if err != nil {
+		return s, err
+	}
+	for _, u := range units {
+		if u.Version != 0 && u.Version != v.version.Version() {
+			continue
+		}
+		s.units++
+		if u.Restarts != nil {
+			s.restarts += int(*u.Restarts)
+		}
+		if u.Ready == nil || *u.Ready {
+			s.readyUnits++
+		}
+	}
+	if v.spec.Prometheus != nil && v.spec.Prometheus.Query != "" {
+		value, err := v.query(ctx, v.spec.Prometheus.Query)
+		if err != nil {
+			return s, err
+		}
+		s.metric = &value
+	}
+	return s, nil
+}
+
+// breach returns the reason why the sample is considered a failure, or an
+// empty string when the sample is within the configured thresholds.
+func (v *deployVerifier) breach(baseline, s verificationSample) string {
+	if v.spec.MaxRestarts > 0 && s.restarts-baseline.restarts > v.spec.MaxRestarts {
+		return fmt.Sprintf("units restarted %d times, max allowed is %d", s.restarts-baseline.restarts, v.spec.MaxRestarts)
+	}
+	if v.spec.MinReadyPercent > 0 && s.units > 0 {
+		ready := s.readyUnits * 100 / s.units
+		if ready < v.spec.MinReadyPercent {
+			return fmt.Sprintf("%d%% of units ready, min required is %d%%", ready, v.spec.MinReadyPercent)
+		}
+	}
+	if s.metric != nil && *s.metric > v.spec.Prometheus.Threshold {
+		return fmt.Sprintf("prometheus query returned %v, threshold is %v", *s.metric, v.spec.Prometheus.Threshold)
+	}
+	return ""
+}
+
+func (v *deployVerifier) rollback(ctx context.Context, verifyEvt *event.Event, reason string) error {
+	reason = fmt.Sprintf("post deploy verification failed: %s", reason)
+	fmt.Fprintf(verifyEvt, "rolling back to version %d\n", v.previous.Version())
+	err := v.version.ToggleEnabled(false, reason)
+	if err != nil {
+		return err
+	}
+	opts := DeployOptions{
+		App:          v.app,
+		Image:        fmt.Sprintf("v%d", v.previous.Version()),
+		Rollback:     true,
+		Kind:         provTypes.DeployRollback,
+		Origin:       "rollback",
+		Message:      reason,
+		OutputStream: verifyEvt,
+	}
+	evt, err := event.New(ctx, &event.Opts{
+		Target:   eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: v.app.Name},
+		Kind:     permission.PermAppDeploy,
+		RawOwner: eventTypes.Owner{Type: eventTypes.OwnerTypeInternal, Name: verificationOwner},
+		CustomData: map[string]interface{}{
+			"image":    opts.Image,
+			"kind":     string(opts.Kind),
+			"origin":   opts.Origin,
+			"message":  opts.Message,
+			"rollback": true,
+		},
+		Allowed: event.Allowed(permission.PermAppReadEvents, permission.Contexts(permTypes.CtxApp, []string{v.app.Name})...),
+	})
+	if err != nil {
+		return err
+	}
+	opts.Event = evt
+	imageID, err := Deploy(ctx, opts)
+	evt.DoneCustomData(ctx, err, map[string]string{"image": imageID})
+	if err != nil {
+		return errors.Wrapf(err, "unable to rollback to version %d", v.previous.Version())
+	}
+	return errors.New(reason)
+}
+
+type prometheusQueryResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		Result []struct {
+			Value []interface{} `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// prometheusQuery runs an instant query against the prometheus server
+// configured in deploy-verification:prometheus-url and returns the highest
+// value among the returned series.
+func prometheusQuery(ctx context.Context, query string) (float64, error) {
+	baseURL, err := config.GetString("deploy-verification:prometheus-url")
+	if err != nil {
+		return 0, errors.New("deploy-verification:prometheus-url is not configured")
+	}
+	u := fmt.Sprintf("%s/api/v1/query?query=%s", baseURL, url.QueryEscape(query))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return 0, err
+	}
+	rsp, err := tsuruNet.Dial15Full60ClientWithPool.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer rsp.Body.Close()
+	var data prometheusQueryResponse
+	err = json.NewDecoder(rsp.Body).Decode(&data)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid prometheus response, status code %d", rsp.StatusCode)
+	}
+	if data.Status != "success" {
+		return 0, errors.Errorf("prometheus query failed: %s", data.Error)
+	}
+	var result float64
+	for i, r := range data.Data.Result {
+		if len(r.Value) != 2 {
+			continue
+		}
+		str, _ := r.Value[1].(string)
+		value, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return 0, errors.Wrapf(err, "invalid prometheus value %q", str)
+		}
+		if i == 0 || value > result {
+			result = value
+		}
+	}
+	return result, nil
+}
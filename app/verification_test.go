@@ -0,0 +1,75 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/servicemanager"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestDeployVerifierBreach(c *check.C) {
+	metric := func(v float64) *float64 { return &v }
+	spec := provTypes.TsuruYamlVerification{
+		MaxRestarts:     2,
+		MinReadyPercent: 50,
+		Prometheus:      &provTypes.TsuruYamlVerificationPrometheus{Query: "errors", Threshold: 10},
+	}
+	v := newDeployVerifier(nil, nil, nil, spec)
+	baseline := verificationSample{restarts: 1}
+	tests := []struct {
+		sample   verificationSample
+		expected string
+	}{
+		{sample: verificationSample{restarts: 3, units: 2, readyUnits: 2, metric: metric(1)}},
+		{sample: verificationSample{restarts: 4, units: 2, readyUnits: 2}, expected: "units restarted 3 times, max allowed is 2"},
+		{sample: verificationSample{restarts: 1, units: 4, readyUnits: 1}, expected: "25% of units ready, min required is 50%"},
+		{sample: verificationSample{restarts: 1, units: 2, readyUnits: 2, metric: metric(11.5)}, expected: "prometheus query returned 11.5, threshold is 10"},
+	}
+	for _, tt := range tests {
+		c.Check(v.breach(baseline, tt.sample), check.Equals, tt.expected)
+	}
+}
+
+func (s *S) TestDeployVerifierDefaults(c *check.C) {
+	v := newDeployVerifier(nil, nil, nil, provTypes.TsuruYamlVerification{})
+	c.Assert(v.spec.WindowSeconds, check.Equals, 300)
+	c.Assert(v.spec.IntervalSeconds, check.Equals, 30)
+}
+
+func (s *S) TestDeployVerifierRollback(c *check.C) {
+	a := App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name, Router: "fake"}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	previous := newSuccessfulAppVersion(c, &a)
+	version := newSuccessfulAppVersion(c, &a)
+	v := newDeployVerifier(&a, version, previous, provTypes.TsuruYamlVerification{
+		WindowSeconds:   1,
+		IntervalSeconds: 1,
+		Prometheus:      &provTypes.TsuruYamlVerificationPrometheus{Query: "errors", Threshold: 1},
+	})
+	v.query = func(ctx context.Context, query string) (float64, error) {
+		c.Check(query, check.Equals, "errors")
+		return 5, nil
+	}
+	err = v.run(context.TODO())
+	c.Assert(err, check.ErrorMatches, `post deploy verification failed: prometheus query returned 5, threshold is 1`)
+	versions, err := servicemanager.AppVersion.AppVersions(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	c.Assert(versions.Versions[version.Version()].Disabled, check.Equals, true)
+	c.Assert(versions.Versions[version.Version()].DisabledReason, check.Equals, "post deploy verification failed: prometheus query returned 5, threshold is 1")
+	evts, err := event.List(context.TODO(), &event.Filter{
+		Target:   eventTypes.Target{Type: eventTypes.TargetTypeApp, Value: a.Name},
+		KindType: eventTypes.KindTypePermission,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].Owner, check.DeepEquals, eventTypes.Owner{Type: eventTypes.OwnerTypeInternal, Name: verificationOwner})
+	c.Assert(evts[0].Error, check.Equals, "")
+}
@@ -19,9 +19,10 @@ var (
 )
 
 type customData struct {
-	Hooks       *provTypes.TsuruYamlHooks
-	Healthcheck *provTypes.TsuruYamlHealthcheck
-	Kubernetes  *tsuruYamlKubernetesConfig
+	Hooks        *provTypes.TsuruYamlHooks
+	Healthcheck  *provTypes.TsuruYamlHealthcheck
+	Verification *provTypes.TsuruYamlVerification
+	Kubernetes   *tsuruYamlKubernetesConfig
 }
 
 type tsuruYamlKubernetesConfig struct {
@@ -60,8 +61,9 @@ func unmarshalYamlData(data map[string]interface{}) (provTypes.TsuruYamlData, er
 	}
 
 	result := provTypes.TsuruYamlData{
-		Hooks:       custom.Hooks,
-		Healthcheck: custom.Healthcheck,
+		Hooks:        custom.Hooks,
+		Healthcheck:  custom.Healthcheck,
+		Verification: custom.Verification,
 	}
 	if custom.Kubernetes == nil {
 		return result, nil
@@ -111,6 +113,9 @@ func marshalCustomData(data map[string]interface{}) (map[string]interface{}, err
 	}
 	result["hooks"] = yamlData.Hooks
 	result["healthcheck"] = yamlData.Healthcheck
+	if yamlData.Verification != nil {
+		result["verification"] = yamlData.Verification
+	}
 	if yamlData.Kubernetes == nil {
 		return result, nil
 	}
@@ -5,6 +5,7 @@
 package version
 
 import (
+	provTypes "github.com/tsuru/tsuru/types/provision"
 	check "gopkg.in/check.v1"
 )
 
@@ -37,3 +38,26 @@ func (s *S) TestGetProcessesFromProcfile(c *check.C) {
 		c.Check(v, check.DeepEquals, t.expected, check.Commentf("failed test %d", i))
 	}
 }
+
+func (s *S) TestUnmarshalYamlDataVerification(c *check.C) {
+	data, err := marshalCustomData(map[string]interface{}{
+		"verification": map[string]interface{}{
+			"window_seconds":    600,
+			"allowed_failures":  2,
+			"min_ready_percent": 80,
+			"prometheus": map[string]interface{}{
+				"query":     "up",
+				"threshold": 0.5,
+			},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	yamlData, err := unmarshalYamlData(data)
+	c.Assert(err, check.IsNil)
+	c.Assert(yamlData.Verification, check.DeepEquals, &provTypes.TsuruYamlVerification{
+		WindowSeconds:   600,
+		AllowedFailures: 2,
+		MinReadyPercent: 80,
+		Prometheus:      &provTypes.TsuruYamlVerificationPrometheus{Query: "up", Threshold: 0.5},
+	})
+}
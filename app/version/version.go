@@ -177,6 +177,15 @@ func (v *appVersionImpl) ToggleEnabled(enabled bool, reason string) error {
 	return v.storage.UpdateVersion(v.ctx, v.app.GetName(), v.versionInfo)
 }
 
+func (v *appVersionImpl) SetTrafficWeight(weight int) error {
+	err := v.refresh()
+	if err != nil {
+		return err
+	}
+	v.versionInfo.TrafficWeight = weight
+	return v.storage.UpdateVersion(v.ctx, v.app.GetName(), v.versionInfo)
+}
+
 func (v *appVersionImpl) Version() int {
 	return v.VersionInfo().Version
 }
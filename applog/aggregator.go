@@ -15,6 +15,7 @@ import (
 	"strconv"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/tsuru/tsuru/auth/peer"
@@ -223,6 +224,21 @@ func buildInstanceRequests(ctx context.Context, args appTypes.ListLogArgs, follo
 			urlValues.Add("unit", u)
 		}
 		urlValues.Add("invert-source", strconv.FormatBool(args.InvertSource))
+		if !args.Since.IsZero() {
+			urlValues.Add("since", args.Since.Format(time.RFC3339Nano))
+		}
+		if !args.Until.IsZero() {
+			urlValues.Add("until", args.Until.Format(time.RFC3339Nano))
+		}
+		if args.Message != "" {
+			urlValues.Add("message", args.Message)
+		}
+		if args.MessageRegex != "" {
+			urlValues.Add("message-regex", args.MessageRegex)
+		}
+		for key, value := range args.Fields {
+			urlValues.Add("field", key+"="+value)
+		}
 		if follow {
 			urlValues.Add("follow", "1")
 		}
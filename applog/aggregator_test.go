@@ -122,6 +122,29 @@ func (s *S) Test_Aggregator_ListFilter(c *check.C) {
 	})
 }
 
+func (s *S) Test_Aggregator_ListMessageFilters(c *check.C) {
+	since := time.Date(2026, 3, 1, 14, 0, 0, 0, time.UTC)
+	rollback := mockServers(2, func(i int, w http.ResponseWriter, r *http.Request) bool {
+		c.Assert(r.URL.Query().Get("since"), check.Equals, "2026-03-01T14:00:00Z")
+		c.Assert(r.URL.Query().Get("until"), check.Equals, "2026-03-01T14:05:00Z")
+		c.Assert(r.URL.Query().Get("message"), check.Equals, "timeout")
+		c.Assert(r.URL.Query().Get("message-regex"), check.Equals, "^GET")
+		c.Assert(r.URL.Query()["field"], check.DeepEquals, []string{"level=error"})
+		return false
+	})
+	defer rollback()
+	svc := &aggregatorLogService{}
+	_, err := svc.List(context.TODO(), appTypes.ListLogArgs{
+		Name:         "myapp",
+		Since:        since,
+		Until:        since.Add(5 * time.Minute),
+		Message:      "timeout",
+		MessageRegex: "^GET",
+		Fields:       map[string]string{"level": "error"},
+	})
+	c.Assert(err, check.IsNil)
+}
+
 func (s *S) Test_Aggregator_ListReorderMessages(c *check.C) {
 	rollback := mockServers(6, func(i int, w http.ResponseWriter, r *http.Request) bool {
 		switch i {
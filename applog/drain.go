@@ -0,0 +1,422 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package applog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	internalConfig "github.com/tsuru/tsuru/config"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/set"
+	"github.com/tsuru/tsuru/storage"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	logTypes "github.com/tsuru/tsuru/types/log"
+	"github.com/tsuru/tsuru/validation"
+)
+
+const (
+	logDrainSubsystem       = "log_drains"
+	defaultDrainQueueSize   = 10000
+	defaultDrainBatchSize   = 100
+	defaultDrainBatchWait   = time.Second
+	defaultDrainMaxRetries  = 3
+	defaultDrainRetryWait   = time.Second
+	drainsCacheTTL          = 30 * time.Second
+	drainSenderIdleInterval = 10 * time.Minute
+)
+
+var (
+	_ appTypes.LogDrainService = &logDrainService{}
+
+	logDrainQueued = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: promNamespace,
+		Subsystem: logDrainSubsystem,
+		Name:      "queue_size",
+		Help:      "The number of log entries waiting to be sent to a drain.",
+	}, []string{"app", "drain"})
+
+	logDrainSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: promNamespace,
+		Subsystem: logDrainSubsystem,
+		Name:      "sent_total",
+		Help:      "The number of log entries sent to drains.",
+	}, []string{"type"})
+
+	logDrainDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: promNamespace,
+		Subsystem: logDrainSubsystem,
+		Name:      "dropped_total",
+		Help:      "The number of log entries dropped due to a full drain queue or failed deliveries.",
+	}, []string{"type"})
+
+	logDrainErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: promNamespace,
+		Subsystem: logDrainSubsystem,
+		Name:      "errors_total",
+		Help:      "The number of failed attempts to send log entries to drains.",
+	}, []string{"type"})
+
+	logDrainLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: promNamespace,
+		Subsystem: logDrainSubsystem,
+		Name:      "latency_seconds",
+		Help:      "The latency for sending batches of log entries to drains in seconds.",
+	}, []string{"type"})
+)
+
+type drainsCacheEntry struct {
+	drains   []appTypes.LogDrain
+	cachedAt time.Time
+}
+
+type logDrainService struct {
+	storage   appTypes.LogDrainStorage
+	poolOf    func(ctx context.Context, appName string) (string, error)
+	cache     sync.Map
+	senders   sync.Map
+	quitCh    chan struct{}
+	wg        sync.WaitGroup
+	newSender func(drain appTypes.LogDrain) (drainSink, error)
+}
+
+var globalLogDrainService struct {
+	sync.Mutex
+	service *logDrainService
+}
+
+// LogDrainService returns the log drain service shared by the API instance,
+// as the senders of each drain must outlive a single caller.
+func LogDrainService() (appTypes.LogDrainService, error) {
+	globalLogDrainService.Lock()
+	defer globalLogDrainService.Unlock()
+	if globalLogDrainService.service != nil {
+		return globalLogDrainService.service, nil
+	}
+	dbDriver, err := storage.GetCurrentDbDriver()
+	if err != nil {
+		dbDriver, err = storage.GetDefaultDbDriver()
+		if err != nil {
+			return nil, err
+		}
+	}
+	s := &logDrainService{
+		storage:   dbDriver.LogDrainStorage,
+		poolOf:    appPool,
+		quitCh:    make(chan struct{}),
+		newSender: newDrainSink,
+	}
+	shutdown.Register(s)
+	globalLogDrainService.service = s
+	return s, nil
+}
+
+func appPool(ctx context.Context, appName string) (string, error) {
+	obj, err := defineLogabbleObject(ctx, logTypes.LogTypeApp, appName)
+	if err != nil {
+		return "", err
+	}
+	return obj.GetPool(), nil
+}
+
+func validateDrain(drain appTypes.LogDrain) error {
+	if drain.Name == "" {
+		return &tsuruErrors.ValidationError{Message: "log drain name must not be empty"}
+	}
+	if !validation.ValidateName(drain.Name) {
+		return &tsuruErrors.ValidationError{Message: "Invalid log drain name, log drain name should have at most 40 " +
+			"characters, containing only lower case letters, numbers or dashes, " +
+			"starting with a letter."}
+	}
+	u, err := url.Parse(drain.URL)
+	if err != nil || u.Host == "" {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("log drain url %q is not valid", drain.URL)}
+	}
+	switch drain.Type {
+	case appTypes.LogDrainTypeSyslog:
+		if u.Scheme != "tcp" && u.Scheme != "tls" {
+			return &tsuruErrors.ValidationError{Message: "syslog drain url scheme must be tcp or tls"}
+		}
+	case appTypes.LogDrainTypeHTTP, appTypes.LogDrainTypeOTLP:
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("%s drain url scheme must be http or https", drain.Type)}
+		}
+	default:
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid log drain type %q, valid types are: syslog, http or otlp", drain.Type)}
+	}
+	return nil
+}
+
+func (s *logDrainService) Create(ctx context.Context, drain appTypes.LogDrain) error {
+	err := validateDrain(drain)
+	if err != nil {
+		return err
+	}
+	err = s.storage.Insert(ctx, drain)
+	if err != nil {
+		return err
+	}
+	s.cache.Delete(drain.App)
+	return nil
+}
+
+func (s *logDrainService) Delete(ctx context.Context, appName, name string) error {
+	err := s.storage.Delete(ctx, appName, name)
+	if err != nil {
+		return err
+	}
+	s.cache.Delete(appName)
+	if sender, ok := s.senders.LoadAndDelete(senderKey(appName, name)); ok {
+		sender.(*drainSender).stop()
+	}
+	return nil
+}
+
+func (s *logDrainService) List(ctx context.Context, appName string) ([]appTypes.LogDrain, error) {
+	drains, err := s.storage.FindByApp(ctx, appName)
+	if err != nil {
+		return nil, err
+	}
+	if len(drains) > 0 {
+		return drains, nil
+	}
+	pool, err := s.poolOf(ctx, appName)
+	if err != nil {
+		return nil, err
+	}
+	return poolDefaultDrains(pool)
+}
+
+// poolDefaultDrains returns the drains configured in
+// log:drains:pools:<pool>, used by apps without drains of their own.
+func poolDefaultDrains(pool string) ([]appTypes.LogDrain, error) {
+	raw, err := config.Get("log:drains:pools:" + pool)
+	if err != nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(internalConfig.ConvertEntries(raw))
+	if err != nil {
+		return nil, err
+	}
+	var drains []appTypes.LogDrain
+	err = json.Unmarshal(data, &drains)
+	if err != nil {
+		return nil, err
+	}
+	for i := range drains {
+		drains[i].Pool = pool
+	}
+	return drains, nil
+}
+
+func (s *logDrainService) appDrains(appName string) []appTypes.LogDrain {
+	cached, ok := s.cache.Load(appName)
+	if ok && time.Since(cached.(drainsCacheEntry).cachedAt) < drainsCacheTTL {
+		return cached.(drainsCacheEntry).drains
+	}
+	drains, err := s.List(context.Background(), appName)
+	if err != nil {
+		log.Errorf("[log drains] unable to list drains for %q: %v", appName, err)
+	}
+	s.cache.Store(appName, drainsCacheEntry{drains: drains, cachedAt: time.Now()})
+	return drains
+}
+
+func senderKey(appName, drainName string) string {
+	return appName + "/" + drainName
+}
+
+func (s *logDrainService) Forward(entry *appTypes.Applog) {
+	if entry.Type != "" && entry.Type != logTypes.LogTypeApp {
+		return
+	}
+	for _, drain := range s.appDrains(entry.Name) {
+		if len(drain.Sources) > 0 && !set.FromSlice(drain.Sources).Includes(entry.Source) {
+			continue
+		}
+		sender, err := s.sender(entry.Name, drain)
+		if err != nil {
+			log.Errorf("[log drains] unable to start drain %q for %q: %v", drain.Name, entry.Name, err)
+			continue
+		}
+		sender.enqueue(entry)
+	}
+}
+
+func (s *logDrainService) sender(appName string, drain appTypes.LogDrain) (*drainSender, error) {
+	key := senderKey(appName, drain.Name)
+	if existing, ok := s.senders.Load(key); ok {
+		sender := existing.(*drainSender)
+		if sender.drain.URL == drain.URL && sender.drain.Type == drain.Type {
+			return sender, nil
+		}
+		s.senders.Delete(key)
+		sender.stop()
+	}
+	sink, err := s.newSender(drain)
+	if err != nil {
+		return nil, err
+	}
+	sender := newDrainSender(appName, drain, sink)
+	actual, loaded := s.senders.LoadOrStore(key, sender)
+	if loaded {
+		return actual.(*drainSender), nil
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		sender.run(s.quitCh)
+		s.senders.CompareAndDelete(key, sender)
+	}()
+	return sender, nil
+}
+
+func (s *logDrainService) Shutdown(ctx context.Context) error {
+	close(s.quitCh)
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+func drainConfigInt(key string, defaultValue int) int {
+	value, err := config.GetInt("log:drains:" + key)
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// drainSender buffers the entries of a single app drain and sends them in
+// batches. Enqueue never blocks, entries are dropped when the queue is full
+// and failed batches are retried with a linear backoff before being dropped.
+type drainSender struct {
+	appName    string
+	drain      appTypes.LogDrain
+	sink       drainSink
+	ch         chan appTypes.Applog
+	stopCh     chan struct{}
+	stopOnce   sync.Once
+	batchSize  int
+	batchWait  time.Duration
+	maxRetries int
+	retryWait  time.Duration
+	queueGauge prometheus.Gauge
+}
+
+func newDrainSender(appName string, drain appTypes.LogDrain, sink drainSink) *drainSender {
+	return &drainSender{
+		appName:    appName,
+		drain:      drain,
+		sink:       sink,
+		ch:         make(chan appTypes.Applog, drainConfigInt("queue-size", defaultDrainQueueSize)),
+		stopCh:     make(chan struct{}),
+		batchSize:  drainConfigInt("batch-size", defaultDrainBatchSize),
+		batchWait:  defaultDrainBatchWait,
+		maxRetries: drainConfigInt("max-retries", defaultDrainMaxRetries),
+		retryWait:  defaultDrainRetryWait,
+		queueGauge: logDrainQueued.WithLabelValues(appName, drain.Name),
+	}
+}
+
+func (d *drainSender) enqueue(entry *appTypes.Applog) {
+	copied := *entry
+	if copied.Date.IsZero() {
+		copied.Date = time.Now().In(time.UTC)
+	}
+	select {
+	case d.ch <- copied:
+		d.queueGauge.Inc()
+	default:
+		logDrainDropped.WithLabelValues(string(d.drain.Type)).Inc()
+	}
+}
+
+func (d *drainSender) stop() {
+	d.stopOnce.Do(func() {
+		close(d.stopCh)
+	})
+}
+
+func (d *drainSender) run(quitCh chan struct{}) {
+	defer d.sink.Close()
+	batch := make([]appTypes.Applog, 0, d.batchSize)
+	timer := time.NewTimer(d.batchWait)
+	defer timer.Stop()
+	idle := time.NewTimer(drainSenderIdleInterval)
+	defer idle.Stop()
+	for {
+		select {
+		case entry := <-d.ch:
+			d.queueGauge.Dec()
+			batch = append(batch, entry)
+			if len(batch) < d.batchSize {
+				continue
+			}
+		case <-timer.C:
+			timer.Reset(d.batchWait)
+			if len(batch) == 0 {
+				continue
+			}
+		case <-idle.C:
+			if len(batch) == 0 && len(d.ch) == 0 {
+				return
+			}
+			idle.Reset(drainSenderIdleInterval)
+			continue
+		case <-d.stopCh:
+			return
+		case <-quitCh:
+			d.send(batch)
+			return
+		}
+		d.send(batch)
+		batch = batch[:0]
+		idle.Reset(drainSenderIdleInterval)
+	}
+}
+
+func (d *drainSender) send(batch []appTypes.Applog) {
+	if len(batch) == 0 {
+		return
+	}
+	drainType := string(d.drain.Type)
+	var err error
+	for attempt := 0; attempt <= d.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(time.Duration(attempt) * d.retryWait):
+			case <-d.stopCh:
+				return
+			}
+		}
+		start := time.Now()
+		err = d.sink.Send(batch)
+		logDrainLatency.WithLabelValues(drainType).Observe(time.Since(start).Seconds())
+		if err == nil {
+			logDrainSent.WithLabelValues(drainType).Add(float64(len(batch)))
+			return
+		}
+		logDrainErrors.WithLabelValues(drainType).Inc()
+	}
+	logDrainDropped.WithLabelValues(drainType).Add(float64(len(batch)))
+	log.Errorf("[log drains] dropping %d entries of drain %q for %q after %d attempts: %v", len(batch), d.drain.Name, d.appName, d.maxRetries+1, err)
+}
@@ -0,0 +1,251 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package applog
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	logTypes "github.com/tsuru/tsuru/types/log"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+const (
+	drainPumpInternalKind        = "log-drains-pump"
+	defaultDrainPumpSyncInterval = time.Minute
+)
+
+var (
+	_ appTypes.AppLogService         = &drainForwarder{}
+	_ appTypes.AppLogServiceInstance = &drainForwarder{}
+)
+
+// drainForwarder feeds the log drains with every entry added to the app log
+// service, regardless of the backend storing them.
+type drainForwarder struct {
+	appTypes.AppLogService
+}
+
+func newDrainForwarder(logService appTypes.AppLogService) appTypes.AppLogService {
+	return &drainForwarder{AppLogService: logService}
+}
+
+func (f *drainForwarder) Add(appName, message, source, unit string) error {
+	if servicemanager.LogDrain != nil {
+		now := time.Now().In(time.UTC)
+		for _, msg := range strings.Split(message, "\n") {
+			if msg != "" {
+				servicemanager.LogDrain.Forward(&appTypes.Applog{
+					Date:    now,
+					Message: msg,
+					Source:  source,
+					Name:    appName,
+					Unit:    unit,
+				})
+			}
+		}
+	}
+	return f.AppLogService.Add(appName, message, source, unit)
+}
+
+func (f *drainForwarder) Enqueue(entry *appTypes.Applog) error {
+	if servicemanager.LogDrain != nil {
+		servicemanager.LogDrain.Forward(entry)
+	}
+	return f.AppLogService.Enqueue(entry)
+}
+
+func (f *drainForwarder) Instance() appTypes.AppLogService {
+	if svcInstance, ok := f.AppLogService.(appTypes.AppLogServiceInstance); ok {
+		return &drainForwarder{AppLogService: svcInstance.Instance()}
+	}
+	return f
+}
+
+// InitializeProvisionerDrains starts forwarding the logs of apps in pools
+// using the provisioner log stack, which never reach the app log service,
+// to their drains. Only one API instance forwards these logs at a time, as
+// it holds a running internal event while doing so, otherwise each entry
+// would be delivered once per API instance.
+func InitializeProvisionerDrains() error {
+	if servicemanager.LogDrain == nil {
+		return nil
+	}
+	p := newDrainPump(servicemanager.LogDrain)
+	go p.run()
+	shutdown.Register(p)
+	return nil
+}
+
+type drainPump struct {
+	drains            appTypes.LogDrainService
+	provisionerGetter logsProvisionerGetter
+	interval          time.Duration
+	mu                sync.Mutex
+	watchers          map[string]appTypes.LogWatcher
+	stopCh            chan struct{}
+	doneCh            chan struct{}
+}
+
+func newDrainPump(drains appTypes.LogDrainService) *drainPump {
+	p := &drainPump{
+		drains:            drains,
+		provisionerGetter: defaultLogsProvisionerGetter,
+		watchers:          map[string]appTypes.LogWatcher{},
+		stopCh:            make(chan struct{}),
+		doneCh:            make(chan struct{}),
+	}
+	p.interval, _ = config.GetDuration("log:drains:provisioner-sync-interval")
+	if p.interval <= 0 {
+		p.interval = defaultDrainPumpSyncInterval
+	}
+	return p
+}
+
+func (p *drainPump) run() {
+	defer close(p.doneCh)
+	ctx := context.Background()
+	var evt *event.Event
+	defer func() {
+		p.closeWatchers(nil)
+		if evt != nil {
+			evt.Done(ctx, nil)
+		}
+	}()
+	for {
+		if evt == nil {
+			var err error
+			evt, err = p.acquire(ctx)
+			if err != nil {
+				log.Errorf("[log drains] %v", err)
+			}
+		}
+		if evt != nil {
+			p.sync(ctx)
+		}
+		select {
+		case <-p.stopCh:
+			return
+		case <-time.After(p.interval):
+		}
+	}
+}
+
+// acquire returns the running event guarding the forwarding of provisioner
+// logs, or nil when another API instance already holds it.
+func (p *drainPump) acquire(ctx context.Context) (*event.Event, error) {
+	evt, err := event.NewInternal(ctx, &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeGlobal, Value: drainPumpInternalKind},
+		InternalKind: drainPumpInternalKind,
+		Allowed:      event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxGlobal, "")),
+	})
+	if err != nil {
+		if _, isLocked := err.(event.ErrEventLocked); isLocked {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "could not create event")
+	}
+	return evt, nil
+}
+
+// sync watches the provisioner logs of every app with drains, and stops
+// watching apps that no longer have any.
+func (p *drainPump) sync(ctx context.Context) {
+	apps, err := servicemanager.App.List(ctx, nil)
+	if err != nil {
+		log.Errorf("[log drains] unable to list apps: %v", err)
+		return
+	}
+	active := map[string]bool{}
+	for _, a := range apps {
+		name := a.GetName()
+		drains, err := p.drains.List(ctx, name)
+		if err != nil {
+			log.Errorf("[log drains] unable to list drains for %q: %v", name, err)
+			continue
+		}
+		if len(drains) == 0 {
+			continue
+		}
+		logsProvisioner, err := p.provisionerGetter(ctx, a)
+		if err != nil {
+			if err != provision.ErrLogsUnavailable {
+				log.Errorf("[log drains] unable to get provisioner for %q: %v", name, err)
+			}
+			continue
+		}
+		active[name] = true
+		p.mu.Lock()
+		_, watching := p.watchers[name]
+		p.mu.Unlock()
+		if watching {
+			continue
+		}
+		watcher, err := logsProvisioner.WatchLogs(ctx, a, appTypes.ListLogArgs{Name: name, Type: logTypes.LogTypeApp})
+		if err != nil {
+			if err != provision.ErrLogsUnavailable {
+				log.Errorf("[log drains] unable to watch logs for %q: %v", name, err)
+			}
+			continue
+		}
+		p.mu.Lock()
+		p.watchers[name] = watcher
+		p.mu.Unlock()
+		go p.forward(name, watcher)
+	}
+	p.closeWatchers(active)
+}
+
+func (p *drainPump) forward(appName string, watcher appTypes.LogWatcher) {
+	for entry := range watcher.Chan() {
+		entry.Name = appName
+		p.drains.Forward(&entry)
+	}
+	// the watcher is started again in the next sync when it's closed by
+	// the provisioner.
+	p.mu.Lock()
+	if p.watchers[appName] == watcher {
+		delete(p.watchers, appName)
+	}
+	p.mu.Unlock()
+}
+
+// closeWatchers closes the watchers of apps not in keep.
+func (p *drainPump) closeWatchers(keep map[string]bool) {
+	var toClose []appTypes.LogWatcher
+	p.mu.Lock()
+	for name, watcher := range p.watchers {
+		if !keep[name] {
+			toClose = append(toClose, watcher)
+			delete(p.watchers, name)
+		}
+	}
+	p.mu.Unlock()
+	for _, watcher := range toClose {
+		watcher.Close()
+	}
+}
+
+func (p *drainPump) Shutdown(ctx context.Context) error {
+	close(p.stopCh)
+	select {
+	case <-p.doneCh:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
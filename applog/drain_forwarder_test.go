@@ -0,0 +1,89 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package applog
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	logTypes "github.com/tsuru/tsuru/types/log"
+	check "gopkg.in/check.v1"
+)
+
+func waitDrainEntries(c *check.C, sink *fakeDrainSink) []appTypes.Applog {
+	select {
+	case <-sink.sent:
+	case <-time.After(5 * time.Second):
+		c.Fatal("timeout waiting for drain")
+	}
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	return sink.entries
+}
+
+func (s *S) TestDrainForwarderEnqueue(c *check.C) {
+	sink := &fakeDrainSink{sent: make(chan struct{}, 10)}
+	drains := newTestLogDrainService(&fakeLogDrainStorage{}, sink)
+	defer drains.Shutdown(context.Background())
+	err := drains.Create(context.TODO(), appTypes.LogDrain{Name: "d1", App: "myapp", Type: appTypes.LogDrainTypeHTTP, URL: "http://a.com"})
+	c.Assert(err, check.IsNil)
+	oldLogDrain := servicemanager.LogDrain
+	servicemanager.LogDrain = drains
+	defer func() { servicemanager.LogDrain = oldLogDrain }()
+	memory, err := memoryAppLogService()
+	c.Assert(err, check.IsNil)
+	svc := newDrainForwarder(memory)
+	date := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	err = svc.Enqueue(&appTypes.Applog{Name: "myapp", Source: "web", Message: "hello", Date: date})
+	c.Assert(err, check.IsNil)
+	c.Assert(waitDrainEntries(c, sink), check.DeepEquals, []appTypes.Applog{
+		{Name: "myapp", Source: "web", Message: "hello", Date: date},
+	})
+	logs, err := memory.List(context.TODO(), appTypes.ListLogArgs{Name: "myapp"})
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 1)
+}
+
+func (s *S) TestDrainPumpSyncProvisionerLogs(c *check.C) {
+	sink := &fakeDrainSink{sent: make(chan struct{}, 10)}
+	drains := newTestLogDrainService(&fakeLogDrainStorage{}, sink)
+	defer drains.Shutdown(context.Background())
+	err := drains.Create(context.TODO(), appTypes.LogDrain{Name: "d1", App: "myapp", Type: appTypes.LogDrainTypeHTTP, URL: "http://a.com"})
+	c.Assert(err, check.IsNil)
+	oldApp := servicemanager.App
+	servicemanager.App = &appTypes.MockAppService{
+		Apps: []appTypes.AppInterface{
+			&appTypes.MockApp{Name: "myapp", Pool: "mypool"},
+			&appTypes.MockApp{Name: "nodrains", Pool: "mypool"},
+		},
+	}
+	defer func() { servicemanager.App = oldApp }()
+	provisioner := provisiontest.NewFakeProvisioner()
+	provisioner.LogsEnabled = true
+	p := newDrainPump(drains)
+	p.provisionerGetter = func(ctx context.Context, obj logTypes.LogabbleObject) (provision.LogsProvisioner, error) {
+		return provisioner, nil
+	}
+	p.sync(context.TODO())
+	defer p.closeWatchers(nil)
+	entries := waitDrainEntries(c, sink)
+	c.Assert(entries, check.HasLen, 1)
+	c.Assert(entries[0].Name, check.Equals, "myapp")
+	c.Assert(entries[0].Message, check.Equals, "Fake message from provisioner")
+	p.mu.Lock()
+	c.Assert(p.watchers, check.HasLen, 1)
+	c.Assert(p.watchers["myapp"], check.NotNil)
+	p.mu.Unlock()
+	err = drains.Delete(context.TODO(), "myapp", "d1")
+	c.Assert(err, check.IsNil)
+	p.sync(context.TODO())
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	c.Assert(p.watchers, check.HasLen, 0)
+}
@@ -0,0 +1,281 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package applog
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	tsuruNet "github.com/tsuru/tsuru/net"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+const (
+	syslogDialTimeout  = 15 * time.Second
+	syslogWriteTimeout = 30 * time.Second
+	// syslogPriority is facility user (1) and severity info (6).
+	syslogPriority = 14
+)
+
+type drainSink interface {
+	Send(entries []appTypes.Applog) error
+	Close() error
+}
+
+func newDrainSink(drain appTypes.LogDrain) (drainSink, error) {
+	switch drain.Type {
+	case appTypes.LogDrainTypeSyslog:
+		return newSyslogSink(drain)
+	case appTypes.LogDrainTypeHTTP:
+		return newHTTPSink(drain, encodeHTTPEntries, "application/json")
+	case appTypes.LogDrainTypeOTLP:
+		return newHTTPSink(drain, encodeOTLPEntries, "application/json")
+	}
+	return nil, errors.Errorf("invalid log drain type %q", drain.Type)
+}
+
+type httpSink struct {
+	drain       appTypes.LogDrain
+	client      *http.Client
+	encode      func(entries []appTypes.Applog) ([]byte, error)
+	contentType string
+}
+
+func newHTTPSink(drain appTypes.LogDrain, encode func([]appTypes.Applog) ([]byte, error), contentType string) (*httpSink, error) {
+	client := *tsuruNet.Dial15Full60ClientNoKeepAlive
+	if drain.Insecure {
+		client = *tsuruNet.Dial15Full60ClientNoKeepAliveInsecure
+	}
+	proxyClient, err := tsuruNet.WithProxyFromConfig(client, drain.URL)
+	if err != nil {
+		return nil, err
+	}
+	return &httpSink{
+		drain:       drain,
+		client:      proxyClient,
+		encode:      encode,
+		contentType: contentType,
+	}, nil
+}
+
+func (s *httpSink) Send(entries []appTypes.Applog) error {
+	body, err := s.encode(entries)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.drain.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range s.drain.Headers {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", s.contentType)
+	rsp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode < 200 || rsp.StatusCode >= 400 {
+		data, _ := io.ReadAll(io.LimitReader(rsp.Body, 1024))
+		return errors.Errorf("invalid status code calling log drain %q: %d - %s", s.drain.Name, rsp.StatusCode, string(data))
+	}
+	return nil
+}
+
+func (s *httpSink) Close() error {
+	return nil
+}
+
+type httpDrainEntry struct {
+	Date    time.Time `json:"date"`
+	App     string    `json:"app"`
+	Source  string    `json:"source"`
+	Unit    string    `json:"unit"`
+	Message string    `json:"message"`
+}
+
+func encodeHTTPEntries(entries []appTypes.Applog) ([]byte, error) {
+	data := make([]httpDrainEntry, len(entries))
+	for i, entry := range entries {
+		data[i] = httpDrainEntry{
+			Date:    entry.Date,
+			App:     entry.Name,
+			Source:  entry.Source,
+			Unit:    entry.Unit,
+			Message: entry.Message,
+		}
+	}
+	return json.Marshal(data)
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano   string          `json:"timeUnixNano"`
+	SeverityNumber int             `json:"severityNumber"`
+	SeverityText   string          `json:"severityText"`
+	Body           otlpValue       `json:"body"`
+	Attributes     []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeLogs struct {
+	Scope struct {
+		Name string `json:"name"`
+	} `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpResourceLogs struct {
+	Resource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	} `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpLogsRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+// encodeOTLPEntries encodes the entries as an OTLP/HTTP JSON
+// ExportLogsServiceRequest, grouping them by app in resources.
+func encodeOTLPEntries(entries []appTypes.Applog) ([]byte, error) {
+	var req otlpLogsRequest
+	resourceIdx := map[string]int{}
+	for _, entry := range entries {
+		idx, ok := resourceIdx[entry.Name]
+		if !ok {
+			var resource otlpResourceLogs
+			resource.Resource.Attributes = []otlpAttribute{
+				{Key: "service.name", Value: otlpValue{StringValue: entry.Name}},
+			}
+			scope := otlpScopeLogs{}
+			scope.Scope.Name = "tsuru"
+			resource.ScopeLogs = []otlpScopeLogs{scope}
+			req.ResourceLogs = append(req.ResourceLogs, resource)
+			idx = len(req.ResourceLogs) - 1
+			resourceIdx[entry.Name] = idx
+		}
+		scope := &req.ResourceLogs[idx].ScopeLogs[0]
+		scope.LogRecords = append(scope.LogRecords, otlpLogRecord{
+			TimeUnixNano:   strconv.FormatInt(entry.Date.UnixNano(), 10),
+			SeverityNumber: 9,
+			SeverityText:   "INFO",
+			Body:           otlpValue{StringValue: entry.Message},
+			Attributes: []otlpAttribute{
+				{Key: "tsuru.unit", Value: otlpValue{StringValue: entry.Unit}},
+				{Key: "tsuru.source", Value: otlpValue{StringValue: entry.Source}},
+			},
+		})
+	}
+	return json.Marshal(req)
+}
+
+// syslogSink sends entries as RFC5424 messages using octet counting framing
+// (RFC6587), the connection is lazily created and recreated after errors.
+type syslogSink struct {
+	drain   appTypes.LogDrain
+	network string
+	address string
+	mu      sync.Mutex
+	conn    net.Conn
+}
+
+func newSyslogSink(drain appTypes.LogDrain) (*syslogSink, error) {
+	u, err := url.Parse(drain.URL)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogSink{
+		drain:   drain,
+		network: u.Scheme,
+		address: u.Host,
+	}, nil
+}
+
+func (s *syslogSink) connect() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: syslogDialTimeout}
+	if s.network == "tls" {
+		host, _, _ := net.SplitHostPort(s.address)
+		return tls.DialWithDialer(dialer, "tcp", s.address, &tls.Config{
+			ServerName:         host,
+			InsecureSkipVerify: s.drain.Insecure,
+		})
+	}
+	return dialer.Dial("tcp", s.address)
+}
+
+func (s *syslogSink) Send(entries []appTypes.Applog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		conn, err := s.connect()
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		msg := formatSyslogMessage(entry)
+		fmt.Fprintf(&buf, "%d %s", len(msg), msg)
+	}
+	s.conn.SetWriteDeadline(time.Now().Add(syslogWriteTimeout))
+	_, err := s.conn.Write(buf.Bytes())
+	if err != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+	return err
+}
+
+func (s *syslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+func syslogField(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return strings.ReplaceAll(value, " ", "_")
+}
+
+// formatSyslogMessage formats the entry as a RFC5424 message, using the app
+// name as hostname, the log source as app-name and the unit as procid.
+func formatSyslogMessage(entry appTypes.Applog) string {
+	return fmt.Sprintf("<%d>1 %s %s %s %s - - %s",
+		syslogPriority,
+		entry.Date.UTC().Format(time.RFC3339Nano),
+		syslogField(entry.Name),
+		syslogField(entry.Source),
+		syslogField(entry.Unit),
+		entry.Message,
+	)
+}
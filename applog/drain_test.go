@@ -0,0 +1,228 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package applog
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/tsuru/config"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+type fakeLogDrainStorage struct {
+	drains []appTypes.LogDrain
+}
+
+func (f *fakeLogDrainStorage) Insert(ctx context.Context, drain appTypes.LogDrain) error {
+	f.drains = append(f.drains, drain)
+	return nil
+}
+
+func (f *fakeLogDrainStorage) Delete(ctx context.Context, appName, name string) error {
+	for i, d := range f.drains {
+		if d.App == appName && d.Name == name {
+			f.drains = append(f.drains[:i], f.drains[i+1:]...)
+			return nil
+		}
+	}
+	return appTypes.ErrLogDrainNotFound
+}
+
+func (f *fakeLogDrainStorage) FindByApp(ctx context.Context, appName string) ([]appTypes.LogDrain, error) {
+	var result []appTypes.LogDrain
+	for _, d := range f.drains {
+		if d.App == appName {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+type fakeDrainSink struct {
+	mu      sync.Mutex
+	entries []appTypes.Applog
+	sent    chan struct{}
+}
+
+func (f *fakeDrainSink) Send(entries []appTypes.Applog) error {
+	f.mu.Lock()
+	f.entries = append(f.entries, entries...)
+	f.mu.Unlock()
+	f.sent <- struct{}{}
+	return nil
+}
+
+func (f *fakeDrainSink) Close() error {
+	return nil
+}
+
+func newTestLogDrainService(storage appTypes.LogDrainStorage, sink drainSink) *logDrainService {
+	return &logDrainService{
+		storage: storage,
+		poolOf: func(ctx context.Context, appName string) (string, error) {
+			return "pool1", nil
+		},
+		quitCh: make(chan struct{}),
+		newSender: func(drain appTypes.LogDrain) (drainSink, error) {
+			return sink, nil
+		},
+	}
+}
+
+func (s *S) TestLogDrainServiceCreateValidation(c *check.C) {
+	svc := newTestLogDrainService(&fakeLogDrainStorage{}, nil)
+	tests := []struct {
+		drain appTypes.LogDrain
+		msg   string
+	}{
+		{appTypes.LogDrain{Type: "http", URL: "http://a.com"}, "log drain name must not be empty"},
+		{appTypes.LogDrain{Name: "d1", Type: "kafka", URL: "http://a.com"}, `invalid log drain type "kafka", valid types are: syslog, http or otlp`},
+		{appTypes.LogDrain{Name: "d1", Type: "http", URL: "tcp://a.com:514"}, "http drain url scheme must be http or https"},
+		{appTypes.LogDrain{Name: "d1", Type: "syslog", URL: "https://a.com"}, "syslog drain url scheme must be tcp or tls"},
+		{appTypes.LogDrain{Name: "d1", Type: "otlp", URL: "not a url"}, `log drain url "not a url" is not valid`},
+	}
+	for _, tt := range tests {
+		err := svc.Create(context.TODO(), tt.drain)
+		c.Assert(err, check.DeepEquals, &tsuruErrors.ValidationError{Message: tt.msg})
+	}
+}
+
+func (s *S) TestLogDrainServiceListPoolDefaults(c *check.C) {
+	config.Set("log:drains:pools:pool1", []interface{}{
+		map[interface{}]interface{}{"name": "default", "type": "http", "url": "http://logs.example.com"},
+	})
+	defer config.Unset("log:drains")
+	storage := &fakeLogDrainStorage{}
+	svc := newTestLogDrainService(storage, nil)
+	drains, err := svc.List(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(drains, check.DeepEquals, []appTypes.LogDrain{
+		{Name: "default", Pool: "pool1", Type: appTypes.LogDrainTypeHTTP, URL: "http://logs.example.com"},
+	})
+	err = svc.Create(context.TODO(), appTypes.LogDrain{Name: "mine", App: "myapp", Type: appTypes.LogDrainTypeHTTP, URL: "http://mine.example.com"})
+	c.Assert(err, check.IsNil)
+	drains, err = svc.List(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(drains, check.DeepEquals, []appTypes.LogDrain{
+		{Name: "mine", App: "myapp", Type: appTypes.LogDrainTypeHTTP, URL: "http://mine.example.com"},
+	})
+}
+
+func (s *S) TestLogDrainServiceForward(c *check.C) {
+	storage := &fakeLogDrainStorage{}
+	sink := &fakeDrainSink{sent: make(chan struct{}, 10)}
+	svc := newTestLogDrainService(storage, sink)
+	defer svc.Shutdown(context.Background())
+	err := svc.Create(context.TODO(), appTypes.LogDrain{Name: "d1", App: "myapp", Type: appTypes.LogDrainTypeHTTP, URL: "http://a.com", Sources: []string{"web"}})
+	c.Assert(err, check.IsNil)
+	date := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	svc.Forward(&appTypes.Applog{Name: "myapp", Source: "worker", Message: "ignored", Date: date})
+	svc.Forward(&appTypes.Applog{Name: "myapp", Source: "web", Message: "hello", Date: date})
+	svc.Forward(&appTypes.Applog{Name: "otherapp", Source: "web", Message: "other", Date: date})
+	select {
+	case <-sink.sent:
+	case <-time.After(5 * time.Second):
+		c.Fatal("timeout waiting for drain")
+	}
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	c.Assert(sink.entries, check.DeepEquals, []appTypes.Applog{
+		{Name: "myapp", Source: "web", Message: "hello", Date: date},
+	})
+}
+
+func (s *S) TestDrainSenderDropsWhenFull(c *check.C) {
+	sender := newDrainSender("myapp", appTypes.LogDrain{Name: "d1", Type: appTypes.LogDrainTypeHTTP}, &fakeDrainSink{})
+	sender.ch = make(chan appTypes.Applog, 1)
+	sender.enqueue(&appTypes.Applog{Message: "1"})
+	sender.enqueue(&appTypes.Applog{Message: "2"})
+	c.Assert(sender.ch, check.HasLen, 1)
+	entry := <-sender.ch
+	c.Assert(entry.Message, check.Equals, "1")
+	c.Assert(entry.Date.IsZero(), check.Equals, false)
+}
+
+func (s *S) TestHTTPSinkSend(c *check.C) {
+	var body []byte
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header
+	}))
+	defer srv.Close()
+	sink, err := newDrainSink(appTypes.LogDrain{
+		Name:    "d1",
+		Type:    appTypes.LogDrainTypeHTTP,
+		URL:     srv.URL,
+		Headers: http.Header{"Authorization": []string{"Bearer abc"}},
+	})
+	c.Assert(err, check.IsNil)
+	date := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	err = sink.Send([]appTypes.Applog{{Name: "myapp", Source: "web", Unit: "u1", Message: "hello", Date: date}})
+	c.Assert(err, check.IsNil)
+	c.Assert(header.Get("Authorization"), check.Equals, "Bearer abc")
+	c.Assert(header.Get("Content-Type"), check.Equals, "application/json")
+	c.Assert(string(body), check.Equals, `[{"date":"2026-01-02T03:04:05Z","app":"myapp","source":"web","unit":"u1","message":"hello"}]`)
+}
+
+func (s *S) TestHTTPSinkSendInvalidStatus(c *check.C) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("boom"))
+	}))
+	defer srv.Close()
+	sink, err := newDrainSink(appTypes.LogDrain{Name: "d1", Type: appTypes.LogDrainTypeHTTP, URL: srv.URL})
+	c.Assert(err, check.IsNil)
+	err = sink.Send([]appTypes.Applog{{Name: "myapp", Message: "hello"}})
+	c.Assert(err, check.ErrorMatches, `invalid status code calling log drain "d1": 500 - boom`)
+}
+
+func (s *S) TestEncodeOTLPEntries(c *check.C) {
+	date := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	data, err := encodeOTLPEntries([]appTypes.Applog{
+		{Name: "myapp", Source: "web", Unit: "u1", Message: "hello", Date: date},
+		{Name: "myapp", Source: "worker", Unit: "u2", Message: "world", Date: date},
+	})
+	c.Assert(err, check.IsNil)
+	var req map[string]interface{}
+	err = json.Unmarshal(data, &req)
+	c.Assert(err, check.IsNil)
+	resources := req["resourceLogs"].([]interface{})
+	c.Assert(resources, check.HasLen, 1)
+	resource := resources[0].(map[string]interface{})
+	c.Assert(resource["resource"], check.DeepEquals, map[string]interface{}{
+		"attributes": []interface{}{
+			map[string]interface{}{"key": "service.name", "value": map[string]interface{}{"stringValue": "myapp"}},
+		},
+	})
+	records := resource["scopeLogs"].([]interface{})[0].(map[string]interface{})["logRecords"].([]interface{})
+	c.Assert(records, check.HasLen, 2)
+	c.Assert(records[1], check.DeepEquals, map[string]interface{}{
+		"timeUnixNano":   "1767323045000000000",
+		"severityNumber": float64(9),
+		"severityText":   "INFO",
+		"body":           map[string]interface{}{"stringValue": "world"},
+		"attributes": []interface{}{
+			map[string]interface{}{"key": "tsuru.unit", "value": map[string]interface{}{"stringValue": "u2"}},
+			map[string]interface{}{"key": "tsuru.source", "value": map[string]interface{}{"stringValue": "worker"}},
+		},
+	})
+}
+
+func (s *S) TestFormatSyslogMessage(c *check.C) {
+	date := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	msg := formatSyslogMessage(appTypes.Applog{Name: "myapp", Source: "web", Unit: "u1", Message: "hello world", Date: date})
+	c.Assert(msg, check.Equals, "<14>1 2026-01-02T03:04:05Z myapp web u1 - - hello world")
+	msg = formatSyslogMessage(appTypes.Applog{Name: "myapp", Message: "hello", Date: date})
+	c.Assert(msg, check.Equals, "<14>1 2026-01-02T03:04:05Z myapp - - - - hello")
+}
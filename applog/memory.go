@@ -112,11 +112,19 @@ func (s *memoryLogService) List(ctx context.Context, args appTypes.ListLogArgs)
 	if args.Limit < 0 {
 		return []appTypes.Applog{}, nil
 	}
+	matcher, err := appTypes.NewLogMatcher(args)
+	if err != nil {
+		return nil, err
+	}
 	buffer := s.getAppBuffer(args.Name)
-	return buffer.list(args), nil
+	return buffer.list(args, matcher), nil
 }
 
 func (s *memoryLogService) Watch(ctx context.Context, args appTypes.ListLogArgs) (appTypes.LogWatcher, error) {
+	matcher, err := appTypes.NewLogMatcher(args)
+	if err != nil {
+		return nil, err
+	}
 	buffer := s.getAppBuffer(args.Name)
 	watcher := &memoryWatcher{
 		buffer:     buffer,
@@ -125,6 +133,7 @@ func (s *memoryLogService) Watch(ctx context.Context, args appTypes.ListLogArgs)
 		wg:         &sync.WaitGroup{},
 		nextNotify: time.NewTimer(0),
 		filter:     args,
+		matcher:    matcher,
 		unitsSet:   set.FromSlice(args.Units),
 	}
 	buffer.addWatcher(watcher)
@@ -179,7 +188,7 @@ type appLogBuffer struct {
 	lengthGauge     prometheus.Gauge
 }
 
-func (b *appLogBuffer) list(args appTypes.ListLogArgs) []appTypes.Applog {
+func (b *appLogBuffer) list(args appTypes.ListLogArgs, matcher *appTypes.LogMatcher) []appTypes.Applog {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 	if b.length == 0 {
@@ -193,7 +202,8 @@ func (b *appLogBuffer) list(args appTypes.ListLogArgs) []appTypes.Applog {
 	unitsSet := set.FromSlice(args.Units)
 	for current := b.end; count < args.Limit; {
 		if (args.Source == "" || (args.Source == current.log.Source) != args.InvertSource) &&
-			(len(args.Units) == 0 || unitsSet.Includes(current.log.Unit)) {
+			(len(args.Units) == 0 || unitsSet.Includes(current.log.Unit)) &&
+			matcher.Match(current.log) {
 
 			logs[len(logs)-count-1] = *current.log
 			count++
@@ -279,6 +289,7 @@ type memoryWatcher struct {
 	wg         *sync.WaitGroup
 	nextNotify *time.Timer
 	filter     appTypes.ListLogArgs
+	matcher    *appTypes.LogMatcher
 	unitsSet   set.Set
 }
 
@@ -289,6 +300,9 @@ func (w *memoryWatcher) notify(entry *appTypes.Applog, dropCounter prometheus.Co
 	if len(w.filter.Units) > 0 && !w.unitsSet.Includes(entry.Unit) {
 		return
 	}
+	if !w.matcher.Match(entry) {
+		return
+	}
 	select {
 	case w.ch <- *entry:
 	default:
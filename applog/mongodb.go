@@ -0,0 +1,502 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package applog
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/log"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	defaultLogRetention     = 7 * 24 * time.Hour
+	defaultLogWatchInterval = time.Second
+	logWatchLag             = 5 * time.Second
+	poolCacheTTL            = time.Minute
+	logMongoDBSubsystem     = "logs_mongodb"
+	defaultLogQueueSize     = 10000
+	defaultLogBatchSize     = 500
+	defaultLogBatchWait     = time.Second
+	defaultLogMaxScan       = 10000
+)
+
+var (
+	logsMongoDBWritten = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: promNamespace,
+		Subsystem: logMongoDBSubsystem,
+		Name:      "written_total",
+		Help:      "The number of log entries written to mongodb.",
+	}, []string{"app"})
+
+	logsMongoDBFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: promNamespace,
+		Subsystem: logMongoDBSubsystem,
+		Name:      "write_failures_total",
+		Help:      "The number of failures writing log entries to mongodb.",
+	})
+
+	logsMongoDBDropped = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: promNamespace,
+		Subsystem: logMongoDBSubsystem,
+		Name:      "dropped_total",
+		Help:      "The number of log entries dropped due to a full write queue.",
+	})
+
+	logsMongoDBQueued = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: promNamespace,
+		Subsystem: logMongoDBSubsystem,
+		Name:      "queue_size",
+		Help:      "The number of log entries waiting to be written to mongodb.",
+	})
+
+	logsMongoDBDroppedWatch = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: promNamespace,
+		Subsystem: logMongoDBSubsystem,
+		Name:      "watch_dropped_total",
+		Help:      "The number of messages dropped in watchers due to a slow client.",
+	}, []string{"app"})
+
+	logsMongoDBWatchers = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: promNamespace,
+		Subsystem: logMongoDBSubsystem,
+		Name:      "watchers",
+		Help:      "The number of active log watchers polling mongodb.",
+	})
+)
+
+// mongoLogEntry is the document stored for each log entry, ExpireAt is
+// computed on write based on the retention of the pool of the app and is
+// used by a TTL index to remove old entries.
+type mongoLogEntry struct {
+	appTypes.Applog `bson:",inline"`
+	ExpireAt        time.Time `bson:"expireat"`
+}
+
+type poolCacheEntry struct {
+	pool     string
+	cachedAt time.Time
+}
+
+// mongodbLogService stores app logs in the database, making them durable
+// across restarts and visible to every API instance. Watchers poll the
+// collection, so logs written by any instance are delivered to them.
+// Enqueued entries are buffered and written in batches by a single writer,
+// entries are dropped when the queue is full.
+type mongodbLogService struct {
+	watchInterval time.Duration
+	poolCache     sync.Map
+	poolGetter    func(ctx context.Context, entry *appTypes.Applog) (string, error)
+	ch            chan *appTypes.Applog
+	batchSize     int
+	batchWait     time.Duration
+	maxScan       int
+	startOnce     sync.Once
+	stopOnce      sync.Once
+	quitCh        chan struct{}
+	doneCh        chan struct{}
+}
+
+func mongodbAppLogService() (appTypes.AppLogService, error) {
+	watchInterval, _ := config.GetDuration("log:mongodb:watch-interval")
+	if watchInterval <= 0 {
+		watchInterval = defaultLogWatchInterval
+	}
+	s := newMongodbLogService(watchInterval, logEntryPool)
+	shutdown.Register(s)
+	return s, nil
+}
+
+func newMongodbLogService(watchInterval time.Duration, poolGetter func(ctx context.Context, entry *appTypes.Applog) (string, error)) *mongodbLogService {
+	return &mongodbLogService{
+		watchInterval: watchInterval,
+		poolGetter:    poolGetter,
+		ch:            make(chan *appTypes.Applog, mongodbConfigInt("queue-size", defaultLogQueueSize)),
+		batchSize:     mongodbConfigInt("batch-size", defaultLogBatchSize),
+		batchWait:     defaultLogBatchWait,
+		maxScan:       mongodbConfigInt("max-scanned-entries", defaultLogMaxScan),
+		quitCh:        make(chan struct{}),
+		doneCh:        make(chan struct{}),
+	}
+}
+
+func mongodbConfigInt(key string, defaultValue int) int {
+	value, err := config.GetInt("log:mongodb:" + key)
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+func logEntryPool(ctx context.Context, entry *appTypes.Applog) (string, error) {
+	obj, err := defineLogabbleObject(ctx, entry.Type, entry.Name)
+	if err != nil {
+		return "", err
+	}
+	return obj.GetPool(), nil
+}
+
+// logRetention returns the retention for logs of apps in the given pool,
+// configured in log:mongodb:pools:<pool>:retention with fallback to
+// log:mongodb:retention.
+func logRetention(pool string) time.Duration {
+	if pool != "" {
+		retention, err := config.GetDuration("log:mongodb:pools:" + pool + ":retention")
+		if err == nil && retention > 0 {
+			return retention
+		}
+	}
+	retention, err := config.GetDuration("log:mongodb:retention")
+	if err == nil && retention > 0 {
+		return retention
+	}
+	return defaultLogRetention
+}
+
+func (s *mongodbLogService) expireAt(entry *appTypes.Applog) time.Time {
+	var pool string
+	cached, ok := s.poolCache.Load(entry.Name)
+	if ok && time.Since(cached.(poolCacheEntry).cachedAt) < poolCacheTTL {
+		pool = cached.(poolCacheEntry).pool
+	} else {
+		var err error
+		pool, err = s.poolGetter(context.Background(), entry)
+		if err == nil {
+			s.poolCache.Store(entry.Name, poolCacheEntry{pool: pool, cachedAt: time.Now()})
+		}
+	}
+	return entry.Date.Add(logRetention(pool))
+}
+
+func (s *mongodbLogService) insert(entries ...*appTypes.Applog) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	collection, err := storagev2.AppLogsCollection()
+	if err != nil {
+		return err
+	}
+	docs := make([]interface{}, len(entries))
+	for i, entry := range entries {
+		if entry.Date.IsZero() {
+			entry.Date = time.Now().In(time.UTC)
+		}
+		docs[i] = mongoLogEntry{Applog: *entry, ExpireAt: s.expireAt(entry)}
+	}
+	_, err = collection.InsertMany(context.Background(), docs, options.InsertMany().SetOrdered(true))
+	if err != nil {
+		logsMongoDBFailures.Inc()
+		return errors.Wrap(err, "unable to write logs")
+	}
+	for _, entry := range entries {
+		logsMongoDBWritten.WithLabelValues(entry.Name).Inc()
+	}
+	return nil
+}
+
+func (s *mongodbLogService) Enqueue(entry *appTypes.Applog) error {
+	s.startOnce.Do(func() {
+		go s.run()
+	})
+	copied := *entry
+	if copied.Date.IsZero() {
+		copied.Date = time.Now().In(time.UTC)
+	}
+	select {
+	case s.ch <- &copied:
+		logsMongoDBQueued.Inc()
+	default:
+		logsMongoDBDropped.Inc()
+	}
+	return nil
+}
+
+func (s *mongodbLogService) run() {
+	defer close(s.doneCh)
+	batch := make([]*appTypes.Applog, 0, s.batchSize)
+	timer := time.NewTimer(s.batchWait)
+	defer timer.Stop()
+	for {
+		select {
+		case entry := <-s.ch:
+			logsMongoDBQueued.Dec()
+			batch = append(batch, entry)
+			if len(batch) < s.batchSize {
+				continue
+			}
+		case <-timer.C:
+			timer.Reset(s.batchWait)
+			if len(batch) == 0 {
+				continue
+			}
+		case <-s.quitCh:
+			for len(s.ch) > 0 {
+				logsMongoDBQueued.Dec()
+				batch = append(batch, <-s.ch)
+			}
+			s.write(batch)
+			return
+		}
+		s.write(batch)
+		batch = batch[:0]
+	}
+}
+
+func (s *mongodbLogService) write(batch []*appTypes.Applog) {
+	err := s.insert(batch...)
+	if err != nil {
+		log.Errorf("[log mongodb] dropping %d entries: %v", len(batch), err)
+	}
+}
+
+// Shutdown writes the entries still queued, entries enqueued afterwards are
+// dropped.
+func (s *mongodbLogService) Shutdown(ctx context.Context) error {
+	s.stopOnce.Do(func() {
+		close(s.quitCh)
+	})
+	s.startOnce.Do(func() {
+		close(s.doneCh)
+	})
+	select {
+	case <-s.doneCh:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (s *mongodbLogService) Add(appName, message, source, unit string) error {
+	messages := strings.Split(message, "\n")
+	logs := make([]*appTypes.Applog, 0, len(messages))
+	now := time.Now().In(time.UTC)
+	for _, msg := range messages {
+		if msg != "" {
+			logs = append(logs, &appTypes.Applog{
+				Date:    now,
+				Message: msg,
+				Source:  source,
+				Name:    appName,
+				Unit:    unit,
+			})
+		}
+	}
+	return s.insert(logs...)
+}
+
+func logsQuery(args appTypes.ListLogArgs) mongoBSON.M {
+	query := mongoBSON.M{"name": args.Name}
+	if args.Source != "" {
+		if args.InvertSource {
+			query["source"] = mongoBSON.M{"$ne": args.Source}
+		} else {
+			query["source"] = args.Source
+		}
+	}
+	if len(args.Units) > 0 {
+		query["unit"] = mongoBSON.M{"$in": args.Units}
+	}
+	dateQuery := mongoBSON.M{}
+	if !args.Since.IsZero() {
+		dateQuery["$gte"] = args.Since
+	}
+	if !args.Until.IsZero() {
+		dateQuery["$lte"] = args.Until
+	}
+	if len(dateQuery) > 0 {
+		query["date"] = dateQuery
+	}
+	var messageQuery []mongoBSON.M
+	if args.Message != "" {
+		messageQuery = append(messageQuery, mongoBSON.M{"message": mongoBSON.M{"$regex": regexp.QuoteMeta(args.Message)}})
+	}
+	if args.MessageRegex != "" {
+		messageQuery = append(messageQuery, mongoBSON.M{"message": mongoBSON.M{"$regex": args.MessageRegex}})
+	}
+	if len(messageQuery) > 0 {
+		query["$and"] = messageQuery
+	}
+	return query
+}
+
+func (s *mongodbLogService) List(ctx context.Context, args appTypes.ListLogArgs) ([]appTypes.Applog, error) {
+	if args.Name == "" {
+		return nil, errors.New("app name required to list logs")
+	}
+	if args.Limit < 0 {
+		return []appTypes.Applog{}, nil
+	}
+	collection, err := storagev2.AppLogsCollection()
+	if err != nil {
+		return nil, err
+	}
+	matcher, err := appTypes.NewLogMatcher(args)
+	if err != nil {
+		return nil, err
+	}
+	opts := options.Find().SetSort(mongoBSON.D{{Key: "date", Value: -1}, {Key: "_id", Value: -1}})
+	// field filters are applied while reading the cursor, so the limit can
+	// only be pushed to the database without them. With filters the number
+	// of scanned entries is bounded instead.
+	if len(args.Fields) > 0 {
+		opts.SetLimit(int64(s.maxScan))
+	} else if args.Limit > 0 {
+		opts.SetLimit(int64(args.Limit))
+	}
+	cursor, err := collection.Find(ctx, logsQuery(args), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	logs := []appTypes.Applog{}
+	for cursor.Next(ctx) {
+		var entry appTypes.Applog
+		err = cursor.Decode(&entry)
+		if err != nil {
+			return nil, err
+		}
+		if !matcher.Match(&entry) {
+			continue
+		}
+		logs = append(logs, entry)
+		if args.Limit > 0 && len(logs) == args.Limit {
+			break
+		}
+	}
+	if err = cursor.Err(); err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(logs)-1; i < j; i, j = i+1, j-1 {
+		logs[i], logs[j] = logs[j], logs[i]
+	}
+	return logs, nil
+}
+
+func (s *mongodbLogService) Watch(ctx context.Context, args appTypes.ListLogArgs) (appTypes.LogWatcher, error) {
+	collection, err := storagev2.AppLogsCollection()
+	if err != nil {
+		return nil, err
+	}
+	matcher, err := appTypes.NewLogMatcher(args)
+	if err != nil {
+		return nil, err
+	}
+	w := &mongodbWatcher{
+		collection: collection,
+		args:       args,
+		matcher:    matcher,
+		interval:   s.watchInterval,
+		since:      time.Now().In(time.UTC),
+		seen:       map[primitive.ObjectID]time.Time{},
+		ch:         make(chan appTypes.Applog, watchBufferSize),
+		quit:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	logsMongoDBWatchers.Inc()
+	go w.run()
+	return w, nil
+}
+
+// mongodbWatcher polls the logs collection for new entries. As entries may be
+// written by other API instances with slightly skewed dates, each poll looks
+// back logWatchLag and skips entries already delivered.
+type mongodbWatcher struct {
+	collection *mongo.Collection
+	args       appTypes.ListLogArgs
+	matcher    *appTypes.LogMatcher
+	interval   time.Duration
+	since      time.Time
+	seen       map[primitive.ObjectID]time.Time
+	ch         chan appTypes.Applog
+	quit       chan struct{}
+	done       chan struct{}
+	closeOnce  sync.Once
+}
+
+func (w *mongodbWatcher) run() {
+	defer close(w.done)
+	defer close(w.ch)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.quit:
+			return
+		case <-ticker.C:
+		}
+		err := w.poll()
+		if err != nil {
+			log.Errorf("[log watcher] unable to poll logs for %q: %v", w.args.Name, err)
+		}
+	}
+}
+
+func (w *mongodbWatcher) poll() error {
+	args := w.args
+	args.Since = w.since.Add(-logWatchLag)
+	args.Until = time.Time{}
+	ctx, cancel := context.WithTimeout(context.Background(), w.interval+10*time.Second)
+	defer cancel()
+	cursor, err := w.collection.Find(ctx, logsQuery(args), options.Find().SetSort(mongoBSON.D{{Key: "date", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return err
+	}
+	var logs []appTypes.Applog
+	err = cursor.All(ctx, &logs)
+	if err != nil {
+		return err
+	}
+	for _, entry := range logs {
+		if _, ok := w.seen[entry.MongoID]; ok {
+			continue
+		}
+		w.seen[entry.MongoID] = entry.Date
+		if entry.Date.After(w.since) {
+			w.since = entry.Date
+		}
+		if !w.matcher.Match(&entry) {
+			continue
+		}
+		select {
+		case w.ch <- entry:
+		case <-w.quit:
+			return nil
+		default:
+			logsMongoDBDroppedWatch.WithLabelValues(entry.Name).Inc()
+		}
+	}
+	for id, date := range w.seen {
+		if date.Before(args.Since) {
+			delete(w.seen, id)
+		}
+	}
+	return nil
+}
+
+func (w *mongodbWatcher) Chan() <-chan appTypes.Applog {
+	return w.ch
+}
+
+func (w *mongodbWatcher) Close() {
+	w.closeOnce.Do(func() {
+		close(w.quit)
+		<-w.done
+		logsMongoDBWatchers.Dec()
+	})
+}
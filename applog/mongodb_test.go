@@ -0,0 +1,113 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package applog
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db/storagev2"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"gopkg.in/check.v1"
+)
+
+var _ = check.Suite(&ServiceSuite{svcFunc: testMongodbAppLogService})
+
+func testMongodbAppLogService() (appTypes.AppLogService, error) {
+	return newMongodbLogService(10*time.Millisecond, func(ctx context.Context, entry *appTypes.Applog) (string, error) {
+		return "pool1", nil
+	}), nil
+}
+
+func (s *S) Test_MongodbLogService_ListTimeRange(c *check.C) {
+	svc, err := testMongodbAppLogService()
+	c.Assert(err, check.IsNil)
+	base := time.Date(2026, 1, 10, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		err = svc.Enqueue(&appTypes.Applog{
+			Name:    "myapp",
+			Date:    base.Add(time.Duration(i) * time.Minute),
+			Message: time.Duration(i).String(),
+			Source:  "web",
+		})
+		c.Assert(err, check.IsNil)
+	}
+	err = svc.(*mongodbLogService).Shutdown(context.TODO())
+	c.Assert(err, check.IsNil)
+	logs, err := svc.List(context.TODO(), appTypes.ListLogArgs{
+		Name:  "myapp",
+		Since: base.Add(time.Minute),
+		Until: base.Add(3 * time.Minute),
+	})
+	c.Assert(err, check.IsNil)
+	compareLogsDate(c, logs, []appTypes.Applog{
+		{Name: "myapp", Date: base.Add(time.Minute), Message: "1ns", Source: "web"},
+		{Name: "myapp", Date: base.Add(2 * time.Minute), Message: "2ns", Source: "web"},
+		{Name: "myapp", Date: base.Add(3 * time.Minute), Message: "3ns", Source: "web"},
+	}, true)
+}
+
+func (s *S) Test_MongodbLogService_RetentionPerPool(c *check.C) {
+	config.Set("log:mongodb:retention", "48h")
+	config.Set("log:mongodb:pools:pool1:retention", "2h")
+	defer config.Unset("log:mongodb")
+	svc, err := testMongodbAppLogService()
+	c.Assert(err, check.IsNil)
+	date := time.Date(2026, 1, 10, 10, 0, 0, 0, time.UTC)
+	err = svc.Enqueue(&appTypes.Applog{Name: "myapp", Date: date, Message: "hello"})
+	c.Assert(err, check.IsNil)
+	err = svc.(*mongodbLogService).Shutdown(context.TODO())
+	c.Assert(err, check.IsNil)
+	collection, err := storagev2.AppLogsCollection()
+	c.Assert(err, check.IsNil)
+	var entry mongoLogEntry
+	err = collection.FindOne(context.TODO(), mongoBSON.M{"name": "myapp"}).Decode(&entry)
+	c.Assert(err, check.IsNil)
+	c.Assert(entry.ExpireAt.UTC(), check.DeepEquals, date.Add(2*time.Hour))
+	c.Assert(logRetention("other"), check.Equals, 48*time.Hour)
+	config.Unset("log:mongodb:retention")
+	c.Assert(logRetention("other"), check.Equals, defaultLogRetention)
+}
+
+func (s *S) Test_MongodbLogService_EnqueueBatches(c *check.C) {
+	config.Set("log:mongodb:batch-size", 2)
+	defer config.Unset("log:mongodb")
+	svc, err := testMongodbAppLogService()
+	c.Assert(err, check.IsNil)
+	for i := 0; i < 5; i++ {
+		err = svc.Enqueue(&appTypes.Applog{Name: "myapp", Message: time.Duration(i).String()})
+		c.Assert(err, check.IsNil)
+	}
+	err = svc.(*mongodbLogService).Shutdown(context.TODO())
+	c.Assert(err, check.IsNil)
+	logs, err := svc.List(context.TODO(), appTypes.ListLogArgs{Name: "myapp"})
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 5)
+	err = svc.Enqueue(&appTypes.Applog{Name: "myapp", Message: "after shutdown"})
+	c.Assert(err, check.IsNil)
+	logs, err = svc.List(context.TODO(), appTypes.ListLogArgs{Name: "myapp"})
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 5)
+}
+
+func (s *S) Test_MongodbLogService_ListFieldsBoundsScan(c *check.C) {
+	config.Set("log:mongodb:max-scanned-entries", 3)
+	defer config.Unset("log:mongodb")
+	svc, err := testMongodbAppLogService()
+	c.Assert(err, check.IsNil)
+	for i := 0; i < 5; i++ {
+		err = svc.Add("myapp", fmt.Sprintf(`{"level": "error", "n": %d}`, i), "web", "unit1")
+		c.Assert(err, check.IsNil)
+	}
+	logs, err := svc.List(context.TODO(), appTypes.ListLogArgs{
+		Name:   "myapp",
+		Fields: map[string]string{"level": "error"},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 3)
+}
@@ -26,11 +26,13 @@ func AppLogService() (appTypes.AppLogService, error) {
 		svc, err = memoryAppLogService()
 	case "memory":
 		svc, err = aggregatorAppLogService()
+	case "mongodb":
+		svc, err = mongodbAppLogService()
 	default:
-		return nil, errors.New(`invalid app log service, valid values are: "memory" or "memory-standalone"`)
+		return nil, errors.New(`invalid app log service, valid values are: "memory", "memory-standalone" or "mongodb"`)
 	}
 	if err != nil {
 		return nil, err
 	}
-	return newProvisionerWrapper(svc), nil
+	return newProvisionerWrapper(newDrainForwarder(svc)), nil
 }
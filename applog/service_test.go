@@ -182,6 +182,27 @@ func (s *ServiceSuite) Test_LogService_ListFilterInvert(c *check.C) {
 	c.Check(logs[0].Source, check.Equals, "circus")
 }
 
+func (s *ServiceSuite) Test_LogService_ListMessageFilters(c *check.C) {
+	s.svc.Add("myapp", "request timeout", "web", "u1")
+	s.svc.Add("myapp", "request ok", "web", "u1")
+	s.svc.Add("myapp", `{"level": "error", "msg": "timeout"}`, "web", "u1")
+	logs, err := s.svc.List(context.TODO(), appTypes.ListLogArgs{Name: "myapp", Message: "timeout"})
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 2)
+	c.Assert(logs[0].Message, check.Equals, "request timeout")
+	logs, err = s.svc.List(context.TODO(), appTypes.ListLogArgs{Name: "myapp", MessageRegex: "^request"})
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 2)
+	c.Assert(logs[1].Message, check.Equals, "request ok")
+	logs, err = s.svc.List(context.TODO(), appTypes.ListLogArgs{Name: "myapp", Fields: map[string]string{"level": "error"}, Limit: 1})
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 1)
+	c.Assert(logs[0].Message, check.Equals, `{"level": "error", "msg": "timeout"}`)
+	logs, err = s.svc.List(context.TODO(), appTypes.ListLogArgs{Name: "myapp", Until: time.Now().Add(-time.Hour)})
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 0)
+}
+
 func (s *ServiceSuite) Test_LogService_ListEmpty(c *check.C) {
 	logs, err := s.svc.List(context.TODO(), appTypes.ListLogArgs{Limit: 10, Name: "myapp", Source: "tsuru"})
 	c.Assert(err, check.IsNil)
@@ -0,0 +1,155 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cost
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	costTypes "github.com/tsuru/tsuru/types/cost"
+	volumeTypes "github.com/tsuru/tsuru/types/volume"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestSetAndGetPrice(c *check.C) {
+	price := costTypes.Price{
+		Pool:        "pool1",
+		CPUCoreHour: 0.05,
+	}
+	err := SetPrice(context.TODO(), price)
+	c.Assert(err, check.IsNil)
+	price.MemoryGiBHour = 0.01
+	price.StorageGiBHour = map[string]float64{"nfs": 0.001}
+	err = SetPrice(context.TODO(), price)
+	c.Assert(err, check.IsNil)
+	dbPrice, err := GetPrice(context.TODO(), "pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(*dbPrice, check.DeepEquals, price)
+}
+
+func (s *S) TestGetPriceNotFound(c *check.C) {
+	_, err := GetPrice(context.TODO(), "pool1")
+	c.Assert(err, check.Equals, costTypes.ErrPriceNotFound)
+}
+
+func (s *S) TestSetPriceNegative(c *check.C) {
+	err := SetPrice(context.TODO(), costTypes.Price{
+		Pool:        "pool1",
+		CPUCoreHour: -1,
+	})
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+}
+
+func (s *S) TestAppUsage(c *check.C) {
+	small := appTypes.Plan{Name: "small", CPUMilli: 500, Memory: 512 * 1024 * 1024}
+	big := appTypes.Plan{Name: "big", CPUMilli: 2000, Memory: 2 * 1024 * 1024 * 1024}
+	s.mockService.Plan.OnFindByName = func(name string) (*appTypes.Plan, error) {
+		c.Assert(name, check.Equals, "big")
+		return &big, nil
+	}
+	a := &app.App{
+		Name:      "myapp",
+		Plan:      small,
+		Processes: []appTypes.Process{{Name: "worker", Plan: "big"}},
+	}
+	price := costTypes.Price{
+		Pool:          "pool1",
+		CPUCoreHour:   0.1,
+		MemoryGiBHour: 0.01,
+	}
+	usage, err := appUsage(context.TODO(), a, map[string]int{"web": 2, "worker": 1}, price, 1, map[string]appTypes.Plan{})
+	c.Assert(err, check.IsNil)
+	c.Assert(usage.UnitHours, check.Equals, 3.0)
+	c.Assert(usage.CPUCoreHours, check.Equals, 3.0)
+	c.Assert(usage.MemoryGiBHours, check.Equals, 3.0)
+	c.Assert(math.Abs(usage.Cost-0.33) < 1e-9, check.Equals, true)
+}
+
+func (s *S) TestRunSampleThrottled(c *check.C) {
+	s.mockService.VolumeService.OnListByFilter = func(ctx context.Context, f *volumeTypes.Filter) ([]volumeTypes.Volume, error) {
+		return []volumeTypes.Volume{
+			{Name: "vol1", Pool: "pool1", TeamOwner: "team1", Opts: map[string]string{"capacity": "10Gi"}},
+		}, nil
+	}
+	setSampleThrottling(time.Hour)
+	collection, err := storagev2.CostSamplesCollection()
+	c.Assert(err, check.IsNil)
+	err = runSample(context.TODO(), time.Hour)
+	c.Assert(err, check.IsNil)
+	count, err := collection.CountDocuments(context.TODO(), mongoBSON.M{})
+	c.Assert(err, check.IsNil)
+	c.Assert(count, check.Equals, int64(1))
+	err = runSample(context.TODO(), time.Hour)
+	c.Assert(err, check.IsNil)
+	count, err = collection.CountDocuments(context.TODO(), mongoBSON.M{})
+	c.Assert(err, check.IsNil)
+	c.Assert(count, check.Equals, int64(1))
+}
+
+func (s *S) insertReportSamples(c *check.C, now time.Time) {
+	err := insertSamples(context.TODO(), []costTypes.Sample{
+		{Time: now.Add(-2 * time.Hour), Team: "team1", Pool: "pool1", App: "app1", Usage: costTypes.Usage{UnitHours: 1, Cost: 1}},
+		{Time: now.Add(-time.Hour), Team: "team1", Pool: "pool1", App: "app1", Usage: costTypes.Usage{UnitHours: 1, Cost: 1}},
+		{Time: now.Add(-time.Hour), Team: "team1", Pool: "pool2", App: "app2", Usage: costTypes.Usage{UnitHours: 2, Cost: 4}},
+		{Time: now.Add(-time.Hour), Team: "team2", Pool: "pool1", Volume: "vol1", Usage: costTypes.Usage{StorageGiBHours: 10, Cost: 0.5}},
+		{Time: now.Add(-48 * time.Hour), Team: "team2", Pool: "pool1", App: "app3", Usage: costTypes.Usage{UnitHours: 5, Cost: 5}},
+	})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestReport(c *check.C) {
+	now := time.Now().UTC().Truncate(time.Second)
+	s.insertReportSamples(c, now)
+	entries, err := Report(context.TODO(), costTypes.ReportOpts{
+		Start: now.Add(-24 * time.Hour),
+		End:   now,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(entries, check.DeepEquals, []costTypes.ReportEntry{
+		{Team: "team1", Usage: costTypes.Usage{UnitHours: 4, Cost: 6}},
+		{Team: "team2", Usage: costTypes.Usage{StorageGiBHours: 10, Cost: 0.5}},
+	})
+}
+
+func (s *S) TestReportGroupByAppAndPool(c *check.C) {
+	now := time.Now().UTC().Truncate(time.Second)
+	s.insertReportSamples(c, now)
+	entries, err := Report(context.TODO(), costTypes.ReportOpts{
+		Start:   now.Add(-24 * time.Hour),
+		End:     now,
+		GroupBy: []string{"app", "pool"},
+		Teams:   []string{"team1"},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(entries, check.DeepEquals, []costTypes.ReportEntry{
+		{App: "app1", Pool: "pool1", Usage: costTypes.Usage{UnitHours: 2, Cost: 2}},
+		{App: "app2", Pool: "pool2", Usage: costTypes.Usage{UnitHours: 2, Cost: 4}},
+	})
+}
+
+func (s *S) TestReportInvalidOpts(c *check.C) {
+	now := time.Now()
+	_, err := Report(context.TODO(), costTypes.ReportOpts{Start: now, End: now.Add(time.Hour), GroupBy: []string{"plan"}})
+	c.Assert(err, check.ErrorMatches, `invalid group by "plan", must be one of: team, app, pool`)
+	_, err = Report(context.TODO(), costTypes.ReportOpts{Start: now, End: now})
+	c.Assert(err, check.ErrorMatches, "report end must be after its start")
+}
+
+func (s *S) TestWriteCSV(c *check.C) {
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, []string{"pool", "team"}, []costTypes.ReportEntry{
+		{Team: "team1", Pool: "pool1", Usage: costTypes.Usage{UnitHours: 2, CPUCoreHours: 1, MemoryGiBHours: 0.5, Cost: 1.25}},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Equals, "team,pool,unit_hours,cpu_core_hours,memory_gib_hours,storage_gib_hours,cost\n"+
+		"team1,pool1,2,1,0.5,0,1.25\n")
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cost
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	costTypes "github.com/tsuru/tsuru/types/cost"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SetPrice creates or replaces the prices of a pool.
+func SetPrice(ctx context.Context, price costTypes.Price) error {
+	if price.CPUCoreHour < 0 || price.MemoryGiBHour < 0 {
+		return &tsuruErrors.ValidationError{Message: "prices cannot be negative"}
+	}
+	for _, v := range price.StorageGiBHour {
+		if v < 0 {
+			return &tsuruErrors.ValidationError{Message: "prices cannot be negative"}
+		}
+	}
+	collection, err := storagev2.CostPricesCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.ReplaceOne(ctx, mongoBSON.M{"_id": price.Pool}, price, options.Replace().SetUpsert(true))
+	return err
+}
+
+func GetPrice(ctx context.Context, pool string) (*costTypes.Price, error) {
+	collection, err := storagev2.CostPricesCollection()
+	if err != nil {
+		return nil, err
+	}
+	var price costTypes.Price
+	err = collection.FindOne(ctx, mongoBSON.M{"_id": pool}).Decode(&price)
+	if err == mongo.ErrNoDocuments {
+		return nil, costTypes.ErrPriceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &price, nil
+}
+
+func listPrices(ctx context.Context) (map[string]costTypes.Price, error) {
+	collection, err := storagev2.CostPricesCollection()
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := collection.Find(ctx, mongoBSON.M{})
+	if err != nil {
+		return nil, err
+	}
+	var prices []costTypes.Price
+	err = cursor.All(ctx, &prices)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]costTypes.Price, len(prices))
+	for _, p := range prices {
+		result[p.Pool] = p
+	}
+	return result, nil
+}
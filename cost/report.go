@@ -0,0 +1,155 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cost
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	costTypes "github.com/tsuru/tsuru/types/cost"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+)
+
+// Report aggregates the samples recorded between opts.Start, inclusive, and
+// opts.End, exclusive, by the fields in opts.GroupBy. With no GroupBy the
+// usage is aggregated by team.
+func Report(ctx context.Context, opts costTypes.ReportOpts) ([]costTypes.ReportEntry, error) {
+	groupBy, err := validateGroupBy(opts.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.End.After(opts.Start) {
+		return nil, &tsuruErrors.ValidationError{Message: "report end must be after its start"}
+	}
+	query := mongoBSON.M{"time": mongoBSON.M{"$gte": opts.Start, "$lt": opts.End}}
+	if opts.Teams != nil {
+		query["team"] = mongoBSON.M{"$in": opts.Teams}
+	}
+	collection, err := storagev2.CostSamplesCollection()
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := collection.Find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	var samples []costTypes.Sample
+	err = cursor.All(ctx, &samples)
+	if err != nil {
+		return nil, err
+	}
+	return aggregate(samples, groupBy), nil
+}
+
+func validateGroupBy(groupBy []string) (map[string]bool, error) {
+	if len(groupBy) == 0 {
+		groupBy = []string{costTypes.GroupByTeam}
+	}
+	result := map[string]bool{}
+	for _, g := range groupBy {
+		switch g {
+		case costTypes.GroupByTeam, costTypes.GroupByApp, costTypes.GroupByPool:
+			result[g] = true
+		default:
+			return nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid group by %q, must be one of: team, app, pool", g)}
+		}
+	}
+	return result, nil
+}
+
+func aggregate(samples []costTypes.Sample, groupBy map[string]bool) []costTypes.ReportEntry {
+	type key struct {
+		team, app, pool string
+	}
+	entries := map[key]*costTypes.ReportEntry{}
+	for _, s := range samples {
+		var k key
+		if groupBy[costTypes.GroupByTeam] {
+			k.team = s.Team
+		}
+		if groupBy[costTypes.GroupByApp] {
+			k.app = s.App
+		}
+		if groupBy[costTypes.GroupByPool] {
+			k.pool = s.Pool
+		}
+		entry, ok := entries[k]
+		if !ok {
+			entry = &costTypes.ReportEntry{Team: k.team, App: k.app, Pool: k.pool}
+			entries[k] = entry
+		}
+		entry.Usage = entry.Usage.Add(s.Usage)
+	}
+	result := make([]costTypes.ReportEntry, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, *e)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Team != result[j].Team {
+			return result[i].Team < result[j].Team
+		}
+		if result[i].App != result[j].App {
+			return result[i].App < result[j].App
+		}
+		return result[i].Pool < result[j].Pool
+	})
+	return result
+}
+
+// WriteCSV writes the report entries as CSV, with a header line and only the
+// grouping columns present in groupBy.
+func WriteCSV(w io.Writer, groupBy []string, entries []costTypes.ReportEntry) error {
+	groups, err := validateGroupBy(groupBy)
+	if err != nil {
+		return err
+	}
+	var header []string
+	for _, g := range []string{costTypes.GroupByTeam, costTypes.GroupByApp, costTypes.GroupByPool} {
+		if groups[g] {
+			header = append(header, g)
+		}
+	}
+	header = append(header, "unit_hours", "cpu_core_hours", "memory_gib_hours", "storage_gib_hours", "cost")
+	csvWriter := csv.NewWriter(w)
+	err = csvWriter.Write(header)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		var record []string
+		if groups[costTypes.GroupByTeam] {
+			record = append(record, e.Team)
+		}
+		if groups[costTypes.GroupByApp] {
+			record = append(record, e.App)
+		}
+		if groups[costTypes.GroupByPool] {
+			record = append(record, e.Pool)
+		}
+		record = append(record,
+			formatFloat(e.UnitHours),
+			formatFloat(e.CPUCoreHours),
+			formatFloat(e.MemoryGiBHours),
+			formatFloat(e.StorageGiBHours),
+			formatFloat(e.Cost),
+		)
+		err = csvWriter.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
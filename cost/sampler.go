@@ -0,0 +1,263 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cost
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	costTypes "github.com/tsuru/tsuru/types/cost"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+)
+
+const (
+	defaultSampleInterval = time.Hour
+	sampleEventKind       = "cost-sample"
+
+	gib = 1024 * 1024 * 1024
+)
+
+// Initialize starts the periodic sampler recording the resources used by
+// apps and volumes, when enabled by the cost:enabled config. Only one API
+// instance records samples in each interval.
+func Initialize() error {
+	enabled, _ := config.GetBool("cost:enabled")
+	if !enabled {
+		return nil
+	}
+	interval, err := config.GetDuration("cost:sample-interval")
+	if err != nil || interval <= 0 {
+		interval = defaultSampleInterval
+	}
+	setSampleThrottling(interval)
+	s := &sampler{interval: interval, once: &sync.Once{}}
+	s.start()
+	shutdown.Register(s)
+	return nil
+}
+
+// setSampleThrottling allows a single sample event in each interval, across
+// every API instance.
+func setSampleThrottling(interval time.Duration) {
+	event.SetThrottling(event.ThrottlingSpec{
+		TargetType: eventTypes.TargetTypeGlobal,
+		KindName:   sampleEventKind,
+		Time:       interval,
+		Max:        1,
+		AllTargets: true,
+		WaitFinish: true,
+	})
+}
+
+type sampler struct {
+	interval time.Duration
+	once     *sync.Once
+	stopCh   chan struct{}
+}
+
+func (s *sampler) start() {
+	s.once.Do(func() {
+		s.stopCh = make(chan struct{})
+		go s.spin()
+	})
+}
+
+func (s *sampler) Shutdown(ctx context.Context) error {
+	if s.stopCh == nil {
+		return nil
+	}
+	s.stopCh <- struct{}{}
+	s.stopCh = nil
+	s.once = &sync.Once{}
+	return nil
+}
+
+func (s *sampler) spin() {
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-time.After(s.interval):
+		}
+		err := runSample(context.Background(), s.interval)
+		if err != nil {
+			log.Errorf("[cost sampler] %v", err)
+		}
+	}
+}
+
+type sampleResult struct {
+	Samples int
+}
+
+func runSample(ctx context.Context, interval time.Duration) error {
+	evt, err := event.NewInternal(ctx, &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeGlobal},
+		InternalKind: sampleEventKind,
+		Allowed:      event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxGlobal, "")),
+	})
+	if err != nil {
+		_, isThrottled := err.(event.ErrThrottled)
+		_, isLocked := err.(event.ErrEventLocked)
+		if isThrottled || isLocked {
+			return nil
+		}
+		return errors.Wrap(err, "could not create event")
+	}
+	samples, err := collectSamples(ctx, time.Now().UTC(), interval)
+	if len(samples) > 0 {
+		insertErr := insertSamples(ctx, samples)
+		if insertErr != nil {
+			err = insertErr
+		}
+	}
+	evt.DoneCustomData(ctx, err, sampleResult{Samples: len(samples)})
+	return err
+}
+
+func insertSamples(ctx context.Context, samples []costTypes.Sample) error {
+	collection, err := storagev2.CostSamplesCollection()
+	if err != nil {
+		return err
+	}
+	docs := make([]interface{}, len(samples))
+	for i := range samples {
+		docs[i] = samples[i]
+	}
+	_, err = collection.InsertMany(ctx, docs)
+	return err
+}
+
+// collectSamples returns the usage of every app and volume as if the
+// resources currently reserved were used for the whole interval. Failures
+// in a provisioner don't prevent the remaining samples from being returned.
+func collectSamples(ctx context.Context, now time.Time, interval time.Duration) ([]costTypes.Sample, error) {
+	prices, err := listPrices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	apps, err := app.List(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	multi := tsuruErrors.NewMultiError()
+	appsByProvisioner := map[string][]provision.App{}
+	for i := range apps {
+		prov, err := pool.GetProvisionerForPool(ctx, apps[i].Pool)
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to get provisioner for app %q", apps[i].Name))
+			continue
+		}
+		appsByProvisioner[prov.GetName()] = append(appsByProvisioner[prov.GetName()], &apps[i])
+	}
+	hours := interval.Hours()
+	plans := map[string]appTypes.Plan{}
+	var samples []costTypes.Sample
+	for provName, provApps := range appsByProvisioner {
+		prov, err := provision.Get(provName)
+		if err != nil {
+			multi.Add(err)
+			continue
+		}
+		units, err := prov.Units(ctx, provApps...)
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to list units in provisioner %q", provName))
+			continue
+		}
+		counts := map[string]map[string]int{}
+		for _, u := range units {
+			if u.Status == provTypes.UnitStatusStopped {
+				continue
+			}
+			if counts[u.AppName] == nil {
+				counts[u.AppName] = map[string]int{}
+			}
+			counts[u.AppName][u.ProcessName]++
+		}
+		for _, provApp := range provApps {
+			a := provApp.(*app.App)
+			usage, err := appUsage(ctx, a, counts[a.Name], prices[a.Pool], hours, plans)
+			if err != nil {
+				multi.Add(err)
+				continue
+			}
+			if usage.UnitHours == 0 {
+				continue
+			}
+			samples = append(samples, costTypes.Sample{Time: now, Team: a.TeamOwner, Pool: a.Pool, App: a.Name, Usage: usage})
+		}
+	}
+	volumes, err := servicemanager.Volume.ListByFilter(ctx, nil)
+	if err != nil {
+		multi.Add(err)
+	}
+	for _, v := range volumes {
+		capacity, err := v.Capacity()
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to get capacity for volume %q", v.Name))
+			continue
+		}
+		if capacity == 0 {
+			continue
+		}
+		storageHours := float64(capacity) / gib * hours
+		samples = append(samples, costTypes.Sample{
+			Time:   now,
+			Team:   v.TeamOwner,
+			Pool:   v.Pool,
+			Volume: v.Name,
+			Usage: costTypes.Usage{
+				StorageGiBHours: storageHours,
+				Cost:            storageHours * prices[v.Pool].StorageGiBHour[v.Plan.Name],
+			},
+		})
+	}
+	return samples, multi.ToError()
+}
+
+func appUsage(ctx context.Context, a *app.App, counts map[string]int, price costTypes.Price, hours float64, plans map[string]appTypes.Plan) (costTypes.Usage, error) {
+	var usage costTypes.Usage
+	for process, count := range counts {
+		plan := a.Plan
+		if p := a.GetProcess(process); p != nil && p.Plan != "" {
+			var ok bool
+			plan, ok = plans[p.Plan]
+			if !ok {
+				dbPlan, err := servicemanager.Plan.FindByName(ctx, p.Plan)
+				if err != nil {
+					return usage, errors.Wrapf(err, "unable to find plan %q for app %q", p.Plan, a.Name)
+				}
+				plan = *dbPlan
+				plans[p.Plan] = plan
+			}
+		}
+		unitHours := float64(count) * hours
+		cpuHours := float64(plan.GetMilliCPU()) / 1000 * unitHours
+		memoryHours := float64(plan.GetMemory()) / gib * unitHours
+		usage = usage.Add(costTypes.Usage{
+			UnitHours:      unitHours,
+			CPUCoreHours:   cpuHours,
+			MemoryGiBHours: memoryHours,
+			Cost:           cpuHours*price.CPUCoreHour + memoryHours*price.MemoryGiBHour,
+		})
+	}
+	return usage, nil
+}
@@ -48,6 +48,14 @@ func JobsCollection() (*mongo.Collection, error) {
 	return Collection("jobs")
 }
 
+func JobRunsCollection() (*mongo.Collection, error) {
+	return Collection("job_runs")
+}
+
+func JobWorkflowsCollection() (*mongo.Collection, error) {
+	return Collection("job_workflows")
+}
+
 func TokensCollection() (*mongo.Collection, error) {
 	return Collection("tokens")
 }
@@ -76,6 +84,10 @@ func WebhookCollection() (*mongo.Collection, error) {
 	return Collection("webhook")
 }
 
+func WebhookDeliveriesCollection() (*mongo.Collection, error) {
+	return Collection("webhook_deliveries")
+}
+
 func VolumesCollection() (*mongo.Collection, error) {
 	return Collection("volumes")
 }
@@ -84,6 +96,34 @@ func VolumeBindsCollection() (*mongo.Collection, error) {
 	return Collection("volume_binds")
 }
 
+func AppLogsCollection() (*mongo.Collection, error) {
+	return Collection("app_logs")
+}
+
+func LogDrainsCollection() (*mongo.Collection, error) {
+	return Collection("log_drains")
+}
+
+func ManagedCertificatesCollection() (*mongo.Collection, error) {
+	return Collection("managed_certificates")
+}
+
+func AppSwapsCollection() (*mongo.Collection, error) {
+	return Collection("app_swaps")
+}
+
+func ResourceQuotasCollection() (*mongo.Collection, error) {
+	return Collection("resource_quotas")
+}
+
+func CostPricesCollection() (*mongo.Collection, error) {
+	return Collection("cost_prices")
+}
+
+func CostSamplesCollection() (*mongo.Collection, error) {
+	return Collection("cost_samples")
+}
+
 func TrackerCollection() (*mongo.Collection, error) {
 	return Collection("tracker")
 }
@@ -116,6 +116,24 @@ var EnsureIndexes = []EnsureIndex{
 		},
 	},
 
+	{
+		Collection: "job_runs",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys: mongoBSON.D{{Key: "job", Value: 1}, {Key: "starttime", Value: -1}},
+			},
+		},
+	},
+
+	{
+		Collection: "job_workflows",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys: mongoBSON.D{{Key: "steps.run", Value: 1}},
+			},
+		},
+	},
+
 	{
 		Collection: "tokens",
 		Indexes: []mongo.IndexModel{
@@ -183,6 +201,22 @@ var EnsureIndexes = []EnsureIndex{
 		},
 	},
 
+	{
+		Collection: "webhook_deliveries",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys: mongoBSON.D{{Key: "webhook", Value: 1}, {Key: "_id", Value: -1}},
+			},
+			{
+				Keys: mongoBSON.D{{Key: "status", Value: 1}, {Key: "nextattempt", Value: 1}},
+			},
+			{
+				Keys:    mongoBSON.D{{Key: "expireat", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(1),
+			},
+		},
+	},
+
 	{
 		Collection: "service_broker",
 		Indexes: []mongo.IndexModel{
@@ -223,6 +257,76 @@ var EnsureIndexes = []EnsureIndex{
 		},
 	},
 
+	{
+		Collection: "app_logs",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys: mongoBSON.D{{Key: "name", Value: 1}, {Key: "date", Value: -1}},
+			},
+			{
+				Keys:    mongoBSON.D{{Key: "expireat", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(1),
+			},
+		},
+	},
+
+	{
+		Collection: "resource_quotas",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys:    mongoBSON.D{{Key: "team", Value: 1}, {Key: "pool", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+		},
+	},
+
+	{
+		Collection: "cost_samples",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys: mongoBSON.D{{Key: "time", Value: 1}},
+			},
+			{
+				Keys: mongoBSON.D{{Key: "team", Value: 1}, {Key: "time", Value: 1}},
+			},
+		},
+	},
+
+	{
+		Collection: "log_drains",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys:    mongoBSON.D{{Key: "app", Value: 1}, {Key: "name", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+		},
+	},
+
+	{
+		Collection: "managed_certificates",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys:    mongoBSON.D{{Key: "app", Value: 1}, {Key: "cname", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys: mongoBSON.D{{Key: "challenge.token", Value: 1}},
+			},
+		},
+	},
+
+	{
+		Collection: "app_swaps",
+		Indexes: []mongo.IndexModel{
+			{
+				Keys: mongoBSON.D{{Key: "app1", Value: 1}, {Key: "status", Value: 1}},
+			},
+			{
+				Keys: mongoBSON.D{{Key: "app2", Value: 1}, {Key: "status", Value: 1}},
+			},
+		},
+	},
+
 	{
 		GetCollectionName: getOAuthTokensCollectionName,
 		Indexes: []mongo.IndexModel{
@@ -0,0 +1,167 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package event
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/servicemanager"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrNotPendingApproval = errors.New("event is not pending approval")
+	ErrApprovalByOwner    = errors.New("event can't be approved or rejected by its own owner")
+
+	approvalPollInterval = time.Second
+)
+
+type ErrApprovalDenied struct {
+	Approval eventTypes.ApprovalInfo
+}
+
+func (e *ErrApprovalDenied) Error() string {
+	if e.Approval.Status == eventTypes.ApprovalExpired {
+		return "approval request expired without a decision"
+	}
+	msg := "rejected by " + e.Approval.Owner
+	if e.Approval.Reason != "" {
+		msg += ": " + e.Approval.Reason
+	}
+	return msg
+}
+
+// RequestApproval marks the running event as pending approval until the
+// deadline defined by timeout. Registered webhooks are notified so approvers
+// may be warned about the request.
+func (e *Event) RequestApproval(ctx context.Context, timeout time.Duration) error {
+	if !e.Running {
+		return ErrNotPendingApproval
+	}
+	collection, err := storagev2.EventsCollection()
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	approval := &eventTypes.ApprovalInfo{
+		Status:    eventTypes.ApprovalPending,
+		StartTime: now,
+		Deadline:  now.Add(timeout),
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"_id": e.ID}, mongoBSON.M{"$set": mongoBSON.M{"approval": approval}})
+	if err != nil {
+		return err
+	}
+	e.Approval = approval
+	if servicemanager.Webhook != nil {
+		servicemanager.Webhook.Notify(ctx, e.ID.Hex())
+	}
+	return nil
+}
+
+// WaitApproval blocks until the pending approval request is decided. A nil
+// error is returned when the event is approved, an *ErrApprovalDenied is
+// returned when it's rejected or when the deadline is reached.
+func (e *Event) WaitApproval(ctx context.Context) error {
+	if e.Approval == nil {
+		return ErrNotPendingApproval
+	}
+	collection, err := storagev2.EventsCollection()
+	if err != nil {
+		return err
+	}
+	opts := options.FindOne().SetProjection(mongoBSON.M{"approval": 1})
+	for {
+		var data struct {
+			Approval *eventTypes.ApprovalInfo
+		}
+		err = collection.FindOne(ctx, mongoBSON.M{"_id": e.ID}, opts).Decode(&data)
+		if err != nil {
+			log.Errorf("unable to check event approval: %v", err)
+		} else if data.Approval != nil {
+			e.Approval = data.Approval
+		}
+		switch e.Approval.Status {
+		case eventTypes.ApprovalApproved:
+			return nil
+		case eventTypes.ApprovalRejected, eventTypes.ApprovalExpired:
+			return &ErrApprovalDenied{Approval: *e.Approval}
+		}
+		if !time.Now().Before(e.Approval.Deadline) {
+			err = e.expireApproval(ctx)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(approvalPollInterval):
+		}
+	}
+}
+
+func (e *Event) expireApproval(ctx context.Context) error {
+	collection, err := storagev2.EventsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{
+		"_id":             e.ID,
+		"approval.status": eventTypes.ApprovalPending,
+	}, mongoBSON.M{"$set": mongoBSON.M{
+		"approval.status":       eventTypes.ApprovalExpired,
+		"approval.decisiontime": time.Now().UTC(),
+	}})
+	return err
+}
+
+// Approve allows a running event pending approval to proceed. Events can't be
+// approved or rejected by the same user or token that owns them.
+func (e *Event) Approve(ctx context.Context, owner eventTypes.Owner, reason string) error {
+	return e.decideApproval(ctx, eventTypes.ApprovalApproved, owner, reason)
+}
+
+// Reject denies a running event pending approval.
+func (e *Event) Reject(ctx context.Context, owner eventTypes.Owner, reason string) error {
+	return e.decideApproval(ctx, eventTypes.ApprovalRejected, owner, reason)
+}
+
+func (e *Event) decideApproval(ctx context.Context, status eventTypes.ApprovalStatus, owner eventTypes.Owner, reason string) error {
+	if owner.Type == e.Owner.Type && owner.Name == e.Owner.Name {
+		return ErrApprovalByOwner
+	}
+	collection, err := storagev2.EventsCollection()
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	query := mongoBSON.M{
+		"_id":               e.ID,
+		"running":           true,
+		"approval.status":   eventTypes.ApprovalPending,
+		"approval.deadline": mongoBSON.M{"$gt": now},
+	}
+	update := mongoBSON.M{"$set": mongoBSON.M{
+		"approval.status":       status,
+		"approval.owner":        owner.Name,
+		"approval.reason":       reason,
+		"approval.decisiontime": now,
+	}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = collection.FindOneAndUpdate(ctx, query, update, opts).Decode(&e.EventData)
+	if err == mongo.ErrNoDocuments {
+		return ErrNotPendingApproval
+	}
+	return err
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package event
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/permission"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	check "gopkg.in/check.v1"
+)
+
+var approver = eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: "approver@tsuru.io"}
+
+func (s *S) newApprovalEvent(c *check.C) *Event {
+	evt, err := New(context.TODO(), &Opts{
+		Target:  eventTypes.Target{Type: "app", Value: "myapp"},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	return evt
+}
+
+func (s *S) TestEventApprove(c *check.C) {
+	evt := s.newApprovalEvent(c)
+	err := evt.RequestApproval(context.TODO(), time.Minute)
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.Approval.Status, check.Equals, eventTypes.ApprovalPending)
+	dbEvt, err := GetByID(context.TODO(), evt.ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbEvt.Approval.Status, check.Equals, eventTypes.ApprovalPending)
+	err = dbEvt.Approve(context.TODO(), approver, "looks good")
+	c.Assert(err, check.IsNil)
+	err = evt.WaitApproval(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.Approval.Status, check.Equals, eventTypes.ApprovalApproved)
+	c.Assert(evt.Approval.Owner, check.Equals, "approver@tsuru.io")
+	c.Assert(evt.Approval.Reason, check.Equals, "looks good")
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	dbEvt, err = GetByID(context.TODO(), evt.ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbEvt.Approval.Status, check.Equals, eventTypes.ApprovalApproved)
+}
+
+func (s *S) TestEventReject(c *check.C) {
+	evt := s.newApprovalEvent(c)
+	err := evt.RequestApproval(context.TODO(), time.Minute)
+	c.Assert(err, check.IsNil)
+	dbEvt, err := GetByID(context.TODO(), evt.ID)
+	c.Assert(err, check.IsNil)
+	err = dbEvt.Reject(context.TODO(), approver, "not today")
+	c.Assert(err, check.IsNil)
+	err = evt.WaitApproval(context.TODO())
+	c.Assert(err, check.FitsTypeOf, &ErrApprovalDenied{})
+	c.Assert(err, check.ErrorMatches, "rejected by approver@tsuru.io: not today")
+	err = dbEvt.Approve(context.TODO(), approver, "")
+	c.Assert(err, check.Equals, ErrNotPendingApproval)
+}
+
+func (s *S) TestEventApproveByOwner(c *check.C) {
+	evt := s.newApprovalEvent(c)
+	err := evt.RequestApproval(context.TODO(), time.Minute)
+	c.Assert(err, check.IsNil)
+	err = evt.Approve(context.TODO(), OwnerFromToken(s.token), "")
+	c.Assert(err, check.Equals, ErrApprovalByOwner)
+}
+
+func (s *S) TestEventApproveByOwnerToken(c *check.C) {
+	evt, err := New(context.TODO(), &Opts{
+		Target:   eventTypes.Target{Type: "app", Value: "myapp"},
+		Kind:     permission.PermAppDeploy,
+		RawOwner: eventTypes.Owner{Type: eventTypes.OwnerTypeToken, Name: "deploy-token"},
+		Allowed:  Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.RequestApproval(context.TODO(), time.Minute)
+	c.Assert(err, check.IsNil)
+	err = evt.Approve(context.TODO(), eventTypes.Owner{Type: eventTypes.OwnerTypeToken, Name: "deploy-token"}, "")
+	c.Assert(err, check.Equals, ErrApprovalByOwner)
+	err = evt.Approve(context.TODO(), eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: "deploy-token"}, "")
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestEventApproveNotPending(c *check.C) {
+	evt := s.newApprovalEvent(c)
+	err := evt.Approve(context.TODO(), approver, "")
+	c.Assert(err, check.Equals, ErrNotPendingApproval)
+	err = evt.WaitApproval(context.TODO())
+	c.Assert(err, check.Equals, ErrNotPendingApproval)
+}
+
+func (s *S) TestEventWaitApprovalExpired(c *check.C) {
+	evt := s.newApprovalEvent(c)
+	err := evt.RequestApproval(context.TODO(), 50*time.Millisecond)
+	c.Assert(err, check.IsNil)
+	err = evt.WaitApproval(context.TODO())
+	c.Assert(err, check.ErrorMatches, "approval request expired without a decision")
+	c.Assert(evt.Approval.Status, check.Equals, eventTypes.ApprovalExpired)
+	err = evt.Approve(context.TODO(), approver, "")
+	c.Assert(err, check.Equals, ErrNotPendingApproval)
+}
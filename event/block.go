@@ -7,10 +7,13 @@ package event
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/robfig/cron/v3"
 	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
 	eventTypes "github.com/tsuru/tsuru/types/event"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -43,16 +46,31 @@ func (e ErrEventBlocked) Error() string {
 	)
 }
 
+// Block prevents matching events from running while it's active, between
+// StartTime and EndTime, when set. Blocks with a Schedule are maintenance
+// windows, only blocking events between each occurrence of Schedule and the
+// following occurrence of EndSchedule, evaluated in Timezone.
 type Block struct {
-	ID         primitive.ObjectID `bson:"_id,omitempty"`
-	StartTime  time.Time
-	EndTime    time.Time `bson:"endtime,omitempty"`
-	KindName   string
-	OwnerName  string
-	Target     eventTypes.Target `bson:"target,omitempty"`
-	Conditions map[string]string `bson:"conditions,omitempty"`
-	Reason     string
-	Active     bool
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	StartTime   time.Time
+	EndTime     time.Time `bson:"endtime,omitempty"`
+	KindName    string
+	OwnerName   string
+	Target      eventTypes.Target `bson:"target,omitempty"`
+	Conditions  map[string]string `bson:"conditions,omitempty"`
+	Reason      string
+	Active      bool
+	Schedule    string `bson:"schedule,omitempty"`
+	EndSchedule string `bson:"endschedule,omitempty"`
+	Timezone    string `bson:"timezone,omitempty"`
+}
+
+// BlockWindow is a period of time in which a block is in effect, End is zero
+// for blocks without an end.
+type BlockWindow struct {
+	Block Block
+	Start time.Time
+	End   time.Time
 }
 
 type startCustomDataMatch struct {
@@ -110,9 +128,15 @@ func AddBlock(ctx context.Context, b *Block) error {
 	if err != nil {
 		return err
 	}
+	err = b.validate()
+	if err != nil {
+		return err
+	}
 	b.Active = true
 	b.ID = primitive.NewObjectID()
-	b.StartTime = time.Now()
+	if b.StartTime.IsZero() {
+		b.StartTime = time.Now()
+	}
 
 	_, err = collection.InsertOne(ctx, b)
 
@@ -189,10 +213,159 @@ func checkIsBlocked(ctx context.Context, evt *Event) error {
 		return err
 	}
 
+	now := time.Now()
 	for _, b := range blocks {
-		if b.Blocks(evt) {
+		if b.ActiveAt(now) && b.Blocks(evt) {
 			return ErrEventBlocked{event: evt, block: &b}
 		}
 	}
 	return nil
 }
+
+// maxWindowsPerBlock limits the number of occurrences of a recurring block
+// returned by UpcomingWindows.
+const maxWindowsPerBlock = 100
+
+func (b *Block) schedules() (start cron.Schedule, end cron.Schedule, loc *time.Location, err error) {
+	loc = time.UTC
+	if b.Timezone != "" {
+		loc, err = time.LoadLocation(b.Timezone)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid timezone %q: %w", b.Timezone, err)
+		}
+	}
+	start, err = cron.ParseStandard(b.Schedule)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid schedule %q: %w", b.Schedule, err)
+	}
+	end, err = cron.ParseStandard(b.EndSchedule)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid end schedule %q: %w", b.EndSchedule, err)
+	}
+	return start, end, loc, nil
+}
+
+func (b *Block) validate() error {
+	if !b.EndTime.IsZero() && !b.EndTime.After(b.StartTime) && !b.StartTime.IsZero() {
+		return &tsuruErrors.ValidationError{Message: "block end time must be after its start time"}
+	}
+	if !b.EndTime.IsZero() && b.EndTime.Before(time.Now()) {
+		return &tsuruErrors.ValidationError{Message: "block end time must be in the future"}
+	}
+	if b.Schedule == "" && b.EndSchedule == "" {
+		if b.Timezone != "" {
+			return &tsuruErrors.ValidationError{Message: "block timezone requires a schedule"}
+		}
+		return nil
+	}
+	if b.Schedule == "" || b.EndSchedule == "" {
+		return &tsuruErrors.ValidationError{Message: "block schedule and end schedule must be set together"}
+	}
+	_, _, _, err := b.schedules()
+	if err != nil {
+		return &tsuruErrors.ValidationError{Message: err.Error()}
+	}
+	return nil
+}
+
+// ActiveAt returns whether the block is in effect at the given time.
+func (b *Block) ActiveAt(t time.Time) bool {
+	if !b.Active || t.Before(b.StartTime) || (!b.EndTime.IsZero() && !t.Before(b.EndTime)) {
+		return false
+	}
+	if b.Schedule == "" {
+		return true
+	}
+	start, end, loc, err := b.schedules()
+	if err != nil {
+		return false
+	}
+	t = t.In(loc)
+	// inside a window the next end comes before the next start.
+	return end.Next(t).Before(start.Next(t))
+}
+
+// previousOccurrence returns the last occurrence of the schedule at or
+// before t, looking back at most one year.
+func previousOccurrence(sched cron.Schedule, t time.Time) (time.Time, bool) {
+	for _, lookback := range []time.Duration{time.Hour, 24 * time.Hour, 7 * 24 * time.Hour, 32 * 24 * time.Hour, 366 * 24 * time.Hour} {
+		next := sched.Next(t.Add(-lookback))
+		if next.IsZero() || next.After(t) {
+			continue
+		}
+		prev := next
+		for {
+			next = sched.Next(prev)
+			if next.IsZero() || next.After(t) {
+				return prev, true
+			}
+			prev = next
+		}
+	}
+	return time.Time{}, false
+}
+
+// Windows returns the periods in which the block is in effect between from
+// and until, including a window already in progress at from.
+func (b *Block) Windows(from, until time.Time) []BlockWindow {
+	if !b.Active || (!b.EndTime.IsZero() && !from.Before(b.EndTime)) || until.Before(b.StartTime) {
+		return nil
+	}
+	clamp := func(w BlockWindow) BlockWindow {
+		if w.Start.Before(b.StartTime) {
+			w.Start = b.StartTime
+		}
+		if !b.EndTime.IsZero() && (w.End.IsZero() || w.End.After(b.EndTime)) {
+			w.End = b.EndTime
+		}
+		return w
+	}
+	if b.Schedule == "" {
+		return []BlockWindow{clamp(BlockWindow{Block: *b, Start: b.StartTime, End: b.EndTime})}
+	}
+	start, end, loc, err := b.schedules()
+	if err != nil {
+		return nil
+	}
+	if from.Before(b.StartTime) {
+		from = b.StartTime
+	}
+	from = from.In(loc)
+	var windows []BlockWindow
+	next := start.Next(from)
+	if b.ActiveAt(from) {
+		if prev, ok := previousOccurrence(start, from); ok {
+			next = prev
+		}
+	}
+	for len(windows) < maxWindowsPerBlock && !next.IsZero() && !next.After(until) {
+		if !b.EndTime.IsZero() && !next.Before(b.EndTime) {
+			break
+		}
+		w := clamp(BlockWindow{Block: *b, Start: next, End: end.Next(next)})
+		windows = append(windows, w)
+		next = start.Next(w.End.Add(-time.Second))
+		if !next.After(w.Start) {
+			next = start.Next(w.Start)
+		}
+	}
+	return windows
+}
+
+// UpcomingWindows returns the windows of active blocks in effect between now
+// and until, sorted by their start.
+func UpcomingWindows(ctx context.Context, until time.Time) ([]BlockWindow, error) {
+	blocks, err := listBlocks(ctx, mongoBSON.M{"active": true})
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var windows []BlockWindow
+	for i := range blocks {
+		windows = append(windows, blocks[i].Windows(now, until)...)
+	}
+	sort.SliceStable(windows, func(i, j int) bool {
+		return windows[i].Start.Before(windows[j].Start)
+	})
+	return windows, nil
+}
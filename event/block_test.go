@@ -138,3 +138,118 @@ func (s *S) TestCheckIsBlocked(c *check.C) {
 		}
 	}
 }
+
+func (s *S) TestAddBlockInvalid(c *check.C) {
+	tt := []struct {
+		block *Block
+		err   string
+	}{
+		{&Block{Reason: "x", Schedule: "0 18 * * 5"}, "block schedule and end schedule must be set together"},
+		{&Block{Reason: "x", Schedule: "0 18 * * 5", EndSchedule: "invalid"}, `invalid end schedule "invalid": .*`},
+		{&Block{Reason: "x", Schedule: "0 18 * * 5", EndSchedule: "0 8 * * 1", Timezone: "Mars/Olympus"}, `invalid timezone "Mars/Olympus": .*`},
+		{&Block{Reason: "x", Timezone: "UTC"}, "block timezone requires a schedule"},
+		{&Block{Reason: "x", StartTime: time.Now().Add(2 * time.Hour), EndTime: time.Now().Add(time.Hour)}, "block end time must be after its start time"},
+		{&Block{Reason: "x", EndTime: time.Now().Add(-time.Hour)}, "block end time must be in the future"},
+	}
+	for _, t := range tt {
+		err := AddBlock(context.TODO(), t.block)
+		c.Check(err, check.ErrorMatches, t.err)
+	}
+	blocks, err := listBlocks(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(blocks, check.HasLen, 0)
+}
+
+func (s *S) TestAddBlockFutureWindow(c *check.C) {
+	start := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+	block := &Block{KindName: "app.deploy", Reason: "maintenance", StartTime: start, EndTime: start.Add(time.Hour)}
+	err := AddBlock(context.TODO(), block)
+	c.Assert(err, check.IsNil)
+	c.Assert(block.StartTime.Equal(start), check.Equals, true)
+	err = checkIsBlocked(context.TODO(), &Event{EventData: eventTypes.EventData{Kind: eventTypes.Kind{Name: "app.deploy"}}})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestBlockActiveAt(c *check.C) {
+	loc, err := time.LoadLocation("America/Sao_Paulo")
+	c.Assert(err, check.IsNil)
+	recurring := Block{
+		Active:      true,
+		StartTime:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Schedule:    "0 18 * * 5",
+		EndSchedule: "0 8 * * 1",
+		Timezone:    "America/Sao_Paulo",
+	}
+	oneOff := Block{
+		Active:    true,
+		StartTime: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC),
+	}
+	tt := []struct {
+		block    Block
+		t        time.Time
+		expected bool
+	}{
+		{recurring, time.Date(2026, 10, 16, 17, 59, 0, 0, loc), false},
+		{recurring, time.Date(2026, 10, 16, 18, 0, 0, 0, loc), true},
+		{recurring, time.Date(2026, 10, 18, 12, 0, 0, 0, loc), true},
+		{recurring, time.Date(2026, 10, 19, 7, 59, 0, 0, loc), true},
+		{recurring, time.Date(2026, 10, 19, 8, 0, 0, 0, loc), false},
+		{recurring, time.Date(2026, 10, 21, 12, 0, 0, 0, loc), false},
+		{recurring, time.Date(2025, 10, 18, 12, 0, 0, 0, loc), false},
+		{oneOff, time.Date(2026, 10, 31, 23, 59, 0, 0, time.UTC), false},
+		{oneOff, time.Date(2026, 11, 1, 12, 0, 0, 0, time.UTC), true},
+		{oneOff, time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC), false},
+	}
+	for i, t := range tt {
+		c.Check(t.block.ActiveAt(t.t), check.Equals, t.expected, check.Commentf("(%d) %s", i, t.t))
+	}
+	recurring.Active = false
+	c.Assert(recurring.ActiveAt(time.Date(2026, 10, 18, 12, 0, 0, 0, loc)), check.Equals, false)
+}
+
+func (s *S) TestBlockWindows(c *check.C) {
+	loc, err := time.LoadLocation("America/Sao_Paulo")
+	c.Assert(err, check.IsNil)
+	block := Block{
+		Active:      true,
+		StartTime:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Schedule:    "0 18 * * 5",
+		EndSchedule: "0 8 * * 1",
+		Timezone:    "America/Sao_Paulo",
+	}
+	from := time.Date(2026, 10, 18, 12, 0, 0, 0, loc)
+	windows := block.Windows(from, from.Add(14*24*time.Hour))
+	c.Assert(windows, check.HasLen, 3)
+	expected := [][2]time.Time{
+		{time.Date(2026, 10, 16, 18, 0, 0, 0, loc), time.Date(2026, 10, 19, 8, 0, 0, 0, loc)},
+		{time.Date(2026, 10, 23, 18, 0, 0, 0, loc), time.Date(2026, 10, 26, 8, 0, 0, 0, loc)},
+		{time.Date(2026, 10, 30, 18, 0, 0, 0, loc), time.Date(2026, 11, 2, 8, 0, 0, 0, loc)},
+	}
+	for i, w := range windows {
+		c.Check(w.Start.Equal(expected[i][0]), check.Equals, true, check.Commentf("(%d) start %s", i, w.Start))
+		c.Check(w.End.Equal(expected[i][1]), check.Equals, true, check.Commentf("(%d) end %s", i, w.End))
+	}
+	block.EndTime = time.Date(2026, 10, 25, 0, 0, 0, 0, loc)
+	windows = block.Windows(from, from.Add(14*24*time.Hour))
+	c.Assert(windows, check.HasLen, 2)
+	c.Assert(windows[1].End.Equal(block.EndTime), check.Equals, true)
+}
+
+func (s *S) TestUpcomingWindows(c *check.C) {
+	start := time.Now().Add(time.Hour)
+	later := &Block{KindName: "app.deploy", Reason: "later", StartTime: start.Add(time.Hour), EndTime: start.Add(2 * time.Hour)}
+	err := AddBlock(context.TODO(), later)
+	c.Assert(err, check.IsNil)
+	sooner := &Block{KindName: "app.deploy", Reason: "sooner", StartTime: start, EndTime: start.Add(time.Hour)}
+	err = AddBlock(context.TODO(), sooner)
+	c.Assert(err, check.IsNil)
+	outside := &Block{KindName: "app.deploy", Reason: "outside", StartTime: start.Add(48 * time.Hour)}
+	err = AddBlock(context.TODO(), outside)
+	c.Assert(err, check.IsNil)
+	windows, err := UpcomingWindows(context.TODO(), time.Now().Add(24*time.Hour))
+	c.Assert(err, check.IsNil)
+	c.Assert(windows, check.HasLen, 2)
+	c.Assert(windows[0].Block.Reason, check.Equals, "sooner")
+	c.Assert(windows[1].Block.Reason, check.Equals, "later")
+}
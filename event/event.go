@@ -205,6 +205,7 @@ type Event struct {
 	eventTypes.EventData
 	logMu     sync.Mutex
 	logWriter io.Writer
+	detached  bool
 }
 
 type Opts struct {
@@ -613,6 +614,15 @@ func List(ctx context.Context, filter *Filter) ([]*Event, error) {
 	return evts, nil
 }
 
+// OwnerFromToken returns the event owner identifying the given token: named
+// tokens are identified by their token name and other tokens by their user.
+func OwnerFromToken(t authTypes.Token) eventTypes.Owner {
+	if token, ok := t.(authTypes.NamedToken); ok {
+		return eventTypes.Owner{Type: eventTypes.OwnerTypeToken, Name: token.GetTokenName()}
+	}
+	return eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: t.GetUserName()}
+}
+
 func New(ctx context.Context, opts *Opts) (*Event, error) {
 	if opts == nil {
 		return nil, ErrNoOpts
@@ -784,13 +794,7 @@ func newEvtOnce(ctx context.Context, opts *Opts) (evt *Event, err error) {
 			o.Type = eventTypes.OwnerTypeInternal
 		}
 	} else {
-		if token, ok := opts.Owner.(authTypes.NamedToken); ok {
-			o.Type = eventTypes.OwnerTypeToken
-			o.Name = token.GetTokenName()
-		} else {
-			o.Type = eventTypes.OwnerTypeUser
-			o.Name = opts.Owner.GetUserName()
-		}
+		o = OwnerFromToken(opts.Owner)
 	}
 
 	collection, err := storagev2.EventsCollection()
@@ -955,6 +959,37 @@ func (e *Event) RawInsert(ctx context.Context, start, other, end interface{}) er
 	return err
 }
 
+// Detach hands the running event over to a background worker, which is
+// expected to load it again and finish it once the operation tracked by the
+// event is complete. The log written so far is stored, further Done and Abort
+// calls on the detached value are ignored and its lock is no longer refreshed
+// by the current process. Only the locked target remains locked while the
+// event is detached, the locks on its other targets are released.
+func (e *Event) Detach(ctx context.Context, locked eventTypes.Target) error {
+	updater.remove(e.ID)
+	e.detached = true
+	collection, err := storagev2.EventsCollection()
+	if err != nil {
+		return err
+	}
+	e.logMu.Lock()
+	defer e.logMu.Unlock()
+	set := mongoBSON.M{"structuredlog": e.StructuredLog}
+	update := mongoBSON.M{"$set": set}
+	if e.Lock != nil && *e.Lock != locked {
+		e.Lock = nil
+		update["$unset"] = mongoBSON.M{"lock": ""}
+	}
+	if len(e.ExtraTargets) > 0 {
+		for i := range e.ExtraTargets {
+			e.ExtraTargets[i].Lock = e.ExtraTargets[i].Lock && e.ExtraTargets[i].Target == locked
+		}
+		set["extratargets"] = e.ExtraTargets
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"_id": e.ID}, update)
+	return err
+}
+
 func (e *Event) Abort(ctx context.Context) error {
 	return e.done(ctx, nil, nil, true)
 }
@@ -1126,6 +1161,9 @@ func (e *Event) OtherData(value interface{}) error {
 }
 
 func (e *Event) done(ctx context.Context, evtErr error, customData interface{}, abort bool) (err error) {
+	if e.detached {
+		return nil
+	}
 	ctx = context.WithoutCancel(ctx)
 	// Done will be usually called in a defer block ignoring errors. This is
 	// why we log error messages here.
@@ -420,6 +420,81 @@ func (s *S) TestNewLockExpired(c *check.C) {
 	c.Assert(evts[1].Error, check.Matches, `event expired, no update for [\d.]+\w+`)
 }
 
+func (s *S) TestEventDetach(c *check.C) {
+	evt, err := New(context.TODO(), &Opts{
+		Target:  eventTypes.Target{Type: "app", Value: "myapp"},
+		Kind:    permission.PermAppUpdateEnvSet,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	evt.Logf("started")
+	err = evt.Detach(context.TODO(), evt.Target)
+	c.Assert(err, check.IsNil)
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	dbEvt, err := GetByID(context.TODO(), evt.UniqueID)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbEvt.Running, check.Equals, true)
+	c.Assert(dbEvt.Log(), check.Matches, `(?s).*started.*`)
+	dbEvt.Logf("finished")
+	err = dbEvt.Done(context.TODO(), errors.New("broker error"))
+	c.Assert(err, check.IsNil)
+	dbEvt, err = GetByID(context.TODO(), evt.UniqueID)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbEvt.Running, check.Equals, false)
+	c.Assert(dbEvt.Error, check.Equals, "broker error")
+	c.Assert(dbEvt.Log(), check.Matches, `(?s).*started.*finished.*`)
+}
+
+func (s *S) TestEventDetachKeepsOnlyLockedTarget(c *check.C) {
+	instanceTarget := eventTypes.Target{Type: eventTypes.TargetTypeServiceInstance, Value: "mysql/db"}
+	evt, err := New(context.TODO(), &Opts{
+		Target:       eventTypes.Target{Type: "app", Value: "myapp"},
+		ExtraTargets: []eventTypes.ExtraTarget{{Target: instanceTarget, Lock: true}},
+		Kind:         permission.PermAppUpdateBind,
+		Owner:        s.token,
+		Allowed:      Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.Detach(context.TODO(), instanceTarget)
+	c.Assert(err, check.IsNil)
+	appEvt, err := New(context.TODO(), &Opts{
+		Target:  eventTypes.Target{Type: "app", Value: "myapp"},
+		Kind:    permission.PermAppUpdateEnvSet,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	err = appEvt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	_, err = New(context.TODO(), &Opts{
+		Target:  instanceTarget,
+		Kind:    permission.PermServiceInstanceUpdate,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.FitsTypeOf, ErrEventLocked{})
+}
+
+func (s *S) TestKeepAlive(c *check.C) {
+	evt, err := New(context.TODO(), &Opts{
+		Target:  eventTypes.Target{Type: "app", Value: "myapp"},
+		Kind:    permission.PermAppUpdateEnvSet,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.Detach(context.TODO(), evt.Target)
+	c.Assert(err, check.IsNil)
+	time.Sleep(10 * time.Millisecond)
+	err = KeepAlive(context.TODO(), evt.ID)
+	c.Assert(err, check.IsNil)
+	dbEvt, err := GetByID(context.TODO(), evt.UniqueID)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbEvt.LockUpdateTime.After(evt.LockUpdateTime), check.Equals, true)
+}
+
 func (s *S) TestNewLockRetry(c *check.C) {
 	evt1, err := New(context.TODO(), &Opts{
 		Target:  eventTypes.Target{Type: "app", Value: "myapp"},
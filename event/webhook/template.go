@@ -0,0 +1,114 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+)
+
+// presetTemplates are body templates for common receivers, selected by the
+// webhook Preset field. They all produce JSON payloads.
+var presetTemplates = map[string]string{
+	"slack": `{"attachments": [{` +
+		`"color": "{{if .Error}}danger{{else}}good{{end}}", ` +
+		`"fallback": {{json (summary .)}}, ` +
+		`"title": {{json (printf "%s %s" .Kind.Name .Target)}}, ` +
+		`"text": {{json (summary .)}}, ` +
+		`"ts": {{.StartTime.Unix}}` +
+		`}]}`,
+	"teams": `{"@type": "MessageCard", "@context": "https://schema.org/extensions", ` +
+		`"themeColor": "{{if .Error}}D70000{{else}}2EB886{{end}}", ` +
+		`"summary": {{json (summary .)}}, ` +
+		`"title": {{json (printf "%s %s" .Kind.Name .Target)}}, ` +
+		`"text": {{json (summary .)}}}`,
+	"chat": `{"text": {{json (summary .)}}}`,
+}
+
+var templateFuncs = template.FuncMap{
+	"json":    jsonValue,
+	"summary": eventSummary,
+}
+
+func presetNames() []string {
+	names := make([]string, 0, len(presetTemplates))
+	for name := range presetTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// jsonValue encodes the value as JSON, it's meant to safely embed event
+// fields in JSON payloads, e.g. {"text": {{json .Error}}}.
+func jsonValue(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// eventSummary returns a human readable description of the event, like
+// "app.deploy on app(myapp) by me@me.com succeeded".
+func eventSummary(info *eventTypes.EventInfo) string {
+	status := "succeeded"
+	switch {
+	case info.Running:
+		status = "started"
+	case info.Error != "":
+		status = "failed: " + info.Error
+	}
+	return fmt.Sprintf("%s on %s by %s %s", info.Kind.Name, info.Target, info.Owner.Name, status)
+}
+
+func parseBodyTemplate(name, body string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Parse(body)
+}
+
+// validateBody checks that the body template, or the preset, is valid by
+// rendering it against an empty event. Errors caused only by missing data,
+// like accessing fields of absent custom data, are not reported.
+func validateBody(w eventTypes.Webhook) error {
+	body := w.Body
+	if w.Preset != "" {
+		if w.Body != "" {
+			return &tsuruErrors.ValidationError{Message: "webhook body and preset are mutually exclusive"}
+		}
+		var ok bool
+		body, ok = presetTemplates[w.Preset]
+		if !ok {
+			return &tsuruErrors.ValidationError{
+				Message: fmt.Sprintf("invalid webhook preset %q, valid presets are: %s", w.Preset, strings.Join(presetNames(), ", ")),
+			}
+		}
+	}
+	if body == "" {
+		return nil
+	}
+	tpl, err := parseBodyTemplate(w.Name, body)
+	if err != nil {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("webhook body is not a valid template: %v", err)}
+	}
+	sample := &eventTypes.EventInfo{
+		CustomData: eventTypes.EventInfoCustomData{
+			Start: map[string]interface{}{},
+			End:   map[string]interface{}{},
+			Other: map[string]interface{}{},
+		},
+	}
+	err = tpl.Execute(io.Discard, sample)
+	if err != nil && strings.Contains(err.Error(), "can't evaluate field") {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("webhook body is not a valid template: %v", err)}
+	}
+	return nil
+}
@@ -7,17 +7,20 @@ package webhook
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
-	"text/template"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/api/shutdown"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
@@ -35,6 +38,18 @@ var (
 	defaultUserAgent = "tsuru-webhook-client/1.0"
 )
 
+const (
+	signatureHeader = "X-Tsuru-Signature"
+
+	defaultMaxAttempts       = 5
+	defaultRetryBackoff      = 30 * time.Second
+	defaultRetryInterval     = 10 * time.Second
+	defaultDeliveryRetention = 7 * 24 * time.Hour
+	maxRetryBackoff          = time.Hour
+	retryLease               = 5 * time.Minute
+	maxResponseSnippet       = 1024
+)
+
 func WebhookService() (eventTypes.WebhookService, error) {
 	dbDriver, err := storage.GetCurrentDbDriver()
 	if err != nil {
@@ -44,30 +59,62 @@ func WebhookService() (eventTypes.WebhookService, error) {
 		}
 	}
 	s := &webhookService{
-		storage: dbDriver.WebhookStorage,
-		evtCh:   make(chan string, chanBufferSize),
-		quitCh:  make(chan struct{}),
-		doneCh:  make(chan struct{}),
+		storage:           dbDriver.WebhookStorage,
+		deliveryStorage:   dbDriver.WebhookDeliveryStorage,
+		evtCh:             make(chan string, chanBufferSize),
+		quitCh:            make(chan struct{}),
+		doneCh:            make(chan struct{}),
+		retryDoneCh:       make(chan struct{}),
+		maxAttempts:       configInt("webhooks:max-attempts", defaultMaxAttempts),
+		retryBackoff:      configDuration("webhooks:retry-backoff", defaultRetryBackoff),
+		retryInterval:     configDuration("webhooks:retry-interval", defaultRetryInterval),
+		deliveryRetention: configDuration("webhooks:delivery-retention", defaultDeliveryRetention),
 	}
 	err = s.initMetrics()
 	if err != nil {
 		return nil, err
 	}
 	go s.run()
+	go s.runRetries()
 	shutdown.Register(s)
 	return s, nil
 }
 
+func configInt(key string, defaultValue int) int {
+	value, err := config.GetInt(key)
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+func configDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := config.GetDuration(key)
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
 type webhookService struct {
-	storage eventTypes.WebhookStorage
-	evtCh   chan string
-	quitCh  chan struct{}
-	doneCh  chan struct{}
-
-	webhooksLatency prometheus.Histogram
-	webhooksTotal   prometheus.Counter
-	webhooksError   prometheus.Counter
-	webhooksQueue   prometheus.Collector
+	storage         eventTypes.WebhookStorage
+	deliveryStorage eventTypes.WebhookDeliveryStorage
+	evtCh           chan string
+	quitCh          chan struct{}
+	doneCh          chan struct{}
+	retryDoneCh     chan struct{}
+
+	maxAttempts       int
+	retryBackoff      time.Duration
+	retryInterval     time.Duration
+	deliveryRetention time.Duration
+
+	webhooksLatency    prometheus.Histogram
+	webhooksTotal      prometheus.Counter
+	webhooksError      prometheus.Counter
+	webhooksRetries    prometheus.Counter
+	webhooksDeadLetter prometheus.Counter
+	webhooksQueue      prometheus.Collector
 }
 
 func (s *webhookService) initMetrics() error {
@@ -83,6 +130,14 @@ func (s *webhookService) initMetrics() error {
 		Name: "tsuru_webhooks_calls_error",
 		Help: "The total number of webhooks calls with error",
 	})
+	s.webhooksRetries = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "tsuru_webhooks_retries_total",
+		Help: "The total number of webhooks calls retrying a failed delivery",
+	})
+	s.webhooksDeadLetter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "tsuru_webhooks_dead_letter_total",
+		Help: "The total number of webhooks deliveries moved to dead-letter after failing every attempt",
+	})
 	s.webhooksQueue = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
 		Name: "tsuru_webhooks_event_queue_current",
 		Help: "The current number of queued events waiting for webhooks processing",
@@ -93,6 +148,8 @@ func (s *webhookService) initMetrics() error {
 		s.webhooksLatency,
 		s.webhooksTotal,
 		s.webhooksError,
+		s.webhooksRetries,
+		s.webhooksDeadLetter,
 		s.webhooksQueue,
 	} {
 		err := prometheus.Register(c)
@@ -107,12 +164,16 @@ func (s *webhookService) Shutdown(ctx context.Context) error {
 	prometheus.Unregister(s.webhooksLatency)
 	prometheus.Unregister(s.webhooksTotal)
 	prometheus.Unregister(s.webhooksError)
+	prometheus.Unregister(s.webhooksRetries)
+	prometheus.Unregister(s.webhooksDeadLetter)
 	prometheus.Unregister(s.webhooksQueue)
 	close(s.quitCh)
-	select {
-	case <-s.doneCh:
-	case <-ctx.Done():
-		return ctx.Err()
+	for _, ch := range []chan struct{}{s.doneCh, s.retryDoneCh} {
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 	}
 	return nil
 }
@@ -159,7 +220,12 @@ func (s *webhookService) handleEvent(ctx context.Context, evtID string) error {
 		return err
 	}
 	for _, h := range hooks {
-		err = s.doHook(h, evt)
+		delivery := &eventTypes.WebhookDelivery{
+			Webhook:   h.Name,
+			EventID:   evtID,
+			CreatedAt: time.Now().UTC(),
+		}
+		err = s.deliver(ctx, h, evt, delivery, false)
 		if err != nil {
 			log.Errorf("[webhooks] error calling webhook %q for event %q: %v", h.Name, evtID, err)
 		}
@@ -167,15 +233,155 @@ func (s *webhookService) handleEvent(ctx context.Context, evtID string) error {
 	return nil
 }
 
+// runRetries periodically retries pending deliveries whose next attempt is
+// due, deliveries are claimed one at a time so multiple API instances may
+// process retries concurrently.
+func (s *webhookService) runRetries() {
+	defer close(s.retryDoneCh)
+	ticker := time.NewTicker(s.retryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.retryPending(context.Background())
+		case <-s.quitCh:
+			return
+		}
+	}
+}
+
+func (s *webhookService) retryPending(ctx context.Context) {
+	for {
+		select {
+		case <-s.quitCh:
+			return
+		default:
+		}
+		d, err := s.deliveryStorage.ClaimPending(ctx, time.Now().UTC(), retryLease)
+		if err != nil {
+			if err != eventTypes.ErrWebhookDeliveryNotFound {
+				log.Errorf("[webhooks] unable to fetch pending deliveries: %v", err)
+			}
+			return
+		}
+		s.webhooksRetries.Inc()
+		err = s.retry(ctx, d)
+		if err != nil {
+			log.Errorf("[webhooks] error retrying webhook %q for event %q: %v", d.Webhook, d.EventID, err)
+		}
+	}
+}
+
+func (s *webhookService) retry(ctx context.Context, d *eventTypes.WebhookDelivery) error {
+	hook, err := s.storage.FindByName(ctx, d.Webhook)
+	if err != nil {
+		return s.abandon(ctx, d, err)
+	}
+	evt, err := event.GetByHexID(ctx, d.EventID)
+	if err != nil {
+		return s.abandon(ctx, d, err)
+	}
+	return s.deliver(ctx, *hook, evt, d, false)
+}
+
+// abandon moves the delivery to dead-letter when it can no longer be
+// attempted, e.g. because the webhook or the event were removed.
+func (s *webhookService) abandon(ctx context.Context, d *eventTypes.WebhookDelivery, reason error) error {
+	now := time.Now().UTC()
+	d.Attempts = append(d.Attempts, eventTypes.WebhookDeliveryAttempt{Date: now, Error: reason.Error()})
+	d.Status = eventTypes.WebhookDeliveryDeadLetter
+	d.NextAttempt = time.Time{}
+	d.UpdatedAt = now
+	s.webhooksDeadLetter.Inc()
+	err := s.deliveryStorage.Update(ctx, d)
+	if err != nil {
+		return err
+	}
+	return reason
+}
+
+func (s *webhookService) backoff(attempts int) time.Duration {
+	backoff := s.retryBackoff
+	for i := 1; i < attempts && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+	return backoff
+}
+
+func automaticAttempts(d *eventTypes.WebhookDelivery) int {
+	var count int
+	for _, a := range d.Attempts {
+		if !a.Manual {
+			count++
+		}
+	}
+	return count
+}
+
+// deliver makes a single attempt for the delivery and records it. Failed
+// automatic attempts are scheduled for retry with exponential backoff until
+// maxAttempts is reached, after that the delivery is moved to dead-letter.
+// Failed manual redeliveries never schedule new retries.
+func (s *webhookService) deliver(ctx context.Context, hook eventTypes.Webhook, evt *event.Event, d *eventTypes.WebhookDelivery, manual bool) error {
+	attempt, err := s.doHook(hook, evt)
+	attempt.Manual = manual
+	now := time.Now().UTC()
+	d.Attempts = append(d.Attempts, attempt)
+	d.UpdatedAt = now
+	d.ExpireAt = now.Add(s.deliveryRetention)
+	switch {
+	case err == nil:
+		d.Status = eventTypes.WebhookDeliverySuccess
+		d.NextAttempt = time.Time{}
+	case manual && d.Status == eventTypes.WebhookDeliveryPending:
+	case manual || automaticAttempts(d) >= s.maxAttempts:
+		if d.Status != eventTypes.WebhookDeliveryDeadLetter {
+			s.webhooksDeadLetter.Inc()
+		}
+		d.Status = eventTypes.WebhookDeliveryDeadLetter
+		d.NextAttempt = time.Time{}
+	default:
+		d.Status = eventTypes.WebhookDeliveryPending
+		d.NextAttempt = now.Add(s.backoff(automaticAttempts(d)))
+	}
+	var saveErr error
+	if d.ID.IsZero() {
+		saveErr = s.deliveryStorage.Insert(ctx, d)
+	} else {
+		saveErr = s.deliveryStorage.Update(ctx, d)
+	}
+	if saveErr != nil {
+		log.Errorf("[webhooks] unable to store delivery of webhook %q for event %q: %v", hook.Name, d.EventID, saveErr)
+	}
+	return err
+}
+
 func webhookBody(hook *eventTypes.Webhook, evt *event.Event) (io.Reader, error) {
-	if hook.Body != "" {
-		tpl, err := template.New(hook.Name).Parse(hook.Body)
+	body := hook.Body
+	if preset, ok := presetTemplates[hook.Preset]; ok && body == "" {
+		body = preset
+		if hook.Headers == nil {
+			hook.Headers = make(http.Header)
+		}
+		if hook.Headers.Get("Content-Type") == "" {
+			hook.Headers.Set("Content-Type", "application/json")
+		}
+	}
+	if body != "" {
+		tpl, err := parseBodyTemplate(hook.Name, body)
 		if err != nil {
 			log.Errorf("[webhooks] unable to parse hook body for %q as template, using raw string: %v", hook.Name, err)
-			return strings.NewReader(hook.Body), nil
+			return strings.NewReader(body), nil
+		}
+		info, err := event.EventInfo(evt)
+		if err != nil {
+			return nil, err
 		}
 		buf := bytes.NewBuffer(nil)
-		err = tpl.Execute(buf, evt)
+		err = tpl.Execute(buf, info)
 		if err != nil {
 			return nil, err
 		}
@@ -197,11 +403,19 @@ func webhookBody(hook *eventTypes.Webhook, evt *event.Event) (io.Reader, error)
 	return bytes.NewReader(data), nil
 }
 
-func (s *webhookService) doHook(hook eventTypes.Webhook, evt *event.Event) (err error) {
+func signPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *webhookService) doHook(hook eventTypes.Webhook, evt *event.Event) (attempt eventTypes.WebhookDeliveryAttempt, err error) {
+	attempt.Date = time.Now().UTC()
 	defer func() {
 		s.webhooksTotal.Inc()
 		if err != nil {
 			s.webhooksError.Inc()
+			attempt.Error = err.Error()
 		}
 	}()
 	hook.Method = strings.ToUpper(hook.Method)
@@ -210,11 +424,19 @@ func (s *webhookService) doHook(hook eventTypes.Webhook, evt *event.Event) (err
 	}
 	body, err := webhookBody(&hook, evt)
 	if err != nil {
-		return err
+		return attempt, err
+	}
+	var payload []byte
+	if body != nil {
+		payload, err = io.ReadAll(body)
+		if err != nil {
+			return attempt, err
+		}
+		body = bytes.NewReader(payload)
 	}
 	req, err := http.NewRequest(hook.Method, hook.URL, body)
 	if err != nil {
-		return err
+		return attempt, err
 	}
 	req.Header = hook.Headers
 
@@ -225,6 +447,9 @@ func (s *webhookService) doHook(hook eventTypes.Webhook, evt *event.Event) (err
 	if req.UserAgent() == "" {
 		req.Header.Set("User-Agent", defaultUserAgent)
 	}
+	if hook.Secret != "" {
+		req.Header.Set(signatureHeader, signPayload(hook.Secret, payload))
+	}
 	client := tsuruNet.Dial15Full60ClientNoKeepAlive
 	if hook.Insecure {
 		client = tsuruNet.Dial15Full60ClientNoKeepAliveInsecure
@@ -232,26 +457,29 @@ func (s *webhookService) doHook(hook eventTypes.Webhook, evt *event.Event) (err
 	if hook.ProxyURL != "" {
 		client, err = tsuruNet.WithProxy(*client, hook.ProxyURL)
 		if err != nil {
-			return err
+			return attempt, err
 		}
 	} else {
 		client, err = tsuruNet.WithProxyFromConfig(*client, hook.URL)
 		if err != nil {
-			return err
+			return attempt, err
 		}
 	}
 	reqStart := time.Now()
 	rsp, err := client.Do(req)
-	s.webhooksLatency.Observe(time.Since(reqStart).Seconds())
+	attempt.Latency = time.Since(reqStart)
+	s.webhooksLatency.Observe(attempt.Latency.Seconds())
 	if err != nil {
-		return err
+		return attempt, err
 	}
 	defer rsp.Body.Close()
+	attempt.StatusCode = rsp.StatusCode
+	data, _ := io.ReadAll(io.LimitReader(rsp.Body, maxResponseSnippet))
+	attempt.Response = string(data)
 	if rsp.StatusCode < 200 || rsp.StatusCode >= 400 {
-		data, _ := io.ReadAll(rsp.Body)
-		return errors.Errorf("invalid status code calling hook: %d: %s", rsp.StatusCode, string(data))
+		return attempt, errors.Errorf("invalid status code calling hook: %d: %s", rsp.StatusCode, string(data))
 	}
-	return nil
+	return attempt, nil
 }
 
 func validateURLs(w eventTypes.Webhook) error {
@@ -288,6 +516,10 @@ func (s *webhookService) Create(ctx context.Context, w eventTypes.Webhook) error
 	if err != nil {
 		return err
 	}
+	err = validateBody(w)
+	if err != nil {
+		return err
+	}
 	return s.storage.Insert(ctx, w)
 }
 
@@ -296,6 +528,22 @@ func (s *webhookService) Update(ctx context.Context, w eventTypes.Webhook) error
 	if err != nil {
 		return err
 	}
+	err = validateBody(w)
+	if err != nil {
+		return err
+	}
+	if w.RemoveSecret {
+		if w.Secret != "" {
+			return &tsuruErrors.ValidationError{Message: "webhook secret and remove secret are mutually exclusive"}
+		}
+	} else if w.Secret == "" {
+		// secrets are never returned by the API, so updates without a
+		// secret keep the current one.
+		current, err := s.storage.FindByName(ctx, w.Name)
+		if err == nil {
+			w.Secret = current.Secret
+		}
+	}
 	return s.storage.Update(ctx, w)
 }
 
@@ -314,3 +562,32 @@ func (s *webhookService) Find(ctx context.Context, name string) (eventTypes.Webh
 func (s *webhookService) List(ctx context.Context, teams []string) ([]eventTypes.Webhook, error) {
 	return s.storage.FindAllByTeams(ctx, teams)
 }
+
+func (s *webhookService) Deliveries(ctx context.Context, filter eventTypes.WebhookDeliveryFilter) ([]eventTypes.WebhookDelivery, error) {
+	return s.deliveryStorage.Find(ctx, filter)
+}
+
+// Redeliver makes a new attempt for a delivery using the current webhook
+// settings, regardless of its status.
+func (s *webhookService) Redeliver(ctx context.Context, webhookName, deliveryID string) (*eventTypes.WebhookDelivery, error) {
+	d, err := s.deliveryStorage.FindByID(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if d.Webhook != webhookName {
+		return nil, eventTypes.ErrWebhookDeliveryNotFound
+	}
+	hook, err := s.storage.FindByName(ctx, webhookName)
+	if err != nil {
+		return nil, err
+	}
+	evt, err := event.GetByHexID(ctx, d.EventID)
+	if err != nil {
+		return nil, err
+	}
+	err = s.deliver(ctx, *hook, evt, d, true)
+	if err != nil {
+		log.Errorf("[webhooks] error redelivering webhook %q for event %q: %v", webhookName, d.EventID, err)
+	}
+	return d, nil
+}
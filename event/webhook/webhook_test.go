@@ -6,12 +6,19 @@ package webhook
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/db/storagev2"
@@ -386,3 +393,270 @@ func (s *S) TestWebhookServiceDeleteNotFound(c *check.C) {
 	err := s.service.Delete(context.TODO(), "xyz")
 	c.Assert(err, check.Equals, eventTypes.ErrWebhookNotFound)
 }
+
+func (s *S) newDoneEvent(c *check.C) *event.Event {
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:   eventTypes.Target{Type: "app", Value: "myapp"},
+		RawOwner: eventTypes.Owner{Type: "user", Name: "me@me.com"},
+		Kind:     permission.PermAppUpdateEnvSet,
+		Allowed:  event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxApp, "myapp")),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	return evt
+}
+
+func (s *S) TestWebhookServiceNotifySignature(c *check.C) {
+	evt := s.newDoneEvent(c)
+	called := make(chan struct{})
+	var receivedReq *http.Request
+	var receivedBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(called)
+		receivedBody, _ = io.ReadAll(r.Body)
+		receivedReq = r
+	}))
+	defer srv.Close()
+	err := s.service.storage.Insert(context.TODO(), eventTypes.Webhook{
+		Name:   "xyz",
+		URL:    srv.URL,
+		Body:   "ahoy",
+		Secret: "s3cr3t",
+	})
+	c.Assert(err, check.IsNil)
+	s.service.Notify(context.TODO(), evt.UniqueID.Hex())
+	<-called
+	c.Assert(string(receivedBody), check.Equals, "ahoy")
+	mac := hmac.New(sha256.New, []byte("s3cr3t"))
+	mac.Write([]byte("ahoy"))
+	c.Assert(receivedReq.Header.Get("X-Tsuru-Signature"), check.Equals, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+}
+
+func (s *S) TestWebhookServiceDeliveryHistory(c *check.C) {
+	evt := s.newDoneEvent(c)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("thanks"))
+	}))
+	defer srv.Close()
+	err := s.service.storage.Insert(context.TODO(), eventTypes.Webhook{Name: "xyz", URL: srv.URL})
+	c.Assert(err, check.IsNil)
+	err = s.service.handleEvent(context.TODO(), evt.UniqueID.Hex())
+	c.Assert(err, check.IsNil)
+	deliveries, err := s.service.Deliveries(context.TODO(), eventTypes.WebhookDeliveryFilter{Webhook: "xyz"})
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveries, check.HasLen, 1)
+	c.Assert(deliveries[0].EventID, check.Equals, evt.UniqueID.Hex())
+	c.Assert(deliveries[0].Status, check.Equals, eventTypes.WebhookDeliverySuccess)
+	c.Assert(deliveries[0].Attempts, check.HasLen, 1)
+	c.Assert(deliveries[0].Attempts[0].StatusCode, check.Equals, http.StatusOK)
+	c.Assert(deliveries[0].Attempts[0].Response, check.Equals, "thanks")
+	c.Assert(deliveries[0].Attempts[0].Error, check.Equals, "")
+}
+
+func (s *S) TestWebhookServiceRetryAndDeadLetter(c *check.C) {
+	s.service.maxAttempts = 2
+	s.service.retryBackoff = time.Millisecond
+	evt := s.newDoneEvent(c)
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("try later"))
+	}))
+	defer srv.Close()
+	err := s.service.storage.Insert(context.TODO(), eventTypes.Webhook{Name: "xyz", URL: srv.URL})
+	c.Assert(err, check.IsNil)
+	err = s.service.handleEvent(context.TODO(), evt.UniqueID.Hex())
+	c.Assert(err, check.IsNil)
+	deliveries, err := s.service.Deliveries(context.TODO(), eventTypes.WebhookDeliveryFilter{Webhook: "xyz"})
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveries, check.HasLen, 1)
+	c.Assert(deliveries[0].Status, check.Equals, eventTypes.WebhookDeliveryPending)
+	c.Assert(deliveries[0].NextAttempt.IsZero(), check.Equals, false)
+	c.Assert(deliveries[0].Attempts[0].StatusCode, check.Equals, http.StatusServiceUnavailable)
+	c.Assert(deliveries[0].Attempts[0].Error, check.Equals, "invalid status code calling hook: 503: try later")
+	time.Sleep(10 * time.Millisecond)
+	s.service.retryPending(context.TODO())
+	c.Assert(atomic.LoadInt32(&calls), check.Equals, int32(2))
+	deliveries, err = s.service.Deliveries(context.TODO(), eventTypes.WebhookDeliveryFilter{Webhook: "xyz"})
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveries[0].Status, check.Equals, eventTypes.WebhookDeliveryDeadLetter)
+	c.Assert(deliveries[0].Attempts, check.HasLen, 2)
+	s.service.retryPending(context.TODO())
+	c.Assert(atomic.LoadInt32(&calls), check.Equals, int32(2))
+}
+
+func (s *S) TestWebhookServiceRedeliver(c *check.C) {
+	s.service.maxAttempts = 1
+	evt := s.newDoneEvent(c)
+	var fail int32 = 1
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&fail) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+	err := s.service.storage.Insert(context.TODO(), eventTypes.Webhook{Name: "xyz", URL: srv.URL})
+	c.Assert(err, check.IsNil)
+	err = s.service.handleEvent(context.TODO(), evt.UniqueID.Hex())
+	c.Assert(err, check.IsNil)
+	deliveries, err := s.service.Deliveries(context.TODO(), eventTypes.WebhookDeliveryFilter{Status: eventTypes.WebhookDeliveryDeadLetter})
+	c.Assert(err, check.IsNil)
+	c.Assert(deliveries, check.HasLen, 1)
+	atomic.StoreInt32(&fail, 0)
+	d, err := s.service.Redeliver(context.TODO(), "xyz", deliveries[0].ID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(d.Status, check.Equals, eventTypes.WebhookDeliverySuccess)
+	c.Assert(d.Attempts, check.HasLen, 2)
+	c.Assert(d.Attempts[1].Manual, check.Equals, true)
+	_, err = s.service.Redeliver(context.TODO(), "other", deliveries[0].ID.Hex())
+	c.Assert(err, check.Equals, eventTypes.ErrWebhookDeliveryNotFound)
+}
+
+func (s *S) TestWebhookServiceUpdateKeepsSecret(c *check.C) {
+	err := s.service.Create(context.TODO(), eventTypes.Webhook{Name: "xyz", URL: "http://a", Secret: "s3cr3t"})
+	c.Assert(err, check.IsNil)
+	err = s.service.Update(context.TODO(), eventTypes.Webhook{Name: "xyz", URL: "http://b"})
+	c.Assert(err, check.IsNil)
+	w, err := s.service.Find(context.TODO(), "xyz")
+	c.Assert(err, check.IsNil)
+	c.Assert(w.URL, check.Equals, "http://b")
+	c.Assert(w.Secret, check.Equals, "s3cr3t")
+}
+
+func (s *S) TestWebhookServiceUpdateRemoveSecret(c *check.C) {
+	err := s.service.Create(context.TODO(), eventTypes.Webhook{Name: "xyz", URL: "http://a", Secret: "s3cr3t"})
+	c.Assert(err, check.IsNil)
+	err = s.service.Update(context.TODO(), eventTypes.Webhook{Name: "xyz", URL: "http://a", Secret: "other", RemoveSecret: true})
+	c.Assert(err, check.ErrorMatches, "webhook secret and remove secret are mutually exclusive")
+	err = s.service.Update(context.TODO(), eventTypes.Webhook{Name: "xyz", URL: "http://a", RemoveSecret: true})
+	c.Assert(err, check.IsNil)
+	w, err := s.service.Find(context.TODO(), "xyz")
+	c.Assert(err, check.IsNil)
+	c.Assert(w.Secret, check.Equals, "")
+	c.Assert(w.RemoveSecret, check.Equals, false)
+}
+
+func (s *S) TestWebhookServiceBackoff(c *check.C) {
+	s.service.retryBackoff = 30 * time.Second
+	c.Assert(s.service.backoff(1), check.Equals, 30*time.Second)
+	c.Assert(s.service.backoff(2), check.Equals, time.Minute)
+	c.Assert(s.service.backoff(3), check.Equals, 2*time.Minute)
+	c.Assert(s.service.backoff(20), check.Equals, time.Hour)
+}
+
+func (s *S) TestWebhookServiceNotifyPreset(c *check.C) {
+	evt := s.newDoneEvent(c)
+	called := make(chan struct{})
+	var receivedReq *http.Request
+	var receivedBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(called)
+		receivedBody, _ = io.ReadAll(r.Body)
+		receivedReq = r
+	}))
+	defer srv.Close()
+	err := s.service.storage.Insert(context.TODO(), eventTypes.Webhook{Name: "xyz", URL: srv.URL, Preset: "chat"})
+	c.Assert(err, check.IsNil)
+	s.service.Notify(context.TODO(), evt.UniqueID.Hex())
+	<-called
+	c.Assert(receivedReq.Header.Get("Content-Type"), check.Equals, "application/json")
+	c.Assert(string(receivedBody), check.Equals, `{"text": "app.update.env.set on app(myapp) by me@me.com succeeded"}`)
+}
+
+func (s *S) TestWebhookServiceNotifyTemplateCustomData(c *check.C) {
+	evt, err := event.New(context.TODO(), &event.Opts{
+		Target:     eventTypes.Target{Type: "app", Value: "myapp"},
+		RawOwner:   eventTypes.Owner{Type: "user", Name: "me@me.com"},
+		Kind:       permission.PermAppDeploy,
+		CustomData: map[string]interface{}{"image": "tsuru/app-myapp:v1"},
+		Allowed:    event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxApp, "myapp")),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.Done(context.TODO(), errors.New("deploy \"failed\""))
+	c.Assert(err, check.IsNil)
+	called := make(chan struct{})
+	var receivedBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(called)
+		receivedBody, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+	err = s.service.storage.Insert(context.TODO(), eventTypes.Webhook{
+		Name: "xyz",
+		URL:  srv.URL,
+		Body: `{"image": {{json .CustomData.Start.image}}, "error": {{json .Error}}, "owner": "{{.Owner.Name}}"}`,
+	})
+	c.Assert(err, check.IsNil)
+	s.service.Notify(context.TODO(), evt.UniqueID.Hex())
+	<-called
+	c.Assert(string(receivedBody), check.Equals, `{"image": "tsuru/app-myapp:v1", "error": "deploy \"failed\"", "owner": "me@me.com"}`)
+}
+
+func (s *S) TestWebhookServiceCreateInvalidBody(c *check.C) {
+	tests := []struct {
+		webhook     eventTypes.Webhook
+		expectedErr string
+	}{
+		{
+			webhook:     eventTypes.Webhook{Name: "a", URL: "http://a", Body: "{{ --"},
+			expectedErr: `webhook body is not a valid template: .*`,
+		},
+		{
+			webhook:     eventTypes.Webhook{Name: "a", URL: "http://a", Body: "{{.Nope}}"},
+			expectedErr: `webhook body is not a valid template: .*can't evaluate field Nope.*`,
+		},
+		{
+			webhook:     eventTypes.Webhook{Name: "a", URL: "http://a", Preset: "irc"},
+			expectedErr: `invalid webhook preset "irc", valid presets are: chat, slack, teams`,
+		},
+		{
+			webhook:     eventTypes.Webhook{Name: "a", URL: "http://a", Preset: "slack", Body: "x"},
+			expectedErr: `webhook body and preset are mutually exclusive`,
+		},
+	}
+	for _, tt := range tests {
+		err := s.service.Create(context.TODO(), tt.webhook)
+		c.Check(err, check.ErrorMatches, tt.expectedErr)
+		err = s.service.Update(context.TODO(), tt.webhook)
+		c.Check(err, check.ErrorMatches, tt.expectedErr)
+	}
+	for _, preset := range []string{"slack", "teams", "chat"} {
+		err := s.service.Create(context.TODO(), eventTypes.Webhook{Name: preset, URL: "http://a", Preset: preset})
+		c.Check(err, check.IsNil)
+	}
+	err := s.service.Create(context.TODO(), eventTypes.Webhook{Name: "custom", URL: "http://a", Body: "{{.CustomData.Start.app.name}} {{summary .}}"})
+	c.Check(err, check.IsNil)
+}
+
+func (s *S) TestEventSummary(c *check.C) {
+	info := &eventTypes.EventInfo{EventData: eventTypes.EventData{
+		Target: eventTypes.Target{Type: "app", Value: "myapp"},
+		Kind:   eventTypes.Kind{Type: eventTypes.KindTypePermission, Name: "app.deploy"},
+		Owner:  eventTypes.Owner{Type: eventTypes.OwnerTypeUser, Name: "me@me.com"},
+	}}
+	c.Assert(eventSummary(info), check.Equals, "app.deploy on app(myapp) by me@me.com succeeded")
+	info.Error = "boom"
+	c.Assert(eventSummary(info), check.Equals, "app.deploy on app(myapp) by me@me.com failed: boom")
+	info.Running = true
+	c.Assert(eventSummary(info), check.Equals, "app.deploy on app(myapp) by me@me.com started")
+}
+
+func (s *S) TestPresetTemplatesRenderValidJSON(c *check.C) {
+	info := &eventTypes.EventInfo{EventData: eventTypes.EventData{
+		Target: eventTypes.Target{Type: "app", Value: "myapp"},
+		Kind:   eventTypes.Kind{Name: "app.deploy"},
+		Error:  "a \"quoted\"\nerror",
+	}}
+	for name, body := range presetTemplates {
+		tpl, err := parseBodyTemplate(name, body)
+		c.Assert(err, check.IsNil)
+		var buf strings.Builder
+		err = tpl.Execute(&buf, info)
+		c.Assert(err, check.IsNil)
+		var data map[string]interface{}
+		err = json.Unmarshal([]byte(buf.String()), &data)
+		c.Assert(err, check.IsNil, check.Commentf("preset %s: %s", name, buf.String()))
+	}
+}
@@ -159,21 +159,33 @@ func (l *lockUpdater) spin() {
 			continue
 		}
 
-		collection, err := storagev2.EventsCollection()
-
-		if err != nil {
-			log.Errorf("[events] [lock update] error getting db conn: %s", err)
-			continue
-		}
 		slice := make([]primitive.ObjectID, len(set))
 		i := 0
 		for id := range set {
 			slice[i] = id
 			i++
 		}
-		_, err = collection.UpdateMany(ctx, mongoBSON.M{"_id": mongoBSON.M{"$in": slice}}, mongoBSON.M{"$set": mongoBSON.M{"lockupdatetime": time.Now().UTC()}})
-		if err != nil && err != mongo.ErrNoDocuments {
-			log.Errorf("[events] [lock update] error updating: %s", err)
+		err := KeepAlive(ctx, slice...)
+		if err != nil {
+			log.Errorf("[events] [lock update] %s", err)
 		}
 	}
 }
+
+// KeepAlive refreshes the lock of running events, preventing them from being
+// expired by the event cleaner. It must be periodically called by workers
+// owning detached events.
+func KeepAlive(ctx context.Context, ids ...primitive.ObjectID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	collection, err := storagev2.EventsCollection()
+	if err != nil {
+		return errors.Wrap(err, "error getting db conn")
+	}
+	_, err = collection.UpdateMany(ctx, mongoBSON.M{"_id": mongoBSON.M{"$in": ids}, "running": true}, mongoBSON.M{"$set": mongoBSON.M{"lockupdatetime": time.Now().UTC()}})
+	if err != nil && err != mongo.ErrNoDocuments {
+		return errors.Wrap(err, "error updating")
+	}
+	return nil
+}
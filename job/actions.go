@@ -61,11 +61,15 @@ var triggerCron = action.Action{
 		default:
 			return nil, errors.New("first parameter must be *Job")
 		}
+		var opts jobTypes.TriggerOpts
+		if len(ctx.Params) > 1 {
+			opts, _ = ctx.Params[1].(jobTypes.TriggerOpts)
+		}
 		prov, err := getProvisioner(ctx.Context, job)
 		if err != nil {
 			return nil, err
 		}
-		return nil, prov.TriggerCron(ctx.Context, job.Name, job.Pool)
+		return prov.TriggerCron(ctx.Context, job.Name, job.Pool, opts)
 	},
 	MinParams: 1,
 }
@@ -30,6 +30,7 @@ import (
 	bindTypes "github.com/tsuru/tsuru/types/bind"
 	jobTypes "github.com/tsuru/tsuru/types/job"
 	provTypes "github.com/tsuru/tsuru/types/provision"
+	volumeTypes "github.com/tsuru/tsuru/types/volume"
 	mongoBSON "go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
@@ -103,6 +104,12 @@ func (*jobService) RemoveJob(ctx context.Context, job *jobTypes.Job) error {
 	if result.DeletedCount == 0 {
 		return jobTypes.ErrJobNotFound
 	}
+	if err = unbindVolumes(ctx, job); err != nil {
+		return err
+	}
+	if err = removeRuns(ctx, job.Name); err != nil {
+		return err
+	}
 
 	servicemanager.TeamQuota.Inc(ctx, &authTypes.Team{Name: job.TeamOwner}, -1)
 	var user *auth.User
@@ -112,6 +119,30 @@ func (*jobService) RemoveJob(ctx context.Context, job *jobTypes.Job) error {
 	return nil
 }
 
+func unbindVolumes(ctx context.Context, job *jobTypes.Job) error {
+	volumes, err := servicemanager.Volume.ListByJob(ctx, job.Name)
+	if err != nil {
+		return errors.Wrap(err, "Unable to list volumes for unbind")
+	}
+	for _, v := range volumes {
+		binds, err := servicemanager.Volume.BindsForJob(ctx, &v, job.Name)
+		if err != nil {
+			return errors.Wrap(err, "Unable to list volume binds for unbind")
+		}
+		for _, b := range binds {
+			err = servicemanager.Volume.UnbindJob(ctx, &volumeTypes.BindOpts{
+				Volume:     &v,
+				JobName:    job.Name,
+				MountPoint: b.ID.MountPoint,
+			})
+			if err != nil {
+				return errors.Wrapf(err, "Unable to unbind volume %q in %q", v.Name, b.ID.MountPoint)
+			}
+		}
+	}
+	return nil
+}
+
 func (*jobService) RemoveJobProv(ctx context.Context, job *jobTypes.Job) error {
 	prov, err := getProvisioner(ctx, job)
 	if err != nil {
@@ -317,9 +348,18 @@ func (*jobService) UpdateJob(ctx context.Context, newJob, oldJob *jobTypes.Job,
 	// in other words: we merge the non-empty values of oldJob and add to the empty values of newJob
 	// TODO: add an option to erase old values, it can be easily done with mergo.Merge(dst, src, mergo.WithOverwriteWithEmptyValue),
 	// in which case we would switch oldJob to be dst and newJob to be src
+	// mergo refills nil and empty slices, so dependents are handled explicitly:
+	// a nil list keeps the current dependents and an empty list removes them
+	onSuccess, onFailure := newJob.Spec.OnSuccess, newJob.Spec.OnFailure
 	if err := mergo.Merge(newJob, oldJob); err != nil {
 		return err
 	}
+	if onSuccess != nil {
+		newJob.Spec.OnSuccess = onSuccess
+	}
+	if onFailure != nil {
+		newJob.Spec.OnFailure = onFailure
+	}
 
 	if deployOptionsHasChanged {
 		err := buildWithDeployAgent(ctx, newJob)
@@ -409,9 +449,21 @@ func (*jobService) UpdateJobProv(ctx context.Context, job *jobTypes.Job) error {
 	return prov.EnsureJob(ctx, job)
 }
 
-// Trigger triggers an execution of either job or cronjob object
-func (*jobService) Trigger(ctx context.Context, job *jobTypes.Job) error {
-	return action.NewPipeline([]*action.Action{&triggerCron}...).Execute(ctx, job)
+// Trigger triggers an execution of either job or cronjob object, returning
+// the ID of the triggered run.
+func (*jobService) Trigger(ctx context.Context, job *jobTypes.Job, opts jobTypes.TriggerOpts) (string, error) {
+	if opts.HasOverrides() {
+		if err := validateTriggerOverrides(ctx, job, opts); err != nil {
+			return "", err
+		}
+	}
+	pipeline := action.NewPipeline([]*action.Action{&triggerCron}...)
+	err := pipeline.Execute(ctx, job, opts)
+	if err != nil {
+		return "", err
+	}
+	runID, _ := pipeline.Result().(string)
+	return runID, nil
 }
 
 func filterQuery(f *jobTypes.Filter) mongoBSON.M {
@@ -646,6 +698,40 @@ func validateTeamOwner(ctx context.Context, job *jobTypes.Job, p *pool.Pool) err
 	return &tsuruErrors.ValidationError{Message: msg}
 }
 
+// validateTriggerOverrides ensures a run overriding the job definition is
+// valid and still allowed in the job pool, with its plan. The image may only
+// change its tag, so an ad-hoc run can't be used to run arbitrary images with
+// the job credentials.
+func validateTriggerOverrides(ctx context.Context, j *jobTypes.Job, opts jobTypes.TriggerOpts) error {
+	if err := validatePool(ctx, j); err != nil {
+		return &tsuruErrors.ValidationError{Message: err.Error()}
+	}
+	if err := validatePlan(ctx, j.Pool, j.Plan.Name); err != nil {
+		return err
+	}
+	if opts.ActiveDeadlineSeconds != nil && *opts.ActiveDeadlineSeconds <= 0 {
+		return &tsuruErrors.ValidationError{Message: jobTypes.ErrInvalidActiveDeadline.Error()}
+	}
+	for _, env := range opts.Envs {
+		if env.Name == "" {
+			return &tsuruErrors.ValidationError{Message: "env name cannot be empty"}
+		}
+	}
+	if opts.Image == "" {
+		return nil
+	}
+	repo, _ := image.SplitImageName(opts.Image)
+	for _, jobImage := range []string{j.Spec.Container.OriginalImageSrc, j.Spec.Container.InternalRegistryImage} {
+		if jobImage == "" {
+			continue
+		}
+		if jobRepo, _ := image.SplitImageName(jobImage); jobRepo == repo {
+			return nil
+		}
+	}
+	return &tsuruErrors.ValidationError{Message: fmt.Sprintf("image %q must be a tag of the job image", opts.Image)}
+}
+
 func validateJob(ctx context.Context, j *jobTypes.Job) error {
 	if err := validatePool(ctx, j); err != nil {
 		return &tsuruErrors.ValidationError{Message: err.Error()}
@@ -653,6 +739,9 @@ func validateJob(ctx context.Context, j *jobTypes.Job) error {
 	if err := validatePlan(ctx, j.Pool, j.Plan.Name); err != nil {
 		return err
 	}
+	if err := validateDependents(ctx, j); err != nil {
+		return err
+	}
 	if !j.Spec.Manual {
 		c := cron.New()
 		if _, err := c.AddFunc(j.Spec.Schedule, func() {}); err != nil {
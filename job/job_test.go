@@ -20,6 +20,7 @@ import (
 	jobTypes "github.com/tsuru/tsuru/types/job"
 	provisionTypes "github.com/tsuru/tsuru/types/provision"
 	"github.com/tsuru/tsuru/types/quota"
+	volumeTypes "github.com/tsuru/tsuru/types/volume"
 	"gopkg.in/check.v1"
 )
 
@@ -178,6 +179,43 @@ func (s *S) TestDeleteJobFromDB(c *check.C) {
 	c.Assert(err, check.Equals, jobTypes.ErrJobNotFound)
 }
 
+func (s *S) TestDeleteJobUnbindsVolumes(c *check.C) {
+	newJob := jobTypes.Job{
+		Name:      "some-job",
+		TeamOwner: s.team.Name,
+		Pool:      s.Pool,
+		Teams:     []string{s.team.Name},
+		Spec: jobTypes.JobSpec{
+			Schedule: "* * * * *",
+			Container: jobTypes.ContainerInfo{
+				OriginalImageSrc: "alpine:latest",
+			},
+		},
+	}
+	err := servicemanager.Job.CreateJob(context.TODO(), &newJob, s.user)
+	c.Assert(err, check.IsNil)
+	v := volumeTypes.Volume{Name: "v1"}
+	s.mockService.VolumeService.OnListByJob = func(ctx context.Context, jobName string) ([]volumeTypes.Volume, error) {
+		c.Assert(jobName, check.Equals, "some-job")
+		return []volumeTypes.Volume{v}, nil
+	}
+	s.mockService.VolumeService.OnBindsForJob = func(ctx context.Context, vol *volumeTypes.Volume, jobName string) ([]volumeTypes.VolumeBind, error) {
+		return []volumeTypes.VolumeBind{
+			{ID: volumeTypes.VolumeBindID{Job: jobName, Volume: vol.Name, MountPoint: "/mnt1"}},
+			{ID: volumeTypes.VolumeBindID{Job: jobName, Volume: vol.Name, MountPoint: "/mnt2"}},
+		}, nil
+	}
+	var unbound []string
+	s.mockService.VolumeService.OnUnbindJob = func(ctx context.Context, opts *volumeTypes.BindOpts) error {
+		c.Assert(opts.JobName, check.Equals, "some-job")
+		unbound = append(unbound, opts.Volume.Name+":"+opts.MountPoint)
+		return nil
+	}
+	err = servicemanager.Job.RemoveJob(context.TODO(), &newJob)
+	c.Assert(err, check.IsNil)
+	c.Assert(unbound, check.DeepEquals, []string{"v1:/mnt1", "v1:/mnt2"})
+}
+
 func (s *S) TestIncreaseDecreaseQuotaForJob(c *check.C) {
 	var userinUseNow *int
 	var teaminUseNow *int
@@ -944,11 +982,103 @@ func (s *S) TestTriggerCronShouldExecuteJob(c *check.C) {
 	c.Assert(err, check.IsNil)
 	c.Assert(s.provisioner.ProvisionedJob(j1.Name), check.Equals, true)
 	c.Assert(s.provisioner.JobExecutions(j1.Name), check.Equals, 0)
-	err = servicemanager.Job.Trigger(context.TODO(), &j1)
+	runID, err := servicemanager.Job.Trigger(context.TODO(), &j1, jobTypes.TriggerOpts{})
 	c.Assert(err, check.IsNil)
+	c.Assert(runID, check.Equals, "some-job-run-1")
 	c.Assert(s.provisioner.JobExecutions(j1.Name), check.Equals, 1)
 }
 
+func (s *S) TestTriggerWithOverrides(c *check.C) {
+	j1 := jobTypes.Job{
+		Name:      "some-job",
+		TeamOwner: s.team.Name,
+		Pool:      s.Pool,
+		Teams:     []string{s.team.Name},
+		Spec: jobTypes.JobSpec{
+			Manual: true,
+			Container: jobTypes.ContainerInfo{
+				OriginalImageSrc: "registry.example.com/tsuru/myjob:v1",
+				Command:          []string{"echo", "hello world!"},
+			},
+		},
+	}
+	err := servicemanager.Job.CreateJob(context.TODO(), &j1, s.user)
+	c.Assert(err, check.IsNil)
+	deadline := int64(60)
+	opts := jobTypes.TriggerOpts{
+		Command:               []string{"echo", "bye"},
+		Envs:                  []bindTypes.EnvVar{{Name: "DRY_RUN", Value: "true"}},
+		Image:                 "registry.example.com/tsuru/myjob:v2",
+		ActiveDeadlineSeconds: &deadline,
+	}
+	_, err = servicemanager.Job.Trigger(context.TODO(), &j1, opts)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.provisioner.JobTriggers(j1.Name), check.DeepEquals, []jobTypes.TriggerOpts{opts})
+}
+
+func (s *S) TestTriggerWithInvalidOverrides(c *check.C) {
+	j1 := jobTypes.Job{
+		Name:      "some-job",
+		TeamOwner: s.team.Name,
+		Pool:      s.Pool,
+		Teams:     []string{s.team.Name},
+		Spec: jobTypes.JobSpec{
+			Manual: true,
+			Container: jobTypes.ContainerInfo{
+				OriginalImageSrc: "registry.example.com/tsuru/myjob:v1",
+			},
+		},
+	}
+	err := servicemanager.Job.CreateJob(context.TODO(), &j1, s.user)
+	c.Assert(err, check.IsNil)
+	zero := int64(0)
+	tests := []struct {
+		opts     jobTypes.TriggerOpts
+		expected string
+	}{
+		{jobTypes.TriggerOpts{Image: "registry.example.com/tsuru/other:v1"}, `image "registry.example.com/tsuru/other:v1" must be a tag of the job image`},
+		{jobTypes.TriggerOpts{Image: "evil.example.com/tsuru/myjob:v1"}, `image "evil.example.com/tsuru/myjob:v1" must be a tag of the job image`},
+		{jobTypes.TriggerOpts{ActiveDeadlineSeconds: &zero}, jobTypes.ErrInvalidActiveDeadline.Error()},
+		{jobTypes.TriggerOpts{Envs: []bindTypes.EnvVar{{Value: "x"}}}, "env name cannot be empty"},
+	}
+	for _, tt := range tests {
+		_, err = servicemanager.Job.Trigger(context.TODO(), &j1, tt.opts)
+		c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+		c.Assert(err.Error(), check.Equals, tt.expected)
+	}
+	c.Assert(s.provisioner.JobExecutions(j1.Name), check.Equals, 0)
+}
+
+func (s *S) TestTriggerWithOverridesDisallowedPlan(c *check.C) {
+	j1 := jobTypes.Job{
+		Name:      "some-job",
+		TeamOwner: s.team.Name,
+		Pool:      s.Pool,
+		Teams:     []string{s.team.Name},
+		Spec: jobTypes.JobSpec{
+			Manual: true,
+			Container: jobTypes.ContainerInfo{
+				OriginalImageSrc: "registry.example.com/tsuru/myjob:v1",
+			},
+		},
+	}
+	err := servicemanager.Job.CreateJob(context.TODO(), &j1, s.user)
+	c.Assert(err, check.IsNil)
+	j1.Plan.Name = "unknown-plan"
+	_, err = servicemanager.Job.Trigger(context.TODO(), &j1, jobTypes.TriggerOpts{Command: []string{"ls"}})
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	c.Assert(err.Error(), check.Equals, fmt.Sprintf("Job plan %q is not allowed on pool %q", "unknown-plan", s.Pool))
+	c.Assert(s.provisioner.JobExecutions(j1.Name), check.Equals, 0)
+}
+
+func (s *S) TestTriggerOptsHasOverrides(c *check.C) {
+	envs := []bindTypes.EnvVar{{Name: "A", Value: "1"}}
+	c.Assert(jobTypes.TriggerOpts{}.HasOverrides(), check.Equals, false)
+	c.Assert(jobTypes.TriggerOpts{Envs: envs}.HasOverrides(), check.Equals, true)
+	c.Assert(jobTypes.TriggerOpts{Envs: envs, Workflow: "wf1"}.HasOverrides(), check.Equals, false)
+	c.Assert(jobTypes.TriggerOpts{Command: []string{"ls"}, Workflow: "wf1"}.HasOverrides(), check.Equals, true)
+}
+
 func (s *S) TestList(c *check.C) {
 	j1 := jobTypes.Job{
 		Name:      "j1",
@@ -0,0 +1,92 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package job
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const defaultRunsLimit = 100
+
+type jobRunService struct{}
+
+func JobRunService() (jobTypes.JobRunService, error) {
+	return &jobRunService{}, nil
+}
+
+// SaveRun creates or replaces a job run, as reported by the provisioner every
+// time the run changes, and advances the workflow the run is part of.
+func (*jobRunService) SaveRun(ctx context.Context, run *jobTypes.Run) error {
+	if run.EndTime != nil {
+		run.Duration = run.EndTime.Sub(run.StartTime).Seconds()
+	}
+	collection, err := storagev2.JobRunsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.ReplaceOne(ctx, mongoBSON.M{"_id": run.ID}, run, options.Replace().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+	return advanceWorkflow(ctx, run)
+}
+
+// ListRuns returns the runs of a job, the most recent first.
+func (*jobRunService) ListRuns(ctx context.Context, jobName string, filter jobTypes.RunFilter) ([]jobTypes.Run, error) {
+	query := mongoBSON.M{"job": jobName}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultRunsLimit
+	}
+	collection, err := storagev2.JobRunsCollection()
+	if err != nil {
+		return nil, err
+	}
+	opts := options.Find().SetSort(mongoBSON.M{"starttime": -1}).SetLimit(int64(limit))
+	cursor, err := collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	runs := []jobTypes.Run{}
+	err = cursor.All(ctx, &runs)
+	if err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+func (*jobRunService) GetRun(ctx context.Context, jobName, id string) (*jobTypes.Run, error) {
+	collection, err := storagev2.JobRunsCollection()
+	if err != nil {
+		return nil, err
+	}
+	var run jobTypes.Run
+	err = collection.FindOne(ctx, mongoBSON.M{"_id": id, "job": jobName}).Decode(&run)
+	if err == mongo.ErrNoDocuments {
+		return nil, jobTypes.ErrJobRunNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func removeRuns(ctx context.Context, jobName string) error {
+	collection, err := storagev2.JobRunsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.DeleteMany(ctx, mongoBSON.M{"job": jobName})
+	return err
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/servicemanager"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestSaveAndGetRun(c *check.C) {
+	start := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	run := jobTypes.Run{ID: "run1", Job: "myjob", Unit: "myjob-1", Status: jobTypes.RunStatusRunning, StartTime: start}
+	err := servicemanager.JobRun.SaveRun(context.TODO(), &run)
+	c.Assert(err, check.IsNil)
+	end := start.Add(time.Minute)
+	run.Status = jobTypes.RunStatusSucceeded
+	run.EndTime = &end
+	err = servicemanager.JobRun.SaveRun(context.TODO(), &run)
+	c.Assert(err, check.IsNil)
+	dbRun, err := servicemanager.JobRun.GetRun(context.TODO(), "myjob", "run1")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbRun.Status, check.Equals, jobTypes.RunStatusSucceeded)
+	c.Assert(dbRun.Duration, check.Equals, 60.0)
+	c.Assert(dbRun.EndTime.Equal(end), check.Equals, true)
+	_, err = servicemanager.JobRun.GetRun(context.TODO(), "otherjob", "run1")
+	c.Assert(err, check.Equals, jobTypes.ErrJobRunNotFound)
+}
+
+func (s *S) TestListRuns(c *check.C) {
+	start := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	for i, status := range []jobTypes.RunStatus{jobTypes.RunStatusFailed, jobTypes.RunStatusSucceeded, jobTypes.RunStatusRunning} {
+		run := jobTypes.Run{
+			ID:        string(status),
+			Job:       "myjob",
+			Status:    status,
+			StartTime: start.Add(time.Duration(i) * time.Hour),
+		}
+		err := servicemanager.JobRun.SaveRun(context.TODO(), &run)
+		c.Assert(err, check.IsNil)
+	}
+	runs, err := servicemanager.JobRun.ListRuns(context.TODO(), "myjob", jobTypes.RunFilter{})
+	c.Assert(err, check.IsNil)
+	c.Assert(runs, check.HasLen, 3)
+	c.Assert(runs[0].ID, check.Equals, "running")
+	c.Assert(runs[2].ID, check.Equals, "failed")
+	runs, err = servicemanager.JobRun.ListRuns(context.TODO(), "myjob", jobTypes.RunFilter{Limit: 1})
+	c.Assert(err, check.IsNil)
+	c.Assert(runs, check.HasLen, 1)
+	c.Assert(runs[0].ID, check.Equals, "running")
+	runs, err = servicemanager.JobRun.ListRuns(context.TODO(), "myjob", jobTypes.RunFilter{Status: jobTypes.RunStatusSucceeded})
+	c.Assert(err, check.IsNil)
+	c.Assert(runs, check.HasLen, 1)
+	c.Assert(runs[0].ID, check.Equals, "succeeded")
+}
+
+func (s *S) TestRemoveJobRemovesRuns(c *check.C) {
+	newJob := jobTypes.Job{
+		Name:      "some-job",
+		TeamOwner: s.team.Name,
+		Pool:      s.Pool,
+		Teams:     []string{s.team.Name},
+		Spec: jobTypes.JobSpec{
+			Schedule: "* * * * *",
+			Container: jobTypes.ContainerInfo{
+				OriginalImageSrc: "alpine:latest",
+			},
+		},
+	}
+	err := servicemanager.Job.CreateJob(context.TODO(), &newJob, s.user)
+	c.Assert(err, check.IsNil)
+	run := jobTypes.Run{ID: "run1", Job: "some-job", StartTime: time.Now().UTC()}
+	err = servicemanager.JobRun.SaveRun(context.TODO(), &run)
+	c.Assert(err, check.IsNil)
+	err = servicemanager.Job.RemoveJob(context.TODO(), &newJob)
+	c.Assert(err, check.IsNil)
+	runs, err := servicemanager.JobRun.ListRuns(context.TODO(), "some-job", jobTypes.RunFilter{})
+	c.Assert(err, check.IsNil)
+	c.Assert(runs, check.HasLen, 0)
+}
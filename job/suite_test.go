@@ -195,4 +195,6 @@ func (s *S) SetUpTest(c *check.C) {
 	c.Assert(err, check.IsNil)
 	servicemanager.Job, err = JobService()
 	c.Assert(err, check.IsNil)
+	servicemanager.JobRun, err = JobRunService()
+	c.Assert(err, check.IsNil)
 }
@@ -0,0 +1,409 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package job
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	workflowEventKind            = "job-workflow"
+	defaultWorkflowTimeout       = 24 * time.Hour
+	workflowTimeoutCheckInterval = time.Minute
+)
+
+// InitializeWorkflowTimeouts starts the periodic check failing the steps of
+// workflows running for longer than the jobs:workflow-timeout config.
+func InitializeWorkflowTimeouts() error {
+	timeout, err := config.GetDuration("jobs:workflow-timeout")
+	if err != nil || timeout <= 0 {
+		timeout = defaultWorkflowTimeout
+	}
+	w := &workflowExpirer{timeout: timeout, once: &sync.Once{}}
+	w.start()
+	shutdown.Register(w)
+	return nil
+}
+
+type workflowExpirer struct {
+	timeout time.Duration
+	once    *sync.Once
+	stopCh  chan struct{}
+}
+
+func (w *workflowExpirer) start() {
+	w.once.Do(func() {
+		w.stopCh = make(chan struct{})
+		go w.spin()
+	})
+}
+
+func (w *workflowExpirer) Shutdown(ctx context.Context) error {
+	if w.stopCh == nil {
+		return nil
+	}
+	w.stopCh <- struct{}{}
+	w.stopCh = nil
+	w.once = &sync.Once{}
+	return nil
+}
+
+func (w *workflowExpirer) spin() {
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-time.After(workflowTimeoutCheckInterval):
+		}
+		err := expireWorkflows(context.Background(), w.timeout)
+		if err != nil {
+			log.Errorf("[job workflow expirer] %v", err)
+		}
+	}
+}
+
+// expireWorkflows fails the steps still running in workflows started longer
+// than timeout ago and finishes them, as a dependent whose run never reports
+// its result would keep its workflow running forever.
+func expireWorkflows(ctx context.Context, timeout time.Duration) error {
+	collection, err := storagev2.JobWorkflowsCollection()
+	if err != nil {
+		return err
+	}
+	cursor, err := collection.Find(ctx, mongoBSON.M{
+		"status":    jobTypes.RunStatusRunning,
+		"starttime": mongoBSON.M{"$lt": time.Now().UTC().Add(-timeout)},
+	})
+	if err != nil {
+		return err
+	}
+	var workflows []jobTypes.Workflow
+	err = cursor.All(ctx, &workflows)
+	if err != nil {
+		return err
+	}
+	multi := tsuruErrors.NewMultiError()
+	for _, wf := range workflows {
+		_, err = collection.UpdateOne(ctx, mongoBSON.M{"_id": wf.ID}, mongoBSON.M{
+			"$set": mongoBSON.M{
+				"steps.$[step].status":  jobTypes.RunStatusFailed,
+				"steps.$[step].error":   fmt.Sprintf("workflow timed out after %s", timeout),
+				"steps.$[step].endtime": time.Now().UTC(),
+			},
+		}, options.Update().SetArrayFilters(options.ArrayFilters{
+			Filters: []interface{}{mongoBSON.M{"step.status": jobTypes.RunStatusRunning}},
+		}))
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to expire workflow %q", wf.ID))
+			continue
+		}
+		err = finishWorkflow(ctx, wf.ID)
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to finish workflow %q", wf.ID))
+		}
+	}
+	return multi.ToError()
+}
+
+// validateDependents ensures every dependent of the job exists and that the
+// dependencies, including the ones of the stored jobs, form no cycle.
+func validateDependents(ctx context.Context, j *jobTypes.Job) error {
+	dependents := func(spec jobTypes.JobSpec) []string {
+		var names []string
+		for _, d := range append(append([]jobTypes.JobDependent{}, spec.OnSuccess...), spec.OnFailure...) {
+			names = append(names, d.Job)
+		}
+		return names
+	}
+	next := dependents(j.Spec)
+	for _, d := range append(append([]jobTypes.JobDependent{}, j.Spec.OnSuccess...), j.Spec.OnFailure...) {
+		if d.Job == "" {
+			return &tsuruErrors.ValidationError{Message: jobTypes.ErrInvalidDependency.Error()}
+		}
+		if d.Job == j.Name {
+			return &tsuruErrors.ValidationError{Message: jobTypes.ErrDependencyOnSelf.Error()}
+		}
+	}
+	visited := map[string]bool{}
+	for len(next) > 0 {
+		name := next[0]
+		next = next[1:]
+		if name == j.Name {
+			return &tsuruErrors.ValidationError{Message: jobTypes.ErrDependencyCycle.Error()}
+		}
+		if visited[name] {
+			continue
+		}
+		visited[name] = true
+		dep, err := servicemanager.Job.GetByName(ctx, name)
+		if err == jobTypes.ErrJobNotFound {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("dependent job %q not found", name)}
+		}
+		if err != nil {
+			return err
+		}
+		next = append(next, dependents(dep.Spec)...)
+	}
+	return nil
+}
+
+// advanceWorkflow updates the workflow of a job run, triggering the
+// dependents of the run job once it finishes. A new workflow is started when
+// a job with dependents starts running outside of a workflow.
+func advanceWorkflow(ctx context.Context, run *jobTypes.Run) error {
+	wf, err := findWorkflowForRun(ctx, run)
+	if err == jobTypes.ErrWorkflowNotFound {
+		if run.Workflow != "" || run.Status != jobTypes.RunStatusRunning {
+			return nil
+		}
+		wf, err = startWorkflow(ctx, run)
+		if wf == nil || err != nil {
+			return err
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if wf.Status != jobTypes.RunStatusRunning {
+		return nil
+	}
+	stepIdx := -1
+	for i := range wf.Steps {
+		if wf.Steps[i].Run == run.ID {
+			stepIdx = i
+			break
+		}
+	}
+	if stepIdx == -1 || wf.Steps[stepIdx].Status == run.Status {
+		return nil
+	}
+	step := &wf.Steps[stepIdx]
+	step.Status = run.Status
+	step.EndTime = run.EndTime
+	var newSteps []jobTypes.WorkflowStep
+	if step.Finished() {
+		newSteps = triggerDependents(ctx, wf, stepIdx)
+	}
+	return saveWorkflowStep(ctx, wf.ID, *step, newSteps)
+}
+
+func findWorkflowForRun(ctx context.Context, run *jobTypes.Run) (*jobTypes.Workflow, error) {
+	collection, err := storagev2.JobWorkflowsCollection()
+	if err != nil {
+		return nil, err
+	}
+	query := mongoBSON.M{"steps.run": run.ID}
+	if run.Workflow != "" {
+		query = mongoBSON.M{"_id": run.Workflow}
+	}
+	var wf jobTypes.Workflow
+	err = collection.FindOne(ctx, query).Decode(&wf)
+	if err == mongo.ErrNoDocuments {
+		return nil, jobTypes.ErrWorkflowNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &wf, nil
+}
+
+func startWorkflow(ctx context.Context, run *jobTypes.Run) (*jobTypes.Workflow, error) {
+	j, err := servicemanager.Job.GetByName(ctx, run.Job)
+	if err == jobTypes.ErrJobNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(j.Spec.OnSuccess) == 0 && len(j.Spec.OnFailure) == 0 {
+		return nil, nil
+	}
+	wf := &jobTypes.Workflow{
+		ID:        primitive.NewObjectID().Hex(),
+		Job:       j.Name,
+		Status:    jobTypes.RunStatusRunning,
+		StartTime: run.StartTime,
+		Steps: []jobTypes.WorkflowStep{
+			{Job: j.Name, Run: run.ID, Parent: -1, Status: jobTypes.RunStatusRunning, StartTime: run.StartTime},
+		},
+	}
+	evt, err := event.NewInternal(ctx, &event.Opts{
+		Target:       eventTypes.Target{Type: eventTypes.TargetTypeJob, Value: j.Name},
+		InternalKind: workflowEventKind,
+		DisableLock:  true,
+		CustomData:   map[string]string{"workflow": wf.ID, "run": run.ID},
+		Allowed: event.Allowed(permission.PermJobReadEvents, append(permission.Contexts(permTypes.CtxTeam, j.Teams),
+			permission.Context(permTypes.CtxJob, j.Name))...),
+	})
+	if err != nil {
+		return nil, err
+	}
+	wf.EventID = evt.UniqueID.Hex()
+	collection, err := storagev2.JobWorkflowsCollection()
+	if err != nil {
+		return nil, err
+	}
+	_, err = collection.InsertOne(ctx, wf)
+	if err != nil {
+		evt.Done(ctx, err)
+		return nil, err
+	}
+	return wf, nil
+}
+
+// triggerDependents triggers the dependents of the finished step, returning
+// the steps created for them.
+func triggerDependents(ctx context.Context, wf *jobTypes.Workflow, stepIdx int) []jobTypes.WorkflowStep {
+	step := wf.Steps[stepIdx]
+	j, err := servicemanager.Job.GetByName(ctx, step.Job)
+	if err != nil {
+		log.Errorf("unable to get job %q in workflow %q: %v", step.Job, wf.ID, err)
+		return nil
+	}
+	dependents := j.Spec.OnSuccess
+	if step.Status == jobTypes.RunStatusFailed {
+		dependents = j.Spec.OnFailure
+	}
+	var newSteps []jobTypes.WorkflowStep
+	for _, d := range dependents {
+		now := time.Now().UTC()
+		newStep := jobTypes.WorkflowStep{
+			Job:       d.Job,
+			Parent:    stepIdx,
+			Status:    jobTypes.RunStatusRunning,
+			StartTime: now,
+		}
+		newStep.Run, err = triggerDependent(ctx, wf, step, d)
+		if err != nil {
+			newStep.Status = jobTypes.RunStatusFailed
+			newStep.Error = err.Error()
+			newStep.EndTime = &now
+		}
+		newSteps = append(newSteps, newStep)
+	}
+	return newSteps
+}
+
+func triggerDependent(ctx context.Context, wf *jobTypes.Workflow, parent jobTypes.WorkflowStep, d jobTypes.JobDependent) (string, error) {
+	j, err := servicemanager.Job.GetByName(ctx, d.Job)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to get job %q", d.Job)
+	}
+	envs := append([]bindTypes.EnvVar{
+		{Name: "TSURU_WORKFLOW_ID", Value: wf.ID},
+		{Name: "TSURU_WORKFLOW_PARENT_JOB", Value: parent.Job},
+		{Name: "TSURU_WORKFLOW_PARENT_STATUS", Value: string(parent.Status)},
+	}, d.Envs...)
+	return servicemanager.Job.Trigger(ctx, j, jobTypes.TriggerOpts{Envs: envs, Workflow: wf.ID})
+}
+
+// saveWorkflowStep stores the status of the step of run, along with the
+// steps it triggered, and finishes the workflow once all its steps are
+// finished. Steps are updated in place, so steps of the same workflow
+// finishing at the same time don't overwrite each other. New steps are stored
+// first, so the workflow isn't finished before its dependents run.
+func saveWorkflowStep(ctx context.Context, id string, step jobTypes.WorkflowStep, newSteps []jobTypes.WorkflowStep) error {
+	collection, err := storagev2.JobWorkflowsCollection()
+	if err != nil {
+		return err
+	}
+	if len(newSteps) > 0 {
+		_, err = collection.UpdateOne(ctx, mongoBSON.M{"_id": id}, mongoBSON.M{
+			"$push": mongoBSON.M{"steps": mongoBSON.M{"$each": newSteps}},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	_, err = collection.UpdateOne(ctx, mongoBSON.M{"_id": id, "steps.run": step.Run}, mongoBSON.M{
+		"$set": mongoBSON.M{"steps.$.status": step.Status, "steps.$.endtime": step.EndTime},
+	})
+	if err != nil {
+		return err
+	}
+	return finishWorkflow(ctx, id)
+}
+
+// finishWorkflow finishes the workflow and its event once all steps are
+// finished. The event other custom data holds the steps while the workflow is
+// running.
+func finishWorkflow(ctx context.Context, id string) error {
+	collection, err := storagev2.JobWorkflowsCollection()
+	if err != nil {
+		return err
+	}
+	var wf jobTypes.Workflow
+	err = collection.FindOne(ctx, mongoBSON.M{"_id": id}).Decode(&wf)
+	if err != nil {
+		return err
+	}
+	if wf.Status != jobTypes.RunStatusRunning {
+		return nil
+	}
+	var failed []string
+	finished := true
+	for _, s := range wf.Steps {
+		finished = finished && s.Finished()
+		if s.Status == jobTypes.RunStatusFailed {
+			failed = append(failed, s.Job)
+		}
+	}
+	evt, err := event.GetByHexID(ctx, wf.EventID)
+	if err != nil {
+		return err
+	}
+	if !finished {
+		return evt.SetOtherCustomData(ctx, wf.Steps)
+	}
+	now := time.Now().UTC()
+	status := jobTypes.RunStatusSucceeded
+	if len(failed) > 0 {
+		status = jobTypes.RunStatusFailed
+	}
+	result, err := collection.UpdateOne(ctx, mongoBSON.M{"_id": id, "status": jobTypes.RunStatusRunning}, mongoBSON.M{
+		"$set": mongoBSON.M{"status": status, "endtime": now},
+	})
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount == 0 {
+		// finished concurrently by another step
+		return nil
+	}
+	for _, s := range wf.Steps {
+		fmt.Fprintf(evt, "%s: %s", s.Job, s.Status)
+		if s.Error != "" {
+			fmt.Fprintf(evt, " (%s)", s.Error)
+		}
+		fmt.Fprintln(evt)
+	}
+	var evtErr error
+	if len(failed) > 0 {
+		evtErr = errors.Errorf("failed jobs: %s", strings.Join(failed, ", "))
+	}
+	return evt.DoneCustomData(ctx, evtErr, wf.Steps)
+}
@@ -0,0 +1,184 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/servicemanager"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) createWorkflowJob(c *check.C, name string, onSuccess, onFailure []jobTypes.JobDependent) *jobTypes.Job {
+	j := jobTypes.Job{
+		Name:      name,
+		TeamOwner: s.team.Name,
+		Pool:      s.Pool,
+		Spec: jobTypes.JobSpec{
+			Manual:    true,
+			OnSuccess: onSuccess,
+			OnFailure: onFailure,
+			Container: jobTypes.ContainerInfo{
+				OriginalImageSrc: "alpine:latest",
+			},
+		},
+	}
+	err := servicemanager.Job.CreateJob(context.TODO(), &j, s.user)
+	c.Assert(err, check.IsNil)
+	return &j
+}
+
+func (s *S) TestWorkflowTriggersDependents(c *check.C) {
+	s.createWorkflowJob(c, "cleanup", nil, nil)
+	s.createWorkflowJob(c, "notify", nil, nil)
+	s.createWorkflowJob(c, "extract", []jobTypes.JobDependent{
+		{Job: "cleanup", Envs: []bindTypes.EnvVar{{Name: "MODE", Value: "full"}}},
+	}, []jobTypes.JobDependent{{Job: "notify"}})
+	start := time.Now().UTC()
+	run := jobTypes.Run{ID: "run1", Job: "extract", Status: jobTypes.RunStatusRunning, StartTime: start}
+	err := servicemanager.JobRun.SaveRun(context.TODO(), &run)
+	c.Assert(err, check.IsNil)
+	wf, err := findWorkflowForRun(context.TODO(), &run)
+	c.Assert(err, check.IsNil)
+	c.Assert(wf.Job, check.Equals, "extract")
+	c.Assert(wf.Steps, check.HasLen, 1)
+	end := start.Add(time.Minute)
+	run.Status = jobTypes.RunStatusSucceeded
+	run.EndTime = &end
+	err = servicemanager.JobRun.SaveRun(context.TODO(), &run)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.provisioner.JobTriggers("notify"), check.HasLen, 0)
+	triggers := s.provisioner.JobTriggers("cleanup")
+	c.Assert(triggers, check.HasLen, 1)
+	c.Assert(triggers[0].Workflow, check.Equals, wf.ID)
+	c.Assert(triggers[0].Envs, check.DeepEquals, []bindTypes.EnvVar{
+		{Name: "TSURU_WORKFLOW_ID", Value: wf.ID},
+		{Name: "TSURU_WORKFLOW_PARENT_JOB", Value: "extract"},
+		{Name: "TSURU_WORKFLOW_PARENT_STATUS", Value: "succeeded"},
+		{Name: "MODE", Value: "full"},
+	})
+	wf, err = findWorkflowForRun(context.TODO(), &run)
+	c.Assert(err, check.IsNil)
+	c.Assert(wf.Status, check.Equals, jobTypes.RunStatusRunning)
+	c.Assert(wf.Steps, check.HasLen, 2)
+	c.Assert(wf.Steps[1].Job, check.Equals, "cleanup")
+	c.Assert(wf.Steps[1].Run, check.Equals, "cleanup-run-1")
+	c.Assert(wf.Steps[1].Parent, check.Equals, 0)
+	depRun := jobTypes.Run{
+		ID:        "cleanup-run-1",
+		Job:       "cleanup",
+		Workflow:  wf.ID,
+		Status:    jobTypes.RunStatusFailed,
+		StartTime: end,
+		EndTime:   &end,
+	}
+	err = servicemanager.JobRun.SaveRun(context.TODO(), &depRun)
+	c.Assert(err, check.IsNil)
+	wf, err = findWorkflowForRun(context.TODO(), &run)
+	c.Assert(err, check.IsNil)
+	c.Assert(wf.Status, check.Equals, jobTypes.RunStatusFailed)
+	c.Assert(wf.EndTime, check.NotNil)
+	evts, err := event.List(context.TODO(), &event.Filter{
+		Target:    eventTypes.Target{Type: eventTypes.TargetTypeJob, Value: "extract"},
+		KindNames: []string{workflowEventKind},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].Running, check.Equals, false)
+	c.Assert(evts[0].Error, check.Equals, "failed jobs: cleanup")
+	c.Assert(evts[0].Log(), check.Matches, "(?s).*extract: succeeded\ncleanup: failed\n.*")
+}
+
+func (s *S) TestExpireWorkflows(c *check.C) {
+	s.createWorkflowJob(c, "cleanup", nil, nil)
+	s.createWorkflowJob(c, "extract", []jobTypes.JobDependent{{Job: "cleanup"}}, nil)
+	start := time.Now().UTC().Add(-time.Hour)
+	run := jobTypes.Run{ID: "run1", Job: "extract", Status: jobTypes.RunStatusRunning, StartTime: start}
+	err := servicemanager.JobRun.SaveRun(context.TODO(), &run)
+	c.Assert(err, check.IsNil)
+	err = expireWorkflows(context.TODO(), 2*time.Hour)
+	c.Assert(err, check.IsNil)
+	wf, err := findWorkflowForRun(context.TODO(), &run)
+	c.Assert(err, check.IsNil)
+	c.Assert(wf.Status, check.Equals, jobTypes.RunStatusRunning)
+	err = expireWorkflows(context.TODO(), time.Minute)
+	c.Assert(err, check.IsNil)
+	wf, err = findWorkflowForRun(context.TODO(), &run)
+	c.Assert(err, check.IsNil)
+	c.Assert(wf.Status, check.Equals, jobTypes.RunStatusFailed)
+	c.Assert(wf.EndTime, check.NotNil)
+	c.Assert(wf.Steps, check.HasLen, 1)
+	c.Assert(wf.Steps[0].Status, check.Equals, jobTypes.RunStatusFailed)
+	c.Assert(wf.Steps[0].Error, check.Equals, "workflow timed out after 1m0s")
+	evts, err := event.List(context.TODO(), &event.Filter{
+		Target:    eventTypes.Target{Type: eventTypes.TargetTypeJob, Value: "extract"},
+		KindNames: []string{workflowEventKind},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].Running, check.Equals, false)
+	c.Assert(evts[0].Error, check.Equals, "failed jobs: extract")
+	run.Status = jobTypes.RunStatusSucceeded
+	err = servicemanager.JobRun.SaveRun(context.TODO(), &run)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.provisioner.JobTriggers("cleanup"), check.HasLen, 0)
+}
+
+func (s *S) TestWorkflowNotStartedWithoutDependents(c *check.C) {
+	s.createWorkflowJob(c, "lonely", nil, nil)
+	run := jobTypes.Run{ID: "run1", Job: "lonely", Status: jobTypes.RunStatusRunning, StartTime: time.Now().UTC()}
+	err := servicemanager.JobRun.SaveRun(context.TODO(), &run)
+	c.Assert(err, check.IsNil)
+	collection, err := storagev2.JobWorkflowsCollection()
+	c.Assert(err, check.IsNil)
+	count, err := collection.CountDocuments(context.TODO(), mongoBSON.M{})
+	c.Assert(err, check.IsNil)
+	c.Assert(count, check.Equals, int64(0))
+}
+
+func (s *S) TestValidateDependents(c *check.C) {
+	s.createWorkflowJob(c, "second", nil, nil)
+	s.createWorkflowJob(c, "first", []jobTypes.JobDependent{{Job: "second"}}, nil)
+	tests := []struct {
+		deps     []jobTypes.JobDependent
+		expected string
+	}{
+		{[]jobTypes.JobDependent{{Job: ""}}, jobTypes.ErrInvalidDependency.Error()},
+		{[]jobTypes.JobDependent{{Job: "second"}}, jobTypes.ErrDependencyOnSelf.Error()},
+		{[]jobTypes.JobDependent{{Job: "first"}}, jobTypes.ErrDependencyCycle.Error()},
+		{[]jobTypes.JobDependent{{Job: "missing"}}, `dependent job "missing" not found`},
+	}
+	for _, tt := range tests {
+		j := jobTypes.Job{Name: "second", Spec: jobTypes.JobSpec{OnFailure: tt.deps}}
+		err := validateDependents(context.TODO(), &j)
+		c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+		c.Assert(err, check.ErrorMatches, tt.expected)
+	}
+	j := jobTypes.Job{Name: "third", Spec: jobTypes.JobSpec{OnSuccess: []jobTypes.JobDependent{{Job: "first"}}}}
+	c.Assert(validateDependents(context.TODO(), &j), check.IsNil)
+}
+
+func (s *S) TestUpdateJobDependents(c *check.C) {
+	s.createWorkflowJob(c, "cleanup", nil, nil)
+	s.createWorkflowJob(c, "notify", nil, nil)
+	s.createWorkflowJob(c, "extract", []jobTypes.JobDependent{{Job: "cleanup"}}, []jobTypes.JobDependent{{Job: "notify"}})
+	oldJob, err := servicemanager.Job.GetByName(context.TODO(), "extract")
+	c.Assert(err, check.IsNil)
+	newJob := jobTypes.Job{Name: "extract", Spec: jobTypes.JobSpec{OnFailure: []jobTypes.JobDependent{}}}
+	err = servicemanager.Job.UpdateJob(context.TODO(), &newJob, oldJob, s.user)
+	c.Assert(err, check.IsNil)
+	updated, err := servicemanager.Job.GetByName(context.TODO(), "extract")
+	c.Assert(err, check.IsNil)
+	c.Assert(updated.Spec.OnSuccess, check.DeepEquals, []jobTypes.JobDependent{{Job: "cleanup"}})
+	c.Assert(updated.Spec.OnFailure, check.HasLen, 0)
+}
@@ -55,6 +55,7 @@ github.com/tsuru/tsuru/api.forceDeleteLock
 github.com/tsuru/tsuru/api.diffDeploy
 github.com/tsuru/tsuru/api.swap
 github.com/tsuru/tsuru/api.serviceAuthenticatedResourcesProxy
+github.com/tsuru/tsuru/api.openAPI
 EOF
     )
     ignored=$(echo "$ignored" | sort)
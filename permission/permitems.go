@@ -20,8 +20,10 @@ var (
 	PermAppCreate                        = PermissionRegistry.get("app.create")                          // [global team]
 	PermAppDelete                        = PermissionRegistry.get("app.delete")                          // [global app team pool]
 	PermAppDeploy                        = PermissionRegistry.get("app.deploy")                          // [global app team pool]
+	PermAppDeployApprove                 = PermissionRegistry.get("app.deploy.approve")                  // [global app team pool]
 	PermAppDeployArchiveUrl              = PermissionRegistry.get("app.deploy.archive-url")              // [global app team pool]
 	PermAppDeployBuild                   = PermissionRegistry.get("app.deploy.build")                    // [global app team pool]
+	PermAppDeployCanary                  = PermissionRegistry.get("app.deploy.canary")                   // [global app team pool]
 	PermAppDeployDockerfile              = PermissionRegistry.get("app.deploy.dockerfile")               // [global app team pool]
 	PermAppDeployGit                     = PermissionRegistry.get("app.deploy.git")                      // [global app team pool]
 	PermAppDeployImage                   = PermissionRegistry.get("app.deploy.image")                    // [global app team pool]
@@ -34,6 +36,8 @@ var (
 	PermAppReadEvents                    = PermissionRegistry.get("app.read.events")                     // [global app team pool]
 	PermAppReadInfo                      = PermissionRegistry.get("app.read.info")                       // [global app team pool]
 	PermAppReadLog                       = PermissionRegistry.get("app.read.log")                        // [global app team pool]
+	PermAppReadLogDrain                  = PermissionRegistry.get("app.read.log-drain")                  // [global app team pool]
+	PermAppReadManifest                  = PermissionRegistry.get("app.read.manifest")                   // [global app team pool]
 	PermAppReadRouter                    = PermissionRegistry.get("app.read.router")                     // [global app team pool]
 	PermAppRun                           = PermissionRegistry.get("app.run")                             // [global app team pool]
 	PermAppRunShell                      = PermissionRegistry.get("app.run.shell")                       // [global app team pool]
@@ -56,6 +60,10 @@ var (
 	PermAppUpdateGrant                   = PermissionRegistry.get("app.update.grant")                    // [global app team pool]
 	PermAppUpdateImageReset              = PermissionRegistry.get("app.update.image-reset")              // [global app team pool]
 	PermAppUpdateLog                     = PermissionRegistry.get("app.update.log")                      // [global app team pool]
+	PermAppUpdateLogDrain                = PermissionRegistry.get("app.update.log-drain")                // [global app team pool]
+	PermAppUpdateLogDrainAdd             = PermissionRegistry.get("app.update.log-drain.add")            // [global app team pool]
+	PermAppUpdateLogDrainRemove          = PermissionRegistry.get("app.update.log-drain.remove")         // [global app team pool]
+	PermAppUpdateManifest                = PermissionRegistry.get("app.update.manifest")                 // [global app team pool]
 	PermAppUpdateMetadata                = PermissionRegistry.get("app.update.metadata")                 // [global app team pool]
 	PermAppUpdatePlan                    = PermissionRegistry.get("app.update.plan")                     // [global app team pool]
 	PermAppUpdatePlanoverride            = PermissionRegistry.get("app.update.planoverride")             // [global app team pool]
@@ -71,6 +79,7 @@ var (
 	PermAppUpdateRouterUpdate            = PermissionRegistry.get("app.update.router.update")            // [global app team pool]
 	PermAppUpdateStart                   = PermissionRegistry.get("app.update.start")                    // [global app team pool]
 	PermAppUpdateStop                    = PermissionRegistry.get("app.update.stop")                     // [global app team pool]
+	PermAppUpdateSwap                    = PermissionRegistry.get("app.update.swap")                     // [global app team pool]
 	PermAppUpdateTags                    = PermissionRegistry.get("app.update.tags")                     // [global app team pool]
 	PermAppUpdateTeamowner               = PermissionRegistry.get("app.update.teamowner")                // [global app team pool]
 	PermAppUpdateUnbind                  = PermissionRegistry.get("app.update.unbind")                   // [global app team pool]
@@ -103,10 +112,13 @@ var (
 	PermJobReadLogs                      = PermissionRegistry.get("job.read.logs")                       // [global team pool job]
 	PermJobRun                           = PermissionRegistry.get("job.run")                             // [global team pool job]
 	PermJobTrigger                       = PermissionRegistry.get("job.trigger")                         // [global team pool job]
+	PermJobTriggerAdhoc                  = PermissionRegistry.get("job.trigger.adhoc")                   // [global team pool job]
 	PermJobUnit                          = PermissionRegistry.get("job.unit")                            // [global team pool job]
 	PermJobUnitKill                      = PermissionRegistry.get("job.unit.kill")                       // [global team pool job]
 	PermJobUpdate                        = PermissionRegistry.get("job.update")                          // [global team pool job]
+	PermJobUpdateBindVolume              = PermissionRegistry.get("job.update.bind-volume")              // [global team pool job]
 	PermJobUpdateEvents                  = PermissionRegistry.get("job.update.events")                   // [global team pool job]
+	PermJobUpdateUnbindVolume            = PermissionRegistry.get("job.update.unbind-volume")            // [global team pool job]
 	PermJobDeploy                        = PermissionRegistry.get("job.deploy")                          // [global team pool job]
 	PermPlan                             = PermissionRegistry.get("plan")                                // [global]
 	PermPlanCreate                       = PermissionRegistry.get("plan.create")                         // [global]
@@ -126,9 +138,11 @@ var (
 	PermPoolRead                         = PermissionRegistry.get("pool.read")                           // [global pool]
 	PermPoolReadConstraints              = PermissionRegistry.get("pool.read.constraints")               // [global pool]
 	PermPoolReadEvents                   = PermissionRegistry.get("pool.read.events")                    // [global pool]
+	PermPoolReadPrice                    = PermissionRegistry.get("pool.read.price")                     // [global pool]
 	PermPoolUpdate                       = PermissionRegistry.get("pool.update")                         // [global pool]
 	PermPoolUpdateConstraints            = PermissionRegistry.get("pool.update.constraints")             // [global pool]
 	PermPoolUpdateConstraintsSet         = PermissionRegistry.get("pool.update.constraints.set")         // [global pool]
+	PermPoolUpdatePrice                  = PermissionRegistry.get("pool.update.price")                   // [global pool]
 	PermPoolUpdateTeam                   = PermissionRegistry.get("pool.update.team")                    // [global pool]
 	PermPoolUpdateTeamAdd                = PermissionRegistry.get("pool.update.team.add")                // [global pool]
 	PermPoolUpdateTeamRemove             = PermissionRegistry.get("pool.update.team.remove")             // [global pool]
@@ -167,15 +181,19 @@ var (
 	PermServiceInstanceCreate            = PermissionRegistry.get("service-instance.create")             // [global team]
 	PermServiceInstanceDelete            = PermissionRegistry.get("service-instance.delete")             // [global service-instance team]
 	PermServiceInstanceRead              = PermissionRegistry.get("service-instance.read")               // [global service-instance team]
+	PermServiceInstanceReadBackups       = PermissionRegistry.get("service-instance.read.backups")       // [global service-instance team]
 	PermServiceInstanceReadEvents        = PermissionRegistry.get("service-instance.read.events")        // [global service-instance team]
 	PermServiceInstanceReadStatus        = PermissionRegistry.get("service-instance.read.status")        // [global service-instance team]
 	PermServiceInstanceUpdate            = PermissionRegistry.get("service-instance.update")             // [global service-instance team]
+	PermServiceInstanceUpdateBackup      = PermissionRegistry.get("service-instance.update.backup")      // [global service-instance team]
 	PermServiceInstanceUpdateBind        = PermissionRegistry.get("service-instance.update.bind")        // [global service-instance team]
+	PermServiceInstanceUpdateCredentials = PermissionRegistry.get("service-instance.update.credentials") // [global service-instance team]
 	PermServiceInstanceUpdateDescription = PermissionRegistry.get("service-instance.update.description") // [global service-instance team]
 	PermServiceInstanceUpdateGrant       = PermissionRegistry.get("service-instance.update.grant")       // [global service-instance team]
 	PermServiceInstanceUpdateParameters  = PermissionRegistry.get("service-instance.update.parameters")  // [global service-instance team]
 	PermServiceInstanceUpdatePlan        = PermissionRegistry.get("service-instance.update.plan")        // [global service-instance team]
 	PermServiceInstanceUpdateProxy       = PermissionRegistry.get("service-instance.update.proxy")       // [global service-instance team]
+	PermServiceInstanceUpdateRestore     = PermissionRegistry.get("service-instance.update.restore")     // [global service-instance team]
 	PermServiceInstanceUpdateRevoke      = PermissionRegistry.get("service-instance.update.revoke")      // [global service-instance team]
 	PermServiceInstanceUpdateTags        = PermissionRegistry.get("service-instance.update.tags")        // [global service-instance team]
 	PermServiceInstanceUpdateTeamowner   = PermissionRegistry.get("service-instance.update.teamowner")   // [global service-instance team]
@@ -195,6 +213,7 @@ var (
 	PermTeamCreate                       = PermissionRegistry.get("team.create")                         // [global]
 	PermTeamDelete                       = PermissionRegistry.get("team.delete")                         // [global team]
 	PermTeamRead                         = PermissionRegistry.get("team.read")                           // [global team]
+	PermTeamReadCost                     = PermissionRegistry.get("team.read.cost")                      // [global team]
 	PermTeamReadEvents                   = PermissionRegistry.get("team.read.events")                    // [global team]
 	PermTeamReadQuota                    = PermissionRegistry.get("team.read.quota")                     // [global team]
 	PermTeamToken                        = PermissionRegistry.get("team.token")                          // [global team]
@@ -228,4 +247,5 @@ var (
 	PermWebhookRead                      = PermissionRegistry.get("webhook.read")                        // [global team]
 	PermWebhookReadEvents                = PermissionRegistry.get("webhook.read.events")                 // [global team]
 	PermWebhookUpdate                    = PermissionRegistry.get("webhook.update")                      // [global team]
+	PermWebhookUpdateRedeliver           = PermissionRegistry.get("webhook.update.redeliver")            // [global team]
 )
@@ -51,7 +51,11 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"app.update.router.update",
 	"app.update.router.remove",
 	"app.update.routable",
+	"app.update.swap",
 	"app.update.metadata",
+	"app.update.log-drain.add",
+	"app.update.log-drain.remove",
+	"app.update.manifest",
 	"app.deploy",
 	"app.deploy.archive-url",
 	"app.deploy.build",
@@ -60,6 +64,8 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"app.deploy.rollback",
 	"app.deploy.upload",
 	"app.deploy.dockerfile",
+	"app.deploy.canary",
+	"app.deploy.approve",
 	"app.read",
 	"app.read.deploy",
 	"app.read.router",
@@ -68,6 +74,8 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"app.read.log",
 	"app.read.certificate",
 	"app.read.info",
+	"app.read.log-drain",
+	"app.read.manifest",
 	"app.delete",
 	"app.run",
 	"app.run.shell",
@@ -88,6 +96,7 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"team.token.update",
 	"team.read.quota",
 	"team.update.quota",
+	"team.read.cost",
 ).addWithCtx(
 	"user", []permTypes.ContextType{permTypes.CtxUser},
 ).addWithCtx(
@@ -129,6 +138,7 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 ).add(
 	"service-instance.read.events",
 	"service-instance.read.status",
+	"service-instance.read.backups",
 	"service-instance.delete",
 	"service-instance.update.proxy",
 	"service-instance.update.bind",
@@ -140,6 +150,9 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"service-instance.update.teamowner",
 	"service-instance.update.plan",
 	"service-instance.update.parameters",
+	"service-instance.update.credentials",
+	"service-instance.update.backup",
+	"service-instance.update.restore",
 ).add(
 	"role.create",
 	"role.delete",
@@ -172,6 +185,8 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"pool.update.team.remove",
 	"pool.update.constraints.set",
 	"pool.read.constraints",
+	"pool.read.price",
+	"pool.update.price",
 	"pool.delete",
 ).add(
 	"debug",
@@ -202,6 +217,7 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"webhook.read.events",
 	"webhook.create",
 	"webhook.update",
+	"webhook.update.redeliver",
 	"webhook.delete",
 ).addWithCtx(
 	"router", []permTypes.ContextType{permTypes.CtxRouter},
@@ -217,6 +233,8 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"job.create", []permTypes.ContextType{permTypes.CtxTeam},
 ).add(
 	"job.update",
+	"job.update.bind-volume",
+	"job.update.unbind-volume",
 ).add(
 	"job.run",
 ).add(
@@ -230,6 +248,7 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"job.read.logs",
 ).add(
 	"job.trigger",
+	"job.trigger.adhoc",
 ).add(
 	"job.unit.kill",
 ).add(
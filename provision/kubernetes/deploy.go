@@ -900,7 +900,7 @@ func createDeployTimeoutError(ctx context.Context, client *ClusterClient, ns str
 
 	crashedUnitsLogs, err = listLogsFromPods(ctx, client, ns, pods, appTypes.ListLogArgs{
 		Limit: 10,
-	})
+	}, nil)
 
 	if err != nil {
 		return errors.Wrap(err, "Could not get logs from crashed units")
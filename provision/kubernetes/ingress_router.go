@@ -0,0 +1,581 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/router"
+	apiv1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	ingressRouterType = "kubernetes-ingress"
+
+	tsuruLabelRouterName      = tsuruLabelPrefix + "router-name"
+	tsuruAnnotationRouterHost = tsuruLabelPrefix + "router-cname"
+
+	acmeSolverServiceName   = "tsuru-acme-solver"
+	defaultACMESolverPort   = 80
+	acmeChallengePathPrefix = "/.well-known/acme-challenge/"
+)
+
+var (
+	_ router.Router              = &ingressRouter{}
+	_ router.TLSRouter           = &ingressRouter{}
+	_ router.ACMEChallengeRouter = &ingressRouter{}
+	_ router.BackendStateRouter  = &ingressRouter{}
+)
+
+func init() {
+	router.Register(ingressRouterType, createIngressRouter)
+}
+
+// ingressRouter is a router that manages Kubernetes Ingress objects directly
+// in the cluster where each app is running, without requiring an external
+// router API.
+type ingressRouter struct {
+	name                      string
+	domain                    string
+	ingressClass              string
+	annotations               map[string]string
+	healthcheckPathAnnotation string
+	acmeSolverHost            string
+	acmeSolverPort            int
+}
+
+func createIngressRouter(routerName string, config router.ConfigGetter) (router.Router, error) {
+	domain, _ := config.GetString("domain")
+	ingressClass, _ := config.GetString("ingress-class")
+	healthcheckPathAnnotation, _ := config.GetString("healthcheck-path-annotation")
+	acmeSolverHost, _ := config.GetString("acme-solver-host")
+	acmeSolverPort, _ := config.GetInt("acme-solver-port")
+	if acmeSolverPort == 0 {
+		acmeSolverPort = defaultACMESolverPort
+	}
+	annotations := map[string]string{}
+	rawAnnotations, _ := config.Get("annotations")
+	if rawAnnotations != nil {
+		m, ok := rawAnnotations.(map[interface{}]interface{})
+		if !ok {
+			return nil, errors.Errorf("invalid annotations configuration: %v", rawAnnotations)
+		}
+		for k := range m {
+			value, err := config.GetString(fmt.Sprintf("annotations:%s", k))
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid annotation configuration at key: %v", k)
+			}
+			annotations[fmt.Sprint(k)] = value
+		}
+	}
+	return &ingressRouter{
+		name:                      routerName,
+		domain:                    domain,
+		ingressClass:              ingressClass,
+		annotations:               annotations,
+		healthcheckPathAnnotation: healthcheckPathAnnotation,
+		acmeSolverHost:            acmeSolverHost,
+		acmeSolverPort:            acmeSolverPort,
+	}, nil
+}
+
+func (r *ingressRouter) GetName() string {
+	return r.name
+}
+
+func (r *ingressRouter) GetType() string {
+	return ingressRouterType
+}
+
+func (r *ingressRouter) GetInfo(ctx context.Context) (map[string]string, error) {
+	return map[string]string{
+		"domain":        r.domain,
+		"ingress-class": r.ingressClass,
+	}, nil
+}
+
+func (r *ingressRouter) ingressName(app router.App) string {
+	return fmt.Sprintf("%s-%s", app.GetName(), r.name)
+}
+
+func (r *ingressRouter) secretName(app router.App, cname string) string {
+	return r.cnameResourceName(app, "tls", cname)
+}
+
+// cnameResourceName returns a valid kubernetes name for a resource of the
+// cname. Cnames may be wildcards or have characters and lengths not allowed
+// in names, so the name is built from the sanitized cname followed by a hash
+// of the original one, keeping names of different cnames apart. The cname
+// itself is kept in the router-cname annotation of the resource.
+func (r *ingressRouter) cnameResourceName(app router.App, kind, cname string) string {
+	const kubeNameMaxLen = 253
+	const hashLen = 10
+	h := sha256.Sum256([]byte(cname))
+	hash := hex.EncodeToString(h[:])[:hashLen]
+	prefix := fmt.Sprintf("%s-%s-%s-", app.GetName(), r.name, kind)
+	var parts []string
+	for _, part := range strings.Split(provision.ValidKubeName(cname), ".") {
+		if part = strings.Trim(part, "-"); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	name := strings.Join(parts, ".")
+	if maxLen := kubeNameMaxLen - len(prefix) - hashLen - 1; len(name) > maxLen {
+		name = strings.Trim(name[:maxLen], "-.")
+	}
+	if name == "" {
+		return prefix + hash
+	}
+	return prefix + name + "-" + hash
+}
+
+func (r *ingressRouter) labels(app router.App) map[string]string {
+	return map[string]string{
+		tsuruLabelAppName:    app.GetName(),
+		tsuruLabelRouterName: r.name,
+	}
+}
+
+func (r *ingressRouter) host(app router.App, prefix string) string {
+	if prefix == "" {
+		return fmt.Sprintf("%s.%s", app.GetName(), r.domain)
+	}
+	return fmt.Sprintf("%s.%s.%s", prefix, app.GetName(), r.domain)
+}
+
+func (r *ingressRouter) clientAndNamespace(ctx context.Context, app router.App) (*ClusterClient, string, error) {
+	client, err := clusterForPool(ctx, app.GetPool())
+	if err != nil {
+		return nil, "", err
+	}
+	ns, err := client.appNamespaceByName(ctx, app.GetName())
+	if err != nil {
+		return nil, "", err
+	}
+	return client, ns, nil
+}
+
+func (r *ingressRouter) EnsureBackend(ctx context.Context, app router.App, o router.EnsureBackendOpts) error {
+	var mainTarget map[string]string
+	for _, prefix := range o.Prefixes {
+		if prefix.Prefix == "" {
+			mainTarget = prefix.Target
+			break
+		}
+	}
+	if mainTarget == nil {
+		return errors.Errorf("no main prefix found for app %q", app.GetName())
+	}
+	client, err := clusterForPool(ctx, app.GetPool())
+	if err != nil {
+		return err
+	}
+	ns := mainTarget["namespace"]
+	mainBackend, err := ingressBackendForTarget(ctx, client, mainTarget)
+	if err != nil {
+		return err
+	}
+	var rules []networkingv1.IngressRule
+	if r.domain != "" {
+		for _, prefix := range o.Prefixes {
+			backend := mainBackend
+			if prefix.Prefix != "" {
+				backend, err = ingressBackendForTarget(ctx, client, prefix.Target)
+				if err != nil {
+					return err
+				}
+			}
+			rules = append(rules, ingressRule(r.host(app, prefix.Prefix), backend))
+		}
+	}
+	for _, cname := range o.CNames {
+		rules = append(rules, ingressRule(cname, mainBackend))
+	}
+	tls, err := r.ingressTLS(ctx, client, ns, app)
+	if err != nil {
+		return err
+	}
+	annotations := map[string]string{}
+	for k, v := range r.annotations {
+		annotations[k] = v
+	}
+	if r.healthcheckPathAnnotation != "" && o.Healthcheck.Path != "" {
+		annotations[r.healthcheckPathAnnotation] = o.Healthcheck.Path
+	}
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        r.ingressName(app),
+			Namespace:   ns,
+			Labels:      r.labels(app),
+			Annotations: annotations,
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: rules,
+			TLS:   tls,
+		},
+	}
+	if r.domain == "" {
+		ingress.Spec.DefaultBackend = mainBackend
+	}
+	if r.ingressClass != "" {
+		ingress.Spec.IngressClassName = &r.ingressClass
+	}
+	existing, err := client.NetworkingV1().Ingresses(ns).Get(ctx, ingress.Name, metav1.GetOptions{})
+	if k8sErrors.IsNotFound(err) {
+		_, err = client.NetworkingV1().Ingresses(ns).Create(ctx, ingress, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	ingress.ResourceVersion = existing.ResourceVersion
+	_, err = client.NetworkingV1().Ingresses(ns).Update(ctx, ingress, metav1.UpdateOptions{})
+	return err
+}
+
+func ingressBackendForTarget(ctx context.Context, client *ClusterClient, target map[string]string) (*networkingv1.IngressBackend, error) {
+	svc, err := client.CoreV1().Services(target["namespace"]).Get(ctx, target["service"], metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to find service %q", target["service"])
+	}
+	if len(svc.Spec.Ports) == 0 {
+		return nil, errors.Errorf("service %q has no ports", svc.Name)
+	}
+	return &networkingv1.IngressBackend{
+		Service: &networkingv1.IngressServiceBackend{
+			Name: svc.Name,
+			Port: networkingv1.ServiceBackendPort{Number: svc.Spec.Ports[0].Port},
+		},
+	}, nil
+}
+
+func ingressRule(host string, backend *networkingv1.IngressBackend) networkingv1.IngressRule {
+	pathType := networkingv1.PathTypePrefix
+	return networkingv1.IngressRule{
+		Host: host,
+		IngressRuleValue: networkingv1.IngressRuleValue{
+			HTTP: &networkingv1.HTTPIngressRuleValue{
+				Paths: []networkingv1.HTTPIngressPath{
+					{Path: "/", PathType: &pathType, Backend: *backend},
+				},
+			},
+		},
+	}
+}
+
+func (r *ingressRouter) tlsSecrets(ctx context.Context, client *ClusterClient, ns string, app router.App) ([]apiv1.Secret, error) {
+	secrets, err := client.CoreV1().Secrets(ns).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set(r.labels(app))).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return secrets.Items, nil
+}
+
+func (r *ingressRouter) ingressTLS(ctx context.Context, client *ClusterClient, ns string, app router.App) ([]networkingv1.IngressTLS, error) {
+	secrets, err := r.tlsSecrets(ctx, client, ns, app)
+	if err != nil {
+		return nil, err
+	}
+	var tls []networkingv1.IngressTLS
+	for _, secret := range secrets {
+		tls = append(tls, networkingv1.IngressTLS{
+			Hosts:      []string{secret.Annotations[tsuruAnnotationRouterHost]},
+			SecretName: secret.Name,
+		})
+	}
+	sort.Slice(tls, func(i, j int) bool {
+		return tls[i].SecretName < tls[j].SecretName
+	})
+	return tls, nil
+}
+
+func (r *ingressRouter) syncIngressTLS(ctx context.Context, client *ClusterClient, ns string, app router.App) error {
+	ingress, err := client.NetworkingV1().Ingresses(ns).Get(ctx, r.ingressName(app), metav1.GetOptions{})
+	if k8sErrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	ingress.Spec.TLS, err = r.ingressTLS(ctx, client, ns, app)
+	if err != nil {
+		return err
+	}
+	_, err = client.NetworkingV1().Ingresses(ns).Update(ctx, ingress, metav1.UpdateOptions{})
+	return err
+}
+
+func (r *ingressRouter) RemoveBackend(ctx context.Context, app router.App) error {
+	client, ns, err := r.clientAndNamespace(ctx, app)
+	if err != nil {
+		return err
+	}
+	err = client.NetworkingV1().Ingresses(ns).Delete(ctx, r.ingressName(app), metav1.DeleteOptions{})
+	notFound := k8sErrors.IsNotFound(err)
+	if err != nil && !notFound {
+		return err
+	}
+	// ACME challenge ingresses and certificate secrets share the app labels
+	// and are removed along with the backend.
+	listOpts := metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set(r.labels(app))).String(),
+	}
+	err = client.NetworkingV1().Ingresses(ns).DeleteCollection(ctx, metav1.DeleteOptions{}, listOpts)
+	if err != nil {
+		return err
+	}
+	err = client.CoreV1().Secrets(ns).DeleteCollection(ctx, metav1.DeleteOptions{}, listOpts)
+	if err != nil {
+		return err
+	}
+	if notFound {
+		return router.ErrBackendNotFound
+	}
+	return nil
+}
+
+func (r *ingressRouter) getIngress(ctx context.Context, app router.App) (*networkingv1.Ingress, error) {
+	client, ns, err := r.clientAndNamespace(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	ingress, err := client.NetworkingV1().Ingresses(ns).Get(ctx, r.ingressName(app), metav1.GetOptions{})
+	if k8sErrors.IsNotFound(err) {
+		return nil, router.ErrBackendNotFound
+	}
+	return ingress, err
+}
+
+func (r *ingressRouter) Addresses(ctx context.Context, app router.App) ([]string, error) {
+	ingress, err := r.getIngress(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	if r.domain != "" {
+		return []string{r.host(app, "")}, nil
+	}
+	var addrs []string
+	for _, lb := range ingress.Status.LoadBalancer.Ingress {
+		if lb.Hostname != "" {
+			addrs = append(addrs, lb.Hostname)
+		} else if lb.IP != "" {
+			addrs = append(addrs, lb.IP)
+		}
+	}
+	return addrs, nil
+}
+
+// GetBackendState rebuilds the cnames and prefixes of the app from the rules
+// in its ingress. Prefixes are only reported when a domain is configured, as
+// only the main prefix is routed otherwise, and the healthcheck isn't reported
+// as the ingress holds no more than its path.
+func (r *ingressRouter) GetBackendState(ctx context.Context, app router.App) (router.BackendState, error) {
+	ingress, err := r.getIngress(ctx, app)
+	if err != nil {
+		return router.BackendState{}, err
+	}
+	var state router.BackendState
+	if r.domain != "" {
+		state.Prefixes = []router.BackendPrefix{}
+	}
+	appSuffix := "." + r.host(app, "")
+	for _, rule := range ingress.Spec.Rules {
+		var prefix string
+		switch {
+		case r.domain != "" && rule.Host == r.host(app, ""):
+		case r.domain != "" && strings.HasSuffix(rule.Host, appSuffix):
+			prefix = strings.TrimSuffix(rule.Host, appSuffix)
+		default:
+			state.CNames = append(state.CNames, rule.Host)
+			continue
+		}
+		var target map[string]string
+		if rule.HTTP != nil && len(rule.HTTP.Paths) > 0 && rule.HTTP.Paths[0].Backend.Service != nil {
+			target = map[string]string{
+				"service":   rule.HTTP.Paths[0].Backend.Service.Name,
+				"namespace": ingress.Namespace,
+			}
+		}
+		state.Prefixes = append(state.Prefixes, router.BackendPrefix{Prefix: prefix, Target: target})
+	}
+	return state, nil
+}
+
+func (r *ingressRouter) GetBackendStatus(ctx context.Context, app router.App) (router.RouterBackendStatus, error) {
+	ingress, err := r.getIngress(ctx, app)
+	if err != nil {
+		return router.RouterBackendStatus{}, err
+	}
+	if len(ingress.Status.LoadBalancer.Ingress) == 0 {
+		return router.RouterBackendStatus{
+			Status: router.BackendStatusNotReady,
+			Detail: "waiting for load balancer address",
+		}, nil
+	}
+	return router.RouterBackendStatus{Status: router.BackendStatusReady}, nil
+}
+
+func (r *ingressRouter) AddCertificate(ctx context.Context, app router.App, cname, certificate, key string) error {
+	client, ns, err := r.clientAndNamespace(ctx, app)
+	if err != nil {
+		return err
+	}
+	secret := &apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        r.secretName(app, cname),
+			Namespace:   ns,
+			Labels:      r.labels(app),
+			Annotations: map[string]string{tsuruAnnotationRouterHost: cname},
+		},
+		Type: apiv1.SecretTypeTLS,
+		Data: map[string][]byte{
+			apiv1.TLSCertKey:       []byte(certificate),
+			apiv1.TLSPrivateKeyKey: []byte(key),
+		},
+	}
+	existing, err := client.CoreV1().Secrets(ns).Get(ctx, secret.Name, metav1.GetOptions{})
+	if k8sErrors.IsNotFound(err) {
+		_, err = client.CoreV1().Secrets(ns).Create(ctx, secret, metav1.CreateOptions{})
+	} else if err == nil {
+		secret.ResourceVersion = existing.ResourceVersion
+		_, err = client.CoreV1().Secrets(ns).Update(ctx, secret, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+	return r.syncIngressTLS(ctx, client, ns, app)
+}
+
+func (r *ingressRouter) RemoveCertificate(ctx context.Context, app router.App, cname string) error {
+	client, ns, err := r.clientAndNamespace(ctx, app)
+	if err != nil {
+		return err
+	}
+	err = client.CoreV1().Secrets(ns).Delete(ctx, r.secretName(app, cname), metav1.DeleteOptions{})
+	if k8sErrors.IsNotFound(err) {
+		return router.ErrCertificateNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return r.syncIngressTLS(ctx, client, ns, app)
+}
+
+func (r *ingressRouter) GetCertificate(ctx context.Context, app router.App, cname string) (string, error) {
+	client, ns, err := r.clientAndNamespace(ctx, app)
+	if err != nil {
+		return "", err
+	}
+	secret, err := client.CoreV1().Secrets(ns).Get(ctx, r.secretName(app, cname), metav1.GetOptions{})
+	if k8sErrors.IsNotFound(err) {
+		return "", router.ErrCertificateNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(secret.Data[apiv1.TLSCertKey]), nil
+}
+
+func (r *ingressRouter) acmeIngressName(app router.App, cname string) string {
+	return r.cnameResourceName(app, "acme", cname)
+}
+
+// AddACMEChallenge creates an ingress routing the challenge path of the cname
+// to the tsuru API, through an ExternalName service pointing to the host set
+// in the acme-solver-host config.
+func (r *ingressRouter) AddACMEChallenge(ctx context.Context, app router.App, cname, token string) error {
+	if r.acmeSolverHost == "" {
+		return errors.Errorf("acme-solver-host is not configured for router %q", r.name)
+	}
+	client, ns, err := r.clientAndNamespace(ctx, app)
+	if err != nil {
+		return err
+	}
+	_, err = client.CoreV1().Services(ns).Create(ctx, &apiv1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      acmeSolverServiceName,
+			Namespace: ns,
+		},
+		Spec: apiv1.ServiceSpec{
+			Type:         apiv1.ServiceTypeExternalName,
+			ExternalName: r.acmeSolverHost,
+			Ports:        []apiv1.ServicePort{{Port: int32(r.acmeSolverPort)}},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil && !k8sErrors.IsAlreadyExists(err) {
+		return err
+	}
+	annotations := map[string]string{tsuruAnnotationRouterHost: cname}
+	for k, v := range r.annotations {
+		annotations[k] = v
+	}
+	pathType := networkingv1.PathTypeExact
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        r.acmeIngressName(app, cname),
+			Namespace:   ns,
+			Labels:      r.labels(app),
+			Annotations: annotations,
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{{
+				Host: cname,
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     acmeChallengePathPrefix + token,
+							PathType: &pathType,
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{
+									Name: acmeSolverServiceName,
+									Port: networkingv1.ServiceBackendPort{Number: int32(r.acmeSolverPort)},
+								},
+							},
+						}},
+					},
+				},
+			}},
+		},
+	}
+	if r.ingressClass != "" {
+		ingress.Spec.IngressClassName = &r.ingressClass
+	}
+	existing, err := client.NetworkingV1().Ingresses(ns).Get(ctx, ingress.Name, metav1.GetOptions{})
+	if k8sErrors.IsNotFound(err) {
+		_, err = client.NetworkingV1().Ingresses(ns).Create(ctx, ingress, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	ingress.ResourceVersion = existing.ResourceVersion
+	_, err = client.NetworkingV1().Ingresses(ns).Update(ctx, ingress, metav1.UpdateOptions{})
+	return err
+}
+
+func (r *ingressRouter) RemoveACMEChallenge(ctx context.Context, app router.App, cname, token string) error {
+	client, ns, err := r.clientAndNamespace(ctx, app)
+	if err != nil {
+		return err
+	}
+	err = client.NetworkingV1().Ingresses(ns).Delete(ctx, r.acmeIngressName(app, cname), metav1.DeleteOptions{})
+	if k8sErrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
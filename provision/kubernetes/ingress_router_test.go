@@ -0,0 +1,260 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+
+	tsuruv1 "github.com/tsuru/tsuru/provision/kubernetes/pkg/apis/tsuru/v1"
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/router/routertest"
+	check "gopkg.in/check.v1"
+	apiv1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func (s *S) setUpIngressRouter(c *check.C, domain string) (*ingressRouter, routertest.FakeApp) {
+	ctx := context.TODO()
+	_, err := s.client.TsuruV1().Apps("tsuru").Create(ctx, &tsuruv1.App{
+		ObjectMeta: metav1.ObjectMeta{Name: "myapp"},
+		Spec:       tsuruv1.AppSpec{NamespaceName: "default"},
+	}, metav1.CreateOptions{})
+	c.Assert(err, check.IsNil)
+	for _, name := range []string{"myapp-web", "myapp-worker"} {
+		_, err = s.client.CoreV1().Services("default").Create(ctx, &apiv1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: apiv1.ServiceSpec{
+				Ports: []apiv1.ServicePort{{Port: 8888}},
+			},
+		}, metav1.CreateOptions{})
+		c.Assert(err, check.IsNil)
+	}
+	config := router.ConfigGetterFromData(map[string]interface{}{
+		"domain":                      domain,
+		"ingress-class":               "nginx",
+		"healthcheck-path-annotation": "example.com/healthcheck-path",
+		"annotations": map[string]interface{}{
+			"example.com/foo": "bar",
+		},
+	})
+	r, err := createIngressRouter("ingress", config)
+	c.Assert(err, check.IsNil)
+	return r.(*ingressRouter), routertest.FakeApp{Name: "myapp", Pool: "test-default"}
+}
+
+func ingressBackendOpts() router.EnsureBackendOpts {
+	return router.EnsureBackendOpts{
+		Prefixes: []router.BackendPrefix{
+			{Target: map[string]string{"service": "myapp-web", "namespace": "default"}},
+			{Prefix: "worker.process", Target: map[string]string{"service": "myapp-worker", "namespace": "default"}},
+		},
+		CNames: []string{"www.example.com"},
+	}
+}
+
+func (s *S) TestIngressRouterEnsureBackend(c *check.C) {
+	r, app := s.setUpIngressRouter(c, "apps.example.com")
+	opts := ingressBackendOpts()
+	opts.Healthcheck.Path = "/healthz"
+	err := r.EnsureBackend(context.TODO(), app, opts)
+	c.Assert(err, check.IsNil)
+	ingress, err := s.client.NetworkingV1().Ingresses("default").Get(context.TODO(), "myapp-ingress", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(ingress.Labels, check.DeepEquals, map[string]string{
+		"tsuru.io/app-name":    "myapp",
+		"tsuru.io/router-name": "ingress",
+	})
+	c.Assert(ingress.Annotations, check.DeepEquals, map[string]string{
+		"example.com/foo":              "bar",
+		"example.com/healthcheck-path": "/healthz",
+	})
+	c.Assert(*ingress.Spec.IngressClassName, check.Equals, "nginx")
+	pathType := networkingv1.PathTypePrefix
+	webBackend := networkingv1.IngressBackend{
+		Service: &networkingv1.IngressServiceBackend{Name: "myapp-web", Port: networkingv1.ServiceBackendPort{Number: 8888}},
+	}
+	workerBackend := networkingv1.IngressBackend{
+		Service: &networkingv1.IngressServiceBackend{Name: "myapp-worker", Port: networkingv1.ServiceBackendPort{Number: 8888}},
+	}
+	rule := func(host string, backend networkingv1.IngressBackend) networkingv1.IngressRule {
+		return networkingv1.IngressRule{
+			Host: host,
+			IngressRuleValue: networkingv1.IngressRuleValue{
+				HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{{Path: "/", PathType: &pathType, Backend: backend}},
+				},
+			},
+		}
+	}
+	c.Assert(ingress.Spec.Rules, check.DeepEquals, []networkingv1.IngressRule{
+		rule("myapp.apps.example.com", webBackend),
+		rule("worker.process.myapp.apps.example.com", workerBackend),
+		rule("www.example.com", webBackend),
+	})
+	opts.CNames = nil
+	err = r.EnsureBackend(context.TODO(), app, opts)
+	c.Assert(err, check.IsNil)
+	ingress, err = s.client.NetworkingV1().Ingresses("default").Get(context.TODO(), "myapp-ingress", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(ingress.Spec.Rules, check.HasLen, 2)
+}
+
+func (s *S) TestIngressRouterEnsureBackendWithoutDomain(c *check.C) {
+	r, app := s.setUpIngressRouter(c, "")
+	err := r.EnsureBackend(context.TODO(), app, ingressBackendOpts())
+	c.Assert(err, check.IsNil)
+	ingress, err := s.client.NetworkingV1().Ingresses("default").Get(context.TODO(), "myapp-ingress", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(ingress.Spec.DefaultBackend.Service.Name, check.Equals, "myapp-web")
+	c.Assert(ingress.Spec.Rules, check.HasLen, 1)
+	c.Assert(ingress.Spec.Rules[0].Host, check.Equals, "www.example.com")
+}
+
+func (s *S) TestIngressRouterAddressesAndStatus(c *check.C) {
+	r, app := s.setUpIngressRouter(c, "")
+	_, err := r.Addresses(context.TODO(), app)
+	c.Assert(err, check.Equals, router.ErrBackendNotFound)
+	err = r.EnsureBackend(context.TODO(), app, ingressBackendOpts())
+	c.Assert(err, check.IsNil)
+	status, err := r.GetBackendStatus(context.TODO(), app)
+	c.Assert(err, check.IsNil)
+	c.Assert(status.Status, check.Equals, router.BackendStatusNotReady)
+	ingress, err := s.client.NetworkingV1().Ingresses("default").Get(context.TODO(), "myapp-ingress", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	ingress.Status.LoadBalancer.Ingress = []networkingv1.IngressLoadBalancerIngress{{IP: "10.0.0.1"}}
+	_, err = s.client.NetworkingV1().Ingresses("default").UpdateStatus(context.TODO(), ingress, metav1.UpdateOptions{})
+	c.Assert(err, check.IsNil)
+	addrs, err := r.Addresses(context.TODO(), app)
+	c.Assert(err, check.IsNil)
+	c.Assert(addrs, check.DeepEquals, []string{"10.0.0.1"})
+	status, err = r.GetBackendStatus(context.TODO(), app)
+	c.Assert(err, check.IsNil)
+	c.Assert(status, check.DeepEquals, router.RouterBackendStatus{Status: router.BackendStatusReady})
+}
+
+func (s *S) TestIngressRouterRemoveBackend(c *check.C) {
+	r, app := s.setUpIngressRouter(c, "apps.example.com")
+	err := r.EnsureBackend(context.TODO(), app, ingressBackendOpts())
+	c.Assert(err, check.IsNil)
+	err = r.AddCertificate(context.TODO(), app, "www.example.com", "cert", "key")
+	c.Assert(err, check.IsNil)
+	r.acmeSolverHost = "tsuru-api.tsuru-system.svc.cluster.local"
+	err = r.AddACMEChallenge(context.TODO(), app, "www.example.com", "tok3n")
+	c.Assert(err, check.IsNil)
+	err = r.RemoveBackend(context.TODO(), app)
+	c.Assert(err, check.IsNil)
+	ingresses, err := s.client.NetworkingV1().Ingresses("default").List(context.TODO(), metav1.ListOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(ingresses.Items, check.HasLen, 0)
+	err = r.RemoveBackend(context.TODO(), app)
+	c.Assert(err, check.Equals, router.ErrBackendNotFound)
+}
+
+func (s *S) TestIngressRouterCertificates(c *check.C) {
+	r, app := s.setUpIngressRouter(c, "apps.example.com")
+	err := r.EnsureBackend(context.TODO(), app, ingressBackendOpts())
+	c.Assert(err, check.IsNil)
+	err = r.AddCertificate(context.TODO(), app, "www.example.com", "cert", "key")
+	c.Assert(err, check.IsNil)
+	secret, err := s.client.CoreV1().Secrets("default").Get(context.TODO(), "myapp-ingress-tls-www.example.com-80fc0fb926", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(secret.Type, check.Equals, apiv1.SecretTypeTLS)
+	c.Assert(secret.Data, check.DeepEquals, map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key")})
+	ingress, err := s.client.NetworkingV1().Ingresses("default").Get(context.TODO(), "myapp-ingress", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(ingress.Spec.TLS, check.DeepEquals, []networkingv1.IngressTLS{
+		{Hosts: []string{"www.example.com"}, SecretName: "myapp-ingress-tls-www.example.com-80fc0fb926"},
+	})
+	cert, err := r.GetCertificate(context.TODO(), app, "www.example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(cert, check.Equals, "cert")
+	err = r.RemoveCertificate(context.TODO(), app, "www.example.com")
+	c.Assert(err, check.IsNil)
+	ingress, err = s.client.NetworkingV1().Ingresses("default").Get(context.TODO(), "myapp-ingress", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(ingress.Spec.TLS, check.HasLen, 0)
+	_, err = r.GetCertificate(context.TODO(), app, "www.example.com")
+	c.Assert(err, check.Equals, router.ErrCertificateNotFound)
+	err = r.RemoveCertificate(context.TODO(), app, "www.example.com")
+	c.Assert(err, check.Equals, router.ErrCertificateNotFound)
+}
+
+func (s *S) TestIngressRouterCNameResourceName(c *check.C) {
+	r, app := s.setUpIngressRouter(c, "apps.example.com")
+	longCName := strings.Repeat(strings.Repeat("a", 62)+".", 4) + "example.com"
+	tests := []struct {
+		cname    string
+		expected string
+	}{
+		{cname: "www.example.com", expected: "myapp-ingress-tls-www.example.com-80fc0fb926"},
+		{cname: "*.example.com", expected: "myapp-ingress-tls-example.com-47287a8f16"},
+		{cname: "WWW.Example.com", expected: "myapp-ingress-tls-www.example.com-c6dc0e62a4"},
+		{cname: "_acme.my_app.example.com", expected: "myapp-ingress-tls-acme.my-app.example.com-238d086214"},
+		{cname: "*", expected: "myapp-ingress-tls-684888c0eb"},
+		{cname: longCName},
+	}
+	for _, tt := range tests {
+		name := r.secretName(app, tt.cname)
+		c.Check(validation.IsDNS1123Subdomain(name), check.HasLen, 0, check.Commentf("cname %q", tt.cname))
+		if tt.expected != "" {
+			c.Check(name, check.Equals, tt.expected)
+		}
+	}
+	c.Assert(r.secretName(app, longCName), check.HasLen, 253)
+	c.Assert(r.acmeIngressName(app, "*.example.com"), check.Equals, "myapp-ingress-acme-example.com-47287a8f16")
+}
+
+func (s *S) TestIngressRouterACMEChallenge(c *check.C) {
+	r, app := s.setUpIngressRouter(c, "apps.example.com")
+	err := r.AddACMEChallenge(context.TODO(), app, "www.example.com", "tok3n")
+	c.Assert(err, check.ErrorMatches, `acme-solver-host is not configured for router "ingress"`)
+	r.acmeSolverHost = "tsuru-api.tsuru-system.svc.cluster.local"
+	err = r.AddACMEChallenge(context.TODO(), app, "www.example.com", "tok3n")
+	c.Assert(err, check.IsNil)
+	svc, err := s.client.CoreV1().Services("default").Get(context.TODO(), "tsuru-acme-solver", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(svc.Spec.Type, check.Equals, apiv1.ServiceTypeExternalName)
+	c.Assert(svc.Spec.ExternalName, check.Equals, "tsuru-api.tsuru-system.svc.cluster.local")
+	ingress, err := s.client.NetworkingV1().Ingresses("default").Get(context.TODO(), "myapp-ingress-acme-www.example.com-80fc0fb926", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(ingress.Spec.Rules, check.HasLen, 1)
+	c.Assert(ingress.Spec.Rules[0].Host, check.Equals, "www.example.com")
+	path := ingress.Spec.Rules[0].HTTP.Paths[0]
+	c.Assert(path.Path, check.Equals, "/.well-known/acme-challenge/tok3n")
+	c.Assert(*path.PathType, check.Equals, networkingv1.PathTypeExact)
+	c.Assert(path.Backend.Service.Name, check.Equals, "tsuru-acme-solver")
+	err = r.AddACMEChallenge(context.TODO(), app, "www.example.com", "other")
+	c.Assert(err, check.IsNil)
+	err = r.RemoveACMEChallenge(context.TODO(), app, "www.example.com", "other")
+	c.Assert(err, check.IsNil)
+	_, err = s.client.NetworkingV1().Ingresses("default").Get(context.TODO(), "myapp-ingress-acme-www.example.com-80fc0fb926", metav1.GetOptions{})
+	c.Assert(err, check.NotNil)
+}
+
+func (s *S) TestIngressRouterGetBackendState(c *check.C) {
+	r, app := s.setUpIngressRouter(c, "apps.example.com")
+	_, err := r.GetBackendState(context.TODO(), app)
+	c.Assert(err, check.Equals, router.ErrBackendNotFound)
+	err = r.EnsureBackend(context.TODO(), app, ingressBackendOpts())
+	c.Assert(err, check.IsNil)
+	state, err := r.GetBackendState(context.TODO(), app)
+	c.Assert(err, check.IsNil)
+	c.Assert(state, check.DeepEquals, router.BackendState{
+		CNames:   []string{"www.example.com"},
+		Prefixes: ingressBackendOpts().Prefixes,
+	})
+}
+
+func (s *S) TestIngressRouterGetBackendStateWithoutDomain(c *check.C) {
+	r, app := s.setUpIngressRouter(c, "")
+	err := r.EnsureBackend(context.TODO(), app, ingressBackendOpts())
+	c.Assert(err, check.IsNil)
+	state, err := r.GetBackendState(context.TODO(), app)
+	c.Assert(err, check.IsNil)
+	c.Assert(state, check.DeepEquals, router.BackendState{CNames: []string{"www.example.com"}})
+}
@@ -25,6 +25,7 @@ import (
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/utils/ptr"
@@ -62,7 +63,7 @@ var (
 	}, []string{"job_name"})
 )
 
-func buildJobSpec(job *jobTypes.Job, client *ClusterClient, labels, annotations map[string]string) (batchv1.JobSpec, error) {
+func buildJobSpec(ctx context.Context, job *jobTypes.Job, client *ClusterClient, labels, annotations map[string]string) (batchv1.JobSpec, error) {
 	jSpec := job.Spec
 
 	requirements, err := resourceRequirements(&job.Plan, job.Pool, client, requirementsFactors{})
@@ -70,6 +71,11 @@ func buildJobSpec(job *jobTypes.Job, client *ClusterClient, labels, annotations
 		return batchv1.JobSpec{}, err
 	}
 
+	volumes, mounts, err := createVolumesForJob(ctx, client, job)
+	if err != nil {
+		return batchv1.JobSpec{}, err
+	}
+
 	envs := []apiv1.EnvVar{}
 
 	for _, env := range jSpec.Envs {
@@ -106,13 +112,15 @@ func buildJobSpec(job *jobTypes.Job, client *ClusterClient, labels, annotations
 				RestartPolicy: "OnFailure",
 				Containers: []apiv1.Container{
 					{
-						Name:      "job",
-						Image:     imageURL,
-						Command:   jSpec.Container.Command,
-						Resources: requirements,
-						Env:       envs,
+						Name:         "job",
+						Image:        imageURL,
+						Command:      jSpec.Container.Command,
+						Resources:    requirements,
+						Env:          envs,
+						VolumeMounts: mounts,
 					},
 				},
+				Volumes:            volumes,
 				ServiceAccountName: serviceAccountNameForJob(*job),
 			},
 		},
@@ -121,7 +129,7 @@ func buildJobSpec(job *jobTypes.Job, client *ClusterClient, labels, annotations
 
 func ensureCronjob(ctx context.Context, client *ClusterClient, job *jobTypes.Job) error {
 	labels, annotations := buildMetadata(ctx, job)
-	jobSpec, err := buildJobSpec(job, client, labels, annotations)
+	jobSpec, err := buildJobSpec(ctx, job, client, labels, annotations)
 	if err != nil {
 		return err
 	}
@@ -269,15 +277,15 @@ func (p *kubernetesProvisioner) EnsureJob(ctx context.Context, job *jobTypes.Job
 	return ensureCronjob(ctx, client, job)
 }
 
-func (p *kubernetesProvisioner) TriggerCron(ctx context.Context, name, pool string) error {
+func (p *kubernetesProvisioner) TriggerCron(ctx context.Context, name, pool string, opts jobTypes.TriggerOpts) (string, error) {
 	client, err := clusterForPool(ctx, pool)
 	if err != nil {
-		return err
+		return "", err
 	}
 	namespace := client.PoolNamespace(pool)
 	cron, err := client.BatchV1().CronJobs(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		return err
+		return "", err
 	}
 	cronChild := batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
@@ -297,12 +305,45 @@ func (p *kubernetesProvisioner) TriggerCron(ctx context.Context, name, pool stri
 	}
 	cronChild.Name = getManualJobName(cron.Name)
 	if cronChild.Annotations == nil {
-		cronChild.Annotations = map[string]string{"cronjob.kubernetes.io/instantiate": "manual"}
-	} else {
-		cronChild.Annotations["cronjob.kubernetes.io/instantiate"] = "manual"
+		cronChild.Annotations = map[string]string{}
 	}
-	_, err = client.BatchV1().Jobs(cron.Namespace).Create(ctx, &cronChild, metav1.CreateOptions{})
-	return err
+	cronChild.Annotations[cronJobInstantiateAnnotation] = "manual"
+	cronChild.Annotations[AnnotationJobTrigger] = string(jobTypes.RunTriggerAPI)
+	if opts.HasOverrides() {
+		cronChild.Name = getAdHocJobName(cron.Name)
+		cronChild.Annotations[AnnotationJobTrigger] = string(jobTypes.RunTriggerAdHoc)
+	}
+	if opts.ActiveDeadlineSeconds != nil {
+		cronChild.Spec.ActiveDeadlineSeconds = opts.ActiveDeadlineSeconds
+	}
+	if opts.Workflow != "" {
+		cronChild.Name = getWorkflowJobName(cron.Name)
+		cronChild.Annotations[AnnotationJobWorkflow] = opts.Workflow
+	}
+	cronChild.Spec.Template = *cronChild.Spec.Template.DeepCopy()
+	for i := range cronChild.Spec.Template.Spec.Containers {
+		container := &cronChild.Spec.Template.Spec.Containers[i]
+		if container.Name != "job" {
+			continue
+		}
+		if opts.Image != "" {
+			container.Image = opts.Image
+		}
+		if len(opts.Command) > 0 {
+			container.Command = opts.Command
+		}
+		for _, env := range opts.Envs {
+			container.Env = append(container.Env, apiv1.EnvVar{
+				Name:  env.Name,
+				Value: strings.ReplaceAll(env.Value, "$", "$$"),
+			})
+		}
+	}
+	created, err := client.BatchV1().Jobs(cron.Namespace).Create(ctx, &cronChild, metav1.CreateOptions{})
+	if err != nil {
+		return "", err
+	}
+	return string(created.UID), nil
 }
 
 func getManualJobName(job string) string {
@@ -310,6 +351,18 @@ func getManualJobName(job string) string {
 	return fmt.Sprintf("%s-manual-job-%d", job, scheduledTime.Unix()/60)
 }
 
+// getWorkflowJobName names runs triggered by workflows with a random suffix,
+// as a job may be a dependent of several steps finishing in the same minute.
+func getWorkflowJobName(job string) string {
+	return fmt.Sprintf("%s-wf-%s", job, rand.String(5))
+}
+
+// getAdHocJobName names runs overriding the job definition with a random
+// suffix, as more than one of them may be started at the same time.
+func getAdHocJobName(job string) string {
+	return fmt.Sprintf("%s-adhoc-%s", job, rand.String(5))
+}
+
 // JobUnits returns information about units related to a specific Job or CronJob
 func (p *kubernetesProvisioner) JobUnits(ctx context.Context, job *jobTypes.Job) ([]provTypes.Unit, error) {
 	client, err := clusterForPool(ctx, job.Pool)
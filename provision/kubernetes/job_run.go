@@ -0,0 +1,143 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/servicemanager"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+const cronJobInstantiateAnnotation = "cronjob.kubernetes.io/instantiate"
+
+// startJobRunRecorder keeps the history of every run of tsuru jobs, so runs
+// are still available after their units are removed from the cluster.
+func (c *clusterController) startJobRunRecorder() error {
+	jobInformer, err := c.getJobInformerWait(false)
+	if err != nil {
+		return err
+	}
+	jobInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.onJobRunChange(obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			c.onJobRunChange(newObj)
+		},
+	})
+	return nil
+}
+
+func (c *clusterController) onJobRunChange(obj interface{}) {
+	if !c.isLeader() || servicemanager.JobRun == nil {
+		return
+	}
+	job, ok := obj.(*batchv1.Job)
+	if !ok || job.Labels[tsuruLabelJobName] == "" {
+		return
+	}
+	var pods []*apiv1.Pod
+	if podInformer, err := c.getPodInformer(); err == nil {
+		pods, _ = podInformer.Lister().Pods(job.Namespace).List(labels.SelectorFromSet(labels.Set{"job-name": job.Name}))
+	}
+	run := jobRunFromJob(job, pods)
+	run.Cluster = c.cluster.Name
+	err := servicemanager.JobRun.SaveRun(context.Background(), run)
+	if err != nil {
+		log.Errorf("unable to save run %q of job %q: %v", run.Unit, run.Job, err)
+	}
+}
+
+func jobRunFromJob(job *batchv1.Job, pods []*apiv1.Pod) *jobTypes.Run {
+	run := &jobTypes.Run{
+		ID:        string(job.UID),
+		Job:       job.Labels[tsuruLabelJobName],
+		Unit:      job.Name,
+		Pool:      job.Labels[tsuruLabelPrefix+provision.LabelJobPool],
+		Workflow:  job.Annotations[AnnotationJobWorkflow],
+		Trigger:   jobRunTrigger(job),
+		Status:    jobTypes.RunStatusRunning,
+		StartTime: job.CreationTimestamp.Time.UTC(),
+	}
+	if job.Status.StartTime != nil {
+		run.StartTime = job.Status.StartTime.Time.UTC()
+	}
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != apiv1.ConditionTrue {
+			continue
+		}
+		endTime := cond.LastTransitionTime.Time.UTC()
+		switch cond.Type {
+		case batchv1.JobComplete:
+			run.Status = jobTypes.RunStatusSucceeded
+			if job.Status.CompletionTime != nil {
+				endTime = job.Status.CompletionTime.Time.UTC()
+			}
+		case batchv1.JobFailed:
+			run.Status = jobTypes.RunStatusFailed
+			run.Reason = cond.Reason
+			run.Message = cond.Message
+		default:
+			continue
+		}
+		run.EndTime = &endTime
+	}
+	fillRunExitStatus(run, pods)
+	return run
+}
+
+func jobRunTrigger(job *batchv1.Job) jobTypes.RunTrigger {
+	switch trigger := jobTypes.RunTrigger(job.Annotations[AnnotationJobTrigger]); trigger {
+	case jobTypes.RunTriggerAPI, jobTypes.RunTriggerAdHoc:
+		return trigger
+	}
+	if job.Annotations[cronJobInstantiateAnnotation] == "manual" {
+		return jobTypes.RunTriggerManual
+	}
+	for _, owner := range job.OwnerReferences {
+		if owner.Kind == "CronJob" {
+			return jobTypes.RunTriggerCron
+		}
+	}
+	return jobTypes.RunTriggerManual
+}
+
+// fillRunExitStatus uses the last terminated job container to set the run
+// exit code. Failed runs only consider failed containers, as a container may
+// succeed in an earlier attempt of a run with more completions.
+func fillRunExitStatus(run *jobTypes.Run, pods []*apiv1.Pod) {
+	var last *apiv1.ContainerStateTerminated
+	for _, pod := range pods {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name != "job" {
+				continue
+			}
+			for _, state := range []apiv1.ContainerState{status.State, status.LastTerminationState} {
+				terminated := state.Terminated
+				if terminated == nil || (run.Status == jobTypes.RunStatusFailed && terminated.ExitCode == 0) {
+					continue
+				}
+				if last == nil || terminated.FinishedAt.After(last.FinishedAt.Time) {
+					last = terminated
+				}
+			}
+		}
+	}
+	if last == nil {
+		return
+	}
+	exitCode := last.ExitCode
+	run.ExitCode = &exitCode
+	if exitCode != 0 {
+		run.UnitReason = last.Reason
+	}
+}
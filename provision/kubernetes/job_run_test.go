@@ -0,0 +1,116 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"time"
+
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	check "gopkg.in/check.v1"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func (s *S) TestJobRunFromJobRunning(c *check.C) {
+	created := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "myjob-29000000",
+			UID:               "uid-1",
+			CreationTimestamp: metav1.NewTime(created),
+			Labels: map[string]string{
+				"tsuru.io/job-name": "myjob",
+				"tsuru.io/job-pool": "pool1",
+			},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "CronJob", Name: "myjob"}},
+		},
+	}
+	run := jobRunFromJob(job, nil)
+	c.Assert(run, check.DeepEquals, &jobTypes.Run{
+		ID:        "uid-1",
+		Job:       "myjob",
+		Unit:      "myjob-29000000",
+		Pool:      "pool1",
+		Trigger:   jobTypes.RunTriggerCron,
+		Status:    jobTypes.RunStatusRunning,
+		StartTime: created,
+	})
+}
+
+func (s *S) TestJobRunFromJobSucceeded(c *check.C) {
+	start := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Minute)
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "myjob-manual-job-1",
+			UID:    "uid-1",
+			Labels: map[string]string{"tsuru.io/job-name": "myjob"},
+			Annotations: map[string]string{
+				"cronjob.kubernetes.io/instantiate": "manual",
+				"job.tsuru.io/trigger":              "api",
+			},
+		},
+		Status: batchv1.JobStatus{
+			StartTime:      ptr.To(metav1.NewTime(start)),
+			CompletionTime: ptr.To(metav1.NewTime(end)),
+			Conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobComplete, Status: apiv1.ConditionTrue, LastTransitionTime: metav1.NewTime(end.Add(time.Second))},
+			},
+		},
+	}
+	pods := []*apiv1.Pod{{
+		Status: apiv1.PodStatus{
+			ContainerStatuses: []apiv1.ContainerStatus{{
+				Name:  "job",
+				State: apiv1.ContainerState{Terminated: &apiv1.ContainerStateTerminated{ExitCode: 0, Reason: "Completed", FinishedAt: metav1.NewTime(end)}},
+			}},
+		},
+	}}
+	run := jobRunFromJob(job, pods)
+	c.Assert(run.Trigger, check.Equals, jobTypes.RunTriggerAPI)
+	c.Assert(run.Status, check.Equals, jobTypes.RunStatusSucceeded)
+	c.Assert(run.StartTime, check.DeepEquals, start)
+	c.Assert(*run.EndTime, check.DeepEquals, end)
+	c.Assert(*run.ExitCode, check.Equals, int32(0))
+	c.Assert(run.UnitReason, check.Equals, "")
+}
+
+func (s *S) TestJobRunFromJobFailed(c *check.C) {
+	start := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Minute)
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "myjob-manual",
+			UID:         "uid-1",
+			Labels:      map[string]string{"tsuru.io/job-name": "myjob"},
+			Annotations: map[string]string{"cronjob.kubernetes.io/instantiate": "manual"},
+		},
+		Status: batchv1.JobStatus{
+			StartTime: ptr.To(metav1.NewTime(start)),
+			Conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobFailed, Status: apiv1.ConditionTrue, Reason: "BackoffLimitExceeded", Message: "Job has reached the specified backoff limit", LastTransitionTime: metav1.NewTime(end)},
+			},
+		},
+	}
+	pods := []*apiv1.Pod{{
+		Status: apiv1.PodStatus{
+			ContainerStatuses: []apiv1.ContainerStatus{{
+				Name:                 "job",
+				State:                apiv1.ContainerState{Terminated: &apiv1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled", FinishedAt: metav1.NewTime(end)}},
+				LastTerminationState: apiv1.ContainerState{Terminated: &apiv1.ContainerStateTerminated{ExitCode: 1, Reason: "Error", FinishedAt: metav1.NewTime(start.Add(time.Second))}},
+			}},
+		},
+	}}
+	run := jobRunFromJob(job, pods)
+	c.Assert(run.Trigger, check.Equals, jobTypes.RunTriggerManual)
+	c.Assert(run.Status, check.Equals, jobTypes.RunStatusFailed)
+	c.Assert(run.Reason, check.Equals, "BackoffLimitExceeded")
+	c.Assert(run.Message, check.Equals, "Job has reached the specified backoff limit")
+	c.Assert(*run.EndTime, check.DeepEquals, end)
+	c.Assert(*run.ExitCode, check.Equals, int32(137))
+	c.Assert(run.UnitReason, check.Equals, "OOMKilled")
+}
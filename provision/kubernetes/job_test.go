@@ -7,6 +7,7 @@ package kubernetes
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -647,7 +648,7 @@ func (s *S) TestProvisionerTriggerCron(c *check.C) {
 			},
 			scenario: func(t *time.Time) {
 				*t = time.Now()
-				err := s.p.TriggerCron(context.TODO(), "myjob", "test-default")
+				_, err := s.p.TriggerCron(context.TODO(), "myjob", "test-default", jobTypes.TriggerOpts{})
 				c.Assert(err, check.IsNil)
 				waitCron()
 			},
@@ -672,7 +673,7 @@ func (s *S) TestProvisionerTriggerCron(c *check.C) {
 							"tsuru.io/job-manual":          "false",
 							"tsuru.io/is-build":            "false",
 						},
-						Annotations: map[string]string{"cronjob.kubernetes.io/instantiate": "manual"},
+						Annotations: map[string]string{"cronjob.kubernetes.io/instantiate": "manual", "job.tsuru.io/trigger": "api"},
 						OwnerReferences: []metav1.OwnerReference{
 							{
 								Name:       cronParent.Name,
@@ -755,6 +756,92 @@ func (s *S) TestProvisionerTriggerCron(c *check.C) {
 	}
 }
 
+func (s *S) TestProvisionerTriggerCronWithOpts(c *check.C) {
+	waitCron := s.mock.CronJobReactions(c)
+	defer waitCron()
+	cj := jobTypes.Job{
+		Name:      "myjob",
+		TeamOwner: s.team.Name,
+		Pool:      "pool1",
+		Spec: jobTypes.JobSpec{
+			Schedule: "* * * * *",
+			Container: jobTypes.ContainerInfo{
+				OriginalImageSrc: "ubuntu:latest",
+			},
+			Envs: []bindTypes.EnvVar{{Name: "MY_ENV", Value: "value"}},
+		},
+	}
+	err := s.p.EnsureJob(context.TODO(), &cj)
+	c.Assert(err, check.IsNil)
+	waitCron()
+	_, err = s.p.TriggerCron(context.TODO(), "myjob", "pool1", jobTypes.TriggerOpts{
+		Envs:     []bindTypes.EnvVar{{Name: "PARAM", Value: "$1"}},
+		Workflow: "wf1",
+	})
+	c.Assert(err, check.IsNil)
+	_, err = s.p.TriggerCron(context.TODO(), "myjob", "pool1", jobTypes.TriggerOpts{Workflow: "wf2"})
+	c.Assert(err, check.IsNil)
+	jobs, err := s.client.BatchV1().Jobs("default").List(context.TODO(), metav1.ListOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(jobs.Items, check.HasLen, 2)
+	sort.Slice(jobs.Items, func(i, j int) bool {
+		return jobs.Items[i].Annotations["job.tsuru.io/workflow"] < jobs.Items[j].Annotations["job.tsuru.io/workflow"]
+	})
+	c.Assert(jobs.Items[0].Name, check.Matches, "myjob-wf-[a-z0-9]{5}")
+	c.Assert(jobs.Items[0].Annotations["job.tsuru.io/workflow"], check.Equals, "wf1")
+	c.Assert(jobs.Items[0].Annotations["job.tsuru.io/trigger"], check.Equals, "api")
+	c.Assert(jobs.Items[0].Spec.Template.Spec.Containers[0].Env, check.DeepEquals, []corev1.EnvVar{
+		{Name: "MY_ENV", Value: "value"},
+		{Name: "PARAM", Value: "$$1"},
+	})
+	cron, err := s.client.BatchV1().CronJobs("default").Get(context.TODO(), "myjob", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(cron.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Env, check.DeepEquals, []corev1.EnvVar{
+		{Name: "MY_ENV", Value: "value"},
+	})
+}
+
+func (s *S) TestProvisionerTriggerCronAdHoc(c *check.C) {
+	waitCron := s.mock.CronJobReactions(c)
+	defer waitCron()
+	cj := jobTypes.Job{
+		Name:      "myjob",
+		TeamOwner: s.team.Name,
+		Pool:      "pool1",
+		Spec: jobTypes.JobSpec{
+			Schedule: "* * * * *",
+			Container: jobTypes.ContainerInfo{
+				OriginalImageSrc: "ubuntu:latest",
+				Command:          []string{"echo", "hello"},
+			},
+		},
+	}
+	err := s.p.EnsureJob(context.TODO(), &cj)
+	c.Assert(err, check.IsNil)
+	waitCron()
+	deadline := int64(30)
+	_, err = s.p.TriggerCron(context.TODO(), "myjob", "pool1", jobTypes.TriggerOpts{
+		Command:               []string{"echo", "bye"},
+		Image:                 "ubuntu:24.04",
+		ActiveDeadlineSeconds: &deadline,
+	})
+	c.Assert(err, check.IsNil)
+	jobs, err := s.client.BatchV1().Jobs("default").List(context.TODO(), metav1.ListOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(jobs.Items, check.HasLen, 1)
+	c.Assert(jobs.Items[0].Name, check.Matches, "myjob-adhoc-[a-z0-9]{5}")
+	c.Assert(jobs.Items[0].Annotations["job.tsuru.io/trigger"], check.Equals, "adhoc")
+	c.Assert(*jobs.Items[0].Spec.ActiveDeadlineSeconds, check.Equals, int64(30))
+	container := jobs.Items[0].Spec.Template.Spec.Containers[0]
+	c.Assert(container.Image, check.Equals, "ubuntu:24.04")
+	c.Assert(container.Command, check.DeepEquals, []string{"echo", "bye"})
+	cron, err := s.client.BatchV1().CronJobs("default").Get(context.TODO(), "myjob", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(cron.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Image, check.Equals, "ubuntu:latest")
+	c.Assert(cron.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Command, check.DeepEquals, []string{"echo", "hello"})
+	c.Assert(*cron.Spec.JobTemplate.Spec.ActiveDeadlineSeconds, check.Equals, int64(3600))
+}
+
 func (s *S) TestCreateJobEvent(c *check.C) {
 	boolTrue := true
 	cleanup := func() {
@@ -15,17 +15,20 @@ import (
 
 	uuid "github.com/nu7hatch/gouuid"
 	"github.com/pkg/errors"
+	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/set"
 	appTypes "github.com/tsuru/tsuru/types/app"
 	logTypes "github.com/tsuru/tsuru/types/log"
 	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	knet "k8s.io/apimachinery/pkg/util/net"
 )
 
 const (
-	logLineTimeSeparator = " "
-	logWatchBufferSize   = 1000
+	logLineTimeSeparator  = " "
+	logWatchBufferSize    = 1000
+	defaultLogsMaxScanned = 10000
 )
 
 var (
@@ -56,7 +59,11 @@ func (p *kubernetesProvisioner) ListLogs(ctx context.Context, obj logTypes.Logab
 	if len(args.Units) > 0 {
 		pods = filterPods(pods, args.Units)
 	}
-	return listLogsFromPods(ctx, clusterClient, ns, pods, args)
+	matcher, err := appTypes.NewLogMatcher(args)
+	if err != nil {
+		return nil, err
+	}
+	return listLogsFromPods(ctx, clusterClient, ns, pods, args, matcher)
 }
 
 func (p *kubernetesProvisioner) WatchLogs(ctx context.Context, obj logTypes.LogabbleObject, args appTypes.ListLogArgs) (appTypes.LogWatcher, error) {
@@ -87,6 +94,10 @@ func (p *kubernetesProvisioner) WatchLogs(ctx context.Context, obj logTypes.Loga
 	if len(args.Units) > 0 {
 		pods = filterPods(pods, args.Units)
 	}
+	matcher, err := appTypes.NewLogMatcher(args)
+	if err != nil {
+		return nil, err
+	}
 	uuidV4, err := uuid.NewV4()
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed to generate uuid v4")
@@ -95,6 +106,7 @@ func (p *kubernetesProvisioner) WatchLogs(ctx context.Context, obj logTypes.Loga
 	watcher := &k8sLogsWatcher{
 		id:           uuidV4.String(),
 		logArgs:      args,
+		matcher:      matcher,
 		ctx:          ctx,
 		ch:           make(chan appTypes.Applog, logWatchBufferSize),
 		ns:           ns,
@@ -118,7 +130,7 @@ func (p *kubernetesProvisioner) WatchLogs(ctx context.Context, obj logTypes.Loga
 	return watcher, nil
 }
 
-func listLogsFromPods(ctx context.Context, clusterClient *ClusterClient, ns string, pods []*apiv1.Pod, args appTypes.ListLogArgs) ([]appTypes.Applog, error) {
+func listLogsFromPods(ctx context.Context, clusterClient *ClusterClient, ns string, pods []*apiv1.Pod, args appTypes.ListLogArgs, matcher *appTypes.LogMatcher) ([]appTypes.Applog, error) {
 	var wg sync.WaitGroup
 
 	errs := make([]error, len(pods))
@@ -127,6 +139,16 @@ func listLogsFromPods(ctx context.Context, clusterClient *ClusterClient, ns stri
 	if args.Limit == 0 {
 		tailLimit = tailLines(100)
 	}
+	var sinceTime *metav1.Time
+	if !args.Since.IsZero() {
+		sinceTime = &metav1.Time{Time: args.Since}
+	}
+	if matcher.HasContentFilters() || !args.Until.IsZero() {
+		// matching lines may be anywhere in the pod log, the limit is
+		// applied after filtering and only the last lines of each pod
+		// are scanned.
+		tailLimit = tailLines(logsMaxScannedLines())
+	}
 
 	for index, pod := range pods {
 		if !loggablePod(&pod.Status) {
@@ -138,6 +160,7 @@ func listLogsFromPods(ctx context.Context, clusterClient *ClusterClient, ns stri
 
 			request := clusterClient.CoreV1().Pods(ns).GetLogs(pod.ObjectMeta.Name, &apiv1.PodLogOptions{
 				TailLines:  tailLimit,
+				SinceTime:  sinceTime,
 				Timestamps: true,
 			})
 			stream, err := request.Stream(ctx)
@@ -170,6 +193,9 @@ func listLogsFromPods(ctx context.Context, clusterClient *ClusterClient, ns stri
 				tsuruLog.Name = name
 				tsuruLog.Type = logType
 				tsuruLog.Source = appProcess
+				if !matcher.Match(&tsuruLog) {
+					continue
+				}
 				tsuruLogs = append(tsuruLogs, tsuruLog)
 			}
 
@@ -185,6 +211,9 @@ func listLogsFromPods(ctx context.Context, clusterClient *ClusterClient, ns stri
 	}
 
 	sort.Slice(unifiedLog, func(i, j int) bool { return unifiedLog[i].Date.Before(unifiedLog[j].Date) })
+	if tailLimit == nil && args.Limit > 0 && len(unifiedLog) > args.Limit {
+		unifiedLog = unifiedLog[len(unifiedLog)-args.Limit:]
+	}
 
 	for index, err := range errs {
 		if err == nil {
@@ -233,6 +262,14 @@ func parseRFC3339(s string) (time.Time, error) {
 	return time.Parse(time.RFC3339, s)
 }
 
+func logsMaxScannedLines() int {
+	lines, err := config.GetInt("kubernetes:logs:max-scanned-lines")
+	if err != nil || lines <= 0 {
+		return defaultLogsMaxScanned
+	}
+	return lines
+}
+
 func tailLines(i int) *int64 {
 	b := int64(i)
 	return &b
@@ -268,6 +305,7 @@ type k8sLogsWatcher struct {
 	done context.CancelFunc
 
 	logArgs           appTypes.ListLogArgs
+	matcher           *appTypes.LogMatcher
 	clusterClient     *ClusterClient
 	clusterController *clusterController
 	watchingPods      map[string]bool
@@ -324,6 +362,9 @@ func (k *k8sLogsWatcher) watchPod(pod *apiv1.Pod, addedLater bool) {
 		tsuruLog.Name = name
 		tsuruLog.Type = logType
 		tsuruLog.Source = appProcess
+		if !k.matcher.Match(&tsuruLog) {
+			continue
+		}
 		k.ch <- tsuruLog
 	}
 }
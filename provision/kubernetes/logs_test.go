@@ -13,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision"
@@ -40,6 +41,13 @@ func (s *S) Test_LogsProvisioner_parsek8sLogLine(c *check.C) {
 	c.Check(tsuruLog.Message, check.Equals, "its a log line")
 }
 
+func (s *S) Test_LogsProvisioner_logsMaxScannedLines(c *check.C) {
+	c.Check(logsMaxScannedLines(), check.Equals, defaultLogsMaxScanned)
+	config.Set("kubernetes:logs:max-scanned-lines", 500)
+	defer config.Unset("kubernetes:logs")
+	c.Check(logsMaxScannedLines(), check.Equals, 500)
+}
+
 func (s *S) Test_LogsProvisioner_ListLogs(c *check.C) {
 	s.mock.LogHook = func(w io.Writer, r *http.Request) {
 		tailLines, _ := strconv.Atoi(r.URL.Query().Get("tailLines"))
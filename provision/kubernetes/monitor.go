@@ -137,6 +137,10 @@ func (c *clusterController) isLeader() bool {
 }
 
 func (c *clusterController) startJobInformer() error {
+	err := c.startJobRunRecorder()
+	if err != nil {
+		return err
+	}
 	if enable, _ := c.cluster.EnableJobEventCreation(); !enable {
 		return errors.New("job event creation is not enabled")
 	}
@@ -285,6 +289,10 @@ func (c *clusterController) getVPAInformer() (vpaV1Informers.VerticalPodAutoscal
 }
 
 func (c *clusterController) getJobInformer() (jobsInformer.JobInformer, error) {
+	return c.getJobInformerWait(true)
+}
+
+func (c *clusterController) getJobInformerWait(wait bool) (jobsInformer.JobInformer, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	if c.jobsInformer == nil {
@@ -296,7 +304,10 @@ func (c *clusterController) getJobInformer() (jobsInformer.JobInformer, error) {
 			return nil, err
 		}
 	}
-	err := c.waitForSync(c.jobsInformer.Informer())
+	var err error
+	if wait {
+		err = c.waitForSync(c.jobsInformer.Informer())
+	}
 	return c.jobsInformer, err
 }
 
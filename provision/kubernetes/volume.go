@@ -13,6 +13,7 @@ import (
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/servicemanager"
 	"github.com/tsuru/tsuru/set"
+	jobTypes "github.com/tsuru/tsuru/types/job"
 	volumeTypes "github.com/tsuru/tsuru/types/volume"
 	"github.com/ugorji/go/codec"
 	apiv1 "k8s.io/api/core/v1"
@@ -35,11 +36,29 @@ func (opts *volumeOptions) isPersistent() bool {
 	return !allowedNonPersistentVolumes.Includes(opts.Plugin)
 }
 
+type volumeBindsFunc func(v *volumeTypes.Volume) ([]volumeTypes.VolumeBind, error)
+
 func createVolumesForApp(ctx context.Context, client *ClusterClient, app provision.App) ([]apiv1.Volume, []apiv1.VolumeMount, error) {
 	volumes, err := servicemanager.Volume.ListByApp(ctx, app.GetName())
 	if err != nil {
 		return nil, nil, errors.WithStack(err)
 	}
+	return createVolumes(ctx, client, volumes, func(v *volumeTypes.Volume) ([]volumeTypes.VolumeBind, error) {
+		return servicemanager.Volume.BindsForApp(ctx, v, app.GetName())
+	})
+}
+
+func createVolumesForJob(ctx context.Context, client *ClusterClient, job *jobTypes.Job) ([]apiv1.Volume, []apiv1.VolumeMount, error) {
+	volumes, err := servicemanager.Volume.ListByJob(ctx, job.Name)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	return createVolumes(ctx, client, volumes, func(v *volumeTypes.Volume) ([]volumeTypes.VolumeBind, error) {
+		return servicemanager.Volume.BindsForJob(ctx, v, job.Name)
+	})
+}
+
+func createVolumes(ctx context.Context, client *ClusterClient, volumes []volumeTypes.Volume, bindsFn volumeBindsFunc) ([]apiv1.Volume, []apiv1.VolumeMount, error) {
 	var kubeVolumes []apiv1.Volume
 	var kubeMounts []apiv1.VolumeMount
 	for i := range volumes {
@@ -53,7 +72,7 @@ func createVolumesForApp(ctx context.Context, client *ClusterClient, app provisi
 				return nil, nil, err
 			}
 		}
-		volume, mounts, err := bindsForVolume(ctx, &volumes[i], opts, app.GetName())
+		volume, mounts, err := bindsForVolume(&volumes[i], opts, bindsFn)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -63,9 +82,9 @@ func createVolumesForApp(ctx context.Context, client *ClusterClient, app provisi
 	return kubeVolumes, kubeMounts, nil
 }
 
-func bindsForVolume(ctx context.Context, v *volumeTypes.Volume, opts *volumeOptions, appName string) (*apiv1.Volume, []apiv1.VolumeMount, error) {
+func bindsForVolume(v *volumeTypes.Volume, opts *volumeOptions, bindsFn volumeBindsFunc) (*apiv1.Volume, []apiv1.VolumeMount, error) {
 	var kubeMounts []apiv1.VolumeMount
-	binds, err := servicemanager.Volume.BindsForApp(ctx, v, appName)
+	binds, err := bindsFn(v)
 	if err != nil {
 		return nil, nil, errors.WithStack(err)
 	}
@@ -319,7 +338,7 @@ func getNamespaceForVolume(ctx context.Context, client *ClusterClient, v *volume
 	}
 	var namespace string
 	for _, b := range binds {
-		ns, err := client.appNamespaceByName(ctx, b.ID.App)
+		ns, err := namespaceForBind(ctx, client, b)
 		if err != nil {
 			return "", err
 		}
@@ -333,3 +352,14 @@ func getNamespaceForVolume(ctx context.Context, client *ClusterClient, v *volume
 	}
 	return namespace, nil
 }
+
+func namespaceForBind(ctx context.Context, client *ClusterClient, b volumeTypes.VolumeBind) (string, error) {
+	if b.ID.Job == "" {
+		return client.appNamespaceByName(ctx, b.ID.App)
+	}
+	job, err := servicemanager.Job.GetByName(ctx, b.ID.Job)
+	if err != nil {
+		return "", err
+	}
+	return client.PoolNamespace(job.Pool), nil
+}
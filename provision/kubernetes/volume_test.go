@@ -11,6 +11,7 @@ import (
 	tsuruv1 "github.com/tsuru/tsuru/provision/kubernetes/pkg/apis/tsuru/v1"
 	"github.com/tsuru/tsuru/provision/provisiontest"
 	"github.com/tsuru/tsuru/servicemanager"
+	jobTypes "github.com/tsuru/tsuru/types/job"
 	volumeTypes "github.com/tsuru/tsuru/types/volume"
 	check "gopkg.in/check.v1"
 	apiv1 "k8s.io/api/core/v1"
@@ -578,3 +579,54 @@ func (s *S) TestVolumeExists(c *check.C) {
 	c.Assert(err, check.IsNil)
 	c.Assert(exists, check.Equals, true)
 }
+
+func (s *S) TestCreateVolumesForJob(c *check.C) {
+	config.Set("volume-plans:p1:kubernetes:storage-class", "my-storage-class")
+	defer config.Unset("volume-plans")
+	job := &jobTypes.Job{Name: "myjob", Pool: "test-default"}
+	s.mockService.JobService.OnGetByName = func(name string) (*jobTypes.Job, error) {
+		c.Assert(name, check.Equals, job.Name)
+		return job, nil
+	}
+	v := volumeTypes.Volume{
+		Name: "v1",
+		Opts: map[string]string{
+			"capacity":     "20Gi",
+			"access-modes": string(apiv1.ReadWriteMany),
+		},
+		Plan:      volumeTypes.VolumePlan{Name: "p1"},
+		Pool:      "test-default",
+		TeamOwner: "admin",
+	}
+	err := servicemanager.Volume.Create(context.TODO(), &v)
+	c.Assert(err, check.IsNil)
+	err = servicemanager.Volume.BindJob(context.TODO(), &volumeTypes.BindOpts{
+		Volume:     &v,
+		JobName:    job.Name,
+		MountPoint: "/data",
+		ReadOnly:   true,
+	})
+	c.Assert(err, check.IsNil)
+	volumes, mounts, err := createVolumesForJob(context.TODO(), s.clusterClient, job)
+	c.Assert(err, check.IsNil)
+	c.Assert(volumes, check.DeepEquals, []apiv1.Volume{{
+		Name: volumeName(v.Name),
+		VolumeSource: apiv1.VolumeSource{
+			PersistentVolumeClaim: &apiv1.PersistentVolumeClaimVolumeSource{
+				ClaimName: volumeClaimName(v.Name),
+				ReadOnly:  true,
+			},
+		},
+	}})
+	c.Assert(mounts, check.DeepEquals, []apiv1.VolumeMount{{
+		Name:      volumeName(v.Name),
+		MountPath: "/data",
+		ReadOnly:  true,
+	}})
+	_, err = s.client.CoreV1().PersistentVolumeClaims(s.client.PoolNamespace(job.Pool)).Get(context.TODO(), volumeClaimName(v.Name), metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	volumes, mounts, err = createVolumesForJob(context.TODO(), s.clusterClient, &jobTypes.Job{Name: "otherjob", Pool: "test-default"})
+	c.Assert(err, check.IsNil)
+	c.Assert(volumes, check.HasLen, 0)
+	c.Assert(mounts, check.HasLen, 0)
+}
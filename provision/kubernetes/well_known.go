@@ -11,6 +11,14 @@ const (
 	// json object that can be parsed as a map[string]string.
 	AnnotationServiceAccountJobAnnotations = "job.tsuru.io/service-account-annotations"
 
+	// AnnotationJobTrigger is set on jobs started by tsuru, outside of their
+	// schedule, with the source that triggered them.
+	AnnotationJobTrigger = "job.tsuru.io/trigger"
+
+	// AnnotationJobWorkflow is set on jobs started as a step of a workflow,
+	// with the workflow ID.
+	AnnotationJobWorkflow = "job.tsuru.io/workflow"
+
 	// ResourceMetadaPrefix is used to define an annotation or label for a subresource of the deployment
 	// i.e: "app.tsuru.io/k8s-<resource-type>"
 	// Example, setting a service annotation: 'app.tsuru.io/k8s-service={"label1": "value"}'
@@ -9,7 +9,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/tsuru/tsuru/db/storagev2"
@@ -42,7 +44,10 @@ var (
 )
 
 const (
-	affinityKey = "affinity"
+	affinityKey               = "affinity"
+	deployApprovalKey         = "deploy-approval"
+	deployApprovalTimeoutKey  = "deploy-approval-timeout"
+	defaultDeployApprovalTime = time.Hour
 )
 
 type Pool struct {
@@ -90,6 +95,44 @@ func (p *Pool) GetAffinity() (*apiv1.Affinity, error) {
 	return nil, nil
 }
 
+// DeployApproval returns whether deploys to apps in the pool must be approved
+// by a second user, along with how long a deploy waits for the approval.
+func (p *Pool) DeployApproval() (bool, time.Duration, error) {
+	required, err := parseDeployApproval(p.Labels)
+	if err != nil || !required {
+		return false, 0, err
+	}
+	timeout, err := parseDeployApprovalTimeout(p.Labels)
+	if err != nil {
+		return false, 0, err
+	}
+	return true, timeout, nil
+}
+
+func parseDeployApproval(labels map[string]string) (bool, error) {
+	value, ok := labels[deployApprovalKey]
+	if !ok {
+		return false, nil
+	}
+	required, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid %s label value %q, must be a boolean", deployApprovalKey, value)}
+	}
+	return required, nil
+}
+
+func parseDeployApprovalTimeout(labels map[string]string) (time.Duration, error) {
+	value, ok := labels[deployApprovalTimeoutKey]
+	if !ok {
+		return defaultDeployApprovalTime, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return 0, &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid %s label value %q, must be a positive duration", deployApprovalTimeoutKey, value)}
+	}
+	return timeout, nil
+}
+
 func (p *Pool) GetProvisioner() (provision.Provisioner, error) {
 	if p.Provisioner != "" {
 		return provision.Get(p.Provisioner)
@@ -370,7 +413,12 @@ func validateLabels(labels map[string]string) error {
 			return err
 		}
 	}
-
+	if _, err := parseDeployApproval(labels); err != nil {
+		return err
+	}
+	if _, err := parseDeployApprovalTimeout(labels); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -8,6 +8,7 @@ import (
 	"context"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/tsuru/config"
 	internalConfig "github.com/tsuru/tsuru/config"
@@ -281,6 +282,22 @@ func (s *S) TestAddPoolValidateLabels(c *check.C) {
 			},
 			expectedErr: "invalid character 'i' looking for beginning of value",
 		},
+		{
+			testName: "deploy approval label with invalid value",
+			opts: AddPoolOptions{
+				Name:   "pool3",
+				Labels: map[string]string{deployApprovalKey: "sometimes"},
+			},
+			expectedErr: `invalid deploy-approval label value "sometimes", must be a boolean`,
+		},
+		{
+			testName: "deploy approval timeout label with invalid value",
+			opts: AddPoolOptions{
+				Name:   "pool4",
+				Labels: map[string]string{deployApprovalKey: "true", deployApprovalTimeoutKey: "-1h"},
+			},
+			expectedErr: `invalid deploy-approval-timeout label value "-1h", must be a positive duration`,
+		},
 	}
 
 	for _, t := range tt {
@@ -872,6 +889,26 @@ func (s *S) TestGetProvisionerForPool(c *check.C) {
 	c.Assert(err, check.Equals, ErrPoolNotFound)
 }
 
+func (s *S) TestDeployApproval(c *check.C) {
+	tt := []struct {
+		labels   map[string]string
+		required bool
+		timeout  time.Duration
+	}{
+		{nil, false, 0},
+		{map[string]string{deployApprovalKey: "false", deployApprovalTimeoutKey: "10m"}, false, 0},
+		{map[string]string{deployApprovalKey: "true"}, true, time.Hour},
+		{map[string]string{deployApprovalKey: "true", deployApprovalTimeoutKey: "10m"}, true, 10 * time.Minute},
+	}
+	for i, t := range tt {
+		p := Pool{Name: "pool1", Labels: t.labels}
+		required, timeout, err := p.DeployApproval()
+		c.Assert(err, check.IsNil, check.Commentf("(%d)", i))
+		c.Assert(required, check.Equals, t.required, check.Commentf("(%d)", i))
+		c.Assert(timeout, check.Equals, t.timeout, check.Commentf("(%d)", i))
+	}
+}
+
 func (s *S) TestGetAffinity(c *check.C) {
 	tt := []struct {
 		testName  string
@@ -191,7 +191,9 @@ type JobProvisioner interface {
 	EnsureJob(context.Context, *jobTypes.Job) error
 
 	DestroyJob(context.Context, *jobTypes.Job) error
-	TriggerCron(ctx context.Context, name, pool string) error
+	// TriggerCron starts a run of the job outside of its schedule, returning
+	// the ID of the run.
+	TriggerCron(ctx context.Context, name, pool string, opts jobTypes.TriggerOpts) (string, error)
 	KillJobUnit(ctx context.Context, job *jobTypes.Job, unitName string, force bool) error
 }
 
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -167,6 +168,18 @@ func (a *FakeApp) GetServiceEnvs() []bindTypes.ServiceEnvVar {
 	return a.serviceEnvs
 }
 
+func (a *FakeApp) InstanceEnvs(serviceName, instanceName string) map[string]bindTypes.EnvVar {
+	a.serviceLock.Lock()
+	defer a.serviceLock.Unlock()
+	envs := make(map[string]bindTypes.EnvVar)
+	for _, env := range a.serviceEnvs {
+		if env.ServiceName == serviceName && env.InstanceName == instanceName {
+			envs[env.Name] = env.EnvVar
+		}
+	}
+	return envs
+}
+
 func (a *FakeApp) AddInstance(ctx context.Context, instanceArgs bind.AddInstanceArgs) error {
 	a.serviceLock.Lock()
 	defer a.serviceLock.Unlock()
@@ -459,6 +472,16 @@ func (p *FakeProvisioner) ProvisionedJob(jobName string) bool {
 	return ok
 }
 
+// JobTriggers returns the options used in each trigger of a job.
+func (p *FakeProvisioner) JobTriggers(jobName string) []jobTypes.TriggerOpts {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	if j, ok := p.jobs[jobName]; ok {
+		return j.triggers
+	}
+	return nil
+}
+
 // JobExecutions returns the number of times a job has run
 func (p *FakeProvisioner) JobExecutions(jobName string) int {
 	p.mut.RLock()
@@ -554,6 +577,10 @@ func (p *FakeProvisioner) Deploy(ctx context.Context, args provision.DeployArgs)
 		pApp.image = args.Version.VersionInfo().BuildImage
 	}
 	args.Event.Write([]byte("Builder deploy called"))
+	if !args.PreserveVersions || pApp.versions == nil {
+		pApp.versions = make(map[int]bool)
+	}
+	pApp.versions[args.Version.Version()] = !args.PreserveVersions
 	p.apps[args.App.GetName()] = pApp
 	err := args.Version.CommitBaseImage()
 	if err != nil {
@@ -1035,6 +1062,7 @@ type provisionedApp struct {
 	lastData  map[string]interface{}
 	image     string
 	mockAddrs []appTypes.RoutableAddresses
+	versions  map[int]bool
 
 	restartsByVersion map[string]int
 }
@@ -1043,6 +1071,7 @@ type provisionedJob struct {
 	units      []provTypes.Unit
 	job        *jobTypes.Job
 	executions int
+	triggers   []jobTypes.TriggerOpts
 }
 
 type AutoScaleProvisioner struct {
@@ -1091,6 +1120,52 @@ func (p *AutoScaleProvisioner) RemoveAutoScale(ctx context.Context, app provisio
 	return nil
 }
 
+type VersionsProvisioner struct {
+	*FakeProvisioner
+}
+
+var _ provision.VersionsProvisioner = &VersionsProvisioner{}
+
+func (p *VersionsProvisioner) ToggleRoutable(ctx context.Context, app provision.App, version appTypes.AppVersion, isRoutable bool) error {
+	if err := p.getError("ToggleRoutable"); err != nil {
+		return err
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	pApp, ok := p.apps[app.GetName()]
+	if !ok {
+		return errNotProvisioned
+	}
+	if _, ok = pApp.versions[version.Version()]; !ok {
+		return errors.Errorf("no deployment found for version %v", version.Version())
+	}
+	pApp.versions[version.Version()] = isRoutable
+	return nil
+}
+
+func (p *VersionsProvisioner) DeployedVersions(ctx context.Context, app provision.App) ([]int, error) {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	pApp, ok := p.apps[app.GetName()]
+	if !ok {
+		return nil, errNotProvisioned
+	}
+	versions := make([]int, 0, len(pApp.versions))
+	for v := range pApp.versions {
+		versions = append(versions, v)
+	}
+	sort.Ints(versions)
+	return versions, nil
+}
+
+// IsRoutable returns whether the given version of the app is receiving
+// traffic from the default app address.
+func (p *VersionsProvisioner) IsRoutable(app provision.App, version int) bool {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	return p.apps[app.GetName()].versions[version]
+}
+
 type JobProvisioner struct {
 	*FakeProvisioner
 }
@@ -1126,15 +1201,16 @@ func (p *JobProvisioner) DestroyJob(ctx context.Context, job *jobTypes.Job) erro
 	return nil
 }
 
-func (p *JobProvisioner) TriggerCron(ctx context.Context, name, pool string) error {
+func (p *JobProvisioner) TriggerCron(ctx context.Context, name, pool string, opts jobTypes.TriggerOpts) (string, error) {
 	p.mut.Lock()
 	defer p.mut.Unlock()
 	j, ok := p.jobs[name]
 	if !ok {
-		return errNotProvisioned
+		return "", errNotProvisioned
 	}
 	j.executions++
-	return nil
+	j.triggers = append(j.triggers, opts)
+	return fmt.Sprintf("%s-run-%d", name, j.executions), nil
 }
 
 func (p *JobProvisioner) NewJobWithUnits(ctx context.Context, job *jobTypes.Job) (string, error) {
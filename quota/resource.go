@@ -0,0 +1,137 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package quota
+
+import (
+	"context"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/types/quota"
+)
+
+var _ quota.ResourceQuotaService = &ResourceQuotaService{}
+
+// ResourceQuotaService enforces capacity quotas, the capacity in use is
+// never stored, being computed by Usage whenever it's needed.
+type ResourceQuotaService struct {
+	Storage quota.ResourceQuotaStorage
+	Usage   quota.ResourceUsageFunc
+}
+
+// Set creates or replaces the resource quota of a team in a pool. Unlike
+// Quota.SetLimit, limits lower than the capacity in use are accepted,
+// preventing any further growth until usage is reduced.
+func (s *ResourceQuotaService) Set(ctx context.Context, q quota.ResourceQuota) error {
+	if q.Team == "" {
+		return &tsuruErrors.ValidationError{Message: "team is required"}
+	}
+	q.Limits = normalizeLimits(q.Limits)
+	return s.Storage.Upsert(ctx, q)
+}
+
+func normalizeLimits(l quota.ResourceList) quota.ResourceList {
+	for _, v := range []*int64{&l.MilliCPU, &l.Memory, &l.Storage} {
+		if *v < 0 {
+			*v = -1
+		}
+	}
+	return l
+}
+
+func (s *ResourceQuotaService) Get(ctx context.Context, team, pool string) (*quota.ResourceQuotaInfo, error) {
+	q, err := s.Storage.Get(ctx, team, pool)
+	if err != nil {
+		return nil, err
+	}
+	inUse, err := s.Usage(ctx, team, pool)
+	if err != nil {
+		return nil, err
+	}
+	return &quota.ResourceQuotaInfo{ResourceQuota: *q, InUse: inUse}, nil
+}
+
+func (s *ResourceQuotaService) List(ctx context.Context, team string) ([]quota.ResourceQuotaInfo, error) {
+	quotas, err := s.Storage.FindByTeam(ctx, team)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]quota.ResourceQuotaInfo, len(quotas))
+	for i, q := range quotas {
+		result[i].ResourceQuota = q
+		result[i].InUse, err = s.Usage(ctx, team, q.Pool)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (s *ResourceQuotaService) Delete(ctx context.Context, team, pool string) error {
+	return s.Storage.Delete(ctx, team, pool)
+}
+
+// Check implements Check method from ResourceQuotaService interface. Both the
+// team wide quota and the quota for the pool are considered.
+func (s *ResourceQuotaService) Check(ctx context.Context, team, pool string, requested quota.ResourceList) error {
+	return s.check(ctx, team, pool, requested, true)
+}
+
+// CheckPool implements CheckPool method from ResourceQuotaService interface.
+// The team wide quota is ignored, as the capacity in use by the team doesn't
+// change.
+func (s *ResourceQuotaService) CheckPool(ctx context.Context, team, pool string, requested quota.ResourceList) error {
+	return s.check(ctx, team, pool, requested, false)
+}
+
+func (s *ResourceQuotaService) check(ctx context.Context, team, pool string, requested quota.ResourceList, teamWide bool) error {
+	if requested.MilliCPU <= 0 && requested.Memory <= 0 && requested.Storage <= 0 {
+		return nil
+	}
+	quotas, err := s.Storage.FindByTeam(ctx, team)
+	if err != nil {
+		return err
+	}
+	for _, q := range quotas {
+		if q.Pool != pool && (q.Pool != "" || !teamWide) {
+			continue
+		}
+		inUse, err := s.Usage(ctx, team, q.Pool)
+		if err != nil {
+			return err
+		}
+		exceeded := exceededResources(q.Limits, inUse, requested)
+		if len(exceeded) > 0 {
+			return &quota.QuotaExceededError{
+				Team:      team,
+				Pool:      q.Pool,
+				Resources: exceeded,
+			}
+		}
+	}
+	return nil
+}
+
+func exceededResources(limits, inUse, requested quota.ResourceList) []quota.ResourceExceeded {
+	var result []quota.ResourceExceeded
+	for _, r := range []struct {
+		name                    string
+		limit, inUse, requested int64
+	}{
+		{quota.ResourceCPU, limits.MilliCPU, inUse.MilliCPU, requested.MilliCPU},
+		{quota.ResourceMemory, limits.Memory, inUse.Memory, requested.Memory},
+		{quota.ResourceStorage, limits.Storage, inUse.Storage, requested.Storage},
+	} {
+		if r.limit < 0 || r.requested <= 0 || r.inUse+r.requested <= r.limit {
+			continue
+		}
+		result = append(result, quota.ResourceExceeded{
+			Resource:  r.name,
+			Limit:     r.limit,
+			InUse:     r.inUse,
+			Requested: r.requested,
+		})
+	}
+	return result
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package quota
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/types/quota"
+	check "gopkg.in/check.v1"
+)
+
+const gi = 1024 * 1024 * 1024
+
+func (s *S) newResourceQuotaService(c *check.C, quotas []quota.ResourceQuota, usage map[string]quota.ResourceList) *ResourceQuotaService {
+	return &ResourceQuotaService{
+		Storage: &quota.MockResourceQuotaStorage{
+			OnFindByTeam: func(team string) ([]quota.ResourceQuota, error) {
+				c.Assert(team, check.Equals, "myteam")
+				return quotas, nil
+			},
+			OnUpsert: func(q quota.ResourceQuota) error {
+				quotas = append(quotas, q)
+				return nil
+			},
+		},
+		Usage: func(ctx context.Context, team, pool string) (quota.ResourceList, error) {
+			c.Assert(team, check.Equals, "myteam")
+			return usage[pool], nil
+		},
+	}
+}
+
+func (s *S) TestResourceQuotaCheck(c *check.C) {
+	qs := s.newResourceQuotaService(c, []quota.ResourceQuota{
+		{Team: "myteam", Limits: quota.ResourceList{MilliCPU: 4000, Memory: 8 * gi, Storage: -1}},
+		{Team: "myteam", Pool: "pool1", Limits: quota.ResourceList{MilliCPU: 1000, Memory: -1, Storage: 10 * gi}},
+	}, map[string]quota.ResourceList{
+		"":      {MilliCPU: 3000, Memory: 4 * gi, Storage: 20 * gi},
+		"pool1": {MilliCPU: 500, Memory: 1 * gi, Storage: 5 * gi},
+	})
+	err := qs.Check(context.TODO(), "myteam", "pool2", quota.ResourceList{MilliCPU: 1000, Memory: 4 * gi, Storage: 100 * gi})
+	c.Assert(err, check.IsNil)
+	err = qs.Check(context.TODO(), "myteam", "pool2", quota.ResourceList{MilliCPU: 1500, Memory: 5 * gi})
+	c.Assert(err, check.DeepEquals, &quota.QuotaExceededError{
+		Team: "myteam",
+		Resources: []quota.ResourceExceeded{
+			{Resource: quota.ResourceCPU, Limit: 4000, InUse: 3000, Requested: 1500},
+			{Resource: quota.ResourceMemory, Limit: 8 * gi, InUse: 4 * gi, Requested: 5 * gi},
+		},
+	})
+	c.Assert(err, check.ErrorMatches, `Quota exceeded for team "myteam": cpu \(limit: 4000m, in use: 3000m, requested: 1500m\), memory \(limit: 8Gi, in use: 4Gi, requested: 5Gi\).`)
+	err = qs.Check(context.TODO(), "myteam", "pool1", quota.ResourceList{MilliCPU: 600, Storage: 6 * gi})
+	c.Assert(err, check.DeepEquals, &quota.QuotaExceededError{
+		Team: "myteam",
+		Pool: "pool1",
+		Resources: []quota.ResourceExceeded{
+			{Resource: quota.ResourceCPU, Limit: 1000, InUse: 500, Requested: 600},
+			{Resource: quota.ResourceStorage, Limit: 10 * gi, InUse: 5 * gi, Requested: 6 * gi},
+		},
+	})
+	c.Assert(err, check.ErrorMatches, `Quota exceeded for team "myteam" in pool "pool1": .*`)
+}
+
+func (s *S) TestResourceQuotaCheckReleasingResources(c *check.C) {
+	qs := s.newResourceQuotaService(c, []quota.ResourceQuota{
+		{Team: "myteam", Limits: quota.ResourceList{MilliCPU: 1000, Memory: gi, Storage: gi}},
+	}, map[string]quota.ResourceList{
+		"": {MilliCPU: 2000, Memory: 2 * gi, Storage: 2 * gi},
+	})
+	err := qs.Check(context.TODO(), "myteam", "pool1", quota.ResourceList{MilliCPU: -500, Memory: -gi})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestResourceQuotaSet(c *check.C) {
+	var stored []quota.ResourceQuota
+	qs := &ResourceQuotaService{
+		Storage: &quota.MockResourceQuotaStorage{
+			OnUpsert: func(q quota.ResourceQuota) error {
+				stored = append(stored, q)
+				return nil
+			},
+		},
+	}
+	err := qs.Set(context.TODO(), quota.ResourceQuota{Team: "myteam", Pool: "pool1", Limits: quota.ResourceList{MilliCPU: 1000, Memory: -10, Storage: -2}})
+	c.Assert(err, check.IsNil)
+	c.Assert(stored, check.DeepEquals, []quota.ResourceQuota{
+		{Team: "myteam", Pool: "pool1", Limits: quota.ResourceList{MilliCPU: 1000, Memory: -1, Storage: -1}},
+	})
+	err = qs.Set(context.TODO(), quota.ResourceQuota{Pool: "pool1"})
+	c.Assert(err, check.ErrorMatches, "team is required")
+}
+
+func (s *S) TestResourceQuotaList(c *check.C) {
+	qs := s.newResourceQuotaService(c, []quota.ResourceQuota{
+		{Team: "myteam", Limits: quota.ResourceList{MilliCPU: 4000, Memory: -1, Storage: -1}},
+		{Team: "myteam", Pool: "pool1", Limits: quota.ResourceList{MilliCPU: 1000, Memory: -1, Storage: -1}},
+	}, map[string]quota.ResourceList{
+		"":      {MilliCPU: 3000},
+		"pool1": {MilliCPU: 500},
+	})
+	quotas, err := qs.List(context.TODO(), "myteam")
+	c.Assert(err, check.IsNil)
+	c.Assert(quotas, check.DeepEquals, []quota.ResourceQuotaInfo{
+		{ResourceQuota: quota.ResourceQuota{Team: "myteam", Limits: quota.ResourceList{MilliCPU: 4000, Memory: -1, Storage: -1}}, InUse: quota.ResourceList{MilliCPU: 3000}},
+		{ResourceQuota: quota.ResourceQuota{Team: "myteam", Pool: "pool1", Limits: quota.ResourceList{MilliCPU: 1000, Memory: -1, Storage: -1}}, InUse: quota.ResourceList{MilliCPU: 500}},
+	})
+}
+
+func (s *S) TestResourceQuotaCheckPool(c *check.C) {
+	qs := s.newResourceQuotaService(c, []quota.ResourceQuota{
+		{Team: "myteam", Limits: quota.ResourceList{MilliCPU: 1000, Memory: -1, Storage: -1}},
+		{Team: "myteam", Pool: "pool1", Limits: quota.ResourceList{MilliCPU: 2000, Memory: -1, Storage: -1}},
+	}, map[string]quota.ResourceList{
+		"":      {MilliCPU: 1000},
+		"pool1": {MilliCPU: 500},
+	})
+	err := qs.CheckPool(context.TODO(), "myteam", "pool1", quota.ResourceList{MilliCPU: 1000})
+	c.Assert(err, check.IsNil)
+	err = qs.Check(context.TODO(), "myteam", "pool1", quota.ResourceList{MilliCPU: 1000})
+	c.Assert(err, check.FitsTypeOf, &quota.QuotaExceededError{})
+	err = qs.CheckPool(context.TODO(), "myteam", "pool1", quota.ResourceList{MilliCPU: 2000})
+	c.Assert(err, check.DeepEquals, &quota.QuotaExceededError{
+		Team: "myteam",
+		Pool: "pool1",
+		Resources: []quota.ResourceExceeded{
+			{Resource: quota.ResourceCPU, Limit: 2000, InUse: 500, Requested: 2000},
+		},
+	})
+}
@@ -0,0 +1,144 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rebuild
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tsuru/tsuru/router"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+// RoutesDrift describes how the backend held by a router for an app differs
+// from the state expected by tsuru.
+type RoutesDrift struct {
+	Router      string   `json:"router"`
+	Differences []string `json:"differences"`
+}
+
+func (d *RoutesDrift) String() string {
+	return fmt.Sprintf("router %q: %s", d.Router, strings.Join(d.Differences, "; "))
+}
+
+// DiffRoutes compares the expected backend of the app in each of its routers
+// with the state reported by the router, returning the routers with drift.
+// Routers unable to report their state are ignored.
+func DiffRoutes(ctx context.Context, app RebuildApp) ([]RoutesDrift, error) {
+	var drifts []RoutesDrift
+	for _, appRouter := range app.GetRouters() {
+		drift, err := DiffRoutesInRouter(ctx, appRouter, app)
+		if err != nil {
+			return nil, err
+		}
+		if drift != nil {
+			drifts = append(drifts, *drift)
+		}
+	}
+	return drifts, nil
+}
+
+// DiffRoutesInRouter compares the cnames, prefixes and healthcheck expected
+// for the app with the state reported by the router. It returns nil when
+// there's no drift or when the router doesn't implement
+// router.BackendStateRouter.
+func DiffRoutesInRouter(ctx context.Context, appRouter appTypes.AppRouter, app RebuildApp) (*RoutesDrift, error) {
+	r, err := router.Get(ctx, appRouter.Name)
+	if err != nil {
+		return nil, err
+	}
+	stateRouter, ok := r.(router.BackendStateRouter)
+	if !ok {
+		return nil, nil
+	}
+	expected, err := expectedBackendOpts(ctx, appRouter, app)
+	if err != nil {
+		return nil, err
+	}
+	state, err := stateRouter.GetBackendState(ctx, app)
+	if err == router.ErrBackendNotFound {
+		return &RoutesDrift{Router: appRouter.Name, Differences: []string{"backend not found"}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var differences []string
+	differences = append(differences, diffCNames(expected.CNames, state.CNames)...)
+	if state.Prefixes != nil {
+		differences = append(differences, diffPrefixes(expected.Prefixes, state.Prefixes)...)
+	}
+	if state.Healthcheck != nil && *state.Healthcheck != expected.Healthcheck {
+		differences = append(differences, fmt.Sprintf("healthcheck is %s, expected %s", state.Healthcheck.String(), expected.Healthcheck.String()))
+	}
+	if len(differences) == 0 {
+		return nil, nil
+	}
+	return &RoutesDrift{Router: appRouter.Name, Differences: differences}, nil
+}
+
+func diffCNames(expected, current []string) []string {
+	currentSet := make(map[string]struct{}, len(current))
+	for _, cname := range current {
+		currentSet[cname] = struct{}{}
+	}
+	var differences []string
+	for _, cname := range expected {
+		if _, ok := currentSet[cname]; !ok {
+			differences = append(differences, fmt.Sprintf("cname %q is missing", cname))
+		}
+		delete(currentSet, cname)
+	}
+	var unexpected []string
+	for cname := range currentSet {
+		unexpected = append(unexpected, cname)
+	}
+	sort.Strings(unexpected)
+	for _, cname := range unexpected {
+		differences = append(differences, fmt.Sprintf("cname %q is not expected", cname))
+	}
+	return differences
+}
+
+func diffPrefixes(expected, current []router.BackendPrefix) []string {
+	currentTargets := make(map[string]map[string]string, len(current))
+	for _, p := range current {
+		currentTargets[p.Prefix] = p.Target
+	}
+	var differences []string
+	for _, p := range expected {
+		target, ok := currentTargets[p.Prefix]
+		delete(currentTargets, p.Prefix)
+		if !ok {
+			differences = append(differences, fmt.Sprintf("prefix %q is missing", p.Prefix))
+			continue
+		}
+		if !sameTarget(p.Target, target) {
+			differences = append(differences, fmt.Sprintf("prefix %q points to %v, expected %v", p.Prefix, target, p.Target))
+		}
+	}
+	var unexpected []string
+	for prefix := range currentTargets {
+		unexpected = append(unexpected, prefix)
+	}
+	sort.Strings(unexpected)
+	for _, prefix := range unexpected {
+		differences = append(differences, fmt.Sprintf("prefix %q is not expected", prefix))
+	}
+	return differences
+}
+
+func sameTarget(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
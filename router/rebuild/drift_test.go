@@ -0,0 +1,57 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rebuild_test
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/router/rebuild"
+	"github.com/tsuru/tsuru/router/routertest"
+	routerTypes "github.com/tsuru/tsuru/types/router"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestDiffRoutes(c *check.C) {
+	a := app.App{Name: "my-test-app", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	a.CName = []string{"app.io"}
+	err = rebuild.RebuildRoutes(context.TODO(), rebuild.RebuildRoutesOpts{App: &a})
+	c.Assert(err, check.IsNil)
+	drifts, err := rebuild.DiffRoutes(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	c.Assert(drifts, check.HasLen, 0)
+	opts := routertest.FakeRouter.BackendOpts["my-test-app"]
+	opts.CNames = []string{"other.io"}
+	opts.Prefixes = append(opts.Prefixes, router.BackendPrefix{Prefix: "old"})
+	opts.Healthcheck = routerTypes.HealthcheckData{Path: "/other"}
+	routertest.FakeRouter.BackendOpts["my-test-app"] = opts
+	drifts, err = rebuild.DiffRoutes(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	c.Assert(drifts, check.DeepEquals, []rebuild.RoutesDrift{
+		{
+			Router: "fake",
+			Differences: []string{
+				`cname "app.io" is missing`,
+				`cname "other.io" is not expected`,
+				`prefix "old" is not expected`,
+				`healthcheck is path: "/other", expected path: "/"`,
+			},
+		},
+	})
+}
+
+func (s *S) TestDiffRoutesBackendNotFound(c *check.C) {
+	a := app.App{Name: "my-test-app", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = routertest.FakeRouter.RemoveBackend(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	drift, err := rebuild.DiffRoutesInRouter(context.TODO(), a.GetRouters()[0], &a)
+	c.Assert(err, check.IsNil)
+	c.Assert(drift, check.DeepEquals, &rebuild.RoutesDrift{Router: "fake", Differences: []string{"backend not found"}})
+}
@@ -23,6 +23,7 @@ type RebuildApp interface {
 	GetCname() []string
 	GetRouters() []appTypes.AppRouter
 	GetHealthcheckData(ctx context.Context) (routerTypes.HealthcheckData, error)
+	GetRouterWeights(ctx context.Context) ([]router.BackendWeight, error)
 	RoutableAddresses(context.Context) ([]appTypes.RoutableAddresses, error)
 }
 
@@ -59,20 +60,32 @@ func RebuildRoutesInRouter(ctx context.Context, appRouter appTypes.AppRouter, o
 	if err != nil {
 		return err
 	}
+	opts, err := expectedBackendOpts(ctx, appRouter, o.App)
+	if err != nil {
+		return err
+	}
+	return r.EnsureBackend(ctx, o.App, opts)
+}
 
-	routes, routesErr := o.App.RoutableAddresses(ctx)
+func expectedBackendOpts(ctx context.Context, appRouter appTypes.AppRouter, app RebuildApp) (router.EnsureBackendOpts, error) {
+	routes, routesErr := app.RoutableAddresses(ctx)
 	if routesErr != nil {
-		return routesErr
+		return router.EnsureBackendOpts{}, routesErr
 	}
-	hcData, errHc := o.App.GetHealthcheckData(ctx)
+	hcData, errHc := app.GetHealthcheckData(ctx)
 	if errHc != nil {
-		return errHc
+		return router.EnsureBackendOpts{}, errHc
+	}
+	weights, errWeights := app.GetRouterWeights(ctx)
+	if errWeights != nil {
+		return router.EnsureBackendOpts{}, errWeights
 	}
 	opts := router.EnsureBackendOpts{
 		Opts:        map[string]interface{}{},
 		Prefixes:    []router.BackendPrefix{},
-		CNames:      o.App.GetCname(),
+		CNames:      app.GetCname(),
 		Healthcheck: hcData,
+		Weights:     weights,
 	}
 	for key, opt := range appRouter.Opts {
 		opts.Opts[key] = opt
@@ -83,7 +96,7 @@ func RebuildRoutesInRouter(ctx context.Context, appRouter appTypes.AppRouter, o
 			Target: route.ExtraData,
 		})
 	}
-	return r.EnsureBackend(ctx, o.App, opts)
+	return opts, nil
 }
 
 type initializeFunc func(string) (RebuildApp, error)
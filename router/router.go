@@ -154,11 +154,19 @@ type BackendPrefix struct {
 	Target map[string]string `json:"target"` // in kubernetes cluster be like {serviceName: "", namespace: ""}
 }
 
+// BackendWeight is the percentage of the app traffic that should be sent to
+// the units of a given app version, it's used by progressive (canary) deploys.
+type BackendWeight struct {
+	Version int `json:"version"`
+	Weight  int `json:"weight"`
+}
+
 type EnsureBackendOpts struct {
 	Opts        map[string]interface{} `json:"opts"`
 	CNames      []string               `json:"cnames"`
 	Prefixes    []BackendPrefix        `json:"prefixes"`
 	Healthcheck router.HealthcheckData `json:"healthcheck"`
+	Weights     []BackendWeight        `json:"weights,omitempty"`
 }
 
 // TLSRouter is a router that supports adding and removing
@@ -169,6 +177,29 @@ type TLSRouter interface {
 	GetCertificate(ctx context.Context, app App, cname string) (string, error)
 }
 
+// ACMEChallengeRouter is a router able to route the ACME HTTP-01 challenge
+// path of a cname to the tsuru API, which answers the challenges of managed
+// certificates.
+type ACMEChallengeRouter interface {
+	AddACMEChallenge(ctx context.Context, app App, cname, token string) error
+	RemoveACMEChallenge(ctx context.Context, app App, cname, token string) error
+}
+
+// BackendState is the backend configuration a router currently holds for an
+// app. Prefixes and Healthcheck are nil when the router doesn't keep track of
+// them.
+type BackendState struct {
+	CNames      []string                `json:"cnames"`
+	Prefixes    []BackendPrefix         `json:"prefixes"`
+	Healthcheck *router.HealthcheckData `json:"healthcheck,omitempty"`
+}
+
+// BackendStateRouter is a router able to report the state of an app backend,
+// which allows tsuru to detect drift from the expected state.
+type BackendStateRouter interface {
+	GetBackendState(ctx context.Context, app App) (BackendState, error)
+}
+
 type BackendStatus string
 
 var (
@@ -22,6 +22,7 @@ var TLSRouter = tlsRouter{
 	fakeRouter: newFakeRouter(),
 	Certs:      make(map[string]string),
 	Keys:       make(map[string]string),
+	Challenges: make(map[string]string),
 }
 
 var ErrForcedFailure = errors.New("Forced failure")
@@ -62,7 +63,8 @@ type fakeRouter struct {
 }
 
 var (
-	_ router.Router = &fakeRouter{}
+	_ router.Router             = &fakeRouter{}
+	_ router.BackendStateRouter = &fakeRouter{}
 )
 
 func (r *fakeRouter) GetName() string {
@@ -79,6 +81,12 @@ func (r *fakeRouter) GetHealthcheck(name string) routerTypes.HealthcheckData {
 	return r.BackendOpts[name].Healthcheck
 }
 
+func (r *fakeRouter) GetWeights(name string) []router.BackendWeight {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.BackendOpts[name].Weights
+}
+
 func (r *fakeRouter) HasBackend(name string) bool {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
@@ -218,13 +226,32 @@ func (r *fakeRouter) Addresses(ctx context.Context, app router.App) ([]string, e
 	return sortedAddrs, nil
 }
 
+func (r *fakeRouter) GetBackendState(ctx context.Context, app router.App) (router.BackendState, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	opts, ok := r.BackendOpts[app.GetName()]
+	if !ok {
+		return router.BackendState{}, router.ErrBackendNotFound
+	}
+	hc := opts.Healthcheck
+	return router.BackendState{
+		CNames:      opts.CNames,
+		Prefixes:    opts.Prefixes,
+		Healthcheck: &hc,
+	}, nil
+}
+
 type tlsRouter struct {
 	fakeRouter
-	Certs map[string]string
-	Keys  map[string]string
+	Certs      map[string]string
+	Keys       map[string]string
+	Challenges map[string]string
 }
 
-var _ router.TLSRouter = &tlsRouter{}
+var (
+	_ router.TLSRouter           = &tlsRouter{}
+	_ router.ACMEChallengeRouter = &tlsRouter{}
+)
 
 func (r *tlsRouter) AddCertificate(ctx context.Context, app router.App, cname, certificate, key string) error {
 	r.Certs[cname] = certificate
@@ -246,6 +273,39 @@ func (r *tlsRouter) GetCertificate(ctx context.Context, app router.App, cname st
 	return data, nil
 }
 
+func (r *tlsRouter) Reset() {
+	r.fakeRouter.Reset()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.Certs = make(map[string]string)
+	r.Keys = make(map[string]string)
+	r.Challenges = make(map[string]string)
+}
+
+func (r *tlsRouter) AddACMEChallenge(ctx context.Context, app router.App, cname, token string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.Challenges[cname] = token
+	return nil
+}
+
+func (r *tlsRouter) RemoveACMEChallenge(ctx context.Context, app router.App, cname, token string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.Challenges[cname] == token {
+		delete(r.Challenges, cname)
+	}
+	return nil
+}
+
+// ACMEChallenge returns the token of the ACME challenge being routed for the
+// cname.
+func (r *tlsRouter) ACMEChallenge(cname string) string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.Challenges[cname]
+}
+
 func (r *tlsRouter) Addresses(ctx context.Context, app router.App) ([]string, error) {
 	addrs, err := r.fakeRouter.Addresses(ctx, app)
 	if err != nil {
@@ -5,6 +5,7 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"sort"
@@ -406,23 +407,8 @@ var setBoundEnvsAction = &action.Action{
 			return nil, errors.New("invalid arguments for pipeline, expected *bindAppPipelineArgs.")
 		}
 		envMap := ctx.Previous.(map[string]string)
-		envs := make([]bindTypes.ServiceEnvVar, 0, len(envMap))
-		for k, v := range envMap {
-			envs = append(envs, bindTypes.ServiceEnvVar{
-				ServiceName:  args.serviceInstance.ServiceName,
-				InstanceName: args.serviceInstance.Name,
-				EnvVar: bindTypes.EnvVar{
-					Public: false,
-					Name:   k,
-					Value:  v,
-				},
-			})
-		}
-		sort.Slice(envs, func(i, j int) bool {
-			return envs[i].Name < envs[j].Name
-		})
 		addArgs := bind.AddInstanceArgs{
-			Envs:          envs,
+			Envs:          boundEnvs(args.serviceInstance, envMap),
 			ShouldRestart: args.shouldRestart,
 			Writer:        args.writer,
 		}
@@ -442,6 +428,25 @@ var setBoundEnvsAction = &action.Action{
 	},
 }
 
+func boundEnvs(si *ServiceInstance, envMap map[string]string) []bindTypes.ServiceEnvVar {
+	envs := make([]bindTypes.ServiceEnvVar, 0, len(envMap))
+	for k, v := range envMap {
+		envs = append(envs, bindTypes.ServiceEnvVar{
+			ServiceName:  si.ServiceName,
+			InstanceName: si.Name,
+			EnvVar: bindTypes.EnvVar{
+				Public: false,
+				Name:   k,
+				Value:  v,
+			},
+		})
+	}
+	sort.Slice(envs, func(i, j int) bool {
+		return envs[i].Name < envs[j].Name
+	})
+	return envs
+}
+
 var setJobBoundEnvsAction = &action.Action{
 	Name: "set-job-bound-envs",
 	Forward: func(ctx action.FWContext) (action.Result, error) {
@@ -450,24 +455,8 @@ var setJobBoundEnvsAction = &action.Action{
 			return nil, errors.New("invalid arguments for pipeline, expected *bindJobPipelineArgs.")
 		}
 		envMap := ctx.Previous.(map[string]string)
-		envs := make([]bindTypes.ServiceEnvVar, 0, len(envMap))
-		for k, v := range envMap {
-			envs = append(envs, bindTypes.ServiceEnvVar{
-				ServiceName:  args.serviceInstance.ServiceName,
-				InstanceName: args.serviceInstance.Name,
-				EnvVar: bindTypes.EnvVar{
-					Public: false,
-					Name:   k,
-					Value:  v,
-				},
-			})
-		}
-		sort.Slice(envs, func(i, j int) bool {
-			return envs[i].Name < envs[j].Name
-		})
-
 		addArgs := jobTypes.AddInstanceArgs{
-			Envs:   envs,
+			Envs:   boundEnvs(args.serviceInstance, envMap),
 			Writer: args.writer,
 		}
 		return addArgs, servicemanager.Job.AddServiceEnv(ctx.Context, args.job, addArgs)
@@ -676,3 +665,132 @@ var removeJobBoundEnvs = action.Action{
 	},
 	MinParams: 1,
 }
+
+type rotateAppPipelineArgs struct {
+	app             bind.App
+	writer          io.Writer
+	serviceInstance *ServiceInstance
+	event           *event.Event
+	requestID       string
+	previousEnvs    []bindTypes.ServiceEnvVar
+}
+
+var rotateAppCredentialsEndpoint = &action.Action{
+	Name: "rotate-app-credentials-endpoint",
+	Forward: func(ctx action.FWContext) (action.Result, error) {
+		args, _ := ctx.Params[0].(*rotateAppPipelineArgs)
+		if args == nil {
+			return nil, errors.New("invalid arguments for pipeline, expected *rotateAppPipelineArgs.")
+		}
+		s, err := Get(ctx.Context, args.serviceInstance.ServiceName)
+		if err != nil {
+			return nil, err
+		}
+		endpoint, err := s.getClientForPool(ctx.Context, args.serviceInstance.Pool)
+		if err != nil {
+			return nil, err
+		}
+		return endpoint.RotateAppCredentials(ctx.Context, args.serviceInstance, args.app, args.event, args.requestID)
+	},
+	Backward: func(ctx action.BWContext) {
+		args, _ := ctx.Params[0].(*rotateAppPipelineArgs)
+		s, err := Get(ctx.Context, args.serviceInstance.ServiceName)
+		if err != nil {
+			log.Errorf("[rotate-app-credentials-endpoint backward] could not service from instance: %s", err)
+			return
+		}
+		endpoint, err := s.getClientForPool(ctx.Context, args.serviceInstance.Pool)
+		if err != nil {
+			log.Errorf("[rotate-app-credentials-endpoint backward] could not get endpoint: %s", err)
+			return
+		}
+		err = endpoint.RevokeAppCredentials(ctx.Context, args.serviceInstance, args.app, RotatedCredentials, args.event, args.requestID)
+		if err != nil {
+			log.Errorf("[rotate-app-credentials-endpoint backward] failed to revoke rotated credentials: %s", err)
+		}
+	},
+	MinParams: 1,
+}
+
+var replaceBoundEnvs = &action.Action{
+	Name: "replace-bound-envs",
+	Forward: func(ctx action.FWContext) (action.Result, error) {
+		args, _ := ctx.Params[0].(*rotateAppPipelineArgs)
+		if args == nil {
+			return nil, errors.New("invalid arguments for pipeline, expected *rotateAppPipelineArgs.")
+		}
+		si := args.serviceInstance
+		for _, env := range args.app.InstanceEnvs(si.ServiceName, si.Name) {
+			args.previousEnvs = append(args.previousEnvs, bindTypes.ServiceEnvVar{
+				ServiceName:  si.ServiceName,
+				InstanceName: si.Name,
+				EnvVar:       env,
+			})
+		}
+		sort.Slice(args.previousEnvs, func(i, j int) bool {
+			return args.previousEnvs[i].Name < args.previousEnvs[j].Name
+		})
+		envMap := ctx.Previous.(map[string]string)
+		err := setInstanceEnvs(ctx.Context, args, boundEnvs(si, envMap))
+		if err != nil {
+			// The Backward of a failed action is not called, so the
+			// previous envs must be restored here.
+			if restoreErr := setInstanceEnvs(ctx.Context, args, args.previousEnvs); restoreErr != nil {
+				log.Errorf("[replace-bound-envs] failed to restore previous envs: %s", restoreErr)
+			}
+			return nil, err
+		}
+		return nil, nil
+	},
+	Backward: func(ctx action.BWContext) {
+		args, _ := ctx.Params[0].(*rotateAppPipelineArgs)
+		err := setInstanceEnvs(ctx.Context, args, args.previousEnvs)
+		if err != nil {
+			log.Errorf("[replace-bound-envs backward] failed to restore previous envs: %s", err)
+		}
+	},
+	MinParams: 1,
+}
+
+// setInstanceEnvs replaces the envs set by the service instance in the app
+// and restarts it.
+func setInstanceEnvs(ctx context.Context, args *rotateAppPipelineArgs, envs []bindTypes.ServiceEnvVar) error {
+	si := args.serviceInstance
+	if len(args.app.InstanceEnvs(si.ServiceName, si.Name)) > 0 {
+		err := args.app.RemoveInstance(ctx, bind.RemoveInstanceArgs{
+			ServiceName:  si.ServiceName,
+			InstanceName: si.Name,
+			Writer:       args.writer,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return args.app.AddInstance(ctx, bind.AddInstanceArgs{
+		Envs:          envs,
+		ShouldRestart: true,
+		Writer:        args.writer,
+	})
+}
+
+var revokePreviousCredentials = &action.Action{
+	Name: "revoke-previous-credentials",
+	Forward: func(ctx action.FWContext) (action.Result, error) {
+		args, _ := ctx.Params[0].(*rotateAppPipelineArgs)
+		if args == nil {
+			return nil, errors.New("invalid arguments for pipeline, expected *rotateAppPipelineArgs.")
+		}
+		s, err := Get(ctx.Context, args.serviceInstance.ServiceName)
+		if err != nil {
+			return nil, err
+		}
+		endpoint, err := s.getClientForPool(ctx.Context, args.serviceInstance.Pool)
+		if err != nil {
+			return nil, err
+		}
+		return nil, endpoint.RevokeAppCredentials(ctx.Context, args.serviceInstance, args.app, PreviousCredentials, args.event, args.requestID)
+	},
+	Backward: func(ctx action.BWContext) {
+	},
+	MinParams: 1,
+}
@@ -0,0 +1,92 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/event"
+)
+
+// BackupsCapability is the capability advertised by service APIs able to
+// manage backups of their instances.
+const BackupsCapability = "backups"
+
+var (
+	ErrBackupsNotSupported = errors.New("service does not support backups")
+	ErrBackupNotFound      = errors.New("backup not found")
+)
+
+// Backup is a backup of a service instance, as reported by the service API.
+type Backup struct {
+	ID          string    `json:"id"`
+	Status      string    `json:"status,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Size        int64     `json:"size,omitempty"`
+	CreatedAt   time.Time `json:"created_at,omitempty"`
+}
+
+// BackupClient is implemented by service clients able to manage backups of
+// service instances. Support is optional in the service API contract, so
+// clients must check the service capabilities before using the other methods.
+type BackupClient interface {
+	SupportsBackups(ctx context.Context, instance *ServiceInstance, requestID string) (bool, error)
+	ListBackups(ctx context.Context, instance *ServiceInstance, requestID string) ([]Backup, error)
+	CreateBackup(ctx context.Context, instance *ServiceInstance, evt *event.Event, requestID string) (*Backup, error)
+	RestoreBackup(ctx context.Context, instance *ServiceInstance, backupID string, evt *event.Event, requestID string) error
+}
+
+func (si *ServiceInstance) backupClient(ctx context.Context, requestID string) (BackupClient, error) {
+	s, err := Get(ctx, si.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+	endpoint, err := s.getClientForPool(ctx, si.Pool)
+	if err != nil {
+		return nil, err
+	}
+	client, ok := endpoint.(BackupClient)
+	if !ok {
+		return nil, ErrBackupsNotSupported
+	}
+	supported, err := client.SupportsBackups(ctx, si, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if !supported {
+		return nil, ErrBackupsNotSupported
+	}
+	return client, nil
+}
+
+// Backups returns the backups of the service instance.
+func (si *ServiceInstance) Backups(ctx context.Context, requestID string) ([]Backup, error) {
+	client, err := si.backupClient(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListBackups(ctx, si, requestID)
+}
+
+// CreateBackup asks the service to create a new backup of the instance.
+func (si *ServiceInstance) CreateBackup(ctx context.Context, evt *event.Event, requestID string) (*Backup, error) {
+	client, err := si.backupClient(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	return client.CreateBackup(ctx, si, evt, requestID)
+}
+
+// RestoreBackup asks the service to restore the instance from one of its
+// backups.
+func (si *ServiceInstance) RestoreBackup(ctx context.Context, backupID string, evt *event.Event, requestID string) error {
+	client, err := si.backupClient(ctx, requestID)
+	if err != nil {
+		return err
+	}
+	return client.RestoreBackup(ctx, si, backupID, evt, requestID)
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	check "gopkg.in/check.v1"
+)
+
+func backupsHandler(c *check.C, capabilities string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.EscapedPath() {
+		case "GET /resources/my-instance/capabilities":
+			w.Write([]byte(capabilities))
+		case "GET /resources/my-instance/backups":
+			w.Write([]byte(`[{"id": "b1", "status": "done", "size": 42, "created_at": "2026-01-02T03:04:05Z"}]`))
+		case "POST /resources/my-instance/backups":
+			r.ParseForm()
+			c.Check(r.Form.Get("user"), check.Equals, "my@user")
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"id": "b2", "status": "pending"}`))
+		case "POST /resources/my-instance/backups/b1/restore", "POST /resources/my-instance/backups/2026%2F01/restore":
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+func (s *S) createBackupService(c *check.C, url string) *ServiceInstance {
+	err := Create(context.TODO(), Service{
+		Name:       "mysql",
+		Password:   "abcde",
+		OwnerTeams: []string{s.team.Name},
+		Endpoint:   map[string]string{"production": url},
+	})
+	c.Assert(err, check.IsNil)
+	return &ServiceInstance{Name: "my-instance", ServiceName: "mysql"}
+}
+
+func (s *S) TestServiceInstanceBackups(c *check.C) {
+	ts := httptest.NewServer(backupsHandler(c, `["backups"]`))
+	defer ts.Close()
+	si := s.createBackupService(c, ts.URL)
+	backups, err := si.Backups(context.TODO(), "")
+	c.Assert(err, check.IsNil)
+	c.Assert(backups, check.DeepEquals, []Backup{
+		{ID: "b1", Status: "done", Size: 42, CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)},
+	})
+}
+
+func (s *S) TestServiceInstanceBackupsNotSupported(c *check.C) {
+	ts := httptest.NewServer(backupsHandler(c, `[]`))
+	defer ts.Close()
+	si := s.createBackupService(c, ts.URL)
+	_, err := si.Backups(context.TODO(), "")
+	c.Assert(err, check.Equals, ErrBackupsNotSupported)
+}
+
+func (s *S) TestServiceInstanceBackupsWithoutCapabilities(c *check.C) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+	si := s.createBackupService(c, ts.URL)
+	_, err := si.Backups(context.TODO(), "")
+	c.Assert(err, check.Equals, ErrBackupsNotSupported)
+}
+
+func (s *S) TestServiceInstanceCreateBackup(c *check.C) {
+	ts := httptest.NewServer(backupsHandler(c, `["backups"]`))
+	defer ts.Close()
+	si := s.createBackupService(c, ts.URL)
+	backup, err := si.CreateBackup(context.TODO(), createEvt(c), "")
+	c.Assert(err, check.IsNil)
+	c.Assert(backup, check.DeepEquals, &Backup{ID: "b2", Status: "pending"})
+}
+
+func (s *S) TestServiceInstanceRestoreBackup(c *check.C) {
+	ts := httptest.NewServer(backupsHandler(c, `["backups"]`))
+	defer ts.Close()
+	si := s.createBackupService(c, ts.URL)
+	err := si.RestoreBackup(context.TODO(), "b1", createEvt(c), "")
+	c.Assert(err, check.IsNil)
+	err = si.RestoreBackup(context.TODO(), "2026/01", createEvt(c), "")
+	c.Assert(err, check.IsNil)
+	err = si.RestoreBackup(context.TODO(), "unknown", createEvt(c), "")
+	c.Assert(err, check.Equals, ErrBackupNotFound)
+}
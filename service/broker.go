@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"time"
 
 	uuid "github.com/nu7hatch/gouuid"
 	"github.com/pkg/errors"
@@ -171,6 +172,9 @@ func (b *brokerClient) Create(ctx context.Context, instance *ServiceInstance, ev
 	if resp != nil && resp.OperationKey != nil {
 		instance.BrokerData.LastOperationKey = string(*resp.OperationKey)
 	}
+	if resp != nil && resp.Async {
+		startOperation(instance, BrokerOperationProvision, resp.OperationKey, evt)
+	}
 	return nil
 }
 
@@ -220,6 +224,9 @@ func (b *brokerClient) Update(ctx context.Context, instance *ServiceInstance, ev
 	if resp != nil && resp.OperationKey != nil {
 		instance.BrokerData.LastOperationKey = string(*resp.OperationKey)
 	}
+	if resp != nil && resp.Async {
+		startOperation(instance, BrokerOperationUpdate, resp.OperationKey, evt)
+	}
 	return updateBrokerData(ctx, instance)
 }
 
@@ -242,49 +249,152 @@ func (b *brokerClient) Destroy(ctx context.Context, instance *ServiceInstance, e
 	if err != nil {
 		return err
 	}
-	if resp != nil && resp.OperationKey != nil {
+	if resp == nil || (resp.OperationKey == nil && !resp.Async) {
+		return nil
+	}
+	if resp.OperationKey != nil {
 		instance.BrokerData.LastOperationKey = string(*resp.OperationKey)
-		err = updateBrokerData(ctx, instance)
 	}
-	return err
+	if resp.Async {
+		startOperation(instance, BrokerOperationDeprovision, resp.OperationKey, evt)
+	}
+	return updateBrokerData(ctx, instance)
 }
 
 func (b *brokerClient) BindApp(ctx context.Context, instance *ServiceInstance, app bind.App, params BindAppParameters, evt *event.Event, requestID string) (map[string]string, error) {
 	if instance.BrokerData == nil {
 		return nil, ErrInvalidBrokerData
 	}
-	id, err := idForEvent(evt)
+	appGUID, err := app.GetUUID(ctx)
 	if err != nil {
 		return nil, err
 	}
+	return b.bind(ctx, instance, bindTarget{app: app.GetName(), appGUID: &appGUID}, params, evt, requestID)
+}
+
+func (b *brokerClient) UnbindApp(ctx context.Context, instance *ServiceInstance, app bind.App, evt *event.Event, requestID string) error {
+	if instance.BrokerData == nil {
+		return ErrInvalidBrokerData
+	}
+	return b.unbind(ctx, instance, bindTarget{app: app.GetName()}, evt)
+}
+
+// RotateAppCredentials creates a new binding for an app already bound to the
+// instance, keeping the previous binding until RevokeAppCredentials is called.
+func (b *brokerClient) RotateAppCredentials(ctx context.Context, instance *ServiceInstance, app bind.App, evt *event.Event, requestID string) (map[string]string, error) {
+	if instance.BrokerData == nil {
+		return nil, ErrInvalidBrokerData
+	}
+	previous, ok := instance.BrokerData.Binds[app.GetName()]
+	if !ok {
+		return nil, ErrAppNotBound
+	}
+	if previous.PreviousUUID != "" {
+		return nil, ErrCredentialsRotationInProgress
+	}
 	appGUID, err := app.GetUUID(ctx)
 	if err != nil {
 		return nil, err
 	}
+	target := bindTarget{app: app.GetName(), appGUID: &appGUID, previousUUID: previous.UUID}
+	return b.bind(ctx, instance, target, previous.Parameters, evt, requestID)
+}
+
+// RevokeAppCredentials removes either the previous or the rotated binding of
+// an app, ending a rotation started by RotateAppCredentials.
+func (b *brokerClient) RevokeAppCredentials(ctx context.Context, instance *ServiceInstance, app bind.App, credentials CredentialsGeneration, evt *event.Event, requestID string) error {
+	if instance.BrokerData == nil {
+		return ErrInvalidBrokerData
+	}
+	current, ok := instance.BrokerData.Binds[app.GetName()]
+	if !ok || current.PreviousUUID == "" {
+		return errors.Errorf("no credentials rotation in progress for app %q", app.GetName())
+	}
+	bindingID := current.PreviousUUID
+	if credentials == RotatedCredentials {
+		bindingID = current.UUID
+	}
+	id, err := idForEvent(evt)
+	if err != nil {
+		return err
+	}
+	_, err = b.client.Unbind(&osb.UnbindRequest{
+		InstanceID:          instance.BrokerData.UUID,
+		BindingID:           bindingID,
+		ServiceID:           instance.BrokerData.ServiceID,
+		PlanID:              instance.BrokerData.PlanID,
+		OriginatingIdentity: id,
+	})
+	if err != nil && !osb.IsGoneError(err) {
+		return err
+	}
+	if credentials == RotatedCredentials {
+		current.UUID = current.PreviousUUID
+	}
+	current.PreviousUUID = ""
+	instance.BrokerData.Binds[app.GetName()] = current
+	return updateBrokerData(ctx, instance)
+}
+
+// bindTarget identifies the app or job a bind belongs to.
+type bindTarget struct {
+	app     string
+	job     string
+	appGUID *string
+	// previousUUID is the binding being replaced when rotating credentials.
+	previousUUID string
+}
+
+// key is the key of the bind in BrokerInstanceData.Binds. Job binds are
+// prefixed so they don't collide with binds of apps with the same name.
+func (t bindTarget) key() string {
+	if t.job != "" {
+		return jobBindKey(t.job)
+	}
+	return t.app
+}
+
+func jobBindKey(jobName string) string {
+	return "job:" + jobName
+}
+
+func (b *brokerClient) bind(ctx context.Context, instance *ServiceInstance, target bindTarget, params map[string]interface{}, evt *event.Event, requestID string) (map[string]string, error) {
+	id, err := idForEvent(evt)
+	if err != nil {
+		return nil, err
+	}
 	bindID, err := uuid.NewV4()
 	if err != nil {
 		return nil, err
 	}
 	bind := BrokerInstanceBind{
-		UUID:       bindID.String(),
-		Parameters: params,
+		UUID:         bindID.String(),
+		PreviousUUID: target.previousUUID,
+		Parameters:   params,
 	}
 	req := osb.BindRequest{
 		ServiceID:           instance.BrokerData.ServiceID,
 		InstanceID:          instance.BrokerData.UUID,
 		PlanID:              instance.BrokerData.PlanID,
 		BindingID:           bind.UUID,
-		AppGUID:             &appGUID,
+		AppGUID:             target.appGUID,
 		Parameters:          params,
 		OriginatingIdentity: id,
-		BindResource: &osb.BindResource{
-			AppGUID: &appGUID,
-		},
 		Context: map[string]interface{}{
 			"request_id": requestID,
 			"event_id":   evt.UniqueID.Hex(),
 		},
-		AcceptsIncomplete: true,
+		// A rotation needs the new credentials right away, so it can't
+		// wait for an asynchronous binding.
+		AcceptsIncomplete: target.previousUUID == "",
+	}
+	if target.appGUID != nil {
+		req.BindResource = &osb.BindResource{
+			AppGUID: target.appGUID,
+		}
+	}
+	if target.job != "" {
+		req.Context["job_name"] = target.job
 	}
 	for k, v := range b.broker.Config.Context {
 		req.Context[k] = v
@@ -301,33 +411,28 @@ func (b *brokerClient) BindApp(ctx context.Context, instance *ServiceInstance, a
 		bind.OperationKey = string(*resp.OperationKey)
 		instance.BrokerData.LastOperationKey = string(*resp.OperationKey)
 	}
-	envs := make(map[string]string)
-	for k, v := range resp.Credentials {
-		switch s := v.(type) {
-		case string:
-			envs[k] = s
-		case int:
-			envs[k] = strconv.Itoa(s)
-		}
+	if resp.Async {
+		op := startOperation(instance, BrokerOperationBind, resp.OperationKey, evt)
+		op.App = target.app
+		op.Job = target.job
+		op.BindingID = bind.UUID
 	}
+	envs := credentialsToEnvs(resp.Credentials)
 	if instance.BrokerData.Binds == nil {
 		instance.BrokerData.Binds = make(map[string]BrokerInstanceBind)
 	}
-	instance.BrokerData.Binds[app.GetName()] = bind
+	instance.BrokerData.Binds[target.key()] = bind
 	return envs, updateBrokerData(ctx, instance)
 }
 
-func (b *brokerClient) UnbindApp(ctx context.Context, instance *ServiceInstance, app bind.App, evt *event.Event, requestID string) error {
-	if instance.BrokerData == nil {
-		return ErrInvalidBrokerData
-	}
+func (b *brokerClient) unbind(ctx context.Context, instance *ServiceInstance, target bindTarget, evt *event.Event) error {
 	id, err := idForEvent(evt)
 	if err != nil {
 		return err
 	}
 	req := osb.UnbindRequest{
 		InstanceID:          instance.BrokerData.UUID,
-		BindingID:           instance.BrokerData.Binds[app.GetName()].UUID,
+		BindingID:           instance.BrokerData.Binds[target.key()].UUID,
 		ServiceID:           instance.BrokerData.ServiceID,
 		PlanID:              instance.BrokerData.PlanID,
 		OriginatingIdentity: id,
@@ -341,12 +446,20 @@ func (b *brokerClient) UnbindApp(ctx context.Context, instance *ServiceInstance,
 	if err != nil {
 		return err
 	}
-	delete(instance.BrokerData.Binds, app.GetName())
-	if resp != nil && resp.OperationKey != nil {
+	delete(instance.BrokerData.Binds, target.key())
+	if resp == nil || (resp.OperationKey == nil && !resp.Async) {
+		return nil
+	}
+	if resp.OperationKey != nil {
 		instance.BrokerData.LastOperationKey = string(*resp.OperationKey)
-		err = updateBrokerData(ctx, instance)
 	}
-	return err
+	if resp.Async {
+		op := startOperation(instance, BrokerOperationUnbind, resp.OperationKey, evt)
+		op.App = target.app
+		op.Job = target.job
+		op.BindingID = req.BindingID
+	}
+	return updateBrokerData(ctx, instance)
 }
 
 func (b *brokerClient) Status(ctx context.Context, instance *ServiceInstance, requestID string) (string, error) {
@@ -412,14 +525,18 @@ func (b *brokerClient) Proxy(ctx context.Context, opts *ProxyOpts) error {
 	return fmt.Errorf("service proxy is not available for broker services")
 }
 
-// UnbindJob is a no-op for OSB API implementations
 func (b *brokerClient) UnbindJob(ctx context.Context, instance *ServiceInstance, job *jobTypes.Job, evt *event.Event, requestID string) error {
-	return nil
+	if instance.BrokerData == nil {
+		return ErrInvalidBrokerData
+	}
+	return b.unbind(ctx, instance, bindTarget{job: job.Name}, evt)
 }
 
-// BindJob is a no-op for OSB API implementations
 func (b *brokerClient) BindJob(ctx context.Context, instance *ServiceInstance, job *jobTypes.Job, evt *event.Event, requestID string) (map[string]string, error) {
-	return nil, nil
+	if instance.BrokerData == nil {
+		return nil, ErrInvalidBrokerData
+	}
+	return b.bind(ctx, instance, bindTarget{job: job.Name}, nil, evt, requestID)
 }
 
 func (b *brokerClient) getCatalog(ctx context.Context, name string) (*osb.CatalogResponse, error) {
@@ -483,6 +600,37 @@ func idForEvent(evt *event.Event) (*osb.OriginatingIdentity, error) {
 	}, nil
 }
 
+func credentialsToEnvs(credentials map[string]interface{}) map[string]string {
+	envs := make(map[string]string)
+	for k, v := range credentials {
+		switch s := v.(type) {
+		case string:
+			envs[k] = s
+		case int:
+			envs[k] = strconv.Itoa(s)
+		}
+	}
+	return envs
+}
+
+// startOperation records an operation the broker accepted to process
+// asynchronously, so it can be tracked by the operation poller.
+func startOperation(instance *ServiceInstance, opType BrokerOperationType, key *osb.OperationKey, evt *event.Event) *BrokerOperation {
+	now := time.Now().UTC()
+	op := &BrokerOperation{
+		Type:      opType,
+		EventID:   evt.UniqueID.Hex(),
+		StartTime: now,
+		NextPoll:  now,
+	}
+	if key != nil {
+		op.Key = string(*key)
+	}
+	instance.BrokerData.Operation = op
+	instance.OperationStatus = OperationStatusInProgress
+	return op
+}
+
 func updateBrokerData(ctx context.Context, instance *ServiceInstance) error {
 	collection, err := storagev2.ServiceInstancesCollection()
 	if err != nil {
@@ -492,7 +640,10 @@ func updateBrokerData(ctx context.Context, instance *ServiceInstance) error {
 	_, err = collection.UpdateOne(
 		ctx,
 		mongoBSON.M{"name": instance.Name, "service_name": instance.ServiceName},
-		mongoBSON.M{"$set": mongoBSON.M{"broker_data": instance.BrokerData}},
+		mongoBSON.M{"$set": mongoBSON.M{
+			"broker_data":      instance.BrokerData,
+			"operation_status": instance.OperationStatus,
+		}},
 	)
 
 	return err
@@ -0,0 +1,372 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	osb "github.com/pmorie/go-open-service-broker-client/v2"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	tsuruNet "github.com/tsuru/tsuru/net"
+	"github.com/tsuru/tsuru/servicemanager"
+	bindTypes "github.com/tsuru/tsuru/types/bind"
+	jobTypes "github.com/tsuru/tsuru/types/job"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultOperationPollInterval = 10 * time.Second
+	defaultOperationTimeout      = 24 * time.Hour
+)
+
+// InitializeOperationPoller starts the worker tracking the operations that
+// brokers process asynchronously. Each operation is polled on the interval
+// defined by the service-brokers:poll-interval config, unless the broker asks
+// for a longer one using the Retry-After header. Operations not finished
+// within service-brokers:operation-timeout are failed.
+func InitializeOperationPoller() error {
+	interval, err := config.GetDuration("service-brokers:poll-interval")
+	if err != nil || interval <= 0 {
+		interval = defaultOperationPollInterval
+	}
+	timeout, err := config.GetDuration("service-brokers:operation-timeout")
+	if err != nil || timeout <= 0 {
+		timeout = defaultOperationTimeout
+	}
+	p := &operationPoller{interval: interval, timeout: timeout, once: &sync.Once{}}
+	p.start()
+	shutdown.Register(p)
+	return nil
+}
+
+type operationPoller struct {
+	interval time.Duration
+	timeout  time.Duration
+	once     *sync.Once
+	stopCh   chan struct{}
+}
+
+func (p *operationPoller) start() {
+	p.once.Do(func() {
+		p.stopCh = make(chan struct{})
+		go p.spin()
+	})
+}
+
+func (p *operationPoller) Shutdown(ctx context.Context) error {
+	if p.stopCh == nil {
+		return nil
+	}
+	p.stopCh <- struct{}{}
+	p.stopCh = nil
+	p.once = &sync.Once{}
+	return nil
+}
+
+func (p *operationPoller) spin() {
+	for {
+		err := pollOperations(context.Background(), p.interval, p.timeout)
+		if err != nil {
+			log.Errorf("[broker operation poller] %v", err)
+		}
+		select {
+		case <-p.stopCh:
+			return
+		case <-time.After(p.interval):
+		}
+	}
+}
+
+// pollOperations checks the state of every pending broker operation due to be
+// polled, keeping the events waiting for them alive.
+func pollOperations(ctx context.Context, interval, timeout time.Duration) error {
+	collection, err := storagev2.ServiceInstancesCollection()
+	if err != nil {
+		return err
+	}
+	cursor, err := collection.Find(ctx, mongoBSON.M{"broker_data.operation": mongoBSON.M{"$exists": true}})
+	if err != nil {
+		return err
+	}
+	var instances []ServiceInstance
+	err = cursor.All(ctx, &instances)
+	if err != nil {
+		return err
+	}
+	eventIDs := make([]primitive.ObjectID, 0, len(instances))
+	for _, si := range instances {
+		id, err := primitive.ObjectIDFromHex(si.BrokerData.Operation.EventID)
+		if err == nil {
+			eventIDs = append(eventIDs, id)
+		}
+	}
+	err = event.KeepAlive(ctx, eventIDs...)
+	if err != nil {
+		log.Errorf("[broker operation poller] unable to keep events alive: %v", err)
+	}
+	now := time.Now().UTC()
+	for i := range instances {
+		si := &instances[i]
+		if si.BrokerData.Operation.NextPoll.After(now) {
+			continue
+		}
+		err = pollOperation(ctx, si, interval, timeout)
+		if err != nil {
+			log.Errorf("[broker operation poller] unable to poll %s operation of instance %s/%s: %v", si.BrokerData.Operation.Type, si.ServiceName, si.Name, err)
+		}
+	}
+	return nil
+}
+
+func pollOperation(ctx context.Context, si *ServiceInstance, interval, timeout time.Duration) error {
+	op := si.BrokerData.Operation
+	now := time.Now().UTC()
+	claimed, err := setOperationNextPoll(ctx, si, op.NextPoll, now.Add(interval))
+	if err != nil || !claimed {
+		// either failed or another tsuru API instance is already polling it
+		return err
+	}
+	client, err := newBrokeredServiceClient(si.ServiceName)
+	if err != nil {
+		return err
+	}
+	expired := now.Sub(op.StartTime) >= timeout
+	resp, retryAfter, err := client.lastOperation(ctx, si)
+	if err != nil {
+		removed := op.Type == BrokerOperationDeprovision || op.Type == BrokerOperationUnbind
+		if !removed || !osb.IsGoneError(err) {
+			if expired {
+				return finishOperation(ctx, client, si, errors.Wrapf(err, "broker didn't finish the %s operation in %s", op.Type, timeout))
+			}
+			return err
+		}
+		resp = &osb.LastOperationResponse{State: osb.StateSucceeded}
+	}
+	switch resp.State {
+	case osb.StateSucceeded:
+		return finishOperation(ctx, client, si, nil)
+	case osb.StateFailed:
+		opErr := errors.Errorf("broker failed to %s", op.Type)
+		if resp.Description != nil {
+			opErr = errors.Errorf("%v: %s", opErr, *resp.Description)
+		}
+		return finishOperation(ctx, client, si, opErr)
+	}
+	if expired {
+		return finishOperation(ctx, client, si, errors.Errorf("broker didn't finish the %s operation in %s", op.Type, timeout))
+	}
+	if retryAfter > interval {
+		_, err = setOperationNextPoll(ctx, si, now.Add(interval), now.Add(retryAfter))
+	}
+	return err
+}
+
+// setOperationNextPoll atomically moves the next poll of the instance
+// operation, returning false if it was changed by someone else.
+func setOperationNextPoll(ctx context.Context, si *ServiceInstance, current, next time.Time) (bool, error) {
+	collection, err := storagev2.ServiceInstancesCollection()
+	if err != nil {
+		return false, err
+	}
+	result, err := collection.UpdateOne(ctx, mongoBSON.M{
+		"name":                           si.Name,
+		"service_name":                   si.ServiceName,
+		"broker_data.operation.eventid":  si.BrokerData.Operation.EventID,
+		"broker_data.operation.nextpoll": current,
+	}, mongoBSON.M{"$set": mongoBSON.M{"broker_data.operation.nextpoll": next}})
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// finishOperation stores the result of the operation in the instance and
+// finishes the event waiting for it. Deprovisioned instances are removed
+// and failed binds are undone.
+func finishOperation(ctx context.Context, client *brokerClient, si *ServiceInstance, opErr error) error {
+	op := si.BrokerData.Operation
+	evt, err := event.GetByHexID(ctx, op.EventID)
+	if err != nil {
+		log.Errorf("[broker operation poller] unable to find event %s: %v", op.EventID, err)
+		evt = nil
+	} else if !evt.Running {
+		evt = nil
+	}
+	var w io.Writer = io.Discard
+	if evt != nil {
+		w = evt
+	}
+	if opErr == nil && op.Type == BrokerOperationBind {
+		opErr = client.addAsyncBindEnvs(ctx, si, op, w)
+	}
+	status := OperationStatusSucceeded
+	if opErr != nil {
+		status = OperationStatusFailed
+	}
+	collection, err := storagev2.ServiceInstancesCollection()
+	if err != nil {
+		return err
+	}
+	query := mongoBSON.M{"name": si.Name, "service_name": si.ServiceName}
+	update := mongoBSON.M{
+		"$set":   mongoBSON.M{"operation_status": status},
+		"$unset": mongoBSON.M{"broker_data.operation": ""},
+	}
+	switch {
+	case op.Type == BrokerOperationDeprovision && opErr == nil:
+		_, err = collection.DeleteOne(ctx, query)
+	case op.Type == BrokerOperationBind && opErr != nil:
+		target := bindTarget{app: op.App, job: op.Job}
+		update["$unset"] = mongoBSON.M{
+			"broker_data.operation":             "",
+			"broker_data.binds." + target.key(): "",
+		}
+		if op.Job != "" {
+			update["$pull"] = mongoBSON.M{"jobs": op.Job}
+		} else {
+			update["$pull"] = mongoBSON.M{"apps": op.App}
+		}
+		_, err = collection.UpdateOne(ctx, query, update)
+	default:
+		_, err = collection.UpdateOne(ctx, query, update)
+	}
+	if err != nil {
+		return err
+	}
+	if evt != nil {
+		fmt.Fprintf(evt, "The %s operation finished with status %q.\n", op.Type, status)
+		return evt.Done(ctx, opErr)
+	}
+	return nil
+}
+
+// addAsyncBindEnvs adds to the app or job the credentials of a bind finished
+// by the broker asynchronously, as they are not part of the response to the
+// bind request.
+func (b *brokerClient) addAsyncBindEnvs(ctx context.Context, si *ServiceInstance, op *BrokerOperation, w io.Writer) error {
+	resp, err := b.client.GetBinding(&osb.GetBindingRequest{
+		InstanceID: si.BrokerData.UUID,
+		BindingID:  op.BindingID,
+	})
+	if err != nil {
+		return err
+	}
+	envs := boundEnvs(si, credentialsToEnvs(resp.Credentials))
+	if op.Job != "" {
+		return addAsyncJobBindEnvs(ctx, op.Job, envs, w)
+	}
+	a, err := servicemanager.App.GetByName(ctx, op.App)
+	if err != nil {
+		return err
+	}
+	app, ok := a.(bind.App)
+	if !ok {
+		return errors.Errorf("app %q can't be bound", op.App)
+	}
+	return app.AddInstance(ctx, bind.AddInstanceArgs{
+		Envs:          envs,
+		ShouldRestart: true,
+		Writer:        w,
+	})
+}
+
+func addAsyncJobBindEnvs(ctx context.Context, jobName string, envs []bindTypes.ServiceEnvVar, w io.Writer) error {
+	job, err := servicemanager.Job.GetByName(ctx, jobName)
+	if err != nil {
+		return err
+	}
+	err = servicemanager.Job.AddServiceEnv(ctx, job, jobTypes.AddInstanceArgs{
+		Envs:   envs,
+		Writer: w,
+	})
+	if err != nil {
+		return err
+	}
+	job, err = servicemanager.Job.GetByName(ctx, jobName)
+	if err != nil {
+		return err
+	}
+	return servicemanager.Job.UpdateJobProv(ctx, job)
+}
+
+// lastOperation returns the state of the operation being processed by the
+// broker and how long the broker asked to wait before polling it again. The
+// request isn't made by the OSB client as it doesn't expose the Retry-After
+// header.
+func (b *brokerClient) lastOperation(ctx context.Context, si *ServiceInstance) (*osb.LastOperationResponse, time.Duration, error) {
+	op := si.BrokerData.Operation
+	u := fmt.Sprintf("%s/v2/service_instances/%s", strings.TrimRight(b.broker.URL, "/"), si.BrokerData.UUID)
+	if op.BindingID != "" {
+		u += "/service_bindings/" + op.BindingID
+	}
+	params := url.Values{}
+	params.Set(osb.VarKeyServiceID, si.BrokerData.ServiceID)
+	params.Set(osb.VarKeyPlanID, si.BrokerData.PlanID)
+	if op.Key != "" {
+		params.Set(osb.VarKeyOperation, op.Key)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u+"/last_operation?"+params.Encode(), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set(osb.APIVersionHeader, osb.LatestAPIVersion().HeaderValue())
+	if authConfig := b.broker.Config.AuthConfig; authConfig != nil {
+		if authConfig.BasicAuthConfig != nil {
+			req.SetBasicAuth(authConfig.BasicAuthConfig.Username, authConfig.BasicAuthConfig.Password)
+		} else if authConfig.BearerConfig != nil {
+			req.Header.Set("Authorization", "Bearer "+authConfig.BearerConfig.Token)
+		}
+	}
+	httpClient := tsuruNet.Dial15Full60ClientNoKeepAlive
+	if b.broker.Config.Insecure {
+		httpClient = tsuruNet.Dial15Full60ClientNoKeepAliveInsecure
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		description := string(body)
+		return nil, retryAfter, osb.HTTPStatusCodeError{StatusCode: resp.StatusCode, Description: &description}
+	}
+	var lastOp osb.LastOperationResponse
+	err = json.NewDecoder(resp.Body).Decode(&lastOp)
+	if err != nil {
+		return nil, retryAfter, err
+	}
+	return &lastOp, retryAfter, nil
+}
+
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
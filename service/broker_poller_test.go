@@ -0,0 +1,197 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	osb "github.com/pmorie/go-open-service-broker-client/v2"
+	osbfake "github.com/pmorie/go-open-service-broker-client/v2/fake"
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/event"
+	serviceTypes "github.com/tsuru/tsuru/types/service"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) setupOperationBroker(c *check.C, handler http.HandlerFunc) *httptest.Server {
+	srv := httptest.NewServer(handler)
+	ClientFactory = osbfake.NewFakeClientFunc(osbfake.FakeClientConfiguration{})
+	s.mockService.ServiceBroker.OnFind = func(name string) (serviceTypes.Broker, error) {
+		return serviceTypes.Broker{
+			Name: name,
+			URL:  srv.URL,
+			Config: serviceTypes.BrokerConfig{
+				AuthConfig: &serviceTypes.AuthConfig{
+					BasicAuthConfig: &serviceTypes.BasicAuthConfig{Username: "user", Password: "pass"},
+				},
+			},
+		}, nil
+	}
+	return srv
+}
+
+func (s *S) insertPendingInstance(c *check.C, opType BrokerOperationType) (*ServiceInstance, *event.Event) {
+	evt := createEvt(c)
+	instance := createTestInstance()
+	instance.ServiceName = "broker::service"
+	startOperation(&instance, opType, nil, evt)
+	instance.BrokerData.Operation.Key = "op-key"
+	collection, err := storagev2.ServiceInstancesCollection()
+	c.Assert(err, check.IsNil)
+	_, err = collection.InsertOne(context.TODO(), instance)
+	c.Assert(err, check.IsNil)
+	detached := instance.detachOperationEvent(context.TODO(), evt)
+	c.Assert(detached, check.Equals, true)
+	return &instance, evt
+}
+
+func (s *S) TestPollOperationsSucceeded(c *check.C) {
+	var requests int
+	srv := s.setupOperationBroker(c, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		c.Check(r.URL.Path, check.Equals, "/v2/service_instances/e7252f14-54be-45df-bd40-e988a0e41059/last_operation")
+		c.Check(r.URL.Query().Get("operation"), check.Equals, "op-key")
+		c.Check(r.URL.Query().Get("service_id"), check.Equals, "s1")
+		c.Check(r.URL.Query().Get("plan_id"), check.Equals, "p1")
+		user, pass, _ := r.BasicAuth()
+		c.Check(user+":"+pass, check.Equals, "user:pass")
+		w.Write([]byte(`{"state": "succeeded"}`))
+	})
+	defer srv.Close()
+	instance, evt := s.insertPendingInstance(c, BrokerOperationProvision)
+	err := pollOperations(context.TODO(), time.Minute, time.Hour)
+	c.Assert(err, check.IsNil)
+	c.Assert(requests, check.Equals, 1)
+	dbInstance, err := GetServiceInstance(context.TODO(), instance.ServiceName, instance.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbInstance.OperationStatus, check.Equals, OperationStatusSucceeded)
+	c.Assert(dbInstance.BrokerData.Operation, check.IsNil)
+	dbEvt, err := event.GetByID(context.TODO(), evt.UniqueID)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbEvt.Running, check.Equals, false)
+	c.Assert(dbEvt.Error, check.Equals, "")
+	c.Assert(dbEvt.Log(), check.Matches, `(?s).*The provision operation is being processed by the broker.*finished with status "succeeded".*`)
+}
+
+func (s *S) TestPollOperationsFailed(c *check.C) {
+	srv := s.setupOperationBroker(c, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"state": "failed", "description": "out of disk"}`))
+	})
+	defer srv.Close()
+	instance, evt := s.insertPendingInstance(c, BrokerOperationDeprovision)
+	err := pollOperations(context.TODO(), time.Minute, time.Hour)
+	c.Assert(err, check.IsNil)
+	dbInstance, err := GetServiceInstance(context.TODO(), instance.ServiceName, instance.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbInstance.OperationStatus, check.Equals, OperationStatusFailed)
+	c.Assert(dbInstance.BrokerData.Operation, check.IsNil)
+	dbEvt, err := event.GetByID(context.TODO(), evt.UniqueID)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbEvt.Running, check.Equals, false)
+	c.Assert(dbEvt.Error, check.Equals, "broker failed to deprovision: out of disk")
+}
+
+func (s *S) TestPollOperationsDeprovisionRemovesInstance(c *check.C) {
+	srv := s.setupOperationBroker(c, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+		w.Write([]byte(`{}`))
+	})
+	defer srv.Close()
+	instance, evt := s.insertPendingInstance(c, BrokerOperationDeprovision)
+	err := pollOperations(context.TODO(), time.Minute, time.Hour)
+	c.Assert(err, check.IsNil)
+	_, err = GetServiceInstance(context.TODO(), instance.ServiceName, instance.Name)
+	c.Assert(err, check.Equals, ErrServiceInstanceNotFound)
+	dbEvt, err := event.GetByID(context.TODO(), evt.UniqueID)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbEvt.Running, check.Equals, false)
+	c.Assert(dbEvt.Error, check.Equals, "")
+}
+
+func (s *S) TestPollOperationsInProgressRespectsRetryAfter(c *check.C) {
+	var requests int
+	srv := s.setupOperationBroker(c, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Retry-After", "600")
+		w.Write([]byte(`{"state": "in progress"}`))
+	})
+	defer srv.Close()
+	instance, evt := s.insertPendingInstance(c, BrokerOperationProvision)
+	err := pollOperations(context.TODO(), time.Minute, time.Hour)
+	c.Assert(err, check.IsNil)
+	err = pollOperations(context.TODO(), time.Minute, time.Hour)
+	c.Assert(err, check.IsNil)
+	c.Assert(requests, check.Equals, 1)
+	dbInstance, err := GetServiceInstance(context.TODO(), instance.ServiceName, instance.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbInstance.OperationStatus, check.Equals, OperationStatusInProgress)
+	c.Assert(dbInstance.BrokerData.Operation, check.NotNil)
+	nextPoll := time.Until(dbInstance.BrokerData.Operation.NextPoll)
+	c.Assert(nextPoll > 9*time.Minute, check.Equals, true, check.Commentf("next poll in %v", nextPoll))
+	dbEvt, err := event.GetByID(context.TODO(), evt.UniqueID)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbEvt.Running, check.Equals, true)
+}
+
+func (s *S) TestCreateServiceInstanceAsyncDetachesEvent(c *check.C) {
+	srv := s.setupOperationBroker(c, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/catalog":
+			w.Write([]byte(`{"services": [{"id": "s1", "name": "service", "plans": [{"id": "p1", "name": "plan1"}]}]}`))
+		default:
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"operation": "provisioning"}`))
+		}
+	})
+	defer srv.Close()
+	ClientFactory = osb.NewClient
+	srvc := Service{Name: "broker::service"}
+	evt := createEvt(c)
+	instance := ServiceInstance{Name: "instance", PlanName: "plan1", TeamOwner: s.team.Name}
+	err := CreateServiceInstance(context.TODO(), instance, &srvc, evt, "")
+	c.Assert(err, check.IsNil)
+	err = evt.Done(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	dbInstance, err := GetServiceInstance(context.TODO(), "broker::service", "instance")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbInstance.OperationStatus, check.Equals, OperationStatusInProgress)
+	c.Assert(dbInstance.BrokerData.Operation, check.NotNil)
+	c.Assert(dbInstance.BrokerData.Operation.Type, check.Equals, BrokerOperationProvision)
+	c.Assert(dbInstance.BrokerData.Operation.Key, check.Equals, "provisioning")
+	c.Assert(dbInstance.BrokerData.Operation.EventID, check.Equals, evt.UniqueID.Hex())
+	dbEvt, err := event.GetByID(context.TODO(), evt.UniqueID)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbEvt.Running, check.Equals, true)
+}
+
+func (s *S) TestParseRetryAfter(c *check.C) {
+	c.Assert(parseRetryAfter(""), check.Equals, time.Duration(0))
+	c.Assert(parseRetryAfter("invalid"), check.Equals, time.Duration(0))
+	c.Assert(parseRetryAfter("30"), check.Equals, 30*time.Second)
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	retryAfter := parseRetryAfter(date)
+	c.Assert(retryAfter > 59*time.Minute, check.Equals, true, check.Commentf("retry after %v", retryAfter))
+}
+
+func (s *S) TestPollOperationsTimedOut(c *check.C) {
+	srv := s.setupOperationBroker(c, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"state": "in progress"}`))
+	})
+	defer srv.Close()
+	instance, evt := s.insertPendingInstance(c, BrokerOperationProvision)
+	err := pollOperations(context.TODO(), time.Minute, 0)
+	c.Assert(err, check.IsNil)
+	dbInstance, err := GetServiceInstance(context.TODO(), instance.ServiceName, instance.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbInstance.OperationStatus, check.Equals, OperationStatusFailed)
+	c.Assert(dbInstance.BrokerData.Operation, check.IsNil)
+	dbEvt, err := event.GetByID(context.TODO(), evt.UniqueID)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbEvt.Running, check.Equals, false)
+	c.Assert(dbEvt.Error, check.Equals, "broker didn't finish the provision operation in 0s")
+}
@@ -9,6 +9,7 @@ import (
 	osbfake "github.com/pmorie/go-open-service-broker-client/v2/fake"
 	"github.com/tsuru/tsuru/db/storagev2"
 	"github.com/tsuru/tsuru/provision/provisiontest"
+	jobTypes "github.com/tsuru/tsuru/types/job"
 	serviceTypes "github.com/tsuru/tsuru/types/service"
 	check "gopkg.in/check.v1"
 )
@@ -304,6 +305,203 @@ func (s *S) TestBrokerClientBindApp(c *check.C) {
 	})
 }
 
+func (s *S) TestBrokerClientBindJob(c *check.C) {
+	ev := createEvt(c)
+	job := &jobTypes.Job{Name: "thejob"}
+	var bindID string
+	reaction := func(req *osb.BindRequest) (*osb.BindResponse, error) {
+		c.Assert(req.BindingID, check.Not(check.DeepEquals), "")
+		bindID = req.BindingID
+		c.Assert(req.AppGUID, check.IsNil)
+		c.Assert(req.BindResource, check.IsNil)
+		c.Assert(req.Context, check.DeepEquals, map[string]interface{}{
+			"request_id": "request-id",
+			"event_id":   ev.UniqueID.Hex(),
+			"job_name":   "thejob",
+		})
+		return &osb.BindResponse{
+			Credentials: map[string]interface{}{
+				"DATABASE_URL": "postgres://db",
+			}}, nil
+	}
+	config := osbfake.FakeClientConfiguration{
+		BindReaction: osbfake.DynamicBindReaction(reaction),
+	}
+	ClientFactory = osbfake.NewFakeClientFunc(config)
+	client, err := newClient(serviceTypes.Broker{Name: "broker"}, "service")
+	c.Assert(err, check.IsNil)
+	instance := createTestInstance()
+	instance.BrokerData.Binds = map[string]BrokerInstanceBind{
+		"thejob": {UUID: "app-bind"},
+	}
+	serviceInstancesCollection, err := storagev2.ServiceInstancesCollection()
+	c.Assert(err, check.IsNil)
+	_, err = serviceInstancesCollection.InsertOne(context.TODO(), &instance)
+	c.Assert(err, check.IsNil)
+	envs, err := client.BindJob(context.TODO(), &instance, job, ev, "request-id")
+	c.Assert(err, check.IsNil)
+	c.Assert(envs, check.DeepEquals, map[string]string{
+		"DATABASE_URL": "postgres://db",
+	})
+	storedInstance, err := GetServiceInstance(context.TODO(), instance.ServiceName, instance.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(storedInstance.BrokerData.Binds, check.DeepEquals, map[string]BrokerInstanceBind{
+		"thejob":     {UUID: "app-bind"},
+		"job:thejob": {UUID: bindID},
+	})
+}
+
+func (s *S) TestBrokerClientUnbindJob(c *check.C) {
+	ev := createEvt(c)
+	job := &jobTypes.Job{Name: "thejob"}
+	var unbindCalls int
+	reaction := func(req *osb.UnbindRequest) (*osb.UnbindResponse, error) {
+		unbindCalls++
+		c.Assert(req.BindingID, check.Equals, "job-bind")
+		return &osb.UnbindResponse{}, nil
+	}
+	config := osbfake.FakeClientConfiguration{
+		UnbindReaction: osbfake.DynamicUnbindReaction(reaction),
+	}
+	ClientFactory = osbfake.NewFakeClientFunc(config)
+	client, err := newClient(serviceTypes.Broker{Name: "broker"}, "service")
+	c.Assert(err, check.IsNil)
+	instance := createTestInstance()
+	instance.BrokerData.Binds = map[string]BrokerInstanceBind{
+		"thejob":     {UUID: "app-bind"},
+		"job:thejob": {UUID: "job-bind"},
+	}
+	err = client.UnbindJob(context.TODO(), &instance, job, ev, "request-id")
+	c.Assert(err, check.IsNil)
+	c.Assert(unbindCalls, check.Equals, 1)
+	c.Assert(instance.BrokerData.Binds, check.DeepEquals, map[string]BrokerInstanceBind{
+		"thejob": {UUID: "app-bind"},
+	})
+}
+
+func (s *S) TestBrokerClientRotateAppCredentials(c *check.C) {
+	ev := createEvt(c)
+	a := provisiontest.NewFakeApp("theapp", "python", 1)
+	var bindID string
+	reaction := func(req *osb.BindRequest) (*osb.BindResponse, error) {
+		c.Assert(req.BindingID, check.Not(check.Equals), "app-bind")
+		c.Assert(req.AcceptsIncomplete, check.Equals, false)
+		c.Assert(req.Parameters, check.DeepEquals, map[string]interface{}{"ro": true})
+		bindID = req.BindingID
+		return &osb.BindResponse{
+			Credentials: map[string]interface{}{
+				"DATABASE_URL": "postgres://db2",
+			}}, nil
+	}
+	var unbound []string
+	unbindReaction := func(req *osb.UnbindRequest) (*osb.UnbindResponse, error) {
+		unbound = append(unbound, req.BindingID)
+		return &osb.UnbindResponse{}, nil
+	}
+	config := osbfake.FakeClientConfiguration{
+		BindReaction:   osbfake.DynamicBindReaction(reaction),
+		UnbindReaction: osbfake.DynamicUnbindReaction(unbindReaction),
+	}
+	ClientFactory = osbfake.NewFakeClientFunc(config)
+	client, err := newClient(serviceTypes.Broker{Name: "broker"}, "service")
+	c.Assert(err, check.IsNil)
+	instance := createTestInstance()
+	instance.BrokerData.Binds = map[string]BrokerInstanceBind{
+		"theapp": {UUID: "app-bind", Parameters: map[string]interface{}{"ro": true}},
+	}
+	serviceInstancesCollection, err := storagev2.ServiceInstancesCollection()
+	c.Assert(err, check.IsNil)
+	_, err = serviceInstancesCollection.InsertOne(context.TODO(), &instance)
+	c.Assert(err, check.IsNil)
+	envs, err := client.RotateAppCredentials(context.TODO(), &instance, a, ev, "request-id")
+	c.Assert(err, check.IsNil)
+	c.Assert(envs, check.DeepEquals, map[string]string{
+		"DATABASE_URL": "postgres://db2",
+	})
+	c.Assert(instance.BrokerData.Binds["theapp"].PreviousUUID, check.Equals, "app-bind")
+	_, err = client.RotateAppCredentials(context.TODO(), &instance, a, ev, "request-id")
+	c.Assert(err, check.Equals, ErrCredentialsRotationInProgress)
+	err = client.RevokeAppCredentials(context.TODO(), &instance, a, PreviousCredentials, ev, "request-id")
+	c.Assert(err, check.IsNil)
+	c.Assert(unbound, check.DeepEquals, []string{"app-bind"})
+	storedInstance, err := GetServiceInstance(context.TODO(), instance.ServiceName, instance.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(storedInstance.BrokerData.Binds, check.DeepEquals, map[string]BrokerInstanceBind{
+		"theapp": {UUID: bindID, Parameters: map[string]interface{}{"ro": true}},
+	})
+}
+
+func (s *S) TestRotateAppCredentialsForceRevokesStaleCredentials(c *check.C) {
+	ev := createEvt(c)
+	a := provisiontest.NewFakeApp("theapp", "python", 1)
+	var unbound []string
+	config := osbfake.FakeClientConfiguration{
+		CatalogReaction: &osbfake.CatalogReaction{Response: &osb.CatalogResponse{
+			Services: []osb.Service{{Name: "service"}},
+		}},
+		BindReaction: osbfake.DynamicBindReaction(func(req *osb.BindRequest) (*osb.BindResponse, error) {
+			return &osb.BindResponse{Credentials: map[string]interface{}{"DATABASE_URL": "postgres://db3"}}, nil
+		}),
+		UnbindReaction: osbfake.DynamicUnbindReaction(func(req *osb.UnbindRequest) (*osb.UnbindResponse, error) {
+			unbound = append(unbound, req.BindingID)
+			return &osb.UnbindResponse{}, nil
+		}),
+	}
+	ClientFactory = osbfake.NewFakeClientFunc(config)
+	s.mockService.ServiceBroker.OnFind = func(name string) (serviceTypes.Broker, error) {
+		return serviceTypes.Broker{Name: name}, nil
+	}
+	instance := createTestInstance()
+	instance.ServiceName = "broker::service"
+	instance.Apps = []string{"theapp"}
+	instance.BrokerData.Binds = map[string]BrokerInstanceBind{
+		"theapp": {UUID: "rotated-bind", PreviousUUID: "stale-bind"},
+	}
+	serviceInstancesCollection, err := storagev2.ServiceInstancesCollection()
+	c.Assert(err, check.IsNil)
+	_, err = serviceInstancesCollection.InsertOne(context.TODO(), &instance)
+	c.Assert(err, check.IsNil)
+	err = instance.RotateAppCredentials(context.TODO(), a, false, ev, "request-id")
+	c.Assert(err, check.Equals, ErrCredentialsRotationInProgress)
+	err = instance.RotateAppCredentials(context.TODO(), a, true, ev, "request-id")
+	c.Assert(err, check.IsNil)
+	c.Assert(unbound, check.DeepEquals, []string{"stale-bind", "rotated-bind"})
+	storedInstance, err := GetServiceInstance(context.TODO(), instance.ServiceName, instance.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(storedInstance.BrokerData.Binds["theapp"].PreviousUUID, check.Equals, "")
+	c.Assert(storedInstance.BrokerData.Binds["theapp"].UUID, check.Not(check.Equals), "rotated-bind")
+}
+
+func (s *S) TestBrokerClientRevokeRotatedAppCredentials(c *check.C) {
+	ev := createEvt(c)
+	a := provisiontest.NewFakeApp("theapp", "python", 1)
+	var unbound []string
+	unbindReaction := func(req *osb.UnbindRequest) (*osb.UnbindResponse, error) {
+		unbound = append(unbound, req.BindingID)
+		return &osb.UnbindResponse{}, nil
+	}
+	config := osbfake.FakeClientConfiguration{
+		UnbindReaction: osbfake.DynamicUnbindReaction(unbindReaction),
+	}
+	ClientFactory = osbfake.NewFakeClientFunc(config)
+	client, err := newClient(serviceTypes.Broker{Name: "broker"}, "service")
+	c.Assert(err, check.IsNil)
+	instance := createTestInstance()
+	instance.BrokerData.Binds = map[string]BrokerInstanceBind{
+		"theapp": {UUID: "new-bind", PreviousUUID: "app-bind"},
+	}
+	serviceInstancesCollection, err := storagev2.ServiceInstancesCollection()
+	c.Assert(err, check.IsNil)
+	_, err = serviceInstancesCollection.InsertOne(context.TODO(), &instance)
+	c.Assert(err, check.IsNil)
+	err = client.RevokeAppCredentials(context.TODO(), &instance, a, RotatedCredentials, ev, "request-id")
+	c.Assert(err, check.IsNil)
+	c.Assert(unbound, check.DeepEquals, []string{"new-bind"})
+	c.Assert(instance.BrokerData.Binds, check.DeepEquals, map[string]BrokerInstanceBind{
+		"theapp": {UUID: "app-bind"},
+	})
+}
+
 func (s *S) TestBrokerClientUnbindApp(c *check.C) {
 	ev := createEvt(c)
 	reaction := func(req *osb.UnbindRequest) (*osb.UnbindResponse, error) {
@@ -31,9 +31,10 @@ import (
 )
 
 var (
-	ErrInstanceAlreadyExistsInAPI = errors.New("instance already exists in the service API")
-	ErrInstanceNotFoundInAPI      = errors.New("instance does not exist in the service API")
-	ErrInstanceNotReady           = errors.New("instance is not ready yet")
+	ErrInstanceAlreadyExistsInAPI      = errors.New("instance already exists in the service API")
+	ErrInstanceNotFoundInAPI           = errors.New("instance does not exist in the service API")
+	ErrInstanceNotReady                = errors.New("instance is not ready yet")
+	ErrCredentialsRotationNotSupported = errors.New("service API does not support credentials rotation")
 
 	requestLatencies = prometheus.NewHistogramVec(prometheus.HistogramOpts{
 		Name: "tsuru_service_request_duration_seconds",
@@ -47,8 +48,10 @@ var (
 	reservedProxyPaths = []string{
 		"",
 		"bind-app",
+		"bind-app/rotate",
 		"bind-job",
 		"bind",
+		"backups",
 	}
 )
 
@@ -58,6 +61,7 @@ func init() {
 }
 
 var _ ServiceClient = &endpointClient{}
+var _ BackupClient = &endpointClient{}
 
 type validationError struct {
 	Msg           string   `json:"msg"`
@@ -304,6 +308,71 @@ func (c *endpointClient) UnbindJob(ctx context.Context, instance *ServiceInstanc
 	return nil
 }
 
+// RotateAppCredentials asks the service API for new credentials for an app
+// already bound to the instance. The service API must keep the previous
+// credentials valid until RevokeAppCredentials is called.
+func (c *endpointClient) RotateAppCredentials(ctx context.Context, instance *ServiceInstance, app bind.App, evt *event.Event, requestID string) (map[string]string, error) {
+	log.Debugf("Calling credentials rotation of instance %q and %q app at %q API",
+		instance.Name, app.GetName(), instance.ServiceName)
+	params, err := buildBindAppParams(ctx, evt, app, nil)
+	if err != nil {
+		return nil, err
+	}
+	header, err := baseHeader(ctx, evt, instance, requestID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.issueRequest(ctx, "/resources/"+instance.GetIdentifier()+"/bind-app/rotate", "POST", params, header)
+	if err != nil {
+		return nil, log.WrapError(errors.Wrapf(err, `Failed to rotate credentials of app %q in service instance "%s/%s"`, app.GetName(), instance.ServiceName, instance.Name))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 300 {
+		var result map[string]string
+		err = c.jsonFromResponse(resp, &result)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+	switch resp.StatusCode {
+	case http.StatusPreconditionFailed:
+		return nil, ErrInstanceNotReady
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return nil, ErrCredentialsRotationNotSupported
+	}
+	err = errors.Wrapf(c.buildErrorMessage(err, resp), `Failed to rotate credentials of app %q in service instance "%s/%s"`, app.GetName(), instance.ServiceName, instance.Name)
+	return nil, log.WrapError(err)
+}
+
+// RevokeAppCredentials asks the service API to revoke either the previous or
+// the rotated credentials of an app, ending a rotation.
+func (c *endpointClient) RevokeAppCredentials(ctx context.Context, instance *ServiceInstance, app bind.App, credentials CredentialsGeneration, evt *event.Event, requestID string) error {
+	log.Debugf("Calling revoke of %s credentials of instance %q and %q app at %q API",
+		credentials, instance.Name, app.GetName(), instance.ServiceName)
+	url := "/resources/" + instance.GetIdentifier() + "/bind-app/rotate"
+	params := map[string][]string{
+		"app-name":    {app.GetName()},
+		"credentials": {string(credentials)},
+		"user":        {evt.Owner.Name},
+		"eventid":     {evt.UniqueID.Hex()},
+	}
+	header, err := baseHeader(ctx, evt, instance, requestID)
+	if err != nil {
+		return err
+	}
+	resp, err := c.issueRequest(ctx, url, "DELETE", params, header)
+	if err != nil {
+		return log.WrapError(errors.Wrapf(err, "Failed to revoke credentials (%q)", url))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode > 299 {
+		err = errors.Wrapf(c.buildErrorMessage(err, resp), "Failed to revoke credentials (%q)", url)
+		return log.WrapError(err)
+	}
+	return nil
+}
+
 func (c *endpointClient) Status(ctx context.Context, instance *ServiceInstance, requestID string) (string, error) {
 	log.Debugf("Attempting to call status of service instance %q at %q api", instance.Name, instance.ServiceName)
 	var (
@@ -406,6 +475,133 @@ func (c *endpointClient) Plans(ctx context.Context, pool, requestID string) ([]P
 	return result, nil
 }
 
+// capabilities returns the optional features supported by the service API
+// for the instance. The api should be prepared to receive the request,
+// like below:
+// GET /resources/<name>/capabilities
+// Service APIs not aware of capabilities have none.
+func (c *endpointClient) capabilities(ctx context.Context, instance *ServiceInstance, requestID string) ([]string, error) {
+	header, err := baseHeader(ctx, nil, instance, requestID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.issueRequest(ctx, "/resources/"+instance.GetIdentifier()+"/capabilities", "GET", nil, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil
+	}
+	var result []string
+	err = c.jsonFromResponse(resp, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *endpointClient) SupportsBackups(ctx context.Context, instance *ServiceInstance, requestID string) (bool, error) {
+	capabilities, err := c.capabilities(ctx, instance, requestID)
+	if err != nil {
+		return false, err
+	}
+	for _, capability := range capabilities {
+		if capability == BackupsCapability {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c *endpointClient) ListBackups(ctx context.Context, instance *ServiceInstance, requestID string) ([]Backup, error) {
+	log.Debugf("Attempting to list backups of service instance %q at %q api", instance.Name, instance.ServiceName)
+	header, err := baseHeader(ctx, nil, instance, requestID)
+	if err != nil {
+		return nil, err
+	}
+	url := "/resources/" + instance.GetIdentifier() + "/backups"
+	resp, err := c.issueRequest(ctx, url, "GET", nil, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		backups := []Backup{}
+		err = c.jsonFromResponse(resp, &backups)
+		if err != nil {
+			return nil, err
+		}
+		return backups, nil
+	case http.StatusNotFound:
+		return nil, ErrInstanceNotFoundInAPI
+	}
+	err = errors.Wrapf(c.buildErrorMessage(err, resp), `Failed to list backups of service instance "%s/%s"`, instance.ServiceName, instance.Name)
+	return nil, log.WrapError(err)
+}
+
+func (c *endpointClient) CreateBackup(ctx context.Context, instance *ServiceInstance, evt *event.Event, requestID string) (*Backup, error) {
+	log.Debugf("Attempting to create backup of service instance %q at %q api", instance.Name, instance.ServiceName)
+	header, err := baseHeader(ctx, evt, instance, requestID)
+	if err != nil {
+		return nil, err
+	}
+	params := map[string][]string{
+		"user":    {evt.Owner.Name},
+		"eventid": {evt.UniqueID.Hex()},
+	}
+	url := "/resources/" + instance.GetIdentifier() + "/backups"
+	resp, err := c.issueRequest(ctx, url, "POST", params, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusAccepted:
+		var backup Backup
+		err = c.jsonFromResponse(resp, &backup)
+		if err != nil {
+			return nil, err
+		}
+		return &backup, nil
+	case http.StatusNotFound:
+		return nil, ErrInstanceNotFoundInAPI
+	case http.StatusPreconditionFailed:
+		return nil, ErrInstanceNotReady
+	}
+	err = errors.Wrapf(c.buildErrorMessage(err, resp), `Failed to create backup of service instance "%s/%s"`, instance.ServiceName, instance.Name)
+	return nil, log.WrapError(err)
+}
+
+func (c *endpointClient) RestoreBackup(ctx context.Context, instance *ServiceInstance, backupID string, evt *event.Event, requestID string) error {
+	log.Debugf("Attempting to restore backup %q of service instance %q at %q api", backupID, instance.Name, instance.ServiceName)
+	header, err := baseHeader(ctx, evt, instance, requestID)
+	if err != nil {
+		return err
+	}
+	params := map[string][]string{
+		"user":    {evt.Owner.Name},
+		"eventid": {evt.UniqueID.Hex()},
+	}
+	path := "/resources/" + instance.GetIdentifier() + "/backups/" + url.PathEscape(backupID) + "/restore"
+	resp, err := c.issueRequest(ctx, path, "POST", params, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusNoContent:
+		return nil
+	case http.StatusNotFound:
+		return ErrBackupNotFound
+	case http.StatusPreconditionFailed:
+		return ErrInstanceNotReady
+	}
+	err = errors.Wrapf(c.buildErrorMessage(err, resp), `Failed to restore backup %q of service instance "%s/%s"`, backupID, instance.ServiceName, instance.Name)
+	return log.WrapError(err)
+}
+
 // Proxy is a proxy between tsuru and the service.
 // This method allow customized service methods.
 func (c *endpointClient) Proxy(ctx context.Context, opts *ProxyOpts) error {
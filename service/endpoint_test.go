@@ -678,6 +678,67 @@ func (s *S) TestUnbindApp(c *check.C) {
 	c.Assert(map[string][]string(v), check.DeepEquals, expected)
 }
 
+func (s *S) TestRotateAppCredentials(c *check.C) {
+	h := TestHandler{}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	instance := ServiceInstance{Name: "heaven-can-wait", ServiceName: "heaven"}
+	a := provisiontest.NewFakeApp("arch-enemy", "python", 1)
+	client := &endpointClient{endpoint: ts.URL, username: "user", password: "abcde"}
+	evt := createEvt(c)
+	envs, err := client.RotateAppCredentials(context.TODO(), &instance, a, evt, "")
+	h.Lock()
+	defer h.Unlock()
+	c.Assert(err, check.IsNil)
+	c.Assert(envs, check.DeepEquals, map[string]string{
+		"MYSQL_DATABASE_NAME": "CHICO",
+		"MYSQL_HOST":          "localhost",
+		"MYSQL_PORT":          "3306",
+	})
+	c.Assert(h.url, check.Equals, "/resources/heaven-can-wait/bind-app/rotate")
+	c.Assert(h.method, check.Equals, http.MethodPost)
+	v, err := url.ParseQuery(string(h.body))
+	c.Assert(err, check.IsNil)
+	c.Assert(v.Get("app-name"), check.Equals, "arch-enemy")
+	c.Assert(v.Get("eventid"), check.Equals, evt.UniqueID.Hex())
+}
+
+func (s *S) TestRotateAppCredentialsNotSupported(c *check.C) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+	instance := ServiceInstance{Name: "heaven-can-wait", ServiceName: "heaven"}
+	a := provisiontest.NewFakeApp("arch-enemy", "python", 1)
+	client := &endpointClient{endpoint: ts.URL, username: "user", password: "abcde"}
+	evt := createEvt(c)
+	_, err := client.RotateAppCredentials(context.TODO(), &instance, a, evt, "")
+	c.Assert(err, check.Equals, ErrCredentialsRotationNotSupported)
+}
+
+func (s *S) TestRevokeAppCredentials(c *check.C) {
+	h := TestHandler{}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	instance := ServiceInstance{Name: "heaven-can-wait", ServiceName: "heaven"}
+	a := provisiontest.NewFakeApp("arch-enemy", "python", 1)
+	client := &endpointClient{endpoint: ts.URL, username: "user", password: "abcde"}
+	evt := createEvt(c)
+	err := client.RevokeAppCredentials(context.TODO(), &instance, a, PreviousCredentials, evt, "")
+	h.Lock()
+	defer h.Unlock()
+	c.Assert(err, check.IsNil)
+	c.Assert(h.url, check.Equals, "/resources/heaven-can-wait/bind-app/rotate")
+	c.Assert(h.method, check.Equals, http.MethodDelete)
+	v, err := url.ParseQuery(string(h.body))
+	c.Assert(err, check.IsNil)
+	expected := map[string][]string{
+		"app-name":    {"arch-enemy"},
+		"credentials": {"previous"},
+		"user":        {"my@user"},
+		"eventid":     {evt.UniqueID.Hex()},
+	}
+	c.Assert(map[string][]string(v), check.DeepEquals, expected)
+}
+
 func (s *S) TestUnbindAppRequestFailure(c *check.C) {
 	ts := httptest.NewServer(http.HandlerFunc(failHandler))
 	defer ts.Close()
@@ -52,6 +52,19 @@ type ProxyOpts struct {
 	Request   *http.Request
 }
 
+// CredentialsGeneration identifies which credentials of an app must be
+// revoked at the end of a rotation.
+type CredentialsGeneration string
+
+const (
+	// PreviousCredentials are the credentials the app used before the
+	// rotation, revoked once the app is running with the new ones.
+	PreviousCredentials = CredentialsGeneration("previous")
+	// RotatedCredentials are the credentials issued by the rotation,
+	// revoked when the rotation is rolled back.
+	RotatedCredentials = CredentialsGeneration("rotated")
+)
+
 // TODO: use requestID inside the context
 type ServiceClient interface {
 	Create(ctx context.Context, instance *ServiceInstance, evt *event.Event, requestID string) error
@@ -61,6 +74,8 @@ type ServiceClient interface {
 	BindJob(ctx context.Context, instance *ServiceInstance, job *jobTypes.Job, evt *event.Event, requestID string) (map[string]string, error)
 	UnbindApp(ctx context.Context, instance *ServiceInstance, app bind.App, evt *event.Event, requestID string) error
 	UnbindJob(ctx context.Context, instance *ServiceInstance, job *jobTypes.Job, evt *event.Event, requestID string) error
+	RotateAppCredentials(ctx context.Context, instance *ServiceInstance, app bind.App, evt *event.Event, requestID string) (map[string]string, error)
+	RevokeAppCredentials(ctx context.Context, instance *ServiceInstance, app bind.App, credentials CredentialsGeneration, evt *event.Event, requestID string) error
 	Status(ctx context.Context, instance *ServiceInstance, requestID string) (string, error)
 	Info(ctx context.Context, instance *ServiceInstance, requestID string) ([]map[string]string, error)
 	Plans(ctx context.Context, pool, requestID string) ([]Plan, error)
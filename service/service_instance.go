@@ -12,6 +12,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/tsuru/tsuru/action"
@@ -19,8 +20,10 @@ import (
 	"github.com/tsuru/tsuru/db/storagev2"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/servicemanager"
 	authTypes "github.com/tsuru/tsuru/types/auth"
+	eventTypes "github.com/tsuru/tsuru/types/event"
 	jobTypes "github.com/tsuru/tsuru/types/job"
 	mongoBSON "go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -36,6 +39,7 @@ var (
 	ErrAppAlreadyBound                          = errors.New("app is already bound to this service instance")
 	ErrJobAlreadyBound                          = errors.New("job is already bound to this service instance")
 	ErrAppNotBound                              = errors.New("app is not bound to this service instance")
+	ErrCredentialsRotationInProgress            = errors.New("a credentials rotation is already in progress for this app, rotate with force to revoke the previous credentials")
 	ErrJobNotBound                              = errors.New("job is not bound to this service instance")
 	ErrUnitNotBound                             = errors.New("unit is not bound to this service instance")
 	ErrServiceInstanceBound                     = errors.New("This service instance is bound to at least one app. Unbind them before removing it")
@@ -69,6 +73,11 @@ type ServiceInstance struct {
 	// BrokerData stores data used by Instances provisioned by Brokers
 	BrokerData *BrokerInstanceData `json:"broker_data,omitempty" bson:"broker_data"`
 
+	// OperationStatus is the state of the last asynchronous operation
+	// requested to the Broker, it's empty when the Broker handled every
+	// operation synchronously.
+	OperationStatus string `json:"operation_status,omitempty" bson:"operation_status,omitempty"`
+
 	// ForceRemove indicates whether service instance should be removed even the
 	// related call to service API fails.
 	ForceRemove bool `bson:"-" json:"-"`
@@ -84,6 +93,42 @@ type BrokerInstanceData struct {
 	LastOperationKey string
 
 	Binds map[string]BrokerInstanceBind
+
+	// Operation is the asynchronous operation still being processed by the
+	// Broker, if any.
+	Operation *BrokerOperation `bson:",omitempty"`
+}
+
+const (
+	OperationStatusInProgress = "in progress"
+	OperationStatusSucceeded  = "succeeded"
+	OperationStatusFailed     = "failed"
+)
+
+type BrokerOperationType string
+
+const (
+	BrokerOperationProvision   = BrokerOperationType("provision")
+	BrokerOperationUpdate      = BrokerOperationType("update")
+	BrokerOperationDeprovision = BrokerOperationType("deprovision")
+	BrokerOperationBind        = BrokerOperationType("bind")
+	BrokerOperationUnbind      = BrokerOperationType("unbind")
+)
+
+// BrokerOperation is an operation accepted by a Broker and not finished yet.
+// It's tracked by the operation poller, which finishes the event that
+// started it once the Broker reports the operation as done.
+type BrokerOperation struct {
+	Type BrokerOperationType
+	Key  string
+	// App or Job and BindingID identify the bind for bind and unbind
+	// operations.
+	App       string
+	Job       string
+	BindingID string
+	EventID   string
+	StartTime time.Time
+	NextPoll  time.Time
 }
 
 type BrokerInstanceBind struct {
@@ -91,6 +136,9 @@ type BrokerInstanceBind struct {
 	UUID         string
 	OperationKey string
 	Parameters   map[string]interface{}
+	// PreviousUUID is the binding replaced by a credentials rotation that
+	// was not revoked yet.
+	PreviousUUID string `bson:",omitempty"`
 }
 
 // DeleteInstance deletes the service instance from the database.
@@ -112,6 +160,10 @@ func DeleteInstance(ctx context.Context, si *ServiceInstance, evt *event.Event,
 			return err
 		}
 		fmt.Fprintf(evt, "could not delete the service instance on service api: %v. ignoring this error due to force removal...\n", err)
+	} else if si.detachOperationEvent(ctx, evt) {
+		// the instance is removed by the operation poller once the broker
+		// finishes deprovisioning it.
+		return nil
 	}
 	collection, err := storagev2.ServiceInstancesCollection()
 	if err != nil {
@@ -215,7 +267,34 @@ func (si *ServiceInstance) Update(ctx context.Context, service Service, updateDa
 	}
 	actions := []*action.Action{&updateServiceInstance, &notifyUpdateServiceInstance}
 	pipeline := action.NewPipeline(actions...)
-	return pipeline.Execute(ctx, service, *si, updateData, evt, requestID)
+	err = pipeline.Execute(ctx, service, *si, updateData, evt, requestID)
+	if err != nil {
+		return err
+	}
+	si.detachOperationEvent(ctx, evt)
+	return nil
+}
+
+// detachOperationEvent hands evt over to the operation poller when the broker
+// is still processing the operation started by it. It returns whether the
+// event was detached.
+func (si *ServiceInstance) detachOperationEvent(ctx context.Context, evt *event.Event) bool {
+	if evt == nil || si.BrokerData == nil || si.BrokerData.Operation == nil {
+		return false
+	}
+	op := si.BrokerData.Operation
+	if op.EventID != evt.UniqueID.Hex() {
+		return false
+	}
+	fmt.Fprintf(evt, "The %s operation is being processed by the broker, this event will finish once it's done.\n", op.Type)
+	// apps and jobs are unlocked while the broker processes the operation,
+	// only the service instance remains locked.
+	target := eventTypes.Target{Type: eventTypes.TargetTypeServiceInstance, Value: fmt.Sprintf("%s/%s", si.ServiceName, si.Name)}
+	err := evt.Detach(ctx, target)
+	if err != nil {
+		log.Errorf("[service instance %s] unable to store event log: %v", si.Name, err)
+	}
+	return true
 }
 
 func (si *ServiceInstance) updateData(ctx context.Context, update mongoBSON.M) error {
@@ -244,7 +323,12 @@ func (si *ServiceInstance) BindApp(ctx context.Context, app bind.App, params Bin
 		setBoundEnvsAction,
 	}
 	pipeline := action.NewPipeline(actions...)
-	return pipeline.Execute(ctx, &args)
+	err := pipeline.Execute(ctx, &args)
+	if err != nil {
+		return err
+	}
+	si.detachOperationEvent(ctx, evt)
+	return nil
 }
 
 // BindJob makes the bind between the service instance and a job.
@@ -263,7 +347,12 @@ func (si *ServiceInstance) BindJob(ctx context.Context, job *jobTypes.Job, write
 		reloadJobProvisioner,
 	}
 	pipeline := action.NewPipeline(actions...)
-	return pipeline.Execute(ctx, &args)
+	err := pipeline.Execute(ctx, &args)
+	if err != nil {
+		return err
+	}
+	si.detachOperationEvent(ctx, evt)
+	return nil
 }
 
 type UnbindJobArgs struct {
@@ -293,7 +382,12 @@ func (si *ServiceInstance) UnbindJob(ctx context.Context, unbindArgs UnbindJobAr
 		reloadJobProvisioner,
 	}
 	pipeline := action.NewPipeline(actions...)
-	return pipeline.Execute(ctx, &args)
+	err := pipeline.Execute(ctx, &args)
+	if err != nil {
+		return err
+	}
+	si.detachOperationEvent(ctx, unbindArgs.Event)
+	return nil
 }
 
 type UnbindAppArgs struct {
@@ -324,7 +418,66 @@ func (si *ServiceInstance) UnbindApp(ctx context.Context, unbindArgs UnbindAppAr
 		&removeBoundEnvs,
 	}
 	pipeline := action.NewPipeline(actions...)
-	return pipeline.Execute(ctx, &args)
+	err := pipeline.Execute(ctx, &args)
+	if err != nil {
+		return err
+	}
+	si.detachOperationEvent(ctx, unbindArgs.Event)
+	return nil
+}
+
+// RotateAppCredentials replaces the credentials of an app bound to the
+// service instance. New credentials are requested from the service and set
+// in the app, which is restarted before the previous credentials are
+// revoked. If the restart fails the app goes back to the previous
+// credentials and the new ones are revoked. With force, previous credentials
+// left by a rotation that didn't finish are revoked first.
+func (si *ServiceInstance) RotateAppCredentials(ctx context.Context, app bind.App, force bool, evt *event.Event, requestID string) error {
+	if si.FindApp(app.GetName()) == -1 {
+		return ErrAppNotBound
+	}
+	if force {
+		err := si.revokeStaleAppCredentials(ctx, app, evt, requestID)
+		if err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(evt, "---- Rotating credentials of app %q in service instance %q ----\n", app.GetName(), si.Name)
+	args := rotateAppPipelineArgs{
+		serviceInstance: si,
+		app:             app,
+		writer:          evt,
+		event:           evt,
+		requestID:       requestID,
+	}
+	actions := []*action.Action{
+		rotateAppCredentialsEndpoint,
+		replaceBoundEnvs,
+		revokePreviousCredentials,
+	}
+	return action.NewPipeline(actions...).Execute(ctx, &args)
+}
+
+// revokeStaleAppCredentials revokes the previous credentials kept by a
+// rotation of the app whose revoke step failed, which would otherwise block
+// further rotations.
+func (si *ServiceInstance) revokeStaleAppCredentials(ctx context.Context, app bind.App, evt *event.Event, requestID string) error {
+	if si.BrokerData == nil {
+		return nil
+	}
+	if current, ok := si.BrokerData.Binds[app.GetName()]; !ok || current.PreviousUUID == "" {
+		return nil
+	}
+	s, err := Get(ctx, si.ServiceName)
+	if err != nil {
+		return err
+	}
+	endpoint, err := s.getClientForPool(ctx, si.Pool)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(evt, "---- Revoking previous credentials left by an unfinished rotation of app %q ----\n", app.GetName())
+	return endpoint.RevokeAppCredentials(ctx, si, app, PreviousCredentials, evt, requestID)
 }
 
 // Status returns the service instance status.
@@ -426,7 +579,12 @@ func CreateServiceInstance(ctx context.Context, instance ServiceInstance, servic
 	instance.Tags = processTags(instance.Tags)
 	actions := []*action.Action{&notifyCreateServiceInstance, &createServiceInstance}
 	pipeline := action.NewPipeline(actions...)
-	return pipeline.Execute(ctx, *service, &instance, evt, requestID)
+	err = pipeline.Execute(ctx, *service, &instance, evt, requestID)
+	if err != nil {
+		return err
+	}
+	instance.detachOperationEvent(ctx, evt)
+	return nil
 }
 
 func GetServiceInstancesByServices(ctx context.Context, services []Service, tags []string) ([]ServiceInstance, error) {
@@ -601,7 +759,8 @@ func ProxyInstance(ctx context.Context, instance *ServiceInstance, path string,
 	prefix := fmt.Sprintf("/resources/%s/", instance.GetIdentifier())
 	path = strings.Trim(strings.TrimPrefix(path+"/", prefix), "/")
 	for _, reserved := range reservedProxyPaths {
-		if path == reserved && r.Method != "GET" {
+		isReserved := path == reserved || strings.HasPrefix(path, reserved+"/")
+		if isReserved && r.Method != "GET" {
 			return &tsuruErrors.ValidationError{
 				Message: fmt.Sprintf("proxy request %s %q is forbidden", r.Method, path),
 			}
@@ -211,6 +211,80 @@ func (s *InstanceSuite) TestBindApp(c *check.C) {
 	c.Assert(buf.String(), check.Equals, "")
 }
 
+func (s *InstanceSuite) createRotationService(c *check.C, ts *httptest.Server) (*ServiceInstance, *provisiontest.FakeApp) {
+	err := Create(context.TODO(), Service{
+		Name:       "mysql",
+		Password:   "password",
+		OwnerTeams: []string{s.team.Name},
+		Endpoint:   map[string]string{"production": ts.URL},
+	})
+	c.Assert(err, check.IsNil)
+	si := &ServiceInstance{Name: "my-mysql", ServiceName: "mysql", Apps: []string{"myapp"}}
+	a := provisiontest.NewFakeApp("myapp", "python", 1)
+	err = a.AddInstance(context.TODO(), bind.AddInstanceArgs{
+		Envs: boundEnvs(si, map[string]string{"DATABASE_PASSWORD": "old"}),
+	})
+	c.Assert(err, check.IsNil)
+	return si, a
+}
+
+func (s *InstanceSuite) TestRotateAppCredentials(c *check.C) {
+	var calls []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		calls = append(calls, r.Method+" "+r.URL.Path+" "+r.Form.Get("credentials"))
+		if r.Method == http.MethodPost {
+			w.Write([]byte(`{"DATABASE_PASSWORD": "new"}`))
+		}
+	}))
+	defer ts.Close()
+	si, a := s.createRotationService(c, ts)
+	evt := createEvt(c)
+	err := si.RotateAppCredentials(context.TODO(), a, false, evt, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(calls, check.DeepEquals, []string{
+		"POST /resources/my-mysql/bind-app/rotate ",
+		"DELETE /resources/my-mysql/bind-app/rotate previous",
+	})
+	c.Assert(a.InstanceEnvs("mysql", "my-mysql"), check.DeepEquals, map[string]bindTypes.EnvVar{
+		"DATABASE_PASSWORD": {Name: "DATABASE_PASSWORD", Value: "new"},
+	})
+}
+
+func (s *InstanceSuite) TestRotateAppCredentialsRollback(c *check.C) {
+	var calls []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		calls = append(calls, r.Method+" "+r.URL.Path+" "+r.Form.Get("credentials"))
+		switch {
+		case r.Method == http.MethodPost:
+			w.Write([]byte(`{"DATABASE_PASSWORD": "new"}`))
+		case r.Form.Get("credentials") == "previous":
+			http.Error(w, "revoke failed", http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+	si, a := s.createRotationService(c, ts)
+	evt := createEvt(c)
+	err := si.RotateAppCredentials(context.TODO(), a, false, evt, "")
+	c.Assert(err, check.ErrorMatches, `(?s).*revoke failed.*`)
+	c.Assert(calls, check.DeepEquals, []string{
+		"POST /resources/my-mysql/bind-app/rotate ",
+		"DELETE /resources/my-mysql/bind-app/rotate previous",
+		"DELETE /resources/my-mysql/bind-app/rotate rotated",
+	})
+	c.Assert(a.InstanceEnvs("mysql", "my-mysql"), check.DeepEquals, map[string]bindTypes.EnvVar{
+		"DATABASE_PASSWORD": {Name: "DATABASE_PASSWORD", Value: "old"},
+	})
+}
+
+func (s *InstanceSuite) TestRotateAppCredentialsAppNotBound(c *check.C) {
+	si := &ServiceInstance{Name: "my-mysql", ServiceName: "mysql"}
+	a := provisiontest.NewFakeApp("myapp", "python", 1)
+	err := si.RotateAppCredentials(context.TODO(), a, false, createEvt(c), "")
+	c.Assert(err, check.Equals, ErrAppNotBound)
+}
+
 func (s *InstanceSuite) TestGetServiceInstancesBoundToApp(c *check.C) {
 	srvc := Service{Name: "mysql"}
 	servicesCollection, err := storagev2.ServicesCollection()
@@ -1795,7 +1869,12 @@ func (s *S) TestProxyInstance(c *check.C) {
 		{method: "POST", path: "", err: "proxy request POST \"\" is forbidden"},
 		{method: "POST", path: "bind-app", err: "proxy request POST \"bind-app\" is forbidden"},
 		{method: "POST", path: "/bind-app", err: "proxy request POST \"bind-app\" is forbidden"},
+		{method: "POST", path: "bind-app/rotate", err: "proxy request POST \"bind-app/rotate\" is forbidden"},
+		{method: "POST", path: "backups/x/restore", err: "proxy request POST \"backups/x/restore\" is forbidden"},
+		{method: "POST", path: "/resources/noflow/backups/x/restore", err: "proxy request POST \"backups/x/restore\" is forbidden"},
 		{method: "GET", path: "/bind-app", expectedPath: "/resources/noflow/bind-app"},
+		{method: "GET", path: "backups/x", expectedPath: "/resources/noflow/backups/x"},
+		{method: "POST", path: "backupsx", expectedPath: "/resources/noflow/backupsx"},
 		{method: "GET", path: "/resources/noflow/bind-app", expectedPath: "/resources/noflow/bind-app"},
 		{method: "POST", path: "/resources/noflow/otherpath", expectedPath: "/resources/noflow/otherpath"},
 		{method: "POST", path: "/resources/otherinstance/otherpath", expectedPath: "/resources/noflow/resources/otherinstance/otherpath"},
@@ -29,6 +29,7 @@ type MockService struct {
 	UserQuota                 *quota.MockQuotaService
 	AppQuota                  *quota.MockQuotaService
 	TeamQuota                 *quota.MockQuotaService
+	ResourceQuota             *quota.MockResourceQuotaService
 	Cluster                   *provision.MockClusterService
 	ServiceBroker             *service.MockServiceBrokerService
 	ServiceBrokerCatalogCache *service.MockServiceBrokerCatalogCacheService
@@ -38,6 +39,7 @@ type MockService struct {
 	Pool                      *provision.MockPoolService
 	VolumeService             *volume.MockVolumeService
 	JobService                *job.MockJobService
+	JobRunService             *job.MockJobRunService
 }
 
 // SetMockService return a new MockService and set as a servicemanager
@@ -50,6 +52,7 @@ func SetMockService(m *MockService) {
 	m.UserQuota = &quota.MockQuotaService{}
 	m.AppQuota = &quota.MockQuotaService{}
 	m.TeamQuota = &quota.MockQuotaService{}
+	m.ResourceQuota = &quota.MockResourceQuotaService{}
 	m.Cluster = &provision.MockClusterService{}
 	m.ServiceBroker = &service.MockServiceBrokerService{}
 	m.ServiceBrokerCatalogCache = &service.MockServiceBrokerCatalogCacheService{}
@@ -61,6 +64,7 @@ func SetMockService(m *MockService) {
 		Storage: volume.MockVolumeStorage{},
 	}
 	m.JobService = &job.MockJobService{}
+	m.JobRunService = &job.MockJobRunService{}
 
 	servicemanager.AppCache = m.Cache
 	servicemanager.Plan = m.Plan
@@ -70,6 +74,7 @@ func SetMockService(m *MockService) {
 	servicemanager.UserQuota = m.UserQuota
 	servicemanager.AppQuota = m.AppQuota
 	servicemanager.TeamQuota = m.TeamQuota
+	servicemanager.ResourceQuota = m.ResourceQuota
 	servicemanager.Cluster = m.Cluster
 	servicemanager.ServiceBroker = m.ServiceBroker
 	servicemanager.ServiceBrokerCatalogCache = m.ServiceBrokerCatalogCache
@@ -79,6 +84,7 @@ func SetMockService(m *MockService) {
 	servicemanager.Pool = m.Pool
 	servicemanager.Volume = m.VolumeService
 	servicemanager.Job = m.JobService
+	servicemanager.JobRun = m.JobRunService
 }
 
 func (m *MockService) ResetCache() {
@@ -29,14 +29,17 @@ var (
 	Team                      auth.TeamService
 	TeamToken                 auth.TeamTokenService
 	Job                       job.JobService
+	JobRun                    job.JobRunService
 	Webhook                   event.WebhookService
 	AppQuota                  quota.QuotaService
 	UserQuota                 quota.QuotaService
 	TeamQuota                 quota.QuotaService
+	ResourceQuota             quota.ResourceQuotaService
 	Cluster                   provision.ClusterService
 	ServiceBroker             service.ServiceBrokerService
 	ServiceBrokerCatalogCache service.ServiceBrokerCatalogCacheService
 	LogService                app.AppLogService
+	LogDrain                  app.LogDrainService
 	InstanceTracker           tracker.InstanceService
 	AppVersion                app.AppVersionService
 	DynamicRouter             router.DynamicRouterService
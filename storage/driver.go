@@ -31,7 +31,9 @@ type DbDriver struct {
 	UserQuotaStorage                 quota.QuotaStorage
 	AppQuotaStorage                  quota.QuotaStorage
 	TeamQuotaStorage                 quota.QuotaStorage
+	ResourceQuotaStorage             quota.ResourceQuotaStorage
 	WebhookStorage                   event.WebhookStorage
+	WebhookDeliveryStorage           event.WebhookDeliveryStorage
 	ClusterStorage                   provision.ClusterStorage
 	ServiceBrokerStorage             service.ServiceBrokerStorage
 	ServiceBrokerCatalogCacheStorage cache.CacheStorage
@@ -42,6 +44,7 @@ type DbDriver struct {
 	AuthGroupStorage                 auth.GroupStorage
 	PoolStorage                      provision.PoolStorage
 	VolumeStorage                    volume.VolumeStorage
+	LogDrainStorage                  app.LogDrainStorage
 }
 
 var (
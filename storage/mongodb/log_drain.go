@@ -0,0 +1,62 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mongodb
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type logDrainStorage struct{}
+
+var _ appTypes.LogDrainStorage = &logDrainStorage{}
+
+func (s *logDrainStorage) Insert(ctx context.Context, drain appTypes.LogDrain) error {
+	collection, err := storagev2.LogDrainsCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.InsertOne(ctx, drain)
+	if err != nil && mongo.IsDuplicateKeyError(err) {
+		err = appTypes.ErrLogDrainAlreadyExists
+	}
+	return err
+}
+
+func (s *logDrainStorage) Delete(ctx context.Context, appName, name string) error {
+	collection, err := storagev2.LogDrainsCollection()
+	if err != nil {
+		return err
+	}
+	result, err := collection.DeleteOne(ctx, mongoBSON.M{"app": appName, "name": name})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return appTypes.ErrLogDrainNotFound
+	}
+	return nil
+}
+
+func (s *logDrainStorage) FindByApp(ctx context.Context, appName string) ([]appTypes.LogDrain, error) {
+	collection, err := storagev2.LogDrainsCollection()
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := collection.Find(ctx, mongoBSON.M{"app": appName})
+	if err != nil {
+		return nil, err
+	}
+	var drains []appTypes.LogDrain
+	err = cursor.All(ctx, &drains)
+	if err != nil {
+		return nil, err
+	}
+	return drains, nil
+}
@@ -19,7 +19,9 @@ func init() {
 		UserQuotaStorage:                 authQuotaStorage(),
 		AppQuotaStorage:                  appQuotaStorage(),
 		TeamQuotaStorage:                 teamQuotaStorage(),
+		ResourceQuotaStorage:             &resourceQuotaStorage{},
 		WebhookStorage:                   &webhookStorage{},
+		WebhookDeliveryStorage:           &webhookDeliveryStorage{},
 		ClusterStorage:                   &clusterStorage{},
 		ServiceBrokerStorage:             &serviceBrokerStorage{},
 		ServiceBrokerCatalogCacheStorage: serviceBrokerCatalogCacheStorage(),
@@ -29,6 +31,7 @@ func init() {
 		AuthGroupStorage:                 &authGroupStorage{},
 		PoolStorage:                      &PoolStorage{},
 		VolumeStorage:                    &volumeStorage{},
+		LogDrainStorage:                  &logDrainStorage{},
 	}
 	storage.RegisterDbDriver("mongodb", mongodbDriver)
 }
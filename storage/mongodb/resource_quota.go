@@ -0,0 +1,76 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mongodb
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/types/quota"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type resourceQuotaStorage struct{}
+
+var _ quota.ResourceQuotaStorage = &resourceQuotaStorage{}
+
+func (s *resourceQuotaStorage) Upsert(ctx context.Context, q quota.ResourceQuota) error {
+	collection, err := storagev2.ResourceQuotasCollection()
+	if err != nil {
+		return err
+	}
+	_, err = collection.ReplaceOne(ctx, mongoBSON.M{"team": q.Team, "pool": q.Pool}, q, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (s *resourceQuotaStorage) Get(ctx context.Context, team, pool string) (*quota.ResourceQuota, error) {
+	collection, err := storagev2.ResourceQuotasCollection()
+	if err != nil {
+		return nil, err
+	}
+	var q quota.ResourceQuota
+	err = collection.FindOne(ctx, mongoBSON.M{"team": team, "pool": pool}).Decode(&q)
+	if err == mongo.ErrNoDocuments {
+		return nil, quota.ErrResourceQuotaNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+func (s *resourceQuotaStorage) FindByTeam(ctx context.Context, team string) ([]quota.ResourceQuota, error) {
+	collection, err := storagev2.ResourceQuotasCollection()
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := collection.Find(ctx, mongoBSON.M{"team": team}, options.Find().SetSort(mongoBSON.D{{Key: "pool", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	var quotas []quota.ResourceQuota
+	err = cursor.All(ctx, &quotas)
+	if err != nil {
+		return nil, err
+	}
+	return quotas, nil
+}
+
+func (s *resourceQuotaStorage) Delete(ctx context.Context, team, pool string) error {
+	collection, err := storagev2.ResourceQuotasCollection()
+	if err != nil {
+		return err
+	}
+	result, err := collection.DeleteOne(ctx, mongoBSON.M{"team": team, "pool": pool})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return quota.ErrResourceQuotaNotFound
+	}
+	return nil
+}
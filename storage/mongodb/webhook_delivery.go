@@ -0,0 +1,120 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/db/storagev2"
+	"github.com/tsuru/tsuru/types/event"
+	mongoBSON "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type webhookDeliveryStorage struct{}
+
+var _ event.WebhookDeliveryStorage = &webhookDeliveryStorage{}
+
+func (s *webhookDeliveryStorage) Insert(ctx context.Context, d *event.WebhookDelivery) error {
+	collection, err := storagev2.WebhookDeliveriesCollection()
+	if err != nil {
+		return err
+	}
+	if d.ID.IsZero() {
+		d.ID = primitive.NewObjectID()
+	}
+	_, err = collection.InsertOne(ctx, d)
+	return err
+}
+
+func (s *webhookDeliveryStorage) Update(ctx context.Context, d *event.WebhookDelivery) error {
+	collection, err := storagev2.WebhookDeliveriesCollection()
+	if err != nil {
+		return err
+	}
+	result, err := collection.ReplaceOne(ctx, mongoBSON.M{"_id": d.ID}, d)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return event.ErrWebhookDeliveryNotFound
+	}
+	return nil
+}
+
+func (s *webhookDeliveryStorage) FindByID(ctx context.Context, id string) (*event.WebhookDelivery, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, event.ErrWebhookDeliveryNotFound
+	}
+	collection, err := storagev2.WebhookDeliveriesCollection()
+	if err != nil {
+		return nil, err
+	}
+	var result event.WebhookDelivery
+	err = collection.FindOne(ctx, mongoBSON.M{"_id": objID}).Decode(&result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = event.ErrWebhookDeliveryNotFound
+		}
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s *webhookDeliveryStorage) Find(ctx context.Context, f event.WebhookDeliveryFilter) ([]event.WebhookDelivery, error) {
+	collection, err := storagev2.WebhookDeliveriesCollection()
+	if err != nil {
+		return nil, err
+	}
+	query := mongoBSON.M{}
+	if f.Webhook != "" {
+		query["webhook"] = f.Webhook
+	}
+	if f.Status != "" {
+		query["status"] = f.Status
+	}
+	opts := options.Find().SetSort(mongoBSON.D{{Key: "_id", Value: -1}})
+	if f.Limit > 0 {
+		opts.SetLimit(int64(f.Limit))
+	}
+	cursor, err := collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	var deliveries []event.WebhookDelivery
+	err = cursor.All(ctx, &deliveries)
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (s *webhookDeliveryStorage) ClaimPending(ctx context.Context, now time.Time, lease time.Duration) (*event.WebhookDelivery, error) {
+	collection, err := storagev2.WebhookDeliveriesCollection()
+	if err != nil {
+		return nil, err
+	}
+	query := mongoBSON.M{
+		"status":      event.WebhookDeliveryPending,
+		"nextattempt": mongoBSON.M{"$lte": now},
+	}
+	update := mongoBSON.M{"$set": mongoBSON.M{"nextattempt": now.Add(lease)}}
+	opts := options.FindOneAndUpdate().
+		SetSort(mongoBSON.D{{Key: "nextattempt", Value: 1}}).
+		SetReturnDocument(options.After)
+	var result event.WebhookDelivery
+	err = collection.FindOneAndUpdate(ctx, query, update, opts).Decode(&result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = event.ErrWebhookDeliveryNotFound
+		}
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storagetest
+
+import (
+	"context"
+	"net/http"
+
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+type LogDrainSuite struct {
+	SuiteHooks
+	LogDrainStorage appTypes.LogDrainStorage
+}
+
+func (s *LogDrainSuite) TestInsertLogDrain(c *check.C) {
+	drain := appTypes.LogDrain{
+		Name:    "papertrail",
+		App:     "myapp",
+		Type:    appTypes.LogDrainTypeSyslog,
+		URL:     "tls://logs.example.com:6514",
+		Headers: http.Header{},
+	}
+	err := s.LogDrainStorage.Insert(context.TODO(), drain)
+	c.Assert(err, check.IsNil)
+	drains, err := s.LogDrainStorage.FindByApp(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(drains, check.DeepEquals, []appTypes.LogDrain{drain})
+}
+
+func (s *LogDrainSuite) TestInsertDuplicateLogDrain(c *check.C) {
+	drain := appTypes.LogDrain{Name: "papertrail", App: "myapp", Type: appTypes.LogDrainTypeHTTP, URL: "https://logs.example.com"}
+	err := s.LogDrainStorage.Insert(context.TODO(), drain)
+	c.Assert(err, check.IsNil)
+	err = s.LogDrainStorage.Insert(context.TODO(), drain)
+	c.Assert(err, check.Equals, appTypes.ErrLogDrainAlreadyExists)
+	drain.App = "otherapp"
+	err = s.LogDrainStorage.Insert(context.TODO(), drain)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *LogDrainSuite) TestFindByApp(c *check.C) {
+	for _, d := range []appTypes.LogDrain{
+		{Name: "d1", App: "myapp", Type: appTypes.LogDrainTypeHTTP, URL: "https://a.example.com"},
+		{Name: "d2", App: "myapp", Type: appTypes.LogDrainTypeOTLP, URL: "https://b.example.com/v1/logs"},
+		{Name: "d1", App: "otherapp", Type: appTypes.LogDrainTypeHTTP, URL: "https://c.example.com"},
+	} {
+		err := s.LogDrainStorage.Insert(context.TODO(), d)
+		c.Assert(err, check.IsNil)
+	}
+	drains, err := s.LogDrainStorage.FindByApp(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(drains, check.HasLen, 2)
+	drains, err = s.LogDrainStorage.FindByApp(context.TODO(), "noapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(drains, check.HasLen, 0)
+}
+
+func (s *LogDrainSuite) TestDeleteLogDrain(c *check.C) {
+	err := s.LogDrainStorage.Insert(context.TODO(), appTypes.LogDrain{Name: "d1", App: "myapp", Type: appTypes.LogDrainTypeHTTP, URL: "https://a.example.com"})
+	c.Assert(err, check.IsNil)
+	err = s.LogDrainStorage.Delete(context.TODO(), "otherapp", "d1")
+	c.Assert(err, check.Equals, appTypes.ErrLogDrainNotFound)
+	err = s.LogDrainStorage.Delete(context.TODO(), "myapp", "d1")
+	c.Assert(err, check.IsNil)
+	drains, err := s.LogDrainStorage.FindByApp(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(drains, check.HasLen, 0)
+}
@@ -0,0 +1,61 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storagetest
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/types/quota"
+	check "gopkg.in/check.v1"
+)
+
+type ResourceQuotaSuite struct {
+	SuiteHooks
+	ResourceQuotaStorage quota.ResourceQuotaStorage
+}
+
+func (s *ResourceQuotaSuite) TestUpsertResourceQuota(c *check.C) {
+	q := quota.ResourceQuota{Team: "myteam", Pool: "mypool", Limits: quota.ResourceList{MilliCPU: 1000, Memory: 1024, Storage: -1}}
+	err := s.ResourceQuotaStorage.Upsert(context.TODO(), q)
+	c.Assert(err, check.IsNil)
+	dbQuota, err := s.ResourceQuotaStorage.Get(context.TODO(), "myteam", "mypool")
+	c.Assert(err, check.IsNil)
+	c.Assert(*dbQuota, check.DeepEquals, q)
+	q.Limits.MilliCPU = 2000
+	err = s.ResourceQuotaStorage.Upsert(context.TODO(), q)
+	c.Assert(err, check.IsNil)
+	dbQuota, err = s.ResourceQuotaStorage.Get(context.TODO(), "myteam", "mypool")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbQuota.Limits.MilliCPU, check.Equals, int64(2000))
+}
+
+func (s *ResourceQuotaSuite) TestGetResourceQuotaNotFound(c *check.C) {
+	_, err := s.ResourceQuotaStorage.Get(context.TODO(), "myteam", "mypool")
+	c.Assert(err, check.Equals, quota.ErrResourceQuotaNotFound)
+}
+
+func (s *ResourceQuotaSuite) TestFindResourceQuotasByTeam(c *check.C) {
+	quotas := []quota.ResourceQuota{
+		{Team: "myteam", Limits: quota.ResourceList{MilliCPU: 4000, Memory: -1, Storage: -1}},
+		{Team: "myteam", Pool: "mypool", Limits: quota.ResourceList{MilliCPU: 1000, Memory: -1, Storage: -1}},
+		{Team: "otherteam", Limits: quota.ResourceList{MilliCPU: 1000, Memory: -1, Storage: -1}},
+	}
+	for _, q := range quotas {
+		err := s.ResourceQuotaStorage.Upsert(context.TODO(), q)
+		c.Assert(err, check.IsNil)
+	}
+	result, err := s.ResourceQuotaStorage.FindByTeam(context.TODO(), "myteam")
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, quotas[:2])
+}
+
+func (s *ResourceQuotaSuite) TestDeleteResourceQuota(c *check.C) {
+	err := s.ResourceQuotaStorage.Upsert(context.TODO(), quota.ResourceQuota{Team: "myteam", Pool: "mypool"})
+	c.Assert(err, check.IsNil)
+	err = s.ResourceQuotaStorage.Delete(context.TODO(), "myteam", "mypool")
+	c.Assert(err, check.IsNil)
+	err = s.ResourceQuotaStorage.Delete(context.TODO(), "myteam", "mypool")
+	c.Assert(err, check.Equals, quota.ErrResourceQuotaNotFound)
+}
@@ -0,0 +1,117 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storagetest
+
+import (
+	"context"
+	"time"
+
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	check "gopkg.in/check.v1"
+)
+
+type WebhookDeliverySuite struct {
+	SuiteHooks
+	WebhookDeliveryStorage eventTypes.WebhookDeliveryStorage
+}
+
+func (s *WebhookDeliverySuite) TestInsertWebhookDelivery(c *check.C) {
+	d := eventTypes.WebhookDelivery{
+		Webhook: "wh1",
+		EventID: "abc",
+		Status:  eventTypes.WebhookDeliverySuccess,
+		Attempts: []eventTypes.WebhookDeliveryAttempt{
+			{StatusCode: 200, Latency: time.Second, Response: "ok"},
+		},
+	}
+	err := s.WebhookDeliveryStorage.Insert(context.TODO(), &d)
+	c.Assert(err, check.IsNil)
+	c.Assert(d.ID.IsZero(), check.Equals, false)
+	result, err := s.WebhookDeliveryStorage.FindByID(context.TODO(), d.ID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(result.ID, check.Equals, d.ID)
+	c.Assert(result.Webhook, check.Equals, "wh1")
+	c.Assert(result.EventID, check.Equals, "abc")
+	c.Assert(result.Status, check.Equals, eventTypes.WebhookDeliverySuccess)
+	c.Assert(result.Attempts, check.HasLen, 1)
+	c.Assert(result.Attempts[0].StatusCode, check.Equals, 200)
+	c.Assert(result.Attempts[0].Latency, check.Equals, time.Second)
+	c.Assert(result.Attempts[0].Response, check.Equals, "ok")
+}
+
+func (s *WebhookDeliverySuite) TestFindByIDNotFound(c *check.C) {
+	_, err := s.WebhookDeliveryStorage.FindByID(context.TODO(), "5c5b1a8f2f1e4b0001000000")
+	c.Assert(err, check.Equals, eventTypes.ErrWebhookDeliveryNotFound)
+	_, err = s.WebhookDeliveryStorage.FindByID(context.TODO(), "invalid")
+	c.Assert(err, check.Equals, eventTypes.ErrWebhookDeliveryNotFound)
+}
+
+func (s *WebhookDeliverySuite) TestUpdateWebhookDelivery(c *check.C) {
+	d := eventTypes.WebhookDelivery{Webhook: "wh1", EventID: "abc", Status: eventTypes.WebhookDeliveryPending}
+	err := s.WebhookDeliveryStorage.Insert(context.TODO(), &d)
+	c.Assert(err, check.IsNil)
+	d.Status = eventTypes.WebhookDeliveryDeadLetter
+	err = s.WebhookDeliveryStorage.Update(context.TODO(), &d)
+	c.Assert(err, check.IsNil)
+	result, err := s.WebhookDeliveryStorage.FindByID(context.TODO(), d.ID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Status, check.Equals, eventTypes.WebhookDeliveryDeadLetter)
+}
+
+func (s *WebhookDeliverySuite) TestUpdateWebhookDeliveryNotFound(c *check.C) {
+	d := eventTypes.WebhookDelivery{Webhook: "wh1"}
+	err := s.WebhookDeliveryStorage.Insert(context.TODO(), &d)
+	c.Assert(err, check.IsNil)
+	other := eventTypes.WebhookDelivery{ID: d.ID}
+	other.ID[0]++
+	err = s.WebhookDeliveryStorage.Update(context.TODO(), &other)
+	c.Assert(err, check.Equals, eventTypes.ErrWebhookDeliveryNotFound)
+}
+
+func (s *WebhookDeliverySuite) TestFindWebhookDeliveries(c *check.C) {
+	for i, d := range []eventTypes.WebhookDelivery{
+		{Webhook: "wh1", EventID: "1", Status: eventTypes.WebhookDeliverySuccess},
+		{Webhook: "wh1", EventID: "2", Status: eventTypes.WebhookDeliveryDeadLetter},
+		{Webhook: "wh2", EventID: "3", Status: eventTypes.WebhookDeliverySuccess},
+		{Webhook: "wh1", EventID: "4", Status: eventTypes.WebhookDeliverySuccess},
+	} {
+		err := s.WebhookDeliveryStorage.Insert(context.TODO(), &d)
+		c.Assert(err, check.IsNil, check.Commentf("delivery %d", i))
+	}
+	eventIDs := func(deliveries []eventTypes.WebhookDelivery) []string {
+		var ids []string
+		for _, d := range deliveries {
+			ids = append(ids, d.EventID)
+		}
+		return ids
+	}
+	deliveries, err := s.WebhookDeliveryStorage.Find(context.TODO(), eventTypes.WebhookDeliveryFilter{Webhook: "wh1"})
+	c.Assert(err, check.IsNil)
+	c.Assert(eventIDs(deliveries), check.DeepEquals, []string{"4", "2", "1"})
+	deliveries, err = s.WebhookDeliveryStorage.Find(context.TODO(), eventTypes.WebhookDeliveryFilter{Webhook: "wh1", Limit: 1})
+	c.Assert(err, check.IsNil)
+	c.Assert(eventIDs(deliveries), check.DeepEquals, []string{"4"})
+	deliveries, err = s.WebhookDeliveryStorage.Find(context.TODO(), eventTypes.WebhookDeliveryFilter{Status: eventTypes.WebhookDeliveryDeadLetter})
+	c.Assert(err, check.IsNil)
+	c.Assert(eventIDs(deliveries), check.DeepEquals, []string{"2"})
+}
+
+func (s *WebhookDeliverySuite) TestClaimPending(c *check.C) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	for _, d := range []eventTypes.WebhookDelivery{
+		{Webhook: "wh1", EventID: "future", Status: eventTypes.WebhookDeliveryPending, NextAttempt: now.Add(time.Hour)},
+		{Webhook: "wh1", EventID: "due", Status: eventTypes.WebhookDeliveryPending, NextAttempt: now.Add(-time.Minute)},
+		{Webhook: "wh1", EventID: "dead", Status: eventTypes.WebhookDeliveryDeadLetter, NextAttempt: now.Add(-time.Minute)},
+	} {
+		err := s.WebhookDeliveryStorage.Insert(context.TODO(), &d)
+		c.Assert(err, check.IsNil)
+	}
+	d, err := s.WebhookDeliveryStorage.ClaimPending(context.TODO(), now, time.Minute)
+	c.Assert(err, check.IsNil)
+	c.Assert(d.EventID, check.Equals, "due")
+	c.Assert(d.NextAttempt.Equal(now.Add(time.Minute)), check.Equals, true)
+	_, err = s.WebhookDeliveryStorage.ClaimPending(context.TODO(), now, time.Minute)
+	c.Assert(err, check.Equals, eventTypes.ErrWebhookDeliveryNotFound)
+}
@@ -0,0 +1,39 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import "time"
+
+type ManagedCertificateStatus string
+
+const (
+	ManagedCertificatePending = ManagedCertificateStatus("pending")
+	ManagedCertificateIssued  = ManagedCertificateStatus("issued")
+	ManagedCertificateFailed  = ManagedCertificateStatus("failed")
+)
+
+// ManagedCertificate is the state of a certificate obtained and renewed by
+// tsuru through ACME for an app cname.
+type ManagedCertificate struct {
+	App         string                   `json:"-"`
+	CName       string                   `json:"cname"`
+	Status      ManagedCertificateStatus `json:"status"`
+	NotAfter    time.Time                `json:"notAfter"`
+	RenewedAt   time.Time                `json:"renewedAt"`
+	NextAttempt time.Time                `json:"nextAttempt"`
+	Error       string                   `json:"error,omitempty"`
+
+	// AlertedNotAfter is the expiration date of the last certificate which
+	// triggered an expiration alert, used to alert only once per certificate.
+	AlertedNotAfter time.Time      `json:"-"`
+	Challenge       *ACMEChallenge `json:"-"`
+}
+
+// ACMEChallenge is a pending HTTP-01 challenge, answered by tsuru while the
+// certificate authority validates the cname.
+type ACMEChallenge struct {
+	Token            string
+	KeyAuthorization string
+}
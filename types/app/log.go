@@ -31,6 +31,18 @@ type ListLogArgs struct {
 	Units        []string
 	Limit        int
 	InvertSource bool
+	// Since and Until restrict the entries to a time range, zero values
+	// leave the range open.
+	Since time.Time
+	Until time.Time
+	// Message matches entries containing the given substring.
+	Message string
+	// MessageRegex matches entries whose message matches the regular
+	// expression.
+	MessageRegex string
+	// Fields matches structured (JSON) log lines by field, nested fields are
+	// addressed using dots, e.g. "http.status".
+	Fields map[string]string
 }
 
 // Applog represents a log entry.
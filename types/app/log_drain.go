@@ -0,0 +1,58 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+var (
+	ErrLogDrainAlreadyExists = errors.New("log drain already exists with the same name")
+	ErrLogDrainNotFound      = errors.New("log drain not found")
+)
+
+type LogDrainType string
+
+const (
+	// LogDrainTypeSyslog sends entries as RFC5424 messages over TCP or TLS,
+	// using octet counting framing.
+	LogDrainTypeSyslog = LogDrainType("syslog")
+	// LogDrainTypeHTTP sends batches of entries as a JSON array in a POST
+	// request.
+	LogDrainTypeHTTP = LogDrainType("http")
+	// LogDrainTypeOTLP sends batches of entries to an OpenTelemetry
+	// collector using OTLP/HTTP with JSON encoding.
+	LogDrainTypeOTLP = LogDrainType("otlp")
+)
+
+// LogDrain forwards the logs of an app to an external sink. Drains without
+// App are pool defaults, used by apps in the pool without drains of their own.
+type LogDrain struct {
+	Name     string       `json:"name" form:"name"`
+	App      string       `json:"app,omitempty" form:"-"`
+	Pool     string       `json:"pool,omitempty" form:"-"`
+	Type     LogDrainType `json:"type" form:"type"`
+	URL      string       `json:"url" form:"url"`
+	Headers  http.Header  `json:"headers,omitempty" form:"headers"`
+	Sources  []string     `json:"sources,omitempty" form:"sources"`
+	Insecure bool         `json:"insecure" form:"insecure"`
+}
+
+type LogDrainService interface {
+	Create(ctx context.Context, drain LogDrain) error
+	Delete(ctx context.Context, appName, name string) error
+	List(ctx context.Context, appName string) ([]LogDrain, error)
+	// Forward queues the entry to every drain of its app, it never blocks,
+	// entries are dropped when the drain queue is full.
+	Forward(entry *Applog)
+}
+
+type LogDrainStorage interface {
+	Insert(ctx context.Context, drain LogDrain) error
+	Delete(ctx context.Context, appName, name string) error
+	FindByApp(ctx context.Context, appName string) ([]LogDrain, error)
+}
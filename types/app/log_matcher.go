@@ -0,0 +1,113 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// LogMatcher checks log entries against the time range, message and field
+// filters in ListLogArgs. A nil LogMatcher matches every entry.
+type LogMatcher struct {
+	args  ListLogArgs
+	regex *regexp.Regexp
+}
+
+// NewLogMatcher returns a matcher for args, or nil when args has no time
+// range, message or field filters.
+func NewLogMatcher(args ListLogArgs) (*LogMatcher, error) {
+	if args.Since.IsZero() && args.Until.IsZero() && args.Message == "" && args.MessageRegex == "" && len(args.Fields) == 0 {
+		return nil, nil
+	}
+	m := &LogMatcher{args: args}
+	if args.MessageRegex != "" {
+		var err error
+		m.regex, err = regexp.Compile(args.MessageRegex)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid message regex")
+		}
+	}
+	return m, nil
+}
+
+// HasContentFilters returns whether the matcher filters entries by message
+// content, meaning the number of matching entries can't be known before
+// reading them.
+func (m *LogMatcher) HasContentFilters() bool {
+	return m != nil && (m.args.Message != "" || m.regex != nil || len(m.args.Fields) > 0)
+}
+
+func (m *LogMatcher) Match(entry *Applog) bool {
+	if m == nil {
+		return true
+	}
+	if !m.args.Since.IsZero() && entry.Date.Before(m.args.Since) {
+		return false
+	}
+	if !m.args.Until.IsZero() && entry.Date.After(m.args.Until) {
+		return false
+	}
+	if m.args.Message != "" && !strings.Contains(entry.Message, m.args.Message) {
+		return false
+	}
+	if m.regex != nil && !m.regex.MatchString(entry.Message) {
+		return false
+	}
+	if len(m.args.Fields) > 0 {
+		return matchLogFields(entry.Message, m.args.Fields)
+	}
+	return true
+}
+
+func matchLogFields(message string, fields map[string]string) bool {
+	message = strings.TrimSpace(message)
+	if !strings.HasPrefix(message, "{") {
+		return false
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(message), &data); err != nil {
+		return false
+	}
+	for key, expected := range fields {
+		value, ok := lookupLogField(data, key)
+		if !ok || logFieldString(value) != expected {
+			return false
+		}
+	}
+	return true
+}
+
+func lookupLogField(data map[string]interface{}, key string) (interface{}, bool) {
+	if value, ok := data[key]; ok {
+		return value, true
+	}
+	parts := strings.SplitN(key, ".", 2)
+	if len(parts) < 2 {
+		return nil, false
+	}
+	nested, ok := data[parts[0]].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	return lookupLogField(nested, parts[1])
+}
+
+func logFieldString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case nil:
+		return "null"
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+	return fmt.Sprint(value)
+}
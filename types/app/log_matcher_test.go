@@ -0,0 +1,54 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+func (s S) TestNewLogMatcherNoFilters(c *check.C) {
+	m, err := NewLogMatcher(ListLogArgs{Name: "myapp", Source: "web", Limit: 10})
+	c.Assert(err, check.IsNil)
+	c.Assert(m, check.IsNil)
+	c.Assert(m.Match(&Applog{Message: "anything"}), check.Equals, true)
+	c.Assert(m.HasContentFilters(), check.Equals, false)
+}
+
+func (s S) TestNewLogMatcherInvalidRegex(c *check.C) {
+	_, err := NewLogMatcher(ListLogArgs{MessageRegex: "(a"})
+	c.Assert(err, check.ErrorMatches, "invalid message regex: .*")
+}
+
+func (s S) TestLogMatcherMatch(c *check.C) {
+	base := time.Date(2026, 3, 1, 14, 0, 0, 0, time.UTC)
+	tests := []struct {
+		args     ListLogArgs
+		entry    Applog
+		expected bool
+	}{
+		{args: ListLogArgs{Since: base}, entry: Applog{Date: base.Add(time.Minute)}, expected: true},
+		{args: ListLogArgs{Since: base}, entry: Applog{Date: base.Add(-time.Minute)}, expected: false},
+		{args: ListLogArgs{Until: base}, entry: Applog{Date: base.Add(time.Minute)}, expected: false},
+		{args: ListLogArgs{Since: base, Until: base.Add(5 * time.Minute)}, entry: Applog{Date: base.Add(2 * time.Minute), Message: "request timeout"}, expected: true},
+		{args: ListLogArgs{Message: "timeout"}, entry: Applog{Message: "request timeout"}, expected: true},
+		{args: ListLogArgs{Message: "timeout"}, entry: Applog{Message: "request ok"}, expected: false},
+		{args: ListLogArgs{MessageRegex: `status=5\d\d`}, entry: Applog{Message: "GET / status=502"}, expected: true},
+		{args: ListLogArgs{MessageRegex: `status=5\d\d`}, entry: Applog{Message: "GET / status=200"}, expected: false},
+		{args: ListLogArgs{Fields: map[string]string{"level": "error"}}, entry: Applog{Message: `{"level": "error", "msg": "x"}`}, expected: true},
+		{args: ListLogArgs{Fields: map[string]string{"level": "error"}}, entry: Applog{Message: `{"level": "info"}`}, expected: false},
+		{args: ListLogArgs{Fields: map[string]string{"level": "error"}}, entry: Applog{Message: `level=error`}, expected: false},
+		{args: ListLogArgs{Fields: map[string]string{"http.status": "500", "ok": "false"}}, entry: Applog{Message: `{"http": {"status": 500}, "ok": false}`}, expected: true},
+		{args: ListLogArgs{Fields: map[string]string{"http.status": "500"}}, entry: Applog{Message: `{"http.status": "500"}`}, expected: true},
+		{args: ListLogArgs{Fields: map[string]string{"http.status": "500"}}, entry: Applog{Message: `{"http": {"status": 200}}`}, expected: false},
+	}
+	for i, tt := range tests {
+		m, err := NewLogMatcher(tt.args)
+		c.Assert(err, check.IsNil)
+		c.Check(m.HasContentFilters(), check.Equals, tt.args.Message != "" || tt.args.MessageRegex != "" || len(tt.args.Fields) > 0)
+		c.Check(m.Match(&tt.entry), check.Equals, tt.expected, check.Commentf("test %d", i))
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"github.com/tsuru/tsuru/types/provision"
+)
+
+// Manifest is the declarative desired state of an app. Only public
+// environment variables not managed by other systems are part of the
+// manifest, private and managed variables are never changed when applying it.
+type Manifest struct {
+	Name         string                    `json:"name"`
+	Description  string                    `json:"description,omitempty"`
+	Platform     string                    `json:"platform,omitempty"`
+	Pool         string                    `json:"pool,omitempty"`
+	TeamOwner    string                    `json:"teamOwner,omitempty"`
+	Plan         string                    `json:"plan,omitempty"`
+	Tags         []string                  `json:"tags,omitempty"`
+	Metadata     Metadata                  `json:"metadata"`
+	Processes    []Process                 `json:"processes,omitempty"`
+	Env          map[string]string         `json:"env,omitempty"`
+	CNames       []string                  `json:"cnames,omitempty"`
+	Routers      []ManifestRouter          `json:"routers,omitempty"`
+	Units        map[string]uint           `json:"units,omitempty"`
+	Autoscale    []provision.AutoScaleSpec `json:"autoscale,omitempty"`
+	ServiceBinds []ManifestServiceBind     `json:"serviceBinds,omitempty"`
+	VolumeBinds  []ManifestVolumeBind      `json:"volumeBinds,omitempty"`
+}
+
+type ManifestRouter struct {
+	Name string            `json:"name"`
+	Opts map[string]string `json:"opts,omitempty"`
+}
+
+type ManifestServiceBind struct {
+	Service  string `json:"service"`
+	Instance string `json:"instance"`
+}
+
+type ManifestVolumeBind struct {
+	Volume     string `json:"volume"`
+	MountPoint string `json:"mountPoint"`
+	ReadOnly   bool   `json:"readOnly,omitempty"`
+}
+
+const (
+	ManifestKindApp         = "app"
+	ManifestKindEnv         = "env"
+	ManifestKindCName       = "cname"
+	ManifestKindRouter      = "router"
+	ManifestKindServiceBind = "service-bind"
+	ManifestKindVolumeBind  = "volume-bind"
+	ManifestKindAutoscale   = "autoscale"
+	ManifestKindUnits       = "units"
+)
+
+type ManifestAction string
+
+const (
+	ManifestActionAdd    = ManifestAction("add")
+	ManifestActionUpdate = ManifestAction("update")
+	ManifestActionRemove = ManifestAction("remove")
+)
+
+// ManifestChange is a single step needed to converge an app to its manifest.
+type ManifestChange struct {
+	Kind   string         `json:"kind"`
+	Action ManifestAction `json:"action"`
+	Name   string         `json:"name"`
+	Detail string         `json:"detail,omitempty"`
+	// Fields holds the manifest keys changed by app updates, such as pool or
+	// teamOwner.
+	Fields []string `json:"fields,omitempty"`
+}
+
+type ManifestPlan struct {
+	App     string           `json:"app"`
+	DryRun  bool             `json:"dryRun"`
+	Changes []ManifestChange `json:"changes"`
+}
@@ -0,0 +1,38 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import "time"
+
+type AppSwapStatus string
+
+const (
+	AppSwapStatusSwapped  = AppSwapStatus("swapped")
+	AppSwapStatusReverted = AppSwapStatus("reverted")
+)
+
+// AppSwap is the record of a blue/green swap between two apps. The cnames
+// each app had before the swap are kept, and a swap with the swapped status
+// can be reverted.
+type AppSwap struct {
+	ID         string        `json:"id" bson:"_id"`
+	App1       string        `json:"app1"`
+	App2       string        `json:"app2"`
+	CNameOnly  bool          `json:"cnameOnly"`
+	App1CNames []string      `json:"app1CNames"`
+	App2CNames []string      `json:"app2CNames"`
+	Status     AppSwapStatus `json:"status"`
+	Owner      string        `json:"owner"`
+	SwappedAt  time.Time     `json:"swappedAt"`
+	RevertedAt time.Time     `json:"revertedAt"`
+}
+
+// Partner returns the name of the app swapped with appName.
+func (s *AppSwap) Partner(appName string) string {
+	if s.App1 == appName {
+		return s.App2
+	}
+	return s.App1
+}
@@ -51,6 +51,7 @@ type AppVersion interface {
 	String() string
 	ToggleEnabled(enabled bool, reason string) error
 	UpdatePastUnits(process string, replicas int) error
+	SetTrafficWeight(weight int) error
 }
 
 type AddVersionDataArgs struct {
@@ -86,6 +87,9 @@ type AppVersionInfo struct {
 	DeploySuccessful bool                   `json:"deploySuccessful"`
 	MarkedToRemoval  bool                   `json:"markedToRemoval"`
 	PastUnits        map[string]int         `json:"pastUnits"`
+	// TrafficWeight is the percentage of the app traffic sent to this
+	// version during a canary deploy, zero means no explicit weight.
+	TrafficWeight int `json:"trafficWeight"`
 }
 
 type NewVersionArgs struct {
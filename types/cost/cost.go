@@ -0,0 +1,75 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cost
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrPriceNotFound = errors.New("price not found")
+
+const (
+	GroupByTeam = "team"
+	GroupByApp  = "app"
+	GroupByPool = "pool"
+)
+
+// Price defines how much a pool charges for the resources reserved by app
+// units and volumes. Storage prices are defined per volume plan.
+type Price struct {
+	Pool           string             `json:"pool" bson:"_id"`
+	CPUCoreHour    float64            `json:"cpuCoreHour"`
+	MemoryGiBHour  float64            `json:"memoryGiBHour"`
+	StorageGiBHour map[string]float64 `json:"storageGiBHour,omitempty"`
+}
+
+// Usage holds the amount of resources reserved over time and their cost.
+type Usage struct {
+	UnitHours       float64 `json:"unitHours"`
+	CPUCoreHours    float64 `json:"cpuCoreHours"`
+	MemoryGiBHours  float64 `json:"memoryGiBHours"`
+	StorageGiBHours float64 `json:"storageGiBHours"`
+	Cost            float64 `json:"cost"`
+}
+
+func (u Usage) Add(o Usage) Usage {
+	return Usage{
+		UnitHours:       u.UnitHours + o.UnitHours,
+		CPUCoreHours:    u.CPUCoreHours + o.CPUCoreHours,
+		MemoryGiBHours:  u.MemoryGiBHours + o.MemoryGiBHours,
+		StorageGiBHours: u.StorageGiBHours + o.StorageGiBHours,
+		Cost:            u.Cost + o.Cost,
+	}
+}
+
+// Sample is the usage of an app or of a volume during a single sampling
+// interval ending at Time, priced with the pool prices at that moment.
+type Sample struct {
+	Time   time.Time
+	Team   string
+	Pool   string
+	App    string `bson:",omitempty"`
+	Volume string `bson:",omitempty"`
+	Usage  `bson:",inline"`
+}
+
+type ReportOpts struct {
+	Start   time.Time
+	End     time.Time
+	GroupBy []string
+	// Teams restricts the report to the usage of the given teams, all teams
+	// are included when it's nil.
+	Teams []string
+}
+
+// ReportEntry is the usage aggregated by the fields in the report GroupBy,
+// the remaining fields are left empty. Volume usage has no app.
+type ReportEntry struct {
+	Team string `json:"team,omitempty"`
+	App  string `json:"app,omitempty"`
+	Pool string `json:"pool,omitempty"`
+	Usage
+}
@@ -36,6 +36,7 @@ type EventData struct {
 	StructuredLog   []LogEntry `bson:",omitempty"`
 	CancelInfo      CancelInfo
 	Cancelable      bool
+	Approval        *ApprovalInfo `bson:",omitempty"`
 	Running         bool
 	Allowed         AllowedPermission
 	AllowedCancel   AllowedPermission
@@ -117,6 +118,24 @@ type CancelInfo struct {
 	Canceled  bool
 }
 
+type ApprovalStatus string
+
+const (
+	ApprovalPending  = ApprovalStatus("pending")
+	ApprovalApproved = ApprovalStatus("approved")
+	ApprovalRejected = ApprovalStatus("rejected")
+	ApprovalExpired  = ApprovalStatus("expired")
+)
+
+type ApprovalInfo struct {
+	Status       ApprovalStatus
+	Owner        string
+	Reason       string
+	StartTime    time.Time
+	Deadline     time.Time
+	DecisionTime time.Time `bson:",omitempty"`
+}
+
 type AllowedPermission struct {
 	Scheme   string
 	Contexts []permission.PermissionContext `bson:",omitempty"`
@@ -8,11 +8,16 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 var (
 	ErrWebhookAlreadyExists = errors.New("webhook already exists with the same name")
 	ErrWebhookNotFound      = errors.New("webhook not found")
+
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
 )
 
 type WebhookEventFilter struct {
@@ -33,8 +38,60 @@ type Webhook struct {
 	ProxyURL    string             `json:"proxy_url" form:"proxy_url"`
 	Headers     http.Header        `json:"headers" form:"headers"`
 	Method      string             `json:"method" form:"method"`
-	Body        string             `json:"body" form:"body"`
-	Insecure    bool               `json:"insecure" form:"insecure"`
+	// Body is rendered as a text/template with the EventInfo of the event.
+	Body string `json:"body" form:"body"`
+	// Preset selects a built-in body template, one of "slack", "teams" or
+	// "chat", it can't be used along with Body.
+	Preset   string `json:"preset,omitempty" form:"preset"`
+	Insecure bool   `json:"insecure" form:"insecure"`
+	// Secret, when set, is used to sign the request body, the signature is
+	// sent in the X-Tsuru-Signature header as "sha256=<hex encoded HMAC>".
+	Secret string `json:"secret,omitempty" form:"secret"`
+	// RemoveSecret clears the current secret on updates, it is never
+	// stored.
+	RemoveSecret bool `json:"remove_secret,omitempty" form:"remove_secret" bson:"-"`
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryPending deliveries are waiting for their next attempt.
+	WebhookDeliveryPending = WebhookDeliveryStatus("pending")
+	// WebhookDeliverySuccess deliveries had a successful attempt.
+	WebhookDeliverySuccess = WebhookDeliveryStatus("success")
+	// WebhookDeliveryDeadLetter deliveries failed every attempt and are no
+	// longer retried, they can still be manually redelivered.
+	WebhookDeliveryDeadLetter = WebhookDeliveryStatus("dead-letter")
+)
+
+// WebhookDeliveryAttempt records a single request made for a delivery.
+type WebhookDeliveryAttempt struct {
+	Date       time.Time     `json:"date"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Latency    time.Duration `json:"latency"`
+	Response   string        `json:"response,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	Manual     bool          `json:"manual,omitempty"`
+}
+
+// WebhookDelivery tracks the delivery of an event to a webhook, across
+// retries and manual redeliveries.
+type WebhookDelivery struct {
+	ID          primitive.ObjectID       `json:"id" bson:"_id,omitempty"`
+	Webhook     string                   `json:"webhook"`
+	EventID     string                   `json:"event_id"`
+	Status      WebhookDeliveryStatus    `json:"status"`
+	Attempts    []WebhookDeliveryAttempt `json:"attempts"`
+	NextAttempt time.Time                `json:"next_attempt,omitempty"`
+	CreatedAt   time.Time                `json:"created_at"`
+	UpdatedAt   time.Time                `json:"updated_at"`
+	ExpireAt    time.Time                `json:"-"`
+}
+
+type WebhookDeliveryFilter struct {
+	Webhook string
+	Status  WebhookDeliveryStatus
+	Limit   int
 }
 
 type WebhookService interface {
@@ -44,6 +101,8 @@ type WebhookService interface {
 	Delete(context.Context, string) error
 	Find(context.Context, string) (Webhook, error)
 	List(context.Context, []string) ([]Webhook, error)
+	Deliveries(context.Context, WebhookDeliveryFilter) ([]WebhookDelivery, error)
+	Redeliver(ctx context.Context, webhookName, deliveryID string) (*WebhookDelivery, error)
 }
 
 type WebhookStorage interface {
@@ -54,3 +113,14 @@ type WebhookStorage interface {
 	FindByEvent(ctx context.Context, f WebhookEventFilter, isSuccess bool) ([]Webhook, error)
 	Delete(context.Context, string) error
 }
+
+type WebhookDeliveryStorage interface {
+	Insert(context.Context, *WebhookDelivery) error
+	Update(context.Context, *WebhookDelivery) error
+	FindByID(context.Context, string) (*WebhookDelivery, error)
+	Find(context.Context, WebhookDeliveryFilter) ([]WebhookDelivery, error)
+	// ClaimPending returns a pending delivery due at the given time, moving
+	// its next attempt by the lease duration so that concurrent API
+	// instances do not retry the same delivery.
+	ClaimPending(ctx context.Context, now time.Time, lease time.Duration) (*WebhookDelivery, error)
+}
@@ -18,6 +18,7 @@ var (
 	ErrInvalidSchedule          = errors.New("invalid schedule")
 	ErrInvalidConcurrencyPolicy = errors.New("invalid concurrency policy, allowed values are: Allow, Forbid, Replace")
 	ErrInvalidDeployKind        = errors.New("invalid deploy kind")
+	ErrInvalidActiveDeadline    = errors.New("active deadline seconds must be greater than zero")
 )
 
 type JobCreationError struct {
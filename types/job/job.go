@@ -59,6 +59,42 @@ type JobSpec struct {
 	Container             ContainerInfo             `json:"container"`
 	ServiceEnvs           []bindTypes.ServiceEnvVar `json:"-"`
 	Envs                  []bindTypes.EnvVar        `json:"envs"`
+	// OnSuccess and OnFailure are the jobs triggered when a run of this job
+	// succeeds or fails, forming a workflow tracked by a single event.
+	OnSuccess []JobDependent `json:"onSuccess,omitempty"`
+	OnFailure []JobDependent `json:"onFailure,omitempty"`
+}
+
+// JobDependent is a job triggered by the result of another job, Envs are
+// added to its envs only for the triggered run.
+type JobDependent struct {
+	Job  string             `json:"job"`
+	Envs []bindTypes.EnvVar `json:"envs,omitempty"`
+}
+
+// TriggerOpts changes how a single run of a job is started.
+type TriggerOpts struct {
+	// Envs are added to the job envs only for the triggered run.
+	Envs []bindTypes.EnvVar `json:"envs,omitempty"`
+	// Command replaces the job container command.
+	Command []string `json:"command,omitempty"`
+	// Image replaces the job image, only the image tag may differ from the
+	// job image.
+	Image string `json:"image,omitempty"`
+	// ActiveDeadlineSeconds replaces the job active deadline.
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+	// Workflow is the ID of the workflow the triggered run is part of.
+	Workflow string `json:"workflow,omitempty"`
+}
+
+// HasOverrides returns whether the triggered run differs from the job
+// definition. The envs of a workflow run are defined by its workflow, so
+// they're not considered overrides.
+func (o TriggerOpts) HasOverrides() bool {
+	if len(o.Command) > 0 || o.Image != "" || o.ActiveDeadlineSeconds != nil {
+		return true
+	}
+	return o.Workflow == "" && len(o.Envs) > 0
 }
 
 type Filter struct {
@@ -87,7 +123,7 @@ type JobService interface {
 	GetByName(ctx context.Context, name string) (*Job, error)
 	List(ctx context.Context, filter *Filter) ([]Job, error)
 	RemoveJob(ctx context.Context, job *Job) error
-	Trigger(ctx context.Context, job *Job) error
+	Trigger(ctx context.Context, job *Job, opts TriggerOpts) (string, error)
 	UpdateJob(ctx context.Context, newJob, oldJob *Job, user *authTypes.User) error
 	AddServiceEnv(ctx context.Context, job *Job, addArgs AddInstanceArgs) error
 	RemoveServiceEnv(ctx context.Context, job *Job, removeArgs RemoveInstanceArgs) error
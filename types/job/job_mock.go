@@ -20,7 +20,7 @@ type MockJobService struct {
 	OnList             func(*Filter) ([]Job, error)
 	OnRemoveJob        func(*Job) error
 	OnRemoveJobProv    func(*Job) error
-	OnTrigger          func(*Job) error
+	OnTrigger          func(*Job, TriggerOpts) (string, error)
 	OnAddServiceEnv    func(*Job, AddInstanceArgs) error
 	OnRemoveServiceEnv func(*Job, RemoveInstanceArgs) error
 	OnUpdateJob        func(*Job, *Job, *authTypes.User) error
@@ -66,11 +66,11 @@ func (m *MockJobService) RemoveJob(ctx context.Context, job *Job) error {
 	return m.OnRemoveJob(job)
 }
 
-func (m *MockJobService) Trigger(ctx context.Context, job *Job) error {
+func (m *MockJobService) Trigger(ctx context.Context, job *Job, opts TriggerOpts) (string, error) {
 	if m.OnTrigger == nil {
-		return nil
+		return "", nil
 	}
-	return m.OnTrigger(job)
+	return m.OnTrigger(job, opts)
 }
 
 func (m *MockJobService) UpdateJob(ctx context.Context, newJob, oldJob *Job, user *authTypes.User) error {
@@ -128,3 +128,32 @@ func (m *MockJobService) Deploy(ctx context.Context, opts DeployOptions, job *Jo
 	}
 	return m.OnDeploy(ctx, opts, job, output)
 }
+
+var _ JobRunService = &MockJobRunService{}
+
+type MockJobRunService struct {
+	OnSaveRun  func(*Run) error
+	OnListRuns func(string, RunFilter) ([]Run, error)
+	OnGetRun   func(string, string) (*Run, error)
+}
+
+func (m *MockJobRunService) SaveRun(ctx context.Context, run *Run) error {
+	if m.OnSaveRun == nil {
+		return nil
+	}
+	return m.OnSaveRun(run)
+}
+
+func (m *MockJobRunService) ListRuns(ctx context.Context, jobName string, filter RunFilter) ([]Run, error) {
+	if m.OnListRuns == nil {
+		return nil, nil
+	}
+	return m.OnListRuns(jobName, filter)
+}
+
+func (m *MockJobRunService) GetRun(ctx context.Context, jobName, id string) (*Run, error) {
+	if m.OnGetRun == nil {
+		return nil, ErrJobRunNotFound
+	}
+	return m.OnGetRun(jobName, id)
+}
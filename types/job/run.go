@@ -0,0 +1,74 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package job
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrJobRunNotFound = errors.New("job run not found")
+
+type RunTrigger string
+
+const (
+	// RunTriggerCron is a run started by the job schedule.
+	RunTriggerCron = RunTrigger("cron")
+	// RunTriggerManual is a run started directly in the cluster, without
+	// going through tsuru.
+	RunTriggerManual = RunTrigger("manual")
+	// RunTriggerAPI is a run started by the tsuru API.
+	RunTriggerAPI = RunTrigger("api")
+	// RunTriggerAdHoc is a run started by the tsuru API overriding parts of
+	// the job definition.
+	RunTriggerAdHoc = RunTrigger("adhoc")
+)
+
+type RunStatus string
+
+const (
+	RunStatusRunning   = RunStatus("running")
+	RunStatusSucceeded = RunStatus("succeeded")
+	RunStatusFailed    = RunStatus("failed")
+)
+
+// Run is a single execution of a job. Runs are kept after the units running
+// them are removed from the cluster.
+type Run struct {
+	ID        string     `json:"id" bson:"_id"`
+	Job       string     `json:"job"`
+	Unit      string     `json:"unit"`
+	Pool      string     `json:"pool"`
+	Cluster   string     `json:"cluster,omitempty"`
+	Workflow  string     `json:"workflow,omitempty"`
+	Trigger   RunTrigger `json:"trigger"`
+	Status    RunStatus  `json:"status"`
+	StartTime time.Time  `json:"startTime"`
+	EndTime   *time.Time `json:"endTime,omitempty"`
+	// Duration is the run duration in seconds, only set once it ends.
+	Duration float64 `json:"duration,omitempty"`
+	ExitCode *int32  `json:"exitCode,omitempty"`
+	// Reason and Message explain why a failed run was stopped, while
+	// UnitReason holds the termination reason of its last failed unit, like
+	// OOMKilled.
+	Reason     string `json:"reason,omitempty"`
+	Message    string `json:"message,omitempty"`
+	UnitReason string `json:"unitReason,omitempty"`
+	// LogsURL points to the job logs during the run, it's filled by the API
+	// and never stored.
+	LogsURL string `json:"logsURL,omitempty" bson:"-"`
+}
+
+type RunFilter struct {
+	Status RunStatus
+	Limit  int
+}
+
+type JobRunService interface {
+	SaveRun(ctx context.Context, run *Run) error
+	ListRuns(ctx context.Context, jobName string, filter RunFilter) ([]Run, error)
+	GetRun(ctx context.Context, jobName, id string) (*Run, error)
+}
@@ -0,0 +1,44 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package job
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrWorkflowNotFound  = errors.New("workflow not found")
+	ErrDependencyCycle   = errors.New("job dependencies must not form a cycle")
+	ErrDependencyOnSelf  = errors.New("a job cannot depend on itself")
+	ErrInvalidDependency = errors.New("job dependency must have a job name")
+)
+
+// Workflow is a chain of job runs started by a run of a job with dependents.
+type Workflow struct {
+	ID        string         `json:"id" bson:"_id"`
+	Job       string         `json:"job"`
+	EventID   string         `json:"eventID"`
+	Status    RunStatus      `json:"status"`
+	Steps     []WorkflowStep `json:"steps"`
+	StartTime time.Time      `json:"startTime"`
+	EndTime   *time.Time     `json:"endTime,omitempty"`
+}
+
+// WorkflowStep is a single run in a workflow, Parent is the index of the
+// step whose result triggered it, or -1 for the first step.
+type WorkflowStep struct {
+	Job       string     `json:"job"`
+	Run       string     `json:"run,omitempty"`
+	Parent    int        `json:"parent"`
+	Status    RunStatus  `json:"status"`
+	Error     string     `json:"error,omitempty"`
+	StartTime time.Time  `json:"startTime"`
+	EndTime   *time.Time `json:"endTime,omitempty"`
+}
+
+func (s *WorkflowStep) Finished() bool {
+	return s.Status == RunStatusSucceeded || s.Status == RunStatusFailed
+}
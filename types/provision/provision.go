@@ -7,9 +7,10 @@ package provision
 import "github.com/tsuru/tsuru/types/router"
 
 type TsuruYamlData struct {
-	Hooks       *TsuruYamlHooks            `json:"hooks,omitempty" bson:",omitempty"`
-	Healthcheck *TsuruYamlHealthcheck      `json:"healthcheck,omitempty" bson:",omitempty"`
-	Kubernetes  *TsuruYamlKubernetesConfig `json:"kubernetes,omitempty" bson:",omitempty"`
+	Hooks        *TsuruYamlHooks            `json:"hooks,omitempty" bson:",omitempty"`
+	Healthcheck  *TsuruYamlHealthcheck      `json:"healthcheck,omitempty" bson:",omitempty"`
+	Verification *TsuruYamlVerification     `json:"verification,omitempty" bson:",omitempty"`
+	Kubernetes   *TsuruYamlKubernetesConfig `json:"kubernetes,omitempty" bson:",omitempty"`
 }
 
 type TsuruYamlHooks struct {
@@ -39,6 +40,23 @@ type TsuruYamlHealthcheck struct {
 	DeployTimeoutSeconds int               `json:"deploy_timeout_seconds,omitempty" yaml:"deploy_timeout_seconds" bson:"deploy_timeout_seconds,omitempty"`
 }
 
+// TsuruYamlVerification configures the post deploy verification window, the
+// new version is sampled during the window and automatically rolled back when
+// the number of failed samples is greater than AllowedFailures.
+type TsuruYamlVerification struct {
+	WindowSeconds   int                              `json:"window_seconds,omitempty" yaml:"window_seconds" bson:"window_seconds,omitempty"`
+	IntervalSeconds int                              `json:"interval_seconds,omitempty" yaml:"interval_seconds" bson:"interval_seconds,omitempty"`
+	AllowedFailures int                              `json:"allowed_failures,omitempty" yaml:"allowed_failures" bson:"allowed_failures,omitempty"`
+	MaxRestarts     int                              `json:"max_restarts,omitempty" yaml:"max_restarts" bson:"max_restarts,omitempty"`
+	MinReadyPercent int                              `json:"min_ready_percent,omitempty" yaml:"min_ready_percent" bson:"min_ready_percent,omitempty"`
+	Prometheus      *TsuruYamlVerificationPrometheus `json:"prometheus,omitempty" bson:",omitempty"`
+}
+
+type TsuruYamlVerificationPrometheus struct {
+	Query     string  `json:"query"`
+	Threshold float64 `json:"threshold"`
+}
+
 type TsuruYamlKubernetesConfig struct {
 	Groups map[string]TsuruYamlKubernetesGroup `json:"groups,omitempty"`
 }
@@ -8,6 +8,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 )
 
 type Quota struct {
@@ -47,10 +48,27 @@ type QuotaStorage interface {
 type QuotaExceededError struct {
 	Requested uint
 	Available uint
+
+	// Team, Pool and Resources are only set when a resource quota is
+	// exceeded, describing each resource over its limit.
+	Team      string
+	Pool      string
+	Resources []ResourceExceeded
 }
 
 func (err *QuotaExceededError) Error() string {
-	return fmt.Sprintf("Quota exceeded. Available: %d, Requested: %d.", err.Available, err.Requested)
+	if len(err.Resources) == 0 {
+		return fmt.Sprintf("Quota exceeded. Available: %d, Requested: %d.", err.Available, err.Requested)
+	}
+	scope := fmt.Sprintf("team %q", err.Team)
+	if err.Pool != "" {
+		scope += fmt.Sprintf(" in pool %q", err.Pool)
+	}
+	resources := make([]string, len(err.Resources))
+	for i, r := range err.Resources {
+		resources[i] = r.String()
+	}
+	return fmt.Sprintf("Quota exceeded for %s: %s.", scope, strings.Join(resources, ", "))
 }
 
 var (
@@ -9,6 +9,9 @@ import "context"
 var (
 	_ QuotaStorage = &MockQuotaStorage{}
 	_ QuotaService = &MockQuotaService{}
+
+	_ ResourceQuotaStorage = &MockResourceQuotaStorage{}
+	_ ResourceQuotaService = &MockResourceQuotaService{}
 )
 
 type MockQuotaStorage struct {
@@ -60,3 +63,68 @@ func (m *MockQuotaService) Set(ctx context.Context, item QuotaItem, quantity int
 func (m *MockQuotaService) Get(ctx context.Context, item QuotaItem) (*Quota, error) {
 	return m.OnGet(item)
 }
+
+type MockResourceQuotaStorage struct {
+	OnUpsert     func(ResourceQuota) error
+	OnGet        func(string, string) (*ResourceQuota, error)
+	OnFindByTeam func(string) ([]ResourceQuota, error)
+	OnDelete     func(string, string) error
+}
+
+func (m *MockResourceQuotaStorage) Upsert(ctx context.Context, q ResourceQuota) error {
+	return m.OnUpsert(q)
+}
+
+func (m *MockResourceQuotaStorage) Get(ctx context.Context, team, pool string) (*ResourceQuota, error) {
+	return m.OnGet(team, pool)
+}
+
+func (m *MockResourceQuotaStorage) FindByTeam(ctx context.Context, team string) ([]ResourceQuota, error) {
+	return m.OnFindByTeam(team)
+}
+
+func (m *MockResourceQuotaStorage) Delete(ctx context.Context, team, pool string) error {
+	return m.OnDelete(team, pool)
+}
+
+type MockResourceQuotaService struct {
+	OnSet       func(ResourceQuota) error
+	OnGet       func(string, string) (*ResourceQuotaInfo, error)
+	OnList      func(string) ([]ResourceQuotaInfo, error)
+	OnDelete    func(string, string) error
+	OnCheck     func(string, string, ResourceList) error
+	OnCheckPool func(string, string, ResourceList) error
+}
+
+func (m *MockResourceQuotaService) Set(ctx context.Context, q ResourceQuota) error {
+	return m.OnSet(q)
+}
+
+func (m *MockResourceQuotaService) Get(ctx context.Context, team, pool string) (*ResourceQuotaInfo, error) {
+	return m.OnGet(team, pool)
+}
+
+func (m *MockResourceQuotaService) List(ctx context.Context, team string) ([]ResourceQuotaInfo, error) {
+	if m.OnList == nil {
+		return nil, nil
+	}
+	return m.OnList(team)
+}
+
+func (m *MockResourceQuotaService) Delete(ctx context.Context, team, pool string) error {
+	return m.OnDelete(team, pool)
+}
+
+func (m *MockResourceQuotaService) Check(ctx context.Context, team, pool string, requested ResourceList) error {
+	if m.OnCheck == nil {
+		return nil
+	}
+	return m.OnCheck(team, pool, requested)
+}
+
+func (m *MockResourceQuotaService) CheckPool(ctx context.Context, team, pool string, requested ResourceList) error {
+	if m.OnCheckPool == nil {
+		return nil
+	}
+	return m.OnCheckPool(team, pool, requested)
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const (
+	ResourceCPU     = "cpu"
+	ResourceMemory  = "memory"
+	ResourceStorage = "storage"
+)
+
+var ErrResourceQuotaNotFound = errors.New("resource quota not found")
+
+// ResourceList holds amounts of compute and storage capacity. CPU is
+// measured in millicores, memory and storage in bytes. In quota limits, a
+// negative value means the resource is unlimited.
+type ResourceList struct {
+	MilliCPU int64 `json:"milliCPU"`
+	Memory   int64 `json:"memory"`
+	Storage  int64 `json:"storage"`
+}
+
+// UnlimitedResources is the ResourceList any new resource quota copies its
+// limits from.
+var UnlimitedResources = ResourceList{MilliCPU: -1, Memory: -1, Storage: -1}
+
+func (l ResourceList) Add(o ResourceList) ResourceList {
+	return ResourceList{
+		MilliCPU: l.MilliCPU + o.MilliCPU,
+		Memory:   l.Memory + o.Memory,
+		Storage:  l.Storage + o.Storage,
+	}
+}
+
+func (l ResourceList) Sub(o ResourceList) ResourceList {
+	return ResourceList{
+		MilliCPU: l.MilliCPU - o.MilliCPU,
+		Memory:   l.Memory - o.Memory,
+		Storage:  l.Storage - o.Storage,
+	}
+}
+
+// ResourceQuota limits the capacity used by apps and volumes owned by a
+// team. When Pool is empty the quota applies to the team in every pool.
+type ResourceQuota struct {
+	Team   string       `json:"team"`
+	Pool   string       `json:"pool,omitempty"`
+	Limits ResourceList `json:"limits"`
+}
+
+type ResourceQuotaInfo struct {
+	ResourceQuota
+	InUse ResourceList `json:"inUse"`
+}
+
+// ResourceUsageFunc returns the capacity currently consumed by a team,
+// restricted to pool when it's not empty.
+type ResourceUsageFunc func(ctx context.Context, team, pool string) (ResourceList, error)
+
+type ResourceQuotaService interface {
+	Set(ctx context.Context, q ResourceQuota) error
+	Get(ctx context.Context, team, pool string) (*ResourceQuotaInfo, error)
+	List(ctx context.Context, team string) ([]ResourceQuotaInfo, error)
+	Delete(ctx context.Context, team, pool string) error
+	// Check returns a *QuotaExceededError when consuming requested more
+	// resources would exceed any quota of the team in pool.
+	Check(ctx context.Context, team, pool string, requested ResourceList) error
+	// CheckPool is like Check but only considers the quota of the team in
+	// pool, for capacity moved between pools of the same team.
+	CheckPool(ctx context.Context, team, pool string, requested ResourceList) error
+}
+
+type ResourceQuotaStorage interface {
+	Upsert(ctx context.Context, q ResourceQuota) error
+	Get(ctx context.Context, team, pool string) (*ResourceQuota, error)
+	FindByTeam(ctx context.Context, team string) ([]ResourceQuota, error)
+	Delete(ctx context.Context, team, pool string) error
+}
+
+// ResourceExceeded describes a single resource over its quota limit.
+type ResourceExceeded struct {
+	Resource  string `json:"resource"`
+	Limit     int64  `json:"limit"`
+	InUse     int64  `json:"inUse"`
+	Requested int64  `json:"requested"`
+}
+
+func (r ResourceExceeded) String() string {
+	format := formatBytes
+	if r.Resource == ResourceCPU {
+		format = formatMilliCPU
+	}
+	return fmt.Sprintf("%s (limit: %s, in use: %s, requested: %s)", r.Resource, format(r.Limit), format(r.InUse), format(r.Requested))
+}
+
+func formatMilliCPU(v int64) string {
+	return fmt.Sprintf("%dm", v)
+}
+
+func formatBytes(v int64) string {
+	const (
+		mi = 1024 * 1024
+		gi = 1024 * mi
+	)
+	if v%gi == 0 {
+		return fmt.Sprintf("%dGi", v/gi)
+	}
+	if v >= gi {
+		return fmt.Sprintf("%.2fGi", float64(v)/gi)
+	}
+	return fmt.Sprintf("%dMi", v/mi)
+}
@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 var (
@@ -25,10 +26,13 @@ type VolumePlan struct {
 	Opts map[string]interface{}
 }
 
+// VolumeBindID identifies a bind of a volume to either an app or a job, only
+// one of App and Job is set.
 type VolumeBindID struct {
 	App        string
 	MountPoint string
 	Volume     string
+	Job        string `bson:",omitempty" json:",omitempty"`
 }
 
 type VolumeBind struct {
@@ -46,6 +50,25 @@ type Volume struct {
 	Opts      map[string]string `bson:",omitempty"`
 }
 
+// Capacity returns the storage capacity of the volume in bytes, as defined
+// by the capacity opt of the volume or of its plan. Volumes without a
+// capacity, like ephemeral ones, have zero capacity.
+func (v *Volume) Capacity() (int64, error) {
+	raw, ok := v.Opts["capacity"]
+	if !ok {
+		planCapacity, _ := v.Plan.Opts["capacity"].(string)
+		raw = planCapacity
+	}
+	if raw == "" {
+		return 0, nil
+	}
+	capacity, err := resource.ParseQuantity(raw)
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to parse `capacity` opt")
+	}
+	return capacity.Value(), nil
+}
+
 func (v *Volume) UnmarshalPlan(result interface{}) error {
 	jsonData, err := json.Marshal(v.Plan.Opts)
 	if err != nil {
@@ -57,6 +80,7 @@ func (v *Volume) UnmarshalPlan(result interface{}) error {
 type BindOpts struct {
 	Volume     *Volume
 	AppName    string
+	JobName    string
 	MountPoint string
 	ReadOnly   bool
 }
@@ -72,6 +96,7 @@ type VolumeService interface {
 	Update(ctx context.Context, v *Volume) error
 	Delete(ctx context.Context, v *Volume) error
 	ListByApp(ctx context.Context, appName string) ([]Volume, error)
+	ListByJob(ctx context.Context, jobName string) ([]Volume, error)
 	ListByFilter(ctx context.Context, f *Filter) ([]Volume, error)
 	ListPlans(ctx context.Context) (map[string][]VolumePlan, error)
 	CheckPoolVolumeConstraints(ctx context.Context, volume Volume) error
@@ -80,6 +105,9 @@ type VolumeService interface {
 	BindApp(ctx context.Context, opts *BindOpts) error
 	UnbindApp(ctx context.Context, opts *BindOpts) error
 	BindsForApp(ctx context.Context, v *Volume, appName string) ([]VolumeBind, error)
+	BindJob(ctx context.Context, opts *BindOpts) error
+	UnbindJob(ctx context.Context, opts *BindOpts) error
+	BindsForJob(ctx context.Context, v *Volume, jobName string) ([]VolumeBind, error)
 	Binds(ctx context.Context, v *Volume) ([]VolumeBind, error)
 }
 
@@ -93,6 +121,7 @@ type VolumeStorage interface {
 	RemoveBind(ctx context.Context, id VolumeBindID) error
 	Binds(ctx context.Context, volumeName string) ([]VolumeBind, error)
 	BindsForApp(ctx context.Context, volumeName, appName string) ([]VolumeBind, error)
+	BindsForJob(ctx context.Context, volumeName, jobName string) ([]VolumeBind, error)
 
 	RenameTeam(ctx context.Context, oldName, newName string) error
 }
@@ -22,6 +22,7 @@ type MockVolumeStorage struct {
 	OnRemoveBind   func(id VolumeBindID) error
 	OnBinds        func(volumeName string) ([]VolumeBind, error)
 	OnBindsForApp  func(volumeName, appName string) ([]VolumeBind, error)
+	OnBindsForJob  func(volumeName, jobName string) ([]VolumeBind, error)
 }
 
 func (m *MockVolumeStorage) Save(ctx context.Context, v *Volume) error {
@@ -144,6 +145,20 @@ func (m *MockVolumeStorage) BindsForApp(ctx context.Context, volumeName, appName
 	return m.OnBindsForApp(volumeName, appName)
 }
 
+func (m *MockVolumeStorage) BindsForJob(ctx context.Context, volumeName, jobName string) ([]VolumeBind, error) {
+	if m.OnBindsForJob == nil {
+		binds := []VolumeBind{}
+		for _, bind := range m.binds {
+			if bind.ID.Job == jobName && (volumeName == "" || bind.ID.Volume == volumeName) {
+				binds = append(binds, bind)
+			}
+		}
+		return binds, nil
+	}
+
+	return m.OnBindsForJob(volumeName, jobName)
+}
+
 func (m *MockVolumeStorage) RenameTeam(ctx context.Context, oldTeam, newTeam string) error {
 	for i := range m.volumes {
 		if m.volumes[i].TeamOwner == oldTeam {
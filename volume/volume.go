@@ -16,6 +16,7 @@ import (
 	"github.com/tsuru/tsuru/provision/pool"
 	"github.com/tsuru/tsuru/servicemanager"
 	"github.com/tsuru/tsuru/storage"
+	quotaTypes "github.com/tsuru/tsuru/types/quota"
 	volumeTypes "github.com/tsuru/tsuru/types/volume"
 	"github.com/tsuru/tsuru/validation"
 )
@@ -66,9 +67,25 @@ func (s *volumeService) Create(ctx context.Context, v *volumeTypes.Volume) error
 		return err
 	}
 
+	err = checkResourceQuota(ctx, v)
+	if err != nil {
+		return err
+	}
+
 	return s.storage.Save(ctx, v)
 }
 
+func checkResourceQuota(ctx context.Context, v *volumeTypes.Volume) error {
+	if servicemanager.ResourceQuota == nil {
+		return nil
+	}
+	capacity, err := v.Capacity()
+	if err != nil {
+		return &tsuruErrors.ValidationError{Message: err.Error()}
+	}
+	return servicemanager.ResourceQuota.Check(ctx, v.TeamOwner, v.Pool, quotaTypes.ResourceList{Storage: capacity})
+}
+
 func (s *volumeService) Update(ctx context.Context, v *volumeTypes.Volume) error {
 	err := s.validateProvisioner(ctx, v)
 	if err != nil {
@@ -91,6 +108,18 @@ func (s *volumeService) ListByApp(ctx context.Context, appName string) ([]volume
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
+	return s.listByBinds(ctx, binds)
+}
+
+func (s *volumeService) ListByJob(ctx context.Context, jobName string) ([]volumeTypes.Volume, error) {
+	binds, err := s.storage.BindsForJob(ctx, "", jobName)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return s.listByBinds(ctx, binds)
+}
+
+func (s *volumeService) listByBinds(ctx context.Context, binds []volumeTypes.VolumeBind) ([]volumeTypes.Volume, error) {
 	if len(binds) == 0 {
 		return []volumeTypes.Volume{}, nil
 	}
@@ -174,6 +203,31 @@ func (s *volumeService) UnbindApp(ctx context.Context, opts *volumeTypes.BindOpt
 	})
 }
 
+func (s *volumeService) BindJob(ctx context.Context, opts *volumeTypes.BindOpts) error {
+	bind := &volumeTypes.VolumeBind{
+		ID: volumeTypes.VolumeBindID{
+			Job:        opts.JobName,
+			MountPoint: opts.MountPoint,
+			Volume:     opts.Volume.Name,
+		},
+		ReadOnly: opts.ReadOnly,
+	}
+
+	err := s.storage.InsertBind(ctx, bind)
+	if err == volumeTypes.ErrVolumeBindAlreadyExists {
+		return volumeTypes.ErrVolumeAlreadyBound
+	}
+	return err
+}
+
+func (s *volumeService) UnbindJob(ctx context.Context, opts *volumeTypes.BindOpts) error {
+	return s.storage.RemoveBind(ctx, volumeTypes.VolumeBindID{
+		Job:        opts.JobName,
+		Volume:     opts.Volume.Name,
+		MountPoint: opts.MountPoint,
+	})
+}
+
 func (s *volumeService) Binds(ctx context.Context, v *volumeTypes.Volume) ([]volumeTypes.VolumeBind, error) {
 	if v.Binds != nil {
 		return v.Binds, nil
@@ -209,6 +263,24 @@ func (s *volumeService) BindsForApp(ctx context.Context, v *volumeTypes.Volume,
 	return binds, nil
 }
 
+func (s *volumeService) BindsForJob(ctx context.Context, v *volumeTypes.Volume, jobName string) ([]volumeTypes.VolumeBind, error) {
+	if v != nil && v.Binds != nil {
+		binds := []volumeTypes.VolumeBind{}
+		for _, bind := range v.Binds {
+			if bind.ID.Job == jobName {
+				binds = append(binds, bind)
+			}
+		}
+		return binds, nil
+	}
+
+	var volumeName string
+	if v != nil {
+		volumeName = v.Name
+	}
+	return s.storage.BindsForJob(ctx, volumeName, jobName)
+}
+
 func (s *volumeService) ListPlans(ctx context.Context) (map[string][]volumeTypes.VolumePlan, error) {
 	plans := map[string][]volumeTypes.VolumePlan{}
 	plansRaw, err := config.Get("volume-plans")
@@ -19,6 +19,7 @@ import (
 	"github.com/tsuru/tsuru/servicemanager"
 	_ "github.com/tsuru/tsuru/storage/mongodb"
 	authTypes "github.com/tsuru/tsuru/types/auth"
+	quotaTypes "github.com/tsuru/tsuru/types/quota"
 	volumeTypes "github.com/tsuru/tsuru/types/volume"
 	check "gopkg.in/check.v1"
 )
@@ -533,6 +534,66 @@ func (s *S) TestVolumeUnbindApp(c *check.C) {
 	c.Assert(err, check.Equals, volumeTypes.ErrVolumeBindNotFound)
 }
 
+func (s *S) TestVolumeBindUnbindJob(c *check.C) {
+	vs := &volumeService{
+		storage: &volumeTypes.MockVolumeStorage{},
+	}
+	v := volumeTypes.Volume{
+		Name:      "v1",
+		Plan:      volumeTypes.VolumePlan{Name: "p1"},
+		Pool:      "mypool",
+		TeamOwner: "myteam",
+	}
+	err := vs.Create(context.TODO(), &v)
+	c.Assert(err, check.IsNil)
+	err = vs.BindApp(context.TODO(), &volumeTypes.BindOpts{
+		Volume:     &v,
+		AppName:    "myjob",
+		MountPoint: "/mnt1",
+	})
+	c.Assert(err, check.IsNil)
+	err = vs.BindJob(context.TODO(), &volumeTypes.BindOpts{
+		Volume:     &v,
+		JobName:    "myjob",
+		MountPoint: "/mnt1",
+		ReadOnly:   true,
+	})
+	c.Assert(err, check.IsNil)
+	err = vs.BindJob(context.TODO(), &volumeTypes.BindOpts{
+		Volume:     &v,
+		JobName:    "myjob",
+		MountPoint: "/mnt1",
+	})
+	c.Assert(err, check.Equals, volumeTypes.ErrVolumeAlreadyBound)
+	binds, err := vs.BindsForJob(context.TODO(), nil, "myjob")
+	c.Assert(err, check.IsNil)
+	c.Assert(binds, check.DeepEquals, []volumeTypes.VolumeBind{
+		{ID: volumeTypes.VolumeBindID{Job: "myjob", MountPoint: "/mnt1", Volume: "v1"}, ReadOnly: true},
+	})
+	jobVolumes, err := vs.ListByJob(context.TODO(), "myjob")
+	c.Assert(err, check.IsNil)
+	c.Assert(jobVolumes, check.HasLen, 1)
+	c.Assert(jobVolumes[0].Name, check.Equals, "v1")
+	err = vs.UnbindJob(context.TODO(), &volumeTypes.BindOpts{
+		Volume:     &v,
+		JobName:    "myjob",
+		MountPoint: "/mnt1",
+	})
+	c.Assert(err, check.IsNil)
+	binds, err = vs.BindsForJob(context.TODO(), &v, "myjob")
+	c.Assert(err, check.IsNil)
+	c.Assert(binds, check.HasLen, 0)
+	binds, err = vs.BindsForApp(context.TODO(), &v, "myjob")
+	c.Assert(err, check.IsNil)
+	c.Assert(binds, check.HasLen, 1)
+	err = vs.UnbindJob(context.TODO(), &volumeTypes.BindOpts{
+		Volume:     &v,
+		JobName:    "myjob",
+		MountPoint: "/mnt1",
+	})
+	c.Assert(err, check.Equals, volumeTypes.ErrVolumeBindNotFound)
+}
+
 func (s *S) TestListByApp(c *check.C) {
 	vs := &volumeService{
 		storage: &volumeTypes.MockVolumeStorage{},
@@ -825,3 +886,29 @@ func (s *S) TestRenameTeam(c *check.C) {
 	c.Assert(vols[0].TeamOwner, check.Equals, "mynewteam")
 	c.Assert(vols[1].TeamOwner, check.Equals, "otherteam")
 }
+
+func (s *S) TestVolumeCreateResourceQuotaExceeded(c *check.C) {
+	var requested []quotaTypes.ResourceList
+	servicemanager.ResourceQuota = &quotaTypes.MockResourceQuotaService{
+		OnCheck: func(team, pool string, r quotaTypes.ResourceList) error {
+			c.Assert(team, check.Equals, "myteam")
+			c.Assert(pool, check.Equals, "mypool")
+			requested = append(requested, r)
+			return &quotaTypes.QuotaExceededError{Team: team}
+		},
+	}
+	defer func() { servicemanager.ResourceQuota = nil }()
+	vs := &volumeService{
+		storage: &volumeTypes.MockVolumeStorage{},
+	}
+	v := volumeTypes.Volume{
+		Name:      "v1",
+		Plan:      volumeTypes.VolumePlan{Name: "p1"},
+		Pool:      "mypool",
+		TeamOwner: "myteam",
+		Opts:      map[string]string{"capacity": "2Gi"},
+	}
+	err := vs.Create(context.TODO(), &v)
+	c.Assert(err, check.FitsTypeOf, &quotaTypes.QuotaExceededError{})
+	c.Assert(requested, check.DeepEquals, []quotaTypes.ResourceList{{Storage: 2 * 1024 * 1024 * 1024}})
+}